@@ -14,8 +14,10 @@ import (
 	"bamboo-rescue/internal/repository"
 	"bamboo-rescue/internal/router"
 	"bamboo-rescue/internal/service"
+	"bamboo-rescue/pkg/clock"
 	"bamboo-rescue/pkg/database"
 	"bamboo-rescue/pkg/jwt"
+	"bamboo-rescue/pkg/mailer"
 	"bamboo-rescue/pkg/migration"
 	"bamboo-rescue/pkg/storage"
 
@@ -83,10 +85,11 @@ func main() {
 	// Initialize JWT service
 	jwtService := jwt.NewService(&cfg.JWT)
 
-	// Initialize S3 storage
-	storageClient, err := storage.NewS3Client(cfg, log)
+	// Initialize storage
+	storageClient, err := storage.NewClient(cfg, log)
 	if err != nil {
-		log.Warn("Failed to initialize S3 client, using mock storage", zap.Error(err))
+		log.Fatal("Failed to initialize storage backend",
+			zap.String("backend", cfg.Storage.Backend), zap.Error(err))
 	}
 
 	// Initialize repositories
@@ -95,8 +98,12 @@ func main() {
 	// Initialize services
 	services := initServices(repos, jwtService, storageClient, cfg, log)
 
+	// Start background job workers once every handler is registered
+	services.Outbox.Start()
+	services.Email.Start()
+
 	// Initialize handlers
-	handlers := initHandlers(services)
+	handlers := initHandlers(services, storageClient, cfg)
 
 	// Setup router
 	r := router.Setup(cfg, handlers, jwtService, log)
@@ -133,6 +140,15 @@ func main() {
 		log.Fatal("Server forced to shutdown", zap.Error(err))
 	}
 
+	if err := services.Email.Shutdown(ctx); err != nil {
+		log.Warn("Email digest scheduler did not stop in time", zap.Error(err))
+	}
+
+	// Let running jobs finish; queued ones wait in the database
+	if err := services.Outbox.Shutdown(ctx); err != nil {
+		log.Warn("Background jobs did not finish before shutdown", zap.Error(err))
+	}
+
 	log.Info("Server exited properly")
 }
 
@@ -149,6 +165,12 @@ type Repositories struct {
 	Case         repository.CaseRepository
 	Notification repository.NotificationRepository
 	Media        repository.MediaRepository
+	MediaUpload  repository.MediaUploadRepository
+	Outbox       repository.OutboxRepository
+	GeocodeCache repository.GeocodeCacheRepository
+	AlertZone    repository.AlertZoneRepository
+	Event        repository.DisasterEventRepository
+	Tx           repository.Transactor
 }
 
 func initRepositories(db *gorm.DB) *Repositories {
@@ -157,6 +179,12 @@ func initRepositories(db *gorm.DB) *Repositories {
 		Case:         repository.NewCaseRepository(db),
 		Notification: repository.NewNotificationRepository(db),
 		Media:        repository.NewMediaRepository(db),
+		MediaUpload:  repository.NewMediaUploadRepository(db),
+		Outbox:       repository.NewOutboxRepository(db),
+		GeocodeCache: repository.NewGeocodeCacheRepository(db),
+		AlertZone:    repository.NewAlertZoneRepository(db),
+		Event:        repository.NewDisasterEventRepository(db),
+		Tx:           repository.NewTransactor(db),
 	}
 }
 
@@ -166,9 +194,17 @@ type Services struct {
 	User         service.UserService
 	Case         service.CaseService
 	Media        service.MediaService
+	Tus          service.TusService
 	Notification service.NotificationService
 	Geocode      service.GeocodeService
+	Location     service.LocationService
+	AlertZone    service.AlertZoneService
+	Event        service.DisasterEventService
 	FCM          service.FCMService
+	WebPush      service.WebPushService
+	Push         service.PushService
+	Email        service.EmailService
+	Outbox       service.OutboxService
 }
 
 func initServices(repos *Repositories, jwtSvc *jwt.Service, storageClient storage.Client, cfg *config.Config, log *zap.Logger) *Services {
@@ -178,26 +214,64 @@ func initServices(repos *Repositories, jwtSvc *jwt.Service, storageClient storag
 		log.Warn("Failed to initialize FCM service", zap.Error(err))
 	}
 
+	webPushSvc := service.NewWebPushService(cfg, repos.User, log)
+	pushSvc := service.NewPushService(repos.User, fcmSvc, webPushSvc, log)
+
 	notificationSvc := service.NewNotificationService(repos.Notification, log)
+	mediaSvc := service.NewMediaService(cfg, repos.Media, repos.Case, storageClient, log)
+	outboxSvc := service.NewOutboxService(cfg, repos.Outbox, log)
+
+	// Initialize geocoding providers, tried in the configured order
+	geocodeProviders, err := service.NewGeocodeProviders(cfg, log)
+	if err != nil {
+		log.Fatal("Failed to initialize geocoding providers", zap.Error(err))
+	}
+	geocodeSvc := service.NewGeocodeService(cfg, geocodeProviders, repos.GeocodeCache, log)
+	locationSvc := service.NewLocationService(geocodeSvc, log)
+	routingSvc := service.NewRoutingService(cfg, log)
+
+	// Initialize email service
+	emailSvc, err := service.NewEmailService(cfg, mailer.NewSMTPMailer(cfg.Email), repos.User, repos.Case, repos.Tx, outboxSvc, log)
+	if err != nil {
+		log.Fatal("Failed to initialize email service", zap.Error(err))
+	}
+
+	dispatcher := service.NewNotificationDispatcher(cfg, repos.User, repos.Notification, repos.Tx, outboxSvc, pushSvc, emailSvc, clock.Real, log)
+	alertZoneSvc := service.NewAlertZoneService(repos.AlertZone, repos.User, repos.Tx, outboxSvc, dispatcher, log)
+	eventSvc := service.NewDisasterEventService(repos.Event, repos.Case, repos.User, repos.Tx, outboxSvc, log)
 
 	return &Services{
 		Auth:         service.NewAuthService(repos.User, jwtSvc, log),
 		User:         service.NewUserService(repos.User, log),
-		Case:         service.NewCaseService(repos.Case, repos.User, notificationSvc, fcmSvc, log),
-		Media:        service.NewMediaService(repos.Media, storageClient, log),
+		Case:         service.NewCaseService(repos.Case, repos.User, repos.Tx, outboxSvc, dispatcher, mediaSvc, geocodeSvc, locationSvc, routingSvc, alertZoneSvc, eventSvc, jwtSvc, log),
+		Media:        mediaSvc,
+		Tus:          service.NewTusService(cfg, repos.MediaUpload, repos.Case, mediaSvc, storageClient, log),
 		Notification: notificationSvc,
-		Geocode:      service.NewGeocodeService(cfg, log),
+		Geocode:      geocodeSvc,
+		Location:     locationSvc,
+		AlertZone:    alertZoneSvc,
+		Event:        eventSvc,
 		FCM:          fcmSvc,
+		WebPush:      webPushSvc,
+		Push:         pushSvc,
+		Email:        emailSvc,
+		Outbox:       outboxSvc,
 	}
 }
 
-func initHandlers(services *Services) *router.Handlers {
+func initHandlers(services *Services, storageClient storage.Client, cfg *config.Config) *router.Handlers {
 	return &router.Handlers{
 		Auth:         handler.NewAuthHandler(services.Auth),
 		User:         handler.NewUserHandler(services.User),
-		Case:         handler.NewCaseHandler(services.Case),
+		Case:         handler.NewCaseHandler(services.Case, services.AlertZone),
 		Media:        handler.NewMediaHandler(services.Media),
+		Tus:          handler.NewTusHandler(services.Tus, cfg.Tus.ChunkTimeout),
 		Notification: handler.NewNotificationHandler(services.Notification),
-		Geocode:      handler.NewGeocodeHandler(services.Geocode),
+		Geocode:      handler.NewGeocodeHandler(services.Geocode, services.Location),
+		File:         handler.NewFileHandler(storageClient, cfg.Storage.TransferTimeout),
+		Outbox:       handler.NewOutboxHandler(services.Outbox),
+		AlertZone:    handler.NewAlertZoneHandler(services.AlertZone),
+		Event:        handler.NewDisasterEventHandler(services.Event, services.Case),
+		Push:         handler.NewPushHandler(services.WebPush),
 	}
 }
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"bamboo-rescue/internal/config"
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/pkg/database"
+	"bamboo-rescue/pkg/storage"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// storage-migrate copies media objects between storage backends, e.g. from
+// local disk to S3 when moving a deployment off a single machine.
+//
+//	go run ./cmd/storage-migrate -from local -to s3
+//	go run ./cmd/storage-migrate -from s3 -to local -prefix private/ -update-urls
+func main() {
+	from := flag.String("from", "", "source backend (s3, local)")
+	to := flag.String("to", "", "destination backend (s3, local)")
+	prefix := flag.String("prefix", "", "only copy keys with this prefix")
+	overwrite := flag.Bool("overwrite", false, "copy objects that already exist at the destination with the same size")
+	dryRun := flag.Bool("dry-run", false, "list what would be copied without copying")
+	updateURLs := flag.Bool("update-urls", false, "point case_media URLs at the destination backend after copying")
+	flag.Parse()
+
+	if *from == "" || *to == "" || *from == *to {
+		fmt.Fprintln(os.Stderr, "usage: storage-migrate -from <backend> -to <backend> [flags]")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	// Initialize logger
+	log, _ := zap.NewProduction()
+	defer log.Sync()
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load configuration", zap.Error(err))
+	}
+
+	src, err := storage.NewBackend(cfg, *from, log)
+	if err != nil {
+		log.Fatal("Failed to initialize source storage", zap.String("backend", *from), zap.Error(err))
+	}
+	dst, err := storage.NewBackend(cfg, *to, log)
+	if err != nil {
+		log.Fatal("Failed to initialize destination storage", zap.String("backend", *to), zap.Error(err))
+	}
+
+	ctx := context.Background()
+	var copied, skipped, failed int
+	var bytes int64
+
+	err = src.List(ctx, *prefix, func(obj storage.ObjectInfo) error {
+		if !*overwrite {
+			if existing, err := dst.Stat(ctx, obj.Key); err == nil && existing.Size == obj.Size {
+				skipped++
+				return nil
+			}
+		}
+
+		if *dryRun {
+			log.Info("Would copy", zap.String("key", obj.Key), zap.Int64("size", obj.Size))
+			copied++
+			return nil
+		}
+
+		if _, err := storage.Transfer(ctx, src, dst, obj.Key); err != nil {
+			log.Error("Failed to copy object", zap.String("key", obj.Key), zap.Error(err))
+			failed++
+			return nil
+		}
+
+		copied++
+		bytes += obj.Size
+		if copied%100 == 0 {
+			log.Info("Progress", zap.Int("copied", copied), zap.Int64("bytes", bytes))
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatal("Failed to list source objects", zap.Error(err))
+	}
+
+	log.Info("Storage migration finished",
+		zap.String("from", *from),
+		zap.String("to", *to),
+		zap.Int("copied", copied),
+		zap.Int("skipped", skipped),
+		zap.Int("failed", failed),
+		zap.Int64("bytes", bytes),
+		zap.Bool("dry_run", *dryRun),
+	)
+
+	if !*updateURLs || *dryRun {
+		return
+	}
+	if failed > 0 {
+		log.Fatal("Not updating media URLs because some objects failed to copy")
+	}
+
+	// Initialize database
+	db, err := database.NewPostgresDB(&cfg.Database, log)
+	if err != nil {
+		log.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	defer database.Close(db)
+
+	updated, err := rewriteMediaURLs(ctx, db, dst, *prefix)
+	if err != nil {
+		log.Fatal("Failed to update media URLs", zap.Error(err))
+	}
+
+	log.Info("Media URLs updated", zap.Int64("rows", updated))
+}
+
+// rewriteMediaURLs points every media row under prefix at the destination backend
+func rewriteMediaURLs(ctx context.Context, db *gorm.DB, dst storage.Client, prefix string) (int64, error) {
+	var updated int64
+	var batch []entity.CaseMedia
+
+	err := db.WithContext(ctx).
+		Where("storage_key LIKE ?", prefix+"%").
+		FindInBatches(&batch, 500, func(_ *gorm.DB, _ int) error {
+			for _, m := range batch {
+				url := dst.GetURL(m.StorageKey)
+				updates := map[string]interface{}{"url": url}
+				if m.ThumbnailURL != nil && *m.ThumbnailURL == m.URL {
+					updates["thumbnail_url"] = url
+				}
+
+				if err := db.WithContext(ctx).Model(&entity.CaseMedia{}).Where("id = ?", m.ID).Updates(updates).Error; err != nil {
+					return err
+				}
+				updated++
+			}
+			return nil
+		}).Error
+
+	return updated, err
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"bamboo-rescue/pkg/webpush"
+)
+
+// vapid-keys generates the VAPID key pair used to sign web push requests.
+// Keep the private key secret; rotating it invalidates every browser
+// subscription, which must then subscribe again with the new public key.
+//
+//	go run ./cmd/vapid-keys >> .env
+func main() {
+	privateKey, publicKey, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to generate VAPID keys:", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("WEBPUSH_VAPID_PRIVATE_KEY=" + privateKey)
+	fmt.Println("WEBPUSH_VAPID_PUBLIC_KEY=" + publicKey)
+}
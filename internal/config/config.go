@@ -1,6 +1,7 @@
 package config
 
 import (
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -12,8 +13,19 @@ type Config struct {
 	JWT       JWTConfig
 	Firebase  FirebaseConfig
 	FCM       FCMConfig
+	WebPush   WebPushConfig
+	Email     EmailConfig
+	Notify    NotifyConfig
+	Outbox    OutboxConfig
+	Admin     AdminConfig
 	S3        S3Config
+	Storage   StorageConfig
+	Tus       TusConfig
 	Nominatim NominatimConfig
+	Photon    PhotonConfig
+	Pelias    PeliasConfig
+	Geocode   GeocodeConfig
+	Routing   RoutingConfig
 	RateLimit RateLimitConfig
 }
 
@@ -36,6 +48,7 @@ type JWTConfig struct {
 	Secret        string
 	AccessExpiry  time.Duration
 	RefreshExpiry time.Duration
+	ActionExpiry  time.Duration // lifetime of the case action tokens sent in pushes
 }
 
 type FirebaseConfig struct {
@@ -44,6 +57,79 @@ type FirebaseConfig struct {
 
 type FCMConfig struct {
 	CredentialsFile string
+
+	// MaxRetries is how many times a transiently failed send is retried
+	MaxRetries int
+	// RetryBaseDelay is the first retry delay, doubled on each attempt
+	RetryBaseDelay time.Duration
+	// DeepLinkBase prefixes the in-app link sent with each push, e.g.
+	// bamboorescue:// gives bamboorescue://cases/<id>
+	DeepLinkBase string
+}
+
+type WebPushConfig struct {
+	// VAPIDPrivateKey disables web push when empty. Generate a key pair with
+	// go run ./cmd/vapid-keys.
+	VAPIDPrivateKey string
+	// VAPIDPublicKey is optional and checked against the private key
+	VAPIDPublicKey string
+	// Subject is the mailto: or https: contact sent to push services
+	Subject string
+	// TTL is how long push services hold a message for an offline browser
+	TTL time.Duration
+	// LinkBase is the web address case links in web pushes point to
+	LinkBase string
+}
+
+type EmailConfig struct {
+	// SMTPHost disables email when empty. MailHog listens on localhost:1025.
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+	FromName     string
+	// LinkBase is the web address case links in emails point to
+	LinkBase string
+	// DigestHour is the local hour the daily digest goes out
+	DigestHour int
+	// DigestTimezone is the IANA zone DigestHour is in
+	DigestTimezone string
+}
+
+type NotifyConfig struct {
+	// PushBudget is how many pushes a user gets per PushWindow before the
+	// rest are rolled into a summary. Zero disables throttling.
+	PushBudget int
+	PushWindow time.Duration
+	// SummaryDelay is how long after the first held-back push the summary
+	// of everything held back goes out
+	SummaryDelay time.Duration
+}
+
+type OutboxConfig struct {
+	// Workers bounds how many jobs run at once
+	Workers      int
+	PollInterval time.Duration
+	// MaxAttempts is how many times a job runs before it is dead-lettered
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// JobTimeout bounds a single run of a job
+	JobTimeout time.Duration
+	// LockTimeout is how long a claimed job may run before another worker
+	// assumes its worker died and reclaims it
+	LockTimeout time.Duration
+	// Retention is how long finished jobs are kept
+	Retention time.Duration
+}
+
+type AdminConfig struct {
+	// UserIDs lists the users allowed to use admin endpoints
+	UserIDs []string
+	// CoordinatorIDs lists the users who see reporter details in case
+	// exports and manage alert zones. Admins are coordinators too.
+	CoordinatorIDs []string
 }
 
 type S3Config struct {
@@ -53,10 +139,110 @@ type S3Config struct {
 	Bucket    string
 	Region    string
 	PublicURL string
+
+	// PresignExpiry is how long a presigned direct upload URL stays valid
+	PresignExpiry time.Duration
+}
+
+type StorageConfig struct {
+	// Backend selects where media is kept: s3 or local
+	Backend string
+	// LocalDir is where files are kept when no S3 endpoint is configured
+	LocalDir string
+	// LocalURL is the public base URL the API serves local files under
+	LocalURL string
+	// Private serves every media object through short-lived signed URLs
+	Private bool
+	// SignedURLExpiry is how long a signed media URL stays valid
+	SignedURLExpiry time.Duration
+	// SigningSecret signs the URLs of local files. It is kept apart from
+	// the JWT secret so a leak of one does not forge the other.
+	SigningSecret string
+	// TransferTimeout bounds how long a local file upload or download
+	// through a signed URL may take to stream
+	TransferTimeout time.Duration
+}
+
+type TusConfig struct {
+	// SpoolDir buffers incoming chunks until they fill a storage part
+	SpoolDir string
+	// UploadExpiry is how long an idle resumable upload is kept
+	UploadExpiry time.Duration
+	// ChunkTimeout bounds how long a single PATCH may take to stream
+	ChunkTimeout time.Duration
 }
 
 type NominatimConfig struct {
 	URL string
+	// UserAgent identifies the app, as the Nominatim usage policy requires
+	UserAgent string
+	// RequestsPerSecond caps outbound calls across all lookups in this
+	// process; the public server allows 1
+	RequestsPerSecond float64
+	// MaxWait is the longest a lookup queues for its turn before failing
+	// over to the next provider
+	MaxWait time.Duration
+}
+
+type PhotonConfig struct {
+	URL string
+	// RequestsPerSecond caps outbound calls; 0 means unlimited, as on a
+	// self-hosted server
+	RequestsPerSecond float64
+}
+
+type PeliasConfig struct {
+	URL string
+	// APIKey is sent as api_key, for hosted Pelias services
+	APIKey            string
+	RequestsPerSecond float64
+}
+
+type GeocodeConfig struct {
+	// Providers are tried in order until one answers: nominatim, photon,
+	// pelias or offline
+	Providers []string
+	// ProviderTimeout bounds a single call to a provider before failing
+	// over to the next
+	ProviderTimeout time.Duration
+	// BreakerThreshold failures in a row take a provider out of rotation;
+	// it is retried once every BreakerCooldown
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	// CacheSize is how many results are kept in memory in front of the
+	// database cache
+	CacheSize int
+	// ReverseTTL and SearchTTL are how long results stay fresh. Older ones
+	// are only served when the provider fails.
+	ReverseTTL time.Duration
+	SearchTTL  time.Duration
+	// CoordPrecision is how many decimal places coordinates are rounded to
+	// before a reverse lookup; 4 is about 11 m
+	CoordPrecision int
+	// CountryCodes limits searches to these ISO 3166-1 countries
+	CountryCodes []string
+	// SearchBiasKm is the half-width of the box around the requester's
+	// location that searches prefer results from
+	SearchBiasKm float64
+}
+
+type RoutingConfig struct {
+	// DrivingURL and WalkingURL are OSRM-compatible servers for the car and
+	// foot profiles. A mode without one is always estimated.
+	DrivingURL string
+	WalkingURL string
+	// Timeout bounds a single call to a routing server
+	Timeout time.Duration
+	// BreakerThreshold failures in a row stop calls to a server; it is
+	// retried once every BreakerCooldown
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	// DrivingSpeedKmh and WalkingSpeedKmh are the average speeds used to
+	// estimate travel time without a routing server, over the straight-line
+	// distance times DetourFactor
+	DrivingSpeedKmh float64
+	WalkingSpeedKmh float64
+	DetourFactor    float64
 }
 
 type RateLimitConfig struct {
@@ -84,7 +270,60 @@ func Load() (*Config, error) {
 	viper.SetDefault("DB_AUTO_MIGRATE", true)
 	viper.SetDefault("JWT_ACCESS_EXPIRY", "15m")
 	viper.SetDefault("JWT_REFRESH_EXPIRY", "168h")
+	viper.SetDefault("JWT_ACTION_EXPIRY", "30m")
+	viper.SetDefault("FCM_MAX_RETRIES", 3)
+	viper.SetDefault("FCM_RETRY_BASE_DELAY", "500ms")
+	viper.SetDefault("FCM_DEEP_LINK_BASE", "bamboorescue://")
+	viper.SetDefault("WEBPUSH_SUBJECT", "mailto:admin@bamboo-rescue.local")
+	viper.SetDefault("WEBPUSH_TTL", "12h")
+	viper.SetDefault("WEBPUSH_LINK_BASE", "http://localhost:3000")
+	viper.SetDefault("SMTP_PORT", 1025)
+	viper.SetDefault("SMTP_FROM", "no-reply@bamboo-rescue.local")
+	viper.SetDefault("SMTP_FROM_NAME", "Bamboo Rescue")
+	viper.SetDefault("EMAIL_LINK_BASE", "http://localhost:3000")
+	viper.SetDefault("EMAIL_DIGEST_HOUR", 7)
+	viper.SetDefault("EMAIL_DIGEST_TIMEZONE", "Asia/Ho_Chi_Minh")
+	viper.SetDefault("NOTIFY_PUSH_BUDGET", 5)
+	viper.SetDefault("NOTIFY_PUSH_WINDOW", "1h")
+	viper.SetDefault("NOTIFY_SUMMARY_DELAY", "10m")
+	viper.SetDefault("OUTBOX_WORKERS", 4)
+	viper.SetDefault("OUTBOX_POLL_INTERVAL", "1s")
+	viper.SetDefault("OUTBOX_MAX_ATTEMPTS", 8)
+	viper.SetDefault("OUTBOX_BASE_BACKOFF", "5s")
+	viper.SetDefault("OUTBOX_MAX_BACKOFF", "30m")
+	viper.SetDefault("OUTBOX_JOB_TIMEOUT", "2m")
+	viper.SetDefault("OUTBOX_LOCK_TIMEOUT", "5m")
+	viper.SetDefault("OUTBOX_RETENTION", "168h")
+	viper.SetDefault("S3_PRESIGN_EXPIRY", "15m")
+	viper.SetDefault("STORAGE_LOCAL_DIR", "./data/media")
+	viper.SetDefault("STORAGE_PRIVATE", false)
+	viper.SetDefault("STORAGE_SIGNED_URL_EXPIRY", "15m")
+	viper.SetDefault("STORAGE_TRANSFER_TIMEOUT", "10m")
+	viper.SetDefault("TUS_UPLOAD_EXPIRY", "24h")
+	viper.SetDefault("TUS_CHUNK_TIMEOUT", "10m")
 	viper.SetDefault("NOMINATIM_URL", "https://nominatim.openstreetmap.org")
+	viper.SetDefault("NOMINATIM_USER_AGENT", "RescueApp/1.0")
+	viper.SetDefault("NOMINATIM_REQUESTS_PER_SECOND", 1)
+	viper.SetDefault("NOMINATIM_MAX_WAIT", "5s")
+	viper.SetDefault("PHOTON_URL", "https://photon.komoot.io")
+	viper.SetDefault("PHOTON_REQUESTS_PER_SECOND", 1)
+	viper.SetDefault("PELIAS_REQUESTS_PER_SECOND", 0)
+	viper.SetDefault("GEOCODE_PROVIDERS", "nominatim,offline")
+	viper.SetDefault("GEOCODE_PROVIDER_TIMEOUT", "8s")
+	viper.SetDefault("GEOCODE_BREAKER_THRESHOLD", 5)
+	viper.SetDefault("GEOCODE_BREAKER_COOLDOWN", "1m")
+	viper.SetDefault("GEOCODE_CACHE_SIZE", 10000)
+	viper.SetDefault("GEOCODE_REVERSE_TTL", "720h")
+	viper.SetDefault("GEOCODE_SEARCH_TTL", "168h")
+	viper.SetDefault("GEOCODE_COORD_PRECISION", 4)
+	viper.SetDefault("GEOCODE_COUNTRY_CODES", "vn")
+	viper.SetDefault("GEOCODE_SEARCH_BIAS_KM", 50)
+	viper.SetDefault("ROUTING_TIMEOUT", "3s")
+	viper.SetDefault("ROUTING_BREAKER_THRESHOLD", 5)
+	viper.SetDefault("ROUTING_BREAKER_COOLDOWN", "1m")
+	viper.SetDefault("ROUTING_DRIVING_SPEED_KMH", 25)
+	viper.SetDefault("ROUTING_WALKING_SPEED_KMH", 4.5)
+	viper.SetDefault("ROUTING_DETOUR_FACTOR", 1.4)
 	viper.SetDefault("RATE_LIMIT_REQUESTS", 100)
 	viper.SetDefault("RATE_LIMIT_DURATION", "1m")
 
@@ -98,11 +337,139 @@ func Load() (*Config, error) {
 		refreshExpiry = 168 * time.Hour
 	}
 
+	actionExpiry, err := time.ParseDuration(viper.GetString("JWT_ACTION_EXPIRY"))
+	if err != nil {
+		actionExpiry = 30 * time.Minute
+	}
+
 	rateLimitDuration, err := time.ParseDuration(viper.GetString("RATE_LIMIT_DURATION"))
 	if err != nil {
 		rateLimitDuration = time.Minute
 	}
 
+	fcmRetryDelay, err := time.ParseDuration(viper.GetString("FCM_RETRY_BASE_DELAY"))
+	if err != nil {
+		fcmRetryDelay = 500 * time.Millisecond
+	}
+
+	webPushTTL, err := time.ParseDuration(viper.GetString("WEBPUSH_TTL"))
+	if err != nil {
+		webPushTTL = 12 * time.Hour
+	}
+
+	notifyPushWindow, err := time.ParseDuration(viper.GetString("NOTIFY_PUSH_WINDOW"))
+	if err != nil {
+		notifyPushWindow = time.Hour
+	}
+
+	notifySummaryDelay, err := time.ParseDuration(viper.GetString("NOTIFY_SUMMARY_DELAY"))
+	if err != nil {
+		notifySummaryDelay = 10 * time.Minute
+	}
+
+	outboxPollInterval, err := time.ParseDuration(viper.GetString("OUTBOX_POLL_INTERVAL"))
+	if err != nil {
+		outboxPollInterval = time.Second
+	}
+
+	outboxBaseBackoff, err := time.ParseDuration(viper.GetString("OUTBOX_BASE_BACKOFF"))
+	if err != nil {
+		outboxBaseBackoff = 5 * time.Second
+	}
+
+	outboxMaxBackoff, err := time.ParseDuration(viper.GetString("OUTBOX_MAX_BACKOFF"))
+	if err != nil {
+		outboxMaxBackoff = 30 * time.Minute
+	}
+
+	outboxJobTimeout, err := time.ParseDuration(viper.GetString("OUTBOX_JOB_TIMEOUT"))
+	if err != nil {
+		outboxJobTimeout = 2 * time.Minute
+	}
+
+	outboxLockTimeout, err := time.ParseDuration(viper.GetString("OUTBOX_LOCK_TIMEOUT"))
+	if err != nil {
+		outboxLockTimeout = 5 * time.Minute
+	}
+
+	outboxRetention, err := time.ParseDuration(viper.GetString("OUTBOX_RETENTION"))
+	if err != nil {
+		outboxRetention = 168 * time.Hour
+	}
+
+	nominatimMaxWait, err := time.ParseDuration(viper.GetString("NOMINATIM_MAX_WAIT"))
+	if err != nil {
+		nominatimMaxWait = 5 * time.Second
+	}
+
+	geocodeProviderTimeout, err := time.ParseDuration(viper.GetString("GEOCODE_PROVIDER_TIMEOUT"))
+	if err != nil {
+		geocodeProviderTimeout = 8 * time.Second
+	}
+
+	geocodeBreakerCooldown, err := time.ParseDuration(viper.GetString("GEOCODE_BREAKER_COOLDOWN"))
+	if err != nil {
+		geocodeBreakerCooldown = time.Minute
+	}
+
+	geocodeReverseTTL, err := time.ParseDuration(viper.GetString("GEOCODE_REVERSE_TTL"))
+	if err != nil {
+		geocodeReverseTTL = 720 * time.Hour
+	}
+
+	geocodeSearchTTL, err := time.ParseDuration(viper.GetString("GEOCODE_SEARCH_TTL"))
+	if err != nil {
+		geocodeSearchTTL = 168 * time.Hour
+	}
+
+	routingTimeout, err := time.ParseDuration(viper.GetString("ROUTING_TIMEOUT"))
+	if err != nil {
+		routingTimeout = 3 * time.Second
+	}
+
+	routingBreakerCooldown, err := time.ParseDuration(viper.GetString("ROUTING_BREAKER_COOLDOWN"))
+	if err != nil {
+		routingBreakerCooldown = time.Minute
+	}
+
+	presignExpiry, err := time.ParseDuration(viper.GetString("S3_PRESIGN_EXPIRY"))
+	if err != nil {
+		presignExpiry = 15 * time.Minute
+	}
+
+	tusUploadExpiry, err := time.ParseDuration(viper.GetString("TUS_UPLOAD_EXPIRY"))
+	if err != nil {
+		tusUploadExpiry = 24 * time.Hour
+	}
+
+	tusChunkTimeout, err := time.ParseDuration(viper.GetString("TUS_CHUNK_TIMEOUT"))
+	if err != nil {
+		tusChunkTimeout = 10 * time.Minute
+	}
+
+	localURL := viper.GetString("STORAGE_LOCAL_URL")
+	if localURL == "" {
+		localURL = "http://localhost:" + viper.GetString("SERVER_PORT") + "/files"
+	}
+
+	signedURLExpiry, err := time.ParseDuration(viper.GetString("STORAGE_SIGNED_URL_EXPIRY"))
+	if err != nil {
+		signedURLExpiry = 15 * time.Minute
+	}
+
+	storageTransferTimeout, err := time.ParseDuration(viper.GetString("STORAGE_TRANSFER_TIMEOUT"))
+	if err != nil {
+		storageTransferTimeout = 10 * time.Minute
+	}
+
+	storageBackend := viper.GetString("STORAGE_BACKEND")
+	if storageBackend == "" {
+		storageBackend = "local"
+		if viper.GetString("S3_ENDPOINT") != "" {
+			storageBackend = "s3"
+		}
+	}
+
 	return &Config{
 		Server: ServerConfig{
 			Port: viper.GetString("SERVER_PORT"),
@@ -121,12 +488,53 @@ func Load() (*Config, error) {
 			Secret:        viper.GetString("JWT_SECRET"),
 			AccessExpiry:  accessExpiry,
 			RefreshExpiry: refreshExpiry,
+			ActionExpiry:  actionExpiry,
 		},
 		Firebase: FirebaseConfig{
 			CredentialsPath: viper.GetString("FIREBASE_CREDENTIALS_PATH"),
 		},
 		FCM: FCMConfig{
 			CredentialsFile: viper.GetString("FCM_CREDENTIALS_FILE"),
+			MaxRetries:      viper.GetInt("FCM_MAX_RETRIES"),
+			RetryBaseDelay:  fcmRetryDelay,
+			DeepLinkBase:    viper.GetString("FCM_DEEP_LINK_BASE"),
+		},
+		WebPush: WebPushConfig{
+			VAPIDPrivateKey: viper.GetString("WEBPUSH_VAPID_PRIVATE_KEY"),
+			VAPIDPublicKey:  viper.GetString("WEBPUSH_VAPID_PUBLIC_KEY"),
+			Subject:         viper.GetString("WEBPUSH_SUBJECT"),
+			TTL:             webPushTTL,
+			LinkBase:        viper.GetString("WEBPUSH_LINK_BASE"),
+		},
+		Email: EmailConfig{
+			SMTPHost:       viper.GetString("SMTP_HOST"),
+			SMTPPort:       viper.GetInt("SMTP_PORT"),
+			SMTPUsername:   viper.GetString("SMTP_USERNAME"),
+			SMTPPassword:   viper.GetString("SMTP_PASSWORD"),
+			From:           viper.GetString("SMTP_FROM"),
+			FromName:       viper.GetString("SMTP_FROM_NAME"),
+			LinkBase:       viper.GetString("EMAIL_LINK_BASE"),
+			DigestHour:     viper.GetInt("EMAIL_DIGEST_HOUR"),
+			DigestTimezone: viper.GetString("EMAIL_DIGEST_TIMEZONE"),
+		},
+		Notify: NotifyConfig{
+			PushBudget:   viper.GetInt("NOTIFY_PUSH_BUDGET"),
+			PushWindow:   notifyPushWindow,
+			SummaryDelay: notifySummaryDelay,
+		},
+		Outbox: OutboxConfig{
+			Workers:      viper.GetInt("OUTBOX_WORKERS"),
+			PollInterval: outboxPollInterval,
+			MaxAttempts:  viper.GetInt("OUTBOX_MAX_ATTEMPTS"),
+			BaseBackoff:  outboxBaseBackoff,
+			MaxBackoff:   outboxMaxBackoff,
+			JobTimeout:   outboxJobTimeout,
+			LockTimeout:  outboxLockTimeout,
+			Retention:    outboxRetention,
+		},
+		Admin: AdminConfig{
+			UserIDs:        splitList(viper.GetString("ADMIN_USER_IDS")),
+			CoordinatorIDs: splitList(viper.GetString("COORDINATOR_USER_IDS")),
 		},
 		S3: S3Config{
 			Endpoint:  viper.GetString("S3_ENDPOINT"),
@@ -135,9 +543,60 @@ func Load() (*Config, error) {
 			Bucket:    viper.GetString("S3_BUCKET"),
 			Region:    viper.GetString("S3_REGION"),
 			PublicURL: viper.GetString("S3_PUBLIC_URL"),
+
+			PresignExpiry: presignExpiry,
+		},
+		Storage: StorageConfig{
+			Backend:  storageBackend,
+			LocalDir: viper.GetString("STORAGE_LOCAL_DIR"),
+			LocalURL: localURL,
+
+			Private:         viper.GetBool("STORAGE_PRIVATE"),
+			SignedURLExpiry: signedURLExpiry,
+			SigningSecret:   viper.GetString("STORAGE_SIGNING_SECRET"),
+			TransferTimeout: storageTransferTimeout,
+		},
+		Tus: TusConfig{
+			SpoolDir:     viper.GetString("TUS_SPOOL_DIR"),
+			UploadExpiry: tusUploadExpiry,
+			ChunkTimeout: tusChunkTimeout,
 		},
 		Nominatim: NominatimConfig{
-			URL: viper.GetString("NOMINATIM_URL"),
+			URL:               viper.GetString("NOMINATIM_URL"),
+			UserAgent:         viper.GetString("NOMINATIM_USER_AGENT"),
+			RequestsPerSecond: viper.GetFloat64("NOMINATIM_REQUESTS_PER_SECOND"),
+			MaxWait:           nominatimMaxWait,
+		},
+		Photon: PhotonConfig{
+			URL:               viper.GetString("PHOTON_URL"),
+			RequestsPerSecond: viper.GetFloat64("PHOTON_REQUESTS_PER_SECOND"),
+		},
+		Pelias: PeliasConfig{
+			URL:               viper.GetString("PELIAS_URL"),
+			APIKey:            viper.GetString("PELIAS_API_KEY"),
+			RequestsPerSecond: viper.GetFloat64("PELIAS_REQUESTS_PER_SECOND"),
+		},
+		Geocode: GeocodeConfig{
+			Providers:        splitList(viper.GetString("GEOCODE_PROVIDERS")),
+			ProviderTimeout:  geocodeProviderTimeout,
+			BreakerThreshold: viper.GetInt("GEOCODE_BREAKER_THRESHOLD"),
+			BreakerCooldown:  geocodeBreakerCooldown,
+			CacheSize:        viper.GetInt("GEOCODE_CACHE_SIZE"),
+			ReverseTTL:       geocodeReverseTTL,
+			SearchTTL:        geocodeSearchTTL,
+			CoordPrecision:   viper.GetInt("GEOCODE_COORD_PRECISION"),
+			CountryCodes:     splitList(viper.GetString("GEOCODE_COUNTRY_CODES")),
+			SearchBiasKm:     viper.GetFloat64("GEOCODE_SEARCH_BIAS_KM"),
+		},
+		Routing: RoutingConfig{
+			DrivingURL:       viper.GetString("ROUTING_DRIVING_URL"),
+			WalkingURL:       viper.GetString("ROUTING_WALKING_URL"),
+			Timeout:          routingTimeout,
+			BreakerThreshold: viper.GetInt("ROUTING_BREAKER_THRESHOLD"),
+			BreakerCooldown:  routingBreakerCooldown,
+			DrivingSpeedKmh:  viper.GetFloat64("ROUTING_DRIVING_SPEED_KMH"),
+			WalkingSpeedKmh:  viper.GetFloat64("ROUTING_WALKING_SPEED_KMH"),
+			DetourFactor:     viper.GetFloat64("ROUTING_DETOUR_FACTOR"),
 		},
 		RateLimit: RateLimitConfig{
 			Requests: viper.GetInt("RATE_LIMIT_REQUESTS"),
@@ -153,3 +612,14 @@ func (c *Config) IsDevelopment() bool {
 func (c *Config) IsProduction() bool {
 	return c.Server.Env == "production"
 }
+
+// splitList parses a comma-separated setting, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
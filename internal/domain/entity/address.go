@@ -0,0 +1,116 @@
+package entity
+
+import "strings"
+
+// Address is a structured postal address. Ward, District and Province are
+// Vietnam's administrative tiers; elsewhere they hold the nearest
+// equivalents. Since the 2025 reform most Vietnamese places no longer
+// belong to a district, so District is often empty.
+type Address struct {
+	HouseNumber string `json:"house_number,omitempty"`
+	Road        string `json:"road,omitempty"`
+	Ward        string `json:"ward,omitempty"`
+	District    string `json:"district,omitempty"`
+	Province    string `json:"province,omitempty"`
+	Postcode    string `json:"postcode,omitempty"`
+	Country     string `json:"country,omitempty"`
+	CountryCode string `json:"country_code,omitempty"`
+}
+
+// AdminTier is a level of Vietnamese administrative division
+type AdminTier int
+
+const (
+	AdminTierUnknown AdminTier = iota
+	AdminTierWard
+	AdminTierDistrict
+	AdminTierProvince
+)
+
+// vnTierPrefixes maps the type word a unit's name starts with to its tier
+var vnTierPrefixes = []struct {
+	prefix string
+	tier   AdminTier
+}{
+	{"phường ", AdminTierWard},
+	{"xã ", AdminTierWard},
+	{"thị trấn ", AdminTierWard},
+	{"đặc khu ", AdminTierWard},
+	{"ward ", AdminTierWard},
+	{"commune ", AdminTierWard},
+	{"quận ", AdminTierDistrict},
+	{"huyện ", AdminTierDistrict},
+	{"thị xã ", AdminTierDistrict},
+	{"district ", AdminTierDistrict},
+	{"tỉnh ", AdminTierProvince},
+	{"province ", AdminTierProvince},
+}
+
+// vnCentralCities are the cities governed as provinces. Any other
+// "Thành phố" is a district-level city.
+var vnCentralCities = []string{"hà nội", "hồ chí minh", "hải phòng", "đà nẵng", "cần thơ", "huế"}
+
+// VNAdminTier tells the tier of a Vietnamese administrative unit from its
+// name, e.g. "Phường Bến Nghé" is a ward
+func VNAdminTier(name string) AdminTier {
+	lower := strings.ToLower(strings.TrimSpace(name))
+	if lower == "" {
+		return AdminTierUnknown
+	}
+
+	for _, p := range vnTierPrefixes {
+		if strings.HasPrefix(lower, p.prefix) {
+			return p.tier
+		}
+	}
+
+	// Central cities also come without the prefix, e.g. "Hà Nội"
+	city, isCity := strings.CutPrefix(lower, "thành phố ")
+	for _, central := range vnCentralCities {
+		if strings.Contains(city, central) {
+			return AdminTierProvince
+		}
+	}
+	if isCity {
+		return AdminTierDistrict
+	}
+	return AdminTierUnknown
+}
+
+// AdminArea names the administrative units a place lies in. Empty fields
+// are unknown, or absent like District for most places since the reform.
+type AdminArea struct {
+	Province string
+	District string
+	Ward     string
+}
+
+// Area returns the address's administrative units in normalized form
+func (a Address) Area() AdminArea {
+	return AdminArea{
+		Province: NormalizeAdminName(a.Province),
+		District: NormalizeAdminName(a.District),
+		Ward:     NormalizeAdminName(a.Ward),
+	}
+}
+
+// IsZero reports whether no unit is known
+func (a AdminArea) IsZero() bool {
+	return a.Province == "" && a.District == "" && a.Ward == ""
+}
+
+// NormalizeAdminName tidies a unit's name so the same unit is always
+// stored and matched the same way: surrounding and repeated spaces are
+// dropped and central cities lose their "Thành phố" prefix, which
+// providers include inconsistently
+func NormalizeAdminName(name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+	if VNAdminTier(name) == AdminTierProvince {
+		for _, prefix := range []string{"thành phố ", "tp. ", "tp "} {
+			if len(name) > len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+				return name[len(prefix):]
+			}
+		}
+	}
+	return name
+}
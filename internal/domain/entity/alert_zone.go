@@ -0,0 +1,71 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/domain/enum"
+)
+
+// AlertZone is an area coordinators warn everyone inside of, such as the
+// land below a broken dyke
+type AlertZone struct {
+	ID       uuid.UUID          `gorm:"type:uuid;primaryKey" json:"id"`
+	Title    string             `gorm:"type:varchar(200);not null" json:"title"`
+	Message  *string            `gorm:"type:text" json:"message,omitempty"`
+	Severity enum.AlertSeverity `gorm:"type:varchar(20);not null" json:"severity"`
+	Polygon  Polygon            `gorm:"type:jsonb;not null" json:"polygon"`
+	// The polygon's bounding box, kept so candidate zones and users can be
+	// found by index before the exact polygon test
+	MinLat float64 `gorm:"type:decimal(10,8);not null" json:"min_lat"`
+	MaxLat float64 `gorm:"type:decimal(10,8);not null" json:"max_lat"`
+	MinLng float64 `gorm:"type:decimal(11,8);not null" json:"min_lng"`
+	MaxLng float64 `gorm:"type:decimal(11,8);not null" json:"max_lng"`
+
+	CreatedBy       *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	ExpiresAt       time.Time  `gorm:"not null" json:"expires_at"`
+	EndedAt         *time.Time `json:"ended_at,omitempty"`
+	LastBroadcastAt *time.Time `json:"last_broadcast_at,omitempty"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName returns the table name for AlertZone
+func (AlertZone) TableName() string {
+	return "alert_zones"
+}
+
+// SetPolygon sets the polygon and its bounding box
+func (z *AlertZone) SetPolygon(p Polygon) {
+	bounds := p.Bounds()
+	z.Polygon = p
+	z.MinLat, z.MaxLat = bounds.MinLat, bounds.MaxLat
+	z.MinLng, z.MaxLng = bounds.MinLng, bounds.MaxLng
+}
+
+// Bounds returns the bounding box of the zone's polygon
+func (z *AlertZone) Bounds() *BoundingBox {
+	return &BoundingBox{MinLat: z.MinLat, MaxLat: z.MaxLat, MinLng: z.MinLng, MaxLng: z.MaxLng}
+}
+
+// Contains checks if a point lies inside the zone
+func (z *AlertZone) Contains(lat, lng float64) bool {
+	return z.Bounds().Contains(lat, lng) && z.Polygon.Contains(lat, lng)
+}
+
+// IsActive reports whether the zone is still in force at now
+func (z *AlertZone) IsActive(now time.Time) bool {
+	return z.EndedAt == nil && now.Before(z.ExpiresAt)
+}
+
+// CaseAlertZone tags a case with an alert zone it was reported inside
+type CaseAlertZone struct {
+	CaseID      uuid.UUID `gorm:"type:uuid;primaryKey" json:"case_id"`
+	AlertZoneID uuid.UUID `gorm:"type:uuid;primaryKey" json:"alert_zone_id"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName returns the table name for CaseAlertZone
+func (CaseAlertZone) TableName() string {
+	return "case_alert_zones"
+}
@@ -17,6 +17,9 @@ type Case struct {
 	Latitude       float64           `gorm:"type:decimal(10,8);not null" json:"latitude"`
 	Longitude      float64           `gorm:"type:decimal(11,8);not null" json:"longitude"`
 	Address        *string           `gorm:"type:text" json:"address,omitempty"`
+	Province       *string           `gorm:"type:varchar(100)" json:"province,omitempty"`
+	District       *string           `gorm:"type:varchar(100)" json:"district,omitempty"`
+	Ward           *string           `gorm:"type:varchar(100)" json:"ward,omitempty"`
 	LocationNote   *string           `gorm:"type:varchar(500)" json:"location_note,omitempty"`
 	Title          string            `gorm:"type:varchar(200);not null" json:"title"`
 	Description    *string           `gorm:"type:text" json:"description,omitempty"`
@@ -31,6 +34,8 @@ type Case struct {
 	AcceptedAt     *time.Time        `json:"accepted_at,omitempty"`
 	ResolvedAt     *time.Time        `json:"resolved_at,omitempty"`
 	ExpiresAt      *time.Time        `gorm:"-" json:"expires_at,omitempty"`
+	// EventID is the disaster event the case is grouped under
+	EventID *uuid.UUID `gorm:"type:uuid" json:"event_id,omitempty"`
 
 	// Relations
 	Reporter        *User                `gorm:"foreignKey:ReporterID" json:"reporter,omitempty"`
@@ -40,6 +45,8 @@ type Case struct {
 	Media           []CaseMedia          `gorm:"foreignKey:CaseID" json:"media,omitempty"`
 	Volunteers      []CaseVolunteer      `gorm:"foreignKey:CaseID" json:"volunteers,omitempty"`
 	Updates         []CaseUpdate         `gorm:"foreignKey:CaseID" json:"updates,omitempty"`
+	// AlertZones are the zones the case was reported inside
+	AlertZones []AlertZone `gorm:"many2many:case_alert_zones" json:"alert_zones,omitempty"`
 }
 
 // TableName returns the table name for Case
@@ -135,6 +142,13 @@ type CaseVolunteer struct {
 	CompletedAt       *time.Time           `json:"completed_at,omitempty"`
 	Note              *string              `gorm:"type:text" json:"note,omitempty"`
 
+	// Travel from the accepted position, by road where the routing engine
+	// answered and otherwise estimated from DistanceKm
+	TravelMode      *enum.TravelMode `gorm:"type:varchar(20)" json:"travel_mode,omitempty"`
+	RouteDistanceKm *float64         `gorm:"type:decimal(10,2)" json:"route_distance_km,omitempty"`
+	EtaSeconds      *int             `json:"eta_seconds,omitempty"`
+	EtaEstimated    bool             `gorm:"not null;default:false" json:"eta_estimated"`
+
 	// Relations
 	Volunteer *User `gorm:"foreignKey:VolunteerID" json:"volunteer,omitempty"`
 }
@@ -221,3 +235,62 @@ type CaseComment struct {
 func (CaseComment) TableName() string {
 	return "case_comments"
 }
+
+// CaseFollower subscribes a user to notifications about a case
+type CaseFollower struct {
+	CaseID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"case_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName returns the table name for CaseFollower
+func (CaseFollower) TableName() string {
+	return "case_followers"
+}
+
+// CaseDismissal records that a volunteer turned a case down, so fan-outs
+// about it skip them
+type CaseDismissal struct {
+	CaseID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"case_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName returns the table name for CaseDismissal
+func (CaseDismissal) TableName() string {
+	return "case_dismissals"
+}
+
+// CaseAreaStats counts the cases in one administrative unit. Cases whose
+// unit is not known yet are counted under an empty name.
+type CaseAreaStats struct {
+	Area     string `json:"area"`
+	Total    int64  `json:"total"`
+	Active   int64  `json:"active"`
+	Resolved int64  `json:"resolved"`
+}
+
+// CaseCluster groups the cases in one cell of a map grid
+type CaseCluster struct {
+	// Latitude and Longitude are the mean position of the cases
+	Latitude  float64
+	Longitude float64
+	// Bounds is the smallest box holding every case, for zooming in
+	Bounds    BoundingBox
+	Count     int64
+	ByType    map[enum.CaseType]int64
+	ByUrgency map[enum.UrgencyLevel]int64
+	// CaseID is set when the cluster holds a single case
+	CaseID *uuid.UUID
+}
+
+// CaseMap is what a map viewport shows: the cases themselves when zoomed
+// in, or clusters of them when zoomed out
+type CaseMap struct {
+	Clustered bool
+	Cases     []Case
+	Clusters  []CaseCluster
+	// Total is how many cases are in the viewport, even when Cases is cut
+	// short
+	Total int64
+}
@@ -0,0 +1,123 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/domain/enum"
+)
+
+// DisasterEvent groups the cases of one large incident, such as a typhoon
+// over a city, so coordinators can follow and dispatch them together
+type DisasterEvent struct {
+	ID          uuid.UUID        `gorm:"type:uuid;primaryKey" json:"id"`
+	Name        string           `gorm:"type:varchar(200);not null" json:"name"`
+	Description *string          `gorm:"type:text" json:"description,omitempty"`
+	Status      enum.EventStatus `gorm:"type:varchar(20);not null;default:'active'" json:"status"`
+	Area        Polygon          `gorm:"type:jsonb;not null" json:"area"`
+	// The area's bounding box, kept so candidate events and cases can be
+	// found by index before the exact polygon test
+	MinLat float64 `gorm:"type:decimal(10,8);not null" json:"min_lat"`
+	MaxLat float64 `gorm:"type:decimal(10,8);not null" json:"max_lat"`
+	MinLng float64 `gorm:"type:decimal(11,8);not null" json:"min_lng"`
+	MaxLng float64 `gorm:"type:decimal(11,8);not null" json:"max_lng"`
+
+	// StartsAt and EndsAt bound when cases are reported for the event; a nil
+	// EndsAt leaves it open
+	StartsAt time.Time  `gorm:"not null" json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+
+	// Dispatch settings for the event's cases, overriding the defaults when
+	// set
+	NotifyRadiusKm *int `json:"notify_radius_km,omitempty"`
+	MaxVolunteers  *int `json:"max_volunteers,omitempty"`
+
+	CreatedBy *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relations
+	Coordinators []DisasterEventCoordinator `gorm:"foreignKey:EventID" json:"coordinators,omitempty"`
+}
+
+// TableName returns the table name for DisasterEvent
+func (DisasterEvent) TableName() string {
+	return "disaster_events"
+}
+
+// SetArea sets the area and its bounding box
+func (e *DisasterEvent) SetArea(p Polygon) {
+	bounds := p.Bounds()
+	e.Area = p
+	e.MinLat, e.MaxLat = bounds.MinLat, bounds.MaxLat
+	e.MinLng, e.MaxLng = bounds.MinLng, bounds.MaxLng
+}
+
+// Bounds returns the bounding box of the event's area
+func (e *DisasterEvent) Bounds() *BoundingBox {
+	return &BoundingBox{MinLat: e.MinLat, MaxLat: e.MaxLat, MinLng: e.MinLng, MaxLng: e.MaxLng}
+}
+
+// Contains checks if a point lies inside the event's area
+func (e *DisasterEvent) Contains(lat, lng float64) bool {
+	return e.Bounds().Contains(lat, lng) && e.Area.Contains(lat, lng)
+}
+
+// Covers reports whether a case reported at t falls within the event's time
+// span
+func (e *DisasterEvent) Covers(t time.Time) bool {
+	return !t.Before(e.StartsAt) && (e.EndsAt == nil || t.Before(*e.EndsAt))
+}
+
+// IsCoordinator checks if a user is one of the event's coordinators
+func (e *DisasterEvent) IsCoordinator(userID uuid.UUID) bool {
+	for _, c := range e.Coordinators {
+		if c.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// CoordinatorIDs returns the IDs of the event's coordinators
+func (e *DisasterEvent) CoordinatorIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, len(e.Coordinators))
+	for i, c := range e.Coordinators {
+		ids[i] = c.UserID
+	}
+	return ids
+}
+
+// SetCoordinators replaces the event's coordinators
+func (e *DisasterEvent) SetCoordinators(userIDs []uuid.UUID) {
+	e.Coordinators = make([]DisasterEventCoordinator, len(userIDs))
+	for i, id := range userIDs {
+		e.Coordinators[i] = DisasterEventCoordinator{EventID: e.ID, UserID: id}
+	}
+}
+
+// DisasterEventCoordinator makes a user one of the coordinators who own an
+// event
+type DisasterEventCoordinator struct {
+	EventID   uuid.UUID `gorm:"type:uuid;primaryKey" json:"event_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName returns the table name for DisasterEventCoordinator
+func (DisasterEventCoordinator) TableName() string {
+	return "disaster_event_coordinators"
+}
+
+// DisasterEventStats sums up the cases of an event
+type DisasterEventStats struct {
+	Total     int64                       `json:"total"`
+	Active    int64                       `json:"active"`
+	Resolved  int64                       `json:"resolved"`
+	ByStatus  map[enum.CaseStatus]int64   `json:"by_status"`
+	ByType    map[enum.CaseType]int64     `json:"by_type"`
+	ByUrgency map[enum.UrgencyLevel]int64 `json:"by_urgency"`
+	// Volunteers counts the people who have taken on at least one of the
+	// event's cases and not withdrawn
+	Volunteers int64 `json:"volunteers"`
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// GeocodeCacheEntry is a cached geocoding result. Entries past ExpiresAt
+// are kept so they can still be served when the provider fails.
+type GeocodeCacheEntry struct {
+	Key string `gorm:"type:varchar(128);primaryKey" json:"key"`
+	// Result is the JSON encoded result
+	Result    string    `gorm:"type:jsonb;not null" json:"result"`
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName returns the table name for GeocodeCacheEntry
+func (GeocodeCacheEntry) TableName() string {
+	return "geocode_cache"
+}
+
+// IsFresh reports whether the entry may be served without asking the
+// provider again
+func (e *GeocodeCacheEntry) IsFresh(now time.Time) bool {
+	return now.Before(e.ExpiresAt)
+}
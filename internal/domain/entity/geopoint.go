@@ -1,7 +1,10 @@
 package entity
 
 import (
+	"database/sql/driver"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"math"
 )
 
@@ -107,3 +110,98 @@ func (bb *BoundingBox) Contains(lat, lng float64) bool {
 	return lat >= bb.MinLat && lat <= bb.MaxLat &&
 		lng >= bb.MinLng && lng <= bb.MaxLng
 }
+
+// Polygon is a closed ring of points. The last point joins back to the
+// first, so it need not repeat it.
+type Polygon []GeoPoint
+
+// Polygon limits
+const (
+	PolygonMinVertices = 3
+	PolygonMaxVertices = 500
+)
+
+// ErrInvalidPolygon is returned for a polygon that does not enclose an area
+// on the map
+var ErrInvalidPolygon = errors.New("invalid polygon")
+
+// Normalize drops a closing point that repeats the first and checks the
+// polygon is a usable ring: enough vertices, coordinates in range, and not
+// wrapping around the antimeridian
+func (p Polygon) Normalize() (Polygon, error) {
+	if len(p) > 1 && p[0] == p[len(p)-1] {
+		p = p[:len(p)-1]
+	}
+	if len(p) < PolygonMinVertices || len(p) > PolygonMaxVertices {
+		return nil, ErrInvalidPolygon
+	}
+	for _, v := range p {
+		if v.Latitude < -90 || v.Latitude > 90 || v.Longitude < -180 || v.Longitude > 180 {
+			return nil, ErrInvalidPolygon
+		}
+	}
+	bounds := p.Bounds()
+	if bounds.MaxLng-bounds.MinLng >= 180 || bounds.MaxLat == bounds.MinLat || bounds.MaxLng == bounds.MinLng {
+		return nil, ErrInvalidPolygon
+	}
+	return p, nil
+}
+
+// Bounds returns the smallest bounding box holding the polygon
+func (p Polygon) Bounds() *BoundingBox {
+	if len(p) == 0 {
+		return &BoundingBox{}
+	}
+	bb := &BoundingBox{MinLat: p[0].Latitude, MaxLat: p[0].Latitude, MinLng: p[0].Longitude, MaxLng: p[0].Longitude}
+	for _, v := range p[1:] {
+		bb.MinLat = math.Min(bb.MinLat, v.Latitude)
+		bb.MaxLat = math.Max(bb.MaxLat, v.Latitude)
+		bb.MinLng = math.Min(bb.MinLng, v.Longitude)
+		bb.MaxLng = math.Max(bb.MaxLng, v.Longitude)
+	}
+	return bb
+}
+
+// Contains checks if a point lies inside the polygon, treating coordinates
+// as planar, which is close enough for areas the size of a district
+func (p Polygon) Contains(lat, lng float64) bool {
+	// Count the edges a ray heading east from the point crosses
+	inside := false
+	for i, j := 0, len(p)-1; i < len(p); j, i = i, i+1 {
+		a, b := p[i], p[j]
+		if (a.Latitude > lat) != (b.Latitude > lat) &&
+			lng < (b.Longitude-a.Longitude)*(lat-a.Latitude)/(b.Latitude-a.Latitude)+a.Longitude {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Value implements driver.Valuer, storing the polygon as JSON
+func (p Polygon) Value() (driver.Value, error) {
+	if p == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]GeoPoint(p))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (p *Polygon) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		*p = nil
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("failed to scan Polygon: %v", value)
+	}
+	return json.Unmarshal(raw, (*[]GeoPoint)(p))
+}
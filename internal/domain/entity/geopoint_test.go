@@ -0,0 +1,124 @@
+package entity
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func pt(lat, lng float64) GeoPoint {
+	return GeoPoint{Latitude: lat, Longitude: lng}
+}
+
+func TestPolygonNormalize(t *testing.T) {
+	square := Polygon{pt(21, 105), pt(21, 106), pt(22, 106), pt(22, 105)}
+
+	many := make(Polygon, PolygonMaxVertices+1)
+	for i := range many {
+		many[i] = pt(21+float64(i)/1000, 105+float64(i%2))
+	}
+
+	tests := []struct {
+		name    string
+		polygon Polygon
+		want    Polygon
+		wantErr bool
+	}{
+		{name: "open ring kept", polygon: square, want: square},
+		{name: "closing point dropped", polygon: append(append(Polygon{}, square...), square[0]), want: square},
+		{name: "triangle", polygon: Polygon{pt(21, 105), pt(21, 106), pt(22, 105.5)}, want: Polygon{pt(21, 105), pt(21, 106), pt(22, 105.5)}},
+		{name: "closed triangle of two points", polygon: Polygon{pt(21, 105), pt(22, 106), pt(21, 105)}, wantErr: true},
+		{name: "too few vertices", polygon: Polygon{pt(21, 105), pt(22, 106)}, wantErr: true},
+		{name: "empty", polygon: nil, wantErr: true},
+		{name: "too many vertices", polygon: many, wantErr: true},
+		{name: "latitude out of range", polygon: Polygon{pt(89, 105), pt(91, 106), pt(89, 107)}, wantErr: true},
+		{name: "longitude out of range", polygon: Polygon{pt(21, 179), pt(22, 181), pt(21, 180)}, wantErr: true},
+		{name: "wraps the antimeridian", polygon: Polygon{pt(-17, 179), pt(-17, -179), pt(-18, -179), pt(-18, 179)}, wantErr: true},
+		{name: "touches the antimeridian", polygon: Polygon{pt(-17, 179), pt(-17, 180), pt(-18, 180), pt(-18, 179)}, want: Polygon{pt(-17, 179), pt(-17, 180), pt(-18, 180), pt(-18, 179)}},
+		{name: "all on one parallel", polygon: Polygon{pt(21, 105), pt(21, 106), pt(21, 107)}, wantErr: true},
+		{name: "all on one meridian", polygon: Polygon{pt(21, 105), pt(22, 105), pt(23, 105)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.polygon.Normalize()
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPolygon) {
+					t.Fatalf("Normalize() = %v, %v, want ErrInvalidPolygon", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Normalize() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Normalize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolygonContains(t *testing.T) {
+	square := Polygon{pt(21, 105), pt(21, 106), pt(22, 106), pt(22, 105)}
+	// A U opening north: the notch between the arms is outside
+	u := Polygon{pt(21, 105), pt(21, 108), pt(24, 108), pt(24, 107), pt(22, 107), pt(22, 106), pt(24, 106), pt(24, 105)}
+	// A triangle whose bounding box holds far more than it does
+	triangle := Polygon{pt(10, 100), pt(10, 110), pt(20, 100)}
+	nearAntimeridian := Polygon{pt(-17, 178), pt(-17, 180), pt(-19, 180), pt(-19, 178)}
+
+	tests := []struct {
+		name     string
+		polygon  Polygon
+		lat, lng float64
+		want     bool
+	}{
+		{name: "inside square", polygon: square, lat: 21.5, lng: 105.5, want: true},
+		{name: "north of square", polygon: square, lat: 22.5, lng: 105.5},
+		{name: "east of square", polygon: square, lat: 21.5, lng: 106.5},
+		{name: "west of square", polygon: square, lat: 21.5, lng: 104.5},
+		{name: "level with a vertex", polygon: square, lat: 22, lng: 104},
+		{name: "in the left arm of the U", polygon: u, lat: 23, lng: 105.5, want: true},
+		{name: "in the right arm of the U", polygon: u, lat: 23, lng: 107.5, want: true},
+		{name: "in the notch of the U", polygon: u, lat: 23, lng: 106.5},
+		{name: "in the base of the U", polygon: u, lat: 21.5, lng: 106.5, want: true},
+		{name: "inside triangle", polygon: triangle, lat: 12, lng: 102, want: true},
+		{name: "in triangle's bounds only", polygon: triangle, lat: 18, lng: 108},
+		{name: "inside by the antimeridian", polygon: nearAntimeridian, lat: -18, lng: 179.5, want: true},
+		{name: "other side of the antimeridian", polygon: nearAntimeridian, lat: -18, lng: -179.5},
+		{name: "empty polygon", polygon: nil, lat: 21.5, lng: 105.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.polygon.Contains(tt.lat, tt.lng); got != tt.want {
+				t.Errorf("Contains(%v, %v) = %v, want %v", tt.lat, tt.lng, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolygonScanValue(t *testing.T) {
+	polygon := Polygon{pt(21, 105), pt(21, 106), pt(22, 105.5)}
+
+	value, err := polygon.Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+	var got Polygon
+	if err := got.Scan([]byte(value.(string))); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, polygon) {
+		t.Errorf("Scan(Value()) = %v, want %v", got, polygon)
+	}
+
+	if value, _ := Polygon(nil).Value(); value != "[]" {
+		t.Errorf("nil Value() = %v, want []", value)
+	}
+	if err := got.Scan(nil); err != nil || got != nil {
+		t.Errorf("Scan(nil) = %v, %v, want nil", got, err)
+	}
+	if err := got.Scan(42); err == nil {
+		t.Error("Scan(42) error = nil, want an error")
+	}
+}
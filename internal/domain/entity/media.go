@@ -14,10 +14,17 @@ type CaseMedia struct {
 	MediaType    enum.MediaType `gorm:"type:varchar(20);not null" json:"media_type"`
 	URL          string         `gorm:"type:varchar(500);not null" json:"url"`
 	ThumbnailURL *string        `gorm:"type:varchar(500)" json:"thumbnail_url,omitempty"`
+	StorageKey   string         `gorm:"type:text" json:"-"`
 	FileName     string         `gorm:"type:varchar(255)" json:"file_name,omitempty"`
 	FileSize     int64          `json:"file_size,omitempty"`
 	UploadedBy   *uuid.UUID     `gorm:"type:uuid" json:"uploaded_by,omitempty"`
+	IsSensitive  bool           `gorm:"not null;default:false" json:"is_sensitive"`
+	Placeholder  *string        `gorm:"type:text" json:"placeholder,omitempty"`
 	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"created_at"`
+
+	// Per-request presentation, set when URLs are resolved for a viewer
+	URLExpiresAt *time.Time `gorm:"-" json:"url_expires_at,omitempty"`
+	Redacted     bool       `gorm:"-" json:"redacted,omitempty"`
 }
 
 // TableName returns the table name for CaseMedia
@@ -42,6 +49,72 @@ type MediaUploadResult struct {
 	ThumbnailURL *string        `json:"thumbnail_url,omitempty"`
 	MediaType    enum.MediaType `json:"media_type"`
 	FileSize     int64          `json:"file_size"`
+	IsSensitive  bool           `json:"is_sensitive"`
+}
+
+// MediaUploadURL represents a presigned slot for uploading media directly to storage
+type MediaUploadURL struct {
+	MediaID   uuid.UUID         `json:"media_id"`
+	Key       string            `json:"key"`
+	UploadURL string            `json:"upload_url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// MediaUpload represents a resumable (tus) upload in progress. Its ID becomes
+// the CaseMedia ID once the upload completes.
+type MediaUpload struct {
+	ID              uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	CaseID          uuid.UUID      `gorm:"type:uuid;not null" json:"case_id"`
+	UploadedBy      *uuid.UUID     `gorm:"type:uuid" json:"uploaded_by,omitempty"`
+	FileName        string         `gorm:"type:varchar(255);not null" json:"file_name"`
+	ContentType     string         `gorm:"type:varchar(100);not null" json:"content_type"`
+	MediaType       enum.MediaType `gorm:"type:varchar(20);not null" json:"media_type"`
+	StorageKey      string         `gorm:"not null" json:"-"`
+	StorageUploadID string         `gorm:"not null" json:"-"`
+	UploadLength    int64          `gorm:"not null" json:"upload_length"`
+	UploadOffset    int64          `gorm:"not null;default:0" json:"upload_offset"`
+	FlushedBytes    int64          `gorm:"not null;default:0" json:"-"`
+	CompletedAt     *time.Time     `json:"completed_at,omitempty"`
+	ExpiresAt       time.Time      `gorm:"not null" json:"expires_at"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName returns the table name for MediaUpload
+func (MediaUpload) TableName() string {
+	return "media_uploads"
+}
+
+// IsComplete returns true if every byte has been received
+func (u *MediaUpload) IsComplete() bool {
+	return u.CompletedAt != nil
+}
+
+// IsAssembled returns true once storage has put the parts together. The
+// multipart upload is then gone, so StorageUploadID is cleared.
+func (u *MediaUpload) IsAssembled() bool {
+	return u.StorageUploadID == ""
+}
+
+// IsExpired returns true if the upload was abandoned past its expiry
+func (u *MediaUpload) IsExpired() bool {
+	return u.CompletedAt == nil && time.Now().After(u.ExpiresAt)
+}
+
+// MediaUploadPart represents a chunk of a resumable upload persisted to storage
+type MediaUploadPart struct {
+	UploadID   uuid.UUID `gorm:"type:uuid;primaryKey" json:"upload_id"`
+	PartNumber int32     `gorm:"primaryKey" json:"part_number"`
+	ETag       string    `gorm:"column:etag;not null" json:"etag"`
+	Size       int64     `gorm:"not null" json:"size"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName returns the table name for MediaUploadPart
+func (MediaUploadPart) TableName() string {
+	return "media_upload_parts"
 }
 
 // AllowedImageTypes contains allowed image MIME types
@@ -1,6 +1,12 @@
 package entity
 
 import (
+	"encoding/base64"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,6 +24,7 @@ type Notification struct {
 	IsRead           bool                  `gorm:"default:false" json:"is_read"`
 	IsPushed         bool                  `gorm:"default:false" json:"is_pushed"`
 	PushedAt         *time.Time            `json:"pushed_at,omitempty"`
+	ArchivedAt       *time.Time            `json:"archived_at,omitempty"`
 	CreatedAt        time.Time             `gorm:"autoCreateTime" json:"created_at"`
 
 	// Relations
@@ -30,6 +37,48 @@ func (Notification) TableName() string {
 	return "notifications"
 }
 
+// ErrInvalidCursor is returned when a page cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// NotificationCursor marks where a page of the inbox ended. The inbox is
+// ordered newest first, with the ID breaking ties in creation time.
+type NotificationCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// CursorAfter returns the cursor for the page following n
+func CursorAfter(n *Notification) *NotificationCursor {
+	return &NotificationCursor{CreatedAt: n.CreatedAt, ID: n.ID}
+}
+
+// String encodes the cursor as an opaque URL-safe token
+func (c *NotificationCursor) String() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseNotificationCursor decodes a cursor made by String
+func ParseNotificationCursor(s string) (*NotificationCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	var c NotificationCursor
+	if c.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.ID, err = uuid.Parse(id); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
 // MarkAsRead marks the notification as read
 func (n *Notification) MarkAsRead() {
 	n.IsRead = true
@@ -52,93 +101,252 @@ type NotificationPayload struct {
 	Urgency    *enum.UrgencyLevel    `json:"urgency,omitempty"`
 	DistanceKm *float64              `json:"distance_km,omitempty"`
 	Data       map[string]string     `json:"data,omitempty"`
+	// Collapse overrides the key returned by CollapseKey
+	Collapse string `json:"collapse_key,omitempty"`
+	// Badge is the recipient's unread count, shown on the app icon
+	Badge *int `json:"badge,omitempty"`
 }
 
-// NewCaseNotificationPayload creates a payload for new case notifications
-func NewCaseNotificationPayload(c *Case, distanceKm float64) *NotificationPayload {
-	body := c.Title
-	if distanceKm > 0 {
-		body = c.Title + " - " + formatDistance(distanceKm)
+// Actions a volunteer can take on a case straight from its new case push
+const (
+	CaseActionAccept  = "accept"
+	CaseActionDismiss = "dismiss"
+)
+
+// CaseActionsCategory is the notification category under which clients
+// register the accept and dismiss buttons
+const CaseActionsCategory = "CASE_ACTIONS"
+
+// SetActionToken attaches the token authorizing action to the push data
+func (p *NotificationPayload) SetActionToken(action, token string) {
+	if p.Data == nil {
+		p.Data = make(map[string]string)
 	}
+	p.Data[action+"_token"] = token
+}
 
-	return &NotificationPayload{
-		Type:       enum.NotificationTypeNewCaseNearby,
-		Title:      "Case mới gần bạn",
-		Body:       body,
-		CaseID:     &c.ID,
-		CaseType:   &c.CaseType,
-		Urgency:    &c.Urgency,
-		DistanceKm: &distanceKm,
+// SetEta attaches the expected travel time to the case to the push data
+func (p *NotificationPayload) SetEta(eta time.Duration) {
+	if p.Data == nil {
+		p.Data = make(map[string]string)
 	}
+	p.Data["eta_minutes"] = strconv.Itoa(int(math.Ceil(eta.Minutes())))
 }
 
-// CaseAcceptedNotificationPayload creates a payload for case accepted notifications
-func CaseAcceptedNotificationPayload(c *Case, volunteerName string) *NotificationPayload {
-	return &NotificationPayload{
-		Type:   enum.NotificationTypeCaseAccepted,
-		Title:  "Case đã có người nhận",
-		Body:   volunteerName + " đã nhận case của bạn",
-		CaseID: &c.ID,
+// HasActions reports whether the push carries case action tokens
+func (p *NotificationPayload) HasActions() bool {
+	_, ok := p.Data[CaseActionAccept+"_token"]
+	return ok
+}
+
+// CollapseKey identifies pushes that replace one another on a device rather
+// than stacking up: routine new nearby case alerts share one key, and
+// everything about the same case shares another. Critical alerts and those
+// carrying action tokens always get their case's key, so a later routine
+// alert cannot replace them or take their accept and dismiss buttons away.
+func (p *NotificationPayload) CollapseKey() string {
+	switch {
+	case p.Collapse != "":
+		return p.Collapse
+	case p.CaseID != nil && (p.IsCritical() || p.HasActions()):
+		return "case:" + p.CaseID.String()
+	case p.Type == enum.NotificationTypeNewCaseNearby:
+		return string(p.Type)
+	case p.CaseID != nil:
+		return "case:" + p.CaseID.String()
+	default:
+		return string(p.Type)
 	}
 }
 
-// CaseResolvedNotificationPayload creates a payload for case resolved notifications
-func CaseResolvedNotificationPayload(c *Case) *NotificationPayload {
-	return &NotificationPayload{
-		Type:   enum.NotificationTypeCaseResolved,
-		Title:  "Case đã hoàn thành",
-		Body:   "Cảm ơn bạn đã tham gia cứu hộ!",
-		CaseID: &c.ID,
+// IsCritical reports whether the push is about a critical case
+func (p *NotificationPayload) IsCritical() bool {
+	return p.Urgency != nil && *p.Urgency == enum.UrgencyCritical
+}
+
+// ToData returns the FCM data payload: the typed fields as strings plus a
+// deep link the app opens when the push is tapped. Entries in Data are kept
+// unless they clash with a typed field.
+func (p *NotificationPayload) ToData(deepLinkBase string) map[string]string {
+	data := make(map[string]string, len(p.Data)+6)
+	for k, v := range p.Data {
+		data[k] = v
+	}
+
+	data["type"] = string(p.Type)
+	if p.CaseID != nil {
+		data["case_id"] = p.CaseID.String()
+		if deepLinkBase != "" {
+			if !strings.HasSuffix(deepLinkBase, "/") {
+				deepLinkBase += "/"
+			}
+			data["link"] = deepLinkBase + "cases/" + p.CaseID.String()
+		}
 	}
+	if p.CaseType != nil {
+		data["case_type"] = string(*p.CaseType)
+	}
+	if p.Urgency != nil {
+		data["urgency"] = string(*p.Urgency)
+	}
+	if p.DistanceKm != nil {
+		data["distance_km"] = strconv.FormatFloat(*p.DistanceKm, 'f', 2, 64)
+	}
+	if p.Badge != nil {
+		data["badge"] = strconv.Itoa(*p.Badge)
+	}
+
+	return data
 }
 
-// VolunteerJoinedNotificationPayload creates a payload for volunteer joined notifications
-func VolunteerJoinedNotificationPayload(c *Case, volunteerName string) *NotificationPayload {
+// newTemplatedPayload renders the catalog texts for t in locale
+func newTemplatedPayload(t enum.NotificationType, locale enum.Locale, params map[string]string) *NotificationPayload {
+	tmpl, _ := GetNotificationTemplate(t, locale)
+	title, body := tmpl.Render(params)
 	return &NotificationPayload{
-		Type:   enum.NotificationTypeVolunteerJoined,
-		Title:  "Tình nguyện viên mới",
-		Body:   volunteerName + " đã tham gia case",
-		CaseID: &c.ID,
+		Type:  t,
+		Title: title,
+		Body:  body,
 	}
 }
 
-func formatDistance(km float64) string {
-	if km < 1 {
-		return "< 1km"
+// NewCaseNotificationPayload creates a payload for new case notifications
+func NewCaseNotificationPayload(c *Case, distanceKm float64, locale enum.Locale) *NotificationPayload {
+	payload := newTemplatedPayload(enum.NotificationTypeNewCaseNearby, locale, map[string]string{
+		"title": c.Title,
+	})
+	if distanceKm > 0 {
+		payload.Body += " - " + FormatDistance(distanceKm, locale)
 	}
-	return formatFloat(km, 1) + "km"
+
+	payload.CaseID = &c.ID
+	payload.CaseType = &c.CaseType
+	payload.Urgency = &c.Urgency
+	payload.DistanceKm = &distanceKm
+	return payload
+}
+
+// CaseAcceptedNotificationPayload creates a payload for case accepted notifications
+func CaseAcceptedNotificationPayload(c *Case, volunteerName string, locale enum.Locale) *NotificationPayload {
+	payload := newTemplatedPayload(enum.NotificationTypeCaseAccepted, locale, map[string]string{
+		"name": volunteerName,
+	})
+	payload.CaseID = &c.ID
+	return payload
+}
+
+// CaseResolvedNotificationPayload creates a payload for case resolved notifications
+func CaseResolvedNotificationPayload(c *Case, locale enum.Locale) *NotificationPayload {
+	payload := newTemplatedPayload(enum.NotificationTypeCaseResolved, locale, nil)
+	payload.CaseID = &c.ID
+	return payload
 }
 
-func formatFloat(f float64, precision int) string {
-	format := "%." + string(rune('0'+precision)) + "f"
-	return sprintf(format, f)
+// VolunteerJoinedNotificationPayload creates a payload for volunteer joined notifications
+func VolunteerJoinedNotificationPayload(c *Case, volunteerName string, locale enum.Locale) *NotificationPayload {
+	payload := newTemplatedPayload(enum.NotificationTypeVolunteerJoined, locale, map[string]string{
+		"name": volunteerName,
+	})
+	payload.CaseID = &c.ID
+	return payload
 }
 
-func sprintf(format string, f float64) string {
-	// Simple implementation for formatting
-	intPart := int(f)
-	fracPart := int((f - float64(intPart)) * 10)
-	if fracPart < 0 {
-		fracPart = -fracPart
+// AlertZoneNotificationPayload creates a payload alerting someone inside an
+// alert zone
+func AlertZoneNotificationPayload(z *AlertZone, locale enum.Locale) *NotificationPayload {
+	message := alertZoneDefaultMessages[locale.OrDefault()]
+	if z.Message != nil && strings.TrimSpace(*z.Message) != "" {
+		message = TruncateText(*z.Message, maxAlertMessageLength)
+	}
+	payload := newTemplatedPayload(enum.NotificationTypeAlertZone, locale, map[string]string{
+		"severity": AlertSeverityLabel(z.Severity, locale),
+		"title":    z.Title,
+		"message":  message,
+	})
+	payload.Data = map[string]string{
+		"alert_zone_id": z.ID.String(),
+		"severity":      string(z.Severity),
 	}
-	return itoa(intPart) + "." + itoa(fracPart)
+	// A repeated broadcast replaces the earlier one on the device
+	payload.Collapse = "alert_zone:" + z.ID.String()
+	if z.Severity == enum.AlertSeverityEmergency {
+		// Emergencies skip push throttling like critical cases do
+		critical := enum.UrgencyCritical
+		payload.Urgency = &critical
+	}
+	return payload
+}
+
+// maxAlertMessageLength bounds the alert zone message put in a notification
+const maxAlertMessageLength = 500
+
+// maxPreviewLength bounds the user-written text quoted in a notification body
+const maxPreviewLength = 140
+
+// CaseUpdateNotificationPayload creates a payload for a new case timeline entry
+func CaseUpdateNotificationPayload(c *Case, actorName, content string, locale enum.Locale) *NotificationPayload {
+	payload := newTemplatedPayload(enum.NotificationTypeCaseUpdate, locale, map[string]string{
+		"name":    actorName,
+		"title":   c.Title,
+		"content": TruncateText(content, maxPreviewLength),
+	})
+	payload.CaseID = &c.ID
+	return payload
 }
 
-func itoa(i int) string {
-	if i == 0 {
-		return "0"
+// CaseCommentNotificationPayload creates a payload for a new case comment
+func CaseCommentNotificationPayload(c *Case, actorName, content string, locale enum.Locale) *NotificationPayload {
+	payload := newTemplatedPayload(enum.NotificationTypeCaseComment, locale, map[string]string{
+		"name":    actorName,
+		"title":   c.Title,
+		"content": TruncateText(content, maxPreviewLength),
+	})
+	payload.CaseID = &c.ID
+	return payload
+}
+
+// PushSummaryNotificationPayload creates the push that stands in for the
+// notifications held back by push throttling, e.g. "7 new cases near you"
+func PushSummaryNotificationPayload(held NotificationCounts, locale enum.Locale) *NotificationPayload {
+	types := make([]enum.NotificationType, 0, len(held))
+	for t, n := range held {
+		if n > 0 {
+			types = append(types, t)
+		}
+	}
+	// Largest count first, then catalog order for a stable text
+	sort.Slice(types, func(i, j int) bool {
+		if held[types[i]] != held[types[j]] {
+			return held[types[i]] > held[types[j]]
+		}
+		return notificationTypeOrder(types[i]) < notificationTypeOrder(types[j])
+	})
+
+	lines := make([]string, len(types))
+	for i, t := range types {
+		lines[i] = SummaryLine(t, held[t], locale)
 	}
-	negative := i < 0
-	if negative {
-		i = -i
+
+	payload := &NotificationPayload{Type: enum.NotificationTypeSystem, Collapse: "summary"}
+	if len(types) == 1 {
+		payload.Type = types[0]
 	}
-	var result []byte
-	for i > 0 {
-		result = append([]byte{byte('0' + i%10)}, result...)
-		i /= 10
+	if len(lines) > 0 {
+		payload.Title = lines[0]
 	}
-	if negative {
-		result = append([]byte{'-'}, result...)
+	if len(lines) > 1 {
+		payload.Body = strings.Join(lines[1:], ", ")
+	} else {
+		payload.Body = GetSummaryFooter(locale)
+	}
+	return payload
+}
+
+func notificationTypeOrder(t enum.NotificationType) int {
+	for i, nt := range enum.AllNotificationTypes {
+		if nt == t {
+			return i
+		}
 	}
-	return string(result)
+	return len(enum.AllNotificationTypes)
 }
@@ -0,0 +1,212 @@
+package entity
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"bamboo-rescue/internal/domain/enum"
+)
+
+// NotificationTemplate holds the texts of one notification type in one
+// locale. Placeholders such as {name} are filled in by Render.
+type NotificationTemplate struct {
+	Title string
+	Body  string
+}
+
+// notificationTemplates is the catalog of push texts by type and locale.
+// Every type must have a DefaultLocale entry.
+var notificationTemplates = map[enum.NotificationType]map[enum.Locale]NotificationTemplate{
+	enum.NotificationTypeNewCaseNearby: {
+		enum.LocaleVietnamese: {Title: "Case mới gần bạn", Body: "{title}"},
+		enum.LocaleEnglish:    {Title: "New case near you", Body: "{title}"},
+	},
+	enum.NotificationTypeCaseAccepted: {
+		enum.LocaleVietnamese: {Title: "Case đã có người nhận", Body: "{name} đã nhận case của bạn"},
+		enum.LocaleEnglish:    {Title: "Your case was accepted", Body: "{name} accepted your case"},
+	},
+	enum.NotificationTypeCaseUpdate: {
+		enum.LocaleVietnamese: {Title: "{name} đã cập nhật case", Body: "{title}: {content}"},
+		enum.LocaleEnglish:    {Title: "{name} posted an update", Body: "{title}: {content}"},
+	},
+	enum.NotificationTypeCaseComment: {
+		enum.LocaleVietnamese: {Title: "{name} đã bình luận", Body: "{title}: {content}"},
+		enum.LocaleEnglish:    {Title: "{name} commented", Body: "{title}: {content}"},
+	},
+	enum.NotificationTypeCaseResolved: {
+		enum.LocaleVietnamese: {Title: "Case đã hoàn thành", Body: "Cảm ơn bạn đã tham gia cứu hộ!"},
+		enum.LocaleEnglish:    {Title: "Case resolved", Body: "Thank you for taking part in the rescue!"},
+	},
+	enum.NotificationTypeVolunteerJoined: {
+		enum.LocaleVietnamese: {Title: "Tình nguyện viên mới", Body: "{name} đã tham gia case"},
+		enum.LocaleEnglish:    {Title: "New volunteer", Body: "{name} joined the case"},
+	},
+	enum.NotificationTypeAlertZone: {
+		enum.LocaleVietnamese: {Title: "{severity}: {title}", Body: "{message}"},
+		enum.LocaleEnglish:    {Title: "{severity}: {title}", Body: "{message}"},
+	},
+}
+
+// summaryLine holds the singular and plural text describing held back
+// notifications of one type. {count} is the number held back.
+type summaryLine struct {
+	One   string
+	Other string
+}
+
+// summaryLines describes held back notifications in a push summary, by
+// type and locale
+var summaryLines = map[enum.NotificationType]map[enum.Locale]summaryLine{
+	enum.NotificationTypeNewCaseNearby: {
+		enum.LocaleVietnamese: {One: "{count} case mới gần bạn", Other: "{count} case mới gần bạn"},
+		enum.LocaleEnglish:    {One: "{count} new case near you", Other: "{count} new cases near you"},
+	},
+	enum.NotificationTypeCaseUpdate: {
+		enum.LocaleVietnamese: {One: "{count} cập nhật case mới", Other: "{count} cập nhật case mới"},
+		enum.LocaleEnglish:    {One: "{count} case update", Other: "{count} case updates"},
+	},
+	enum.NotificationTypeCaseComment: {
+		enum.LocaleVietnamese: {One: "{count} bình luận mới", Other: "{count} bình luận mới"},
+		enum.LocaleEnglish:    {One: "{count} new comment", Other: "{count} new comments"},
+	},
+	enum.NotificationTypeVolunteerJoined: {
+		enum.LocaleVietnamese: {One: "{count} tình nguyện viên mới tham gia", Other: "{count} tình nguyện viên mới tham gia"},
+		enum.LocaleEnglish:    {One: "{count} volunteer joined", Other: "{count} volunteers joined"},
+	},
+}
+
+var (
+	summaryFallbackLines = map[enum.Locale]summaryLine{
+		enum.LocaleVietnamese: {One: "{count} thông báo mới", Other: "{count} thông báo mới"},
+		enum.LocaleEnglish:    {One: "{count} new notification", Other: "{count} new notifications"},
+	}
+	summaryFooters = map[enum.Locale]string{
+		enum.LocaleVietnamese: "Mở ứng dụng để xem chi tiết",
+		enum.LocaleEnglish:    "Open the app to see them",
+	}
+)
+
+// SummaryLine describes count held back notifications of type t in locale
+func SummaryLine(t enum.NotificationType, count int, locale enum.Locale) string {
+	locale = locale.OrDefault()
+	line, ok := summaryLines[t][locale]
+	if !ok {
+		line = summaryFallbackLines[locale]
+	}
+	text := line.Other
+	if count == 1 {
+		text = line.One
+	}
+	return strings.ReplaceAll(text, "{count}", strconv.Itoa(count))
+}
+
+// GetSummaryFooter returns the body of a summary that has only one line
+func GetSummaryFooter(locale enum.Locale) string {
+	return summaryFooters[locale.OrDefault()]
+}
+
+// GetNotificationTemplate returns the template for a notification type,
+// falling back to DefaultLocale when there is no translation
+func GetNotificationTemplate(t enum.NotificationType, locale enum.Locale) (NotificationTemplate, bool) {
+	variants, ok := notificationTemplates[t]
+	if !ok {
+		return NotificationTemplate{}, false
+	}
+	if tmpl, ok := variants[locale.OrDefault()]; ok {
+		return tmpl, true
+	}
+	tmpl, ok := variants[enum.DefaultLocale]
+	return tmpl, ok
+}
+
+// Render returns the title and body with each {key} replaced by params[key]
+func (t NotificationTemplate) Render(params map[string]string) (string, string) {
+	pairs := make([]string, 0, len(params)*2)
+	for k, v := range params {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	r := strings.NewReplacer(pairs...)
+	return r.Replace(t.Title), r.Replace(t.Body)
+}
+
+// caseStatusLabels names case statuses for display, by locale
+var caseStatusLabels = map[enum.Locale]map[enum.CaseStatus]string{
+	enum.LocaleVietnamese: {
+		enum.CaseStatusPending:    "Đang chờ",
+		enum.CaseStatusAccepted:   "Đã có người nhận",
+		enum.CaseStatusInProgress: "Đang xử lý",
+		enum.CaseStatusResolved:   "Đã hoàn thành",
+		enum.CaseStatusCancelled:  "Đã huỷ",
+		enum.CaseStatusExpired:    "Đã hết hạn",
+	},
+	enum.LocaleEnglish: {
+		enum.CaseStatusPending:    "Pending",
+		enum.CaseStatusAccepted:   "Accepted",
+		enum.CaseStatusInProgress: "In progress",
+		enum.CaseStatusResolved:   "Resolved",
+		enum.CaseStatusCancelled:  "Cancelled",
+		enum.CaseStatusExpired:    "Expired",
+	},
+}
+
+// CaseStatusLabel returns the display name of status in locale
+func CaseStatusLabel(status enum.CaseStatus, locale enum.Locale) string {
+	if label, ok := caseStatusLabels[locale.OrDefault()][status]; ok {
+		return label
+	}
+	return string(status)
+}
+
+// alertSeverityLabels names alert zone severities for display, by locale
+var alertSeverityLabels = map[enum.Locale]map[enum.AlertSeverity]string{
+	enum.LocaleVietnamese: {
+		enum.AlertSeverityAdvisory:  "Lưu ý",
+		enum.AlertSeverityWarning:   "Cảnh báo",
+		enum.AlertSeverityEmergency: "Khẩn cấp",
+	},
+	enum.LocaleEnglish: {
+		enum.AlertSeverityAdvisory:  "Advisory",
+		enum.AlertSeverityWarning:   "Warning",
+		enum.AlertSeverityEmergency: "Emergency",
+	},
+}
+
+// AlertSeverityLabel returns the display name of severity in locale
+func AlertSeverityLabel(severity enum.AlertSeverity, locale enum.Locale) string {
+	if label, ok := alertSeverityLabels[locale.OrDefault()][severity]; ok {
+		return label
+	}
+	return string(severity)
+}
+
+// alertZoneDefaultMessages is the body of a zone alert that has no message
+var alertZoneDefaultMessages = map[enum.Locale]string{
+	enum.LocaleVietnamese: "Bạn đang ở trong khu vực được cảnh báo. Hãy theo dõi hướng dẫn của lực lượng cứu hộ.",
+	enum.LocaleEnglish:    "You are inside an area under alert. Follow the instructions of rescue teams.",
+}
+
+// TruncateText shortens s to at most max characters, ending it with an
+// ellipsis when cut
+func TruncateText(s string, max int) string {
+	s = strings.TrimSpace(s)
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:max-1])) + "…"
+}
+
+// FormatDistance formats a distance for display in locale, e.g. "2,9 km" in
+// Vietnamese and "2.9 km" in English
+func FormatDistance(km float64, locale enum.Locale) string {
+	if km < 1 {
+		return "< 1 km"
+	}
+
+	s := strconv.FormatFloat(math.Round(km*10)/10, 'f', -1, 64)
+	if locale.OrDefault() == enum.LocaleVietnamese {
+		s = strings.Replace(s, ".", ",", 1)
+	}
+	return s + " km"
+}
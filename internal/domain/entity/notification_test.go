@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/domain/enum"
+)
+
+func TestNotificationPayloadCollapseKey(t *testing.T) {
+	caseID := uuid.New()
+	caseKey := "case:" + caseID.String()
+	low, critical := enum.UrgencyLow, enum.UrgencyCritical
+
+	withActions := func(p *NotificationPayload) *NotificationPayload {
+		p.SetActionToken(CaseActionAccept, "accept-token")
+		p.SetActionToken(CaseActionDismiss, "dismiss-token")
+		return p
+	}
+
+	tests := []struct {
+		name    string
+		payload *NotificationPayload
+		want    string
+	}{
+		{
+			name:    "routine nearby cases share the type key",
+			payload: &NotificationPayload{Type: enum.NotificationTypeNewCaseNearby, CaseID: &caseID, Urgency: &low},
+			want:    string(enum.NotificationTypeNewCaseNearby),
+		},
+		{
+			name:    "critical nearby case keeps its own key",
+			payload: &NotificationPayload{Type: enum.NotificationTypeNewCaseNearby, CaseID: &caseID, Urgency: &critical},
+			want:    caseKey,
+		},
+		{
+			name:    "nearby case with action tokens keeps its own key",
+			payload: withActions(&NotificationPayload{Type: enum.NotificationTypeNewCaseNearby, CaseID: &caseID, Urgency: &low}),
+			want:    caseKey,
+		},
+		{
+			name:    "case updates share the case key",
+			payload: &NotificationPayload{Type: enum.NotificationTypeCaseUpdate, CaseID: &caseID},
+			want:    caseKey,
+		},
+		{
+			name:    "explicit key wins",
+			payload: &NotificationPayload{Type: enum.NotificationTypeNewCaseNearby, CaseID: &caseID, Urgency: &critical, Collapse: "summary"},
+			want:    "summary",
+		},
+		{
+			name:    "no case falls back to the type",
+			payload: &NotificationPayload{Type: enum.NotificationTypeSystem},
+			want:    string(enum.NotificationTypeSystem),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.payload.CollapseKey(); got != tt.want {
+				t.Errorf("CollapseKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
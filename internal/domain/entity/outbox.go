@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/domain/enum"
+)
+
+// OutboxJob represents a unit of background work recorded in the same
+// transaction as the change that caused it
+type OutboxJob struct {
+	ID          uuid.UUID            `gorm:"type:uuid;primaryKey" json:"id"`
+	Kind        string               `gorm:"type:varchar(100);not null" json:"kind"`
+	Payload     json.RawMessage      `gorm:"type:jsonb;not null" json:"payload"`
+	Status      enum.OutboxJobStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	Attempts    int                  `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts int                  `gorm:"not null" json:"max_attempts"`
+	RunAt       time.Time            `gorm:"not null" json:"run_at"`
+	LockedAt    *time.Time           `json:"locked_at,omitempty"`
+	LastError   *string              `gorm:"type:text" json:"last_error,omitempty"`
+	CompletedAt *time.Time           `json:"completed_at,omitempty"`
+	CreatedAt   time.Time            `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time            `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName returns the table name for OutboxJob
+func (OutboxJob) TableName() string {
+	return "outbox_jobs"
+}
+
+// OutboxStats counts outbox jobs by state
+type OutboxStats struct {
+	Pending    int64      `json:"pending"`
+	Retrying   int64      `json:"retrying"`
+	Processing int64      `json:"processing"`
+	Stale      int64      `json:"stale"`
+	Dead       int64      `json:"dead"`
+	OldestDue  *time.Time `json:"oldest_due,omitempty"`
+}
@@ -0,0 +1,105 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/domain/enum"
+)
+
+// PushThrottle tracks how many pushes a user has been sent in the current
+// budget window, and what was held back since the last summary
+type PushThrottle struct {
+	UserID       uuid.UUID          `gorm:"type:uuid;primaryKey" json:"user_id"`
+	WindowStart  time.Time          `gorm:"not null" json:"window_start"`
+	SentCount    int                `gorm:"not null;default:0" json:"sent_count"`
+	Held         NotificationCounts `gorm:"type:jsonb;not null;default:'{}'" json:"held"`
+	SummaryDueAt *time.Time         `json:"summary_due_at,omitempty"`
+	UpdatedAt    time.Time          `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName returns the table name for PushThrottle
+func (PushThrottle) TableName() string {
+	return "push_throttles"
+}
+
+// Admit reports whether a push may go out at now, counting it against the
+// budget if so. A new window starts once the current one has elapsed.
+// Bypassing pushes are always admitted but still use up the budget.
+func (t *PushThrottle) Admit(now time.Time, budget int, window time.Duration, bypass bool) bool {
+	if !now.Before(t.WindowStart.Add(window)) {
+		t.WindowStart = now
+		t.SentCount = 0
+	}
+	if !bypass && t.SentCount >= budget {
+		return false
+	}
+	t.SentCount++
+	return true
+}
+
+// Hold records a push of type nt that was not admitted. It returns true
+// when this starts a new summary, which is due delay after now.
+func (t *PushThrottle) Hold(nt enum.NotificationType, now time.Time, delay time.Duration) bool {
+	if t.Held == nil {
+		t.Held = NotificationCounts{}
+	}
+	t.Held[nt]++
+
+	if t.SummaryDueAt != nil {
+		return false
+	}
+	due := now.Add(delay)
+	t.SummaryDueAt = &due
+	return true
+}
+
+// TakeHeld returns what was held back and clears it for the next summary
+func (t *PushThrottle) TakeHeld() NotificationCounts {
+	held := t.Held
+	t.Held = NotificationCounts{}
+	t.SummaryDueAt = nil
+	return held
+}
+
+// NotificationCounts counts notifications by type
+type NotificationCounts map[enum.NotificationType]int
+
+// Total returns the sum of all counts
+func (c NotificationCounts) Total() int {
+	total := 0
+	for _, n := range c {
+		total += n
+	}
+	return total
+}
+
+func (c NotificationCounts) Value() (driver.Value, error) {
+	if c == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (c *NotificationCounts) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("failed to scan NotificationCounts: %v", value)
+	}
+	return json.Unmarshal(raw, c)
+}
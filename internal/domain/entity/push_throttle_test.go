@@ -0,0 +1,132 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"bamboo-rescue/internal/domain/enum"
+	"bamboo-rescue/pkg/clock"
+)
+
+var throttleEpoch = time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+
+func TestPushThrottleAdmit(t *testing.T) {
+	const (
+		budget = 3
+		window = time.Hour
+	)
+
+	// step is one push attempt: advance the clock, then try to admit
+	type step struct {
+		advance time.Duration
+		bypass  bool
+		want    bool
+		// wantSent is the count charged to the window afterwards
+		wantSent int
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "admits up to the budget then holds",
+			steps: []step{
+				{want: true, wantSent: 1},
+				{advance: time.Minute, want: true, wantSent: 2},
+				{advance: time.Minute, want: true, wantSent: 3},
+				{advance: time.Minute, want: false, wantSent: 3},
+				{advance: time.Minute, want: false, wantSent: 3},
+			},
+		},
+		{
+			name: "window rolls over once elapsed",
+			steps: []step{
+				{want: true, wantSent: 1},
+				{want: true, wantSent: 2},
+				{want: true, wantSent: 3},
+				{advance: window - time.Second, want: false, wantSent: 3},
+				{advance: time.Second, want: true, wantSent: 1},
+				{want: true, wantSent: 2},
+			},
+		},
+		{
+			name: "critical pushes skip the budget but use it up",
+			steps: []step{
+				{bypass: true, want: true, wantSent: 1},
+				{bypass: true, want: true, wantSent: 2},
+				{bypass: true, want: true, wantSent: 3},
+				{bypass: true, want: true, wantSent: 4},
+				{want: false, wantSent: 4},
+				{bypass: true, want: true, wantSent: 5},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewFake(throttleEpoch)
+			throttle := &PushThrottle{WindowStart: clk.Now()}
+
+			for i, s := range tt.steps {
+				clk.Advance(s.advance)
+				got := throttle.Admit(clk.Now(), budget, window, s.bypass)
+				if got != s.want {
+					t.Fatalf("step %d: Admit() = %v, want %v", i, got, s.want)
+				}
+				if throttle.SentCount != s.wantSent {
+					t.Fatalf("step %d: SentCount = %d, want %d", i, throttle.SentCount, s.wantSent)
+				}
+			}
+		})
+	}
+}
+
+func TestPushThrottleHold(t *testing.T) {
+	const delay = 10 * time.Minute
+
+	clk := clock.NewFake(throttleEpoch)
+	throttle := &PushThrottle{WindowStart: clk.Now()}
+
+	held := []struct {
+		advance      time.Duration
+		nt           enum.NotificationType
+		wantNew      bool
+		wantDueAfter time.Duration
+	}{
+		{nt: enum.NotificationTypeNewCaseNearby, wantNew: true, wantDueAfter: delay},
+		{advance: time.Minute, nt: enum.NotificationTypeNewCaseNearby, wantDueAfter: delay},
+		{advance: time.Minute, nt: enum.NotificationTypeCaseUpdate, wantDueAfter: delay},
+	}
+	for i, h := range held {
+		clk.Advance(h.advance)
+		if got := throttle.Hold(h.nt, clk.Now(), delay); got != h.wantNew {
+			t.Fatalf("hold %d: Hold() = %v, want %v", i, got, h.wantNew)
+		}
+		// The summary stays due a fixed time after the first push held
+		want := throttleEpoch.Add(h.wantDueAfter)
+		if throttle.SummaryDueAt == nil || !throttle.SummaryDueAt.Equal(want) {
+			t.Fatalf("hold %d: SummaryDueAt = %v, want %v", i, throttle.SummaryDueAt, want)
+		}
+	}
+
+	taken := throttle.TakeHeld()
+	if taken.Total() != 3 || taken[enum.NotificationTypeNewCaseNearby] != 2 || taken[enum.NotificationTypeCaseUpdate] != 1 {
+		t.Fatalf("TakeHeld() = %v, want 2 new_case_nearby and 1 case_update", taken)
+	}
+	if throttle.Held.Total() != 0 || throttle.SummaryDueAt != nil {
+		t.Fatalf("after TakeHeld: Held = %v, SummaryDueAt = %v, want both reset", throttle.Held, throttle.SummaryDueAt)
+	}
+
+	// The next push held back starts a new summary
+	clk.Advance(time.Hour)
+	if !throttle.Hold(enum.NotificationTypeCaseUpdate, clk.Now(), delay) {
+		t.Fatal("Hold() after TakeHeld = false, want a new summary")
+	}
+	if want := clk.Now().Add(delay); !throttle.SummaryDueAt.Equal(want) {
+		t.Fatalf("SummaryDueAt = %v, want %v", throttle.SummaryDueAt, want)
+	}
+	if got := throttle.TakeHeld().Total(); got != 1 {
+		t.Fatalf("TakeHeld().Total() = %d, want 1", got)
+	}
+}
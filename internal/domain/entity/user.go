@@ -1,6 +1,9 @@
 package entity
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -26,6 +29,7 @@ type User struct {
 	TotalCasesReported int           `gorm:"default:0" json:"total_cases_reported"`
 	TotalCasesResolved int           `gorm:"default:0" json:"total_cases_resolved"`
 	IsActive           bool          `gorm:"default:true" json:"is_active"`
+	Locale             enum.Locale   `gorm:"type:varchar(5);not null;default:'vi'" json:"locale"`
 	CreatedAt          time.Time     `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt          time.Time     `gorm:"autoUpdateTime" json:"updated_at"`
 
@@ -70,6 +74,10 @@ type UserPreferences struct {
 	UseCurrentLocation   bool           `gorm:"default:true" json:"use_current_location"`
 	QuietHoursStart      *string        `gorm:"type:time" json:"quiet_hours_start,omitempty"`
 	QuietHoursEnd        *string        `gorm:"type:time" json:"quiet_hours_end,omitempty"`
+	EmailEnabled         bool           `gorm:"default:true" json:"email_enabled"`
+	EmailDigestEnabled   bool           `gorm:"default:true" json:"email_digest_enabled"`
+	LastDigestAt         *time.Time     `json:"last_digest_at,omitempty"`
+	NotificationChannels ChannelMatrix  `gorm:"type:jsonb;not null;default:'{}'" json:"notification_channels"`
 	CreatedAt            time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt            time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
 }
@@ -98,6 +106,108 @@ func (p *UserPreferences) SetCenterLocation(loc *GeoPoint) {
 	p.CenterLongitude = &loc.Longitude
 }
 
+// AllowsChannel reports whether notifications of type t may be sent over
+// channel. The global push and email switches override the per-type matrix.
+func (p *UserPreferences) AllowsChannel(t enum.NotificationType, channel enum.NotificationChannel) bool {
+	switch channel {
+	case enum.NotificationChannelPush:
+		if !p.PushEnabled {
+			return false
+		}
+	case enum.NotificationChannelEmail:
+		if !p.EmailEnabled {
+			return false
+		}
+	}
+	return p.NotificationChannels.Allows(t, channel)
+}
+
+// ChannelMatrix holds per notification type channel choices. Only choices
+// that differ from the defaults need to be stored.
+type ChannelMatrix map[enum.NotificationType]map[enum.NotificationChannel]bool
+
+// defaultChannelMatrix is what a user gets for a type they never configured:
+// every type is pushed and kept in the inbox, and only outcomes of the
+// user's own cases and alerts for the area they are in are emailed
+var defaultChannelMatrix = ChannelMatrix{
+	enum.NotificationTypeNewCaseNearby:   {enum.NotificationChannelPush: true, enum.NotificationChannelInApp: true, enum.NotificationChannelEmail: false},
+	enum.NotificationTypeCaseAccepted:    {enum.NotificationChannelPush: true, enum.NotificationChannelInApp: true, enum.NotificationChannelEmail: true},
+	enum.NotificationTypeCaseUpdate:      {enum.NotificationChannelPush: true, enum.NotificationChannelInApp: true, enum.NotificationChannelEmail: false},
+	enum.NotificationTypeCaseComment:     {enum.NotificationChannelPush: true, enum.NotificationChannelInApp: true, enum.NotificationChannelEmail: false},
+	enum.NotificationTypeCaseResolved:    {enum.NotificationChannelPush: true, enum.NotificationChannelInApp: true, enum.NotificationChannelEmail: true},
+	enum.NotificationTypeVolunteerJoined: {enum.NotificationChannelPush: true, enum.NotificationChannelInApp: true, enum.NotificationChannelEmail: false},
+	enum.NotificationTypeAlertZone:       {enum.NotificationChannelPush: true, enum.NotificationChannelInApp: true, enum.NotificationChannelEmail: true},
+	enum.NotificationTypeSystem:          {enum.NotificationChannelPush: true, enum.NotificationChannelInApp: true, enum.NotificationChannelEmail: true},
+}
+
+// Allows reports whether t may be sent over channel, falling back to the
+// default when the user has not chosen
+func (m ChannelMatrix) Allows(t enum.NotificationType, channel enum.NotificationChannel) bool {
+	if allowed, ok := m[t][channel]; ok {
+		return allowed
+	}
+	return defaultChannelMatrix[t][channel]
+}
+
+// Merge applies the choices in update over m, leaving other entries as they are
+func (m ChannelMatrix) Merge(update ChannelMatrix) ChannelMatrix {
+	merged := make(ChannelMatrix, len(m)+len(update))
+	for t, channels := range m {
+		merged[t] = make(map[enum.NotificationChannel]bool, len(channels))
+		for c, allowed := range channels {
+			merged[t][c] = allowed
+		}
+	}
+	for t, channels := range update {
+		if merged[t] == nil {
+			merged[t] = make(map[enum.NotificationChannel]bool, len(channels))
+		}
+		for c, allowed := range channels {
+			merged[t][c] = allowed
+		}
+	}
+	return merged
+}
+
+// Effective returns the complete matrix with defaults filled in
+func (m ChannelMatrix) Effective() ChannelMatrix {
+	full := make(ChannelMatrix, len(enum.AllNotificationTypes))
+	for _, t := range enum.AllNotificationTypes {
+		full[t] = make(map[enum.NotificationChannel]bool, len(enum.AllNotificationChannels))
+		for _, c := range enum.AllNotificationChannels {
+			full[t][c] = m.Allows(t, c)
+		}
+	}
+	return full
+}
+
+func (m ChannelMatrix) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (m *ChannelMatrix) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("failed to scan ChannelMatrix: %v", value)
+	}
+	return json.Unmarshal(raw, m)
+}
+
 // UserStats represents user statistics
 type UserStats struct {
 	CasesReported   int `json:"cases_reported"`
@@ -110,12 +220,21 @@ type UserStats struct {
 type PushToken struct {
 	ID         uuid.UUID           `gorm:"type:uuid;primaryKey" json:"id"`
 	UserID     uuid.UUID           `gorm:"type:uuid;not null;index" json:"user_id"`
-	Token      string              `gorm:"type:varchar(500);not null;uniqueIndex" json:"token"`
+	Token      string              `gorm:"type:text;not null;uniqueIndex" json:"token"`
 	Platform   enum.DevicePlatform `gorm:"type:varchar(20);not null" json:"platform"`
 	DeviceID   *string             `gorm:"type:varchar(255)" json:"device_id,omitempty"`
 	IsActive   bool                `gorm:"default:true" json:"is_active"`
 	LastUsedAt time.Time           `gorm:"autoUpdateTime" json:"last_used_at"`
 	CreatedAt  time.Time           `gorm:"autoCreateTime" json:"created_at"`
+
+	// Web push subscription keys; Token holds the endpoint
+	P256dh    *string    `gorm:"column:p256dh;type:varchar(100)" json:"-"`
+	Auth      *string    `gorm:"type:varchar(50)" json:"-"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// Set when the token is reported as permanently undeliverable
+	DeactivatedAt      *time.Time `json:"deactivated_at,omitempty"`
+	DeactivationReason *string    `gorm:"type:varchar(50)" json:"deactivation_reason,omitempty"`
 }
 
 // TableName returns the table name for PushToken
@@ -200,6 +200,119 @@ func (v *VolunteerStatus) Scan(value interface{}) error {
 	return nil
 }
 
+// TravelMode represents how a volunteer travels to a case
+type TravelMode string
+
+const (
+	TravelModeDriving TravelMode = "driving"
+	TravelModeWalking TravelMode = "walking"
+)
+
+func (t TravelMode) IsValid() bool {
+	switch t {
+	case TravelModeDriving, TravelModeWalking:
+		return true
+	}
+	return false
+}
+
+func (t TravelMode) Value() (driver.Value, error) {
+	return string(t), nil
+}
+
+func (t *TravelMode) Scan(value interface{}) error {
+	if value == nil {
+		*t = TravelModeDriving
+		return nil
+	}
+	str, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("failed to scan TravelMode: %v", value)
+	}
+	*t = TravelMode(str)
+	return nil
+}
+
+// AlertSeverity represents how serious an alert zone is
+type AlertSeverity string
+
+const (
+	AlertSeverityAdvisory  AlertSeverity = "advisory"
+	AlertSeverityWarning   AlertSeverity = "warning"
+	AlertSeverityEmergency AlertSeverity = "emergency"
+)
+
+func (a AlertSeverity) IsValid() bool {
+	switch a {
+	case AlertSeverityAdvisory, AlertSeverityWarning, AlertSeverityEmergency:
+		return true
+	}
+	return false
+}
+
+func (a AlertSeverity) Priority() int {
+	switch a {
+	case AlertSeverityEmergency:
+		return 3
+	case AlertSeverityWarning:
+		return 2
+	case AlertSeverityAdvisory:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (a AlertSeverity) Value() (driver.Value, error) {
+	return string(a), nil
+}
+
+func (a *AlertSeverity) Scan(value interface{}) error {
+	if value == nil {
+		*a = AlertSeverityWarning
+		return nil
+	}
+	str, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("failed to scan AlertSeverity: %v", value)
+	}
+	*a = AlertSeverity(str)
+	return nil
+}
+
+// EventStatus represents the status of a disaster event
+type EventStatus string
+
+const (
+	EventStatusActive EventStatus = "active"
+	EventStatusClosed EventStatus = "closed"
+)
+
+func (e EventStatus) IsValid() bool {
+	switch e {
+	case EventStatusActive, EventStatusClosed:
+		return true
+	}
+	return false
+}
+
+func (e EventStatus) Value() (driver.Value, error) {
+	return string(e), nil
+}
+
+func (e *EventStatus) Scan(value interface{}) error {
+	if value == nil {
+		*e = EventStatusActive
+		return nil
+	}
+	str, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("failed to scan EventStatus: %v", value)
+	}
+	*e = EventStatus(str)
+	return nil
+}
+
 // AnimalType represents the type of animal
 type AnimalType string
 
@@ -344,14 +457,16 @@ const (
 	NotificationTypeNewCaseNearby   NotificationType = "new_case_nearby"
 	NotificationTypeCaseAccepted    NotificationType = "case_accepted"
 	NotificationTypeCaseUpdate      NotificationType = "case_update"
+	NotificationTypeCaseComment     NotificationType = "case_comment"
 	NotificationTypeCaseResolved    NotificationType = "case_resolved"
 	NotificationTypeVolunteerJoined NotificationType = "volunteer_joined"
+	NotificationTypeAlertZone       NotificationType = "alert_zone"
 	NotificationTypeSystem          NotificationType = "system"
 )
 
 func (n NotificationType) IsValid() bool {
 	switch n {
-	case NotificationTypeNewCaseNearby, NotificationTypeCaseAccepted, NotificationTypeCaseUpdate, NotificationTypeCaseResolved, NotificationTypeVolunteerJoined, NotificationTypeSystem:
+	case NotificationTypeNewCaseNearby, NotificationTypeCaseAccepted, NotificationTypeCaseUpdate, NotificationTypeCaseComment, NotificationTypeCaseResolved, NotificationTypeVolunteerJoined, NotificationTypeAlertZone, NotificationTypeSystem:
 		return true
 	}
 	return false
@@ -373,6 +488,42 @@ func (n *NotificationType) Scan(value interface{}) error {
 	return nil
 }
 
+// AllNotificationTypes lists every notification type, in display order
+var AllNotificationTypes = []NotificationType{
+	NotificationTypeNewCaseNearby,
+	NotificationTypeCaseAccepted,
+	NotificationTypeCaseUpdate,
+	NotificationTypeCaseComment,
+	NotificationTypeCaseResolved,
+	NotificationTypeVolunteerJoined,
+	NotificationTypeAlertZone,
+	NotificationTypeSystem,
+}
+
+// NotificationChannel represents a way a notification reaches a user
+type NotificationChannel string
+
+const (
+	NotificationChannelPush  NotificationChannel = "push"
+	NotificationChannelInApp NotificationChannel = "in_app"
+	NotificationChannelEmail NotificationChannel = "email"
+)
+
+// AllNotificationChannels lists every notification channel
+var AllNotificationChannels = []NotificationChannel{
+	NotificationChannelPush,
+	NotificationChannelInApp,
+	NotificationChannelEmail,
+}
+
+func (c NotificationChannel) IsValid() bool {
+	switch c {
+	case NotificationChannelPush, NotificationChannelInApp, NotificationChannelEmail:
+		return true
+	}
+	return false
+}
+
 // DevicePlatform represents the platform of a device
 type DevicePlatform string
 
@@ -441,3 +592,82 @@ func (u *UpdateType) Scan(value interface{}) error {
 	*u = UpdateType(str)
 	return nil
 }
+
+// OutboxJobStatus represents the state of a background job in the outbox
+type OutboxJobStatus string
+
+const (
+	OutboxJobStatusPending    OutboxJobStatus = "pending"
+	OutboxJobStatusProcessing OutboxJobStatus = "processing"
+	OutboxJobStatusDone       OutboxJobStatus = "done"
+	OutboxJobStatusDead       OutboxJobStatus = "dead"
+)
+
+func (o OutboxJobStatus) IsValid() bool {
+	switch o {
+	case OutboxJobStatusPending, OutboxJobStatusProcessing, OutboxJobStatusDone, OutboxJobStatusDead:
+		return true
+	}
+	return false
+}
+
+func (o OutboxJobStatus) Value() (driver.Value, error) {
+	return string(o), nil
+}
+
+func (o *OutboxJobStatus) Scan(value interface{}) error {
+	if value == nil {
+		*o = OutboxJobStatusPending
+		return nil
+	}
+	str, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("failed to scan OutboxJobStatus: %v", value)
+	}
+	*o = OutboxJobStatus(str)
+	return nil
+}
+
+// Locale represents the language a user receives notifications in
+type Locale string
+
+const (
+	LocaleVietnamese Locale = "vi"
+	LocaleEnglish    Locale = "en"
+
+	// DefaultLocale is used when a user has no usable locale stored
+	DefaultLocale = LocaleVietnamese
+)
+
+func (l Locale) IsValid() bool {
+	switch l {
+	case LocaleVietnamese, LocaleEnglish:
+		return true
+	}
+	return false
+}
+
+// OrDefault returns l, or DefaultLocale if l is not a supported locale
+func (l Locale) OrDefault() Locale {
+	if l.IsValid() {
+		return l
+	}
+	return DefaultLocale
+}
+
+func (l Locale) Value() (driver.Value, error) {
+	return string(l), nil
+}
+
+func (l *Locale) Scan(value interface{}) error {
+	if value == nil {
+		*l = DefaultLocale
+		return nil
+	}
+	str, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("failed to scan Locale: %v", value)
+	}
+	*l = Locale(str)
+	return nil
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/handler/dto/request"
+	dto "bamboo-rescue/internal/handler/dto/response"
+	"bamboo-rescue/internal/middleware"
+	"bamboo-rescue/internal/service"
+	"bamboo-rescue/pkg/response"
+)
+
+// AlertZoneHandler handles alert zone requests
+type AlertZoneHandler struct {
+	alertZoneService service.AlertZoneService
+}
+
+// NewAlertZoneHandler creates a new AlertZoneHandler
+func NewAlertZoneHandler(alertZoneService service.AlertZoneService) *AlertZoneHandler {
+	return &AlertZoneHandler{
+		alertZoneService: alertZoneService,
+	}
+}
+
+// Create handles alert zone creation
+// @Summary Create an alert zone
+// @Description Draw an area under alert, such as the land below a broken dyke. The polygon may not cross the antimeridian and the zone must expire within 30 days. With broadcast set, everyone whose last known location is inside is alerted at once. Coordinators only.
+// @Tags Alert Zones
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.CreateAlertZoneRequest true "Alert zone"
+// @Success 201 {object} response.Response{data=dto.AlertZoneResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /alert-zones [post]
+func (h *AlertZoneHandler) Create(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == nil {
+		response.Error(c, middleware.ErrUnauthorized)
+		return
+	}
+
+	var req request.CreateAlertZoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	zone, err := h.alertZoneService.Create(c.Request.Context(), *userID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Created(c, dto.ToAlertZoneResponse(zone))
+}
+
+// GetActive handles listing alert zones
+// @Summary Get active alert zones
+// @Description Get the alert zones in force, most severe first, optionally only those meeting a bounding box
+// @Tags Alert Zones
+// @Produce json
+// @Param bbox query string false "Area as west,south,east,north"
+// @Success 200 {object} response.Response{data=[]dto.AlertZoneResponse}
+// @Failure 400 {object} response.Response
+// @Router /alert-zones [get]
+func (h *AlertZoneHandler) GetActive(c *gin.Context) {
+	var req request.GetAlertZonesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+	bbox, ok := req.BoundingBox()
+	if !ok {
+		response.Error(c, middleware.NewAppError("VALIDATION_ERROR", "bbox must be west,south,east,north in degrees", 400))
+		return
+	}
+
+	zones, err := h.alertZoneService.GetActive(c.Request.Context(), bbox)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, dto.ToAlertZoneListResponse(zones))
+}
+
+// GetByID handles get alert zone by ID
+// @Summary Get alert zone
+// @Description Get an alert zone, including ended and expired ones
+// @Tags Alert Zones
+// @Produce json
+// @Param id path string true "Alert zone ID"
+// @Success 200 {object} response.Response{data=dto.AlertZoneResponse}
+// @Failure 404 {object} response.Response
+// @Router /alert-zones/{id} [get]
+func (h *AlertZoneHandler) GetByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Invalid alert zone ID", 400))
+		return
+	}
+
+	zone, err := h.alertZoneService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, dto.ToAlertZoneResponse(zone))
+}
+
+// Broadcast handles alerting everyone in a zone
+// @Summary Broadcast alert zone
+// @Description Alert every user whose last known location is inside the zone. A repeated broadcast replaces the earlier push on devices. Coordinators only.
+// @Tags Alert Zones
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Alert zone ID"
+// @Success 202 {object} response.Response{data=dto.AlertZoneResponse}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /alert-zones/{id}/broadcast [post]
+func (h *AlertZoneHandler) Broadcast(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Invalid alert zone ID", 400))
+		return
+	}
+
+	zone, err := h.alertZoneService.Broadcast(c.Request.Context(), id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, dto.ToAlertZoneResponse(zone))
+}
+
+// End handles lifting an alert zone
+// @Summary End alert zone
+// @Description Lift an alert zone before it expires. Cases already tagged with it keep the tag. Coordinators only.
+// @Tags Alert Zones
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Alert zone ID"
+// @Success 200 {object} response.Response{data=dto.AlertZoneResponse}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /alert-zones/{id} [delete]
+func (h *AlertZoneHandler) End(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Invalid alert zone ID", 400))
+		return
+	}
+
+	zone, err := h.alertZoneService.End(c.Request.Context(), id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, dto.ToAlertZoneResponse(zone))
+}
@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/pkg/olc"
+)
+
+// caseExportWriter writes cases to an export file one at a time, so an
+// export of any size never sits in memory
+type caseExportWriter interface {
+	begin() error
+	write(c *entity.Case) error
+	end() error
+}
+
+// caseExportFormat is a file format cases can be exported to
+type caseExportFormat struct {
+	contentType string
+	extension   string
+	newWriter   func(w io.Writer, includePII bool) caseExportWriter
+}
+
+var caseExportFormats = map[string]caseExportFormat{
+	"geojson": {
+		contentType: "application/geo+json",
+		extension:   "geojson",
+		newWriter: func(w io.Writer, includePII bool) caseExportWriter {
+			return &geoJSONCaseWriter{w: w, columns: caseExportColumnsFor(includePII)}
+		},
+	},
+	"kml": {
+		contentType: "application/vnd.google-earth.kml+xml",
+		extension:   "kml",
+		newWriter: func(w io.Writer, includePII bool) caseExportWriter {
+			return &kmlCaseWriter{w: w, columns: caseExportColumnsFor(includePII)}
+		},
+	},
+	"csv": {
+		contentType: "text/csv; charset=utf-8",
+		extension:   "csv",
+		newWriter: func(w io.Writer, includePII bool) caseExportWriter {
+			return &csvCaseWriter{w: w, csv: csv.NewWriter(w), columns: caseExportColumnsFor(includePII)}
+		},
+	},
+}
+
+// caseExportColumn is one field of an exported case. value returns nil
+// when the case has nothing for it.
+type caseExportColumn struct {
+	name string
+	// pii columns identify or describe people and are only exported to
+	// coordinators
+	pii   bool
+	value func(c *entity.Case) interface{}
+}
+
+// caseExportColumns are exported in this order. The position is carried
+// by the geometry in GeoJSON and KML, and by columns in CSV.
+var caseExportColumns = []caseExportColumn{
+	{name: "id", value: func(c *entity.Case) interface{} { return c.ID.String() }},
+	{name: "case_type", value: func(c *entity.Case) interface{} { return string(c.CaseType) }},
+	{name: "status", value: func(c *entity.Case) interface{} { return string(c.Status) }},
+	{name: "urgency", value: func(c *entity.Case) interface{} { return string(c.Urgency) }},
+	{name: "title", value: func(c *entity.Case) interface{} { return c.Title }},
+	{name: "description", value: func(c *entity.Case) interface{} { return optional(c.Description) }},
+	{name: "plus_code", value: func(c *entity.Case) interface{} { return olc.Encode(c.Latitude, c.Longitude, 10) }},
+	{name: "address", value: func(c *entity.Case) interface{} { return optional(c.Address) }},
+	{name: "location_note", value: func(c *entity.Case) interface{} { return optional(c.LocationNote) }},
+	{name: "province", value: func(c *entity.Case) interface{} { return optional(c.Province) }},
+	{name: "district", value: func(c *entity.Case) interface{} { return optional(c.District) }},
+	{name: "ward", value: func(c *entity.Case) interface{} { return optional(c.Ward) }},
+	{name: "volunteer_count", value: func(c *entity.Case) interface{} { return c.VolunteerCount }},
+	{name: "created_at", value: func(c *entity.Case) interface{} { return c.CreatedAt }},
+	{name: "updated_at", value: func(c *entity.Case) interface{} { return c.UpdatedAt }},
+	{name: "accepted_at", value: func(c *entity.Case) interface{} { return optional(c.AcceptedAt) }},
+	{name: "resolved_at", value: func(c *entity.Case) interface{} { return optional(c.ResolvedAt) }},
+	{name: "reporter_name", pii: true, value: func(c *entity.Case) interface{} { return optional(c.ReporterName) }},
+	{name: "reporter_phone", pii: true, value: func(c *entity.Case) interface{} { return c.ReporterPhone }},
+
+	// Animal details
+	{name: "animal_type", value: animalDetail(func(d *entity.CaseAnimalDetails) interface{} { return string(d.AnimalType) })},
+	{name: "animal_type_other", value: animalDetail(func(d *entity.CaseAnimalDetails) interface{} { return optional(d.AnimalTypeOther) })},
+	{name: "animal_condition", value: animalDetail(func(d *entity.CaseAnimalDetails) interface{} { return string(d.Condition) })},
+	{name: "animal_count", value: animalDetail(func(d *entity.CaseAnimalDetails) interface{} { return d.EstimatedCount })},
+
+	// Flood details
+	{name: "people_count", value: floodDetail(func(d *entity.CaseFloodDetails) interface{} { return optional(d.PeopleCount) })},
+	{name: "has_children", value: floodDetail(func(d *entity.CaseFloodDetails) interface{} { return d.HasChildren })},
+	{name: "has_elderly", value: floodDetail(func(d *entity.CaseFloodDetails) interface{} { return d.HasElderly })},
+	{name: "has_disabled", value: floodDetail(func(d *entity.CaseFloodDetails) interface{} { return d.HasDisabled })},
+	{name: "water_level_cm", value: floodDetail(func(d *entity.CaseFloodDetails) interface{} { return optional(d.WaterLevelCm) })},
+	{name: "floor_level", value: floodDetail(func(d *entity.CaseFloodDetails) interface{} { return optional(d.FloorLevel) })},
+	{name: "has_power", value: floodDetail(func(d *entity.CaseFloodDetails) interface{} { return optional(d.HasPower) })},
+	{name: "has_food_water", value: floodDetail(func(d *entity.CaseFloodDetails) interface{} { return optional(d.HasFoodWater) })},
+	{name: "medical_needs", pii: true, value: floodDetail(func(d *entity.CaseFloodDetails) interface{} { return optional(d.MedicalNeeds) })},
+
+	// Accident details
+	{name: "accident_type", value: accidentDetail(func(d *entity.CaseAccidentDetails) interface{} { return string(d.AccidentType) })},
+	{name: "victim_count", value: accidentDetail(func(d *entity.CaseAccidentDetails) interface{} { return d.VictimCount })},
+	{name: "has_unconscious", value: accidentDetail(func(d *entity.CaseAccidentDetails) interface{} { return d.HasUnconscious })},
+	{name: "has_bleeding", value: accidentDetail(func(d *entity.CaseAccidentDetails) interface{} { return d.HasBleeding })},
+	{name: "has_fracture", value: accidentDetail(func(d *entity.CaseAccidentDetails) interface{} { return d.HasFracture })},
+	{name: "is_trapped", value: accidentDetail(func(d *entity.CaseAccidentDetails) interface{} { return d.IsTrapped })},
+	{name: "hazard_present", value: accidentDetail(func(d *entity.CaseAccidentDetails) interface{} { return d.HazardPresent })},
+	{name: "hazard_description", value: accidentDetail(func(d *entity.CaseAccidentDetails) interface{} { return optional(d.HazardDescription) })},
+}
+
+// caseExportColumnsFor returns the columns a caller may see
+func caseExportColumnsFor(includePII bool) []caseExportColumn {
+	if includePII {
+		return caseExportColumns
+	}
+	columns := make([]caseExportColumn, 0, len(caseExportColumns))
+	for _, col := range caseExportColumns {
+		if !col.pii {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
+
+func animalDetail(value func(d *entity.CaseAnimalDetails) interface{}) func(c *entity.Case) interface{} {
+	return func(c *entity.Case) interface{} {
+		if c.AnimalDetails == nil {
+			return nil
+		}
+		return value(c.AnimalDetails)
+	}
+}
+
+func floodDetail(value func(d *entity.CaseFloodDetails) interface{}) func(c *entity.Case) interface{} {
+	return func(c *entity.Case) interface{} {
+		if c.FloodDetails == nil {
+			return nil
+		}
+		return value(c.FloodDetails)
+	}
+}
+
+func accidentDetail(value func(d *entity.CaseAccidentDetails) interface{}) func(c *entity.Case) interface{} {
+	return func(c *entity.Case) interface{} {
+		if c.AccidentDetails == nil {
+			return nil
+		}
+		return value(c.AccidentDetails)
+	}
+}
+
+// optional dereferences p, or returns nil for a nil pointer
+func optional[T any](p *T) interface{} {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+// formatExportValue renders a column value as text
+func formatExportValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// geoJSONCaseWriter writes a GeoJSON FeatureCollection of points
+type geoJSONCaseWriter struct {
+	w       io.Writer
+	columns []caseExportColumn
+	count   int
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONPoint           `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+func (g *geoJSONCaseWriter) begin() error {
+	_, err := io.WriteString(g.w, `{"type":"FeatureCollection","features":[`)
+	return err
+}
+
+func (g *geoJSONCaseWriter) write(c *entity.Case) error {
+	feature := geoJSONFeature{
+		Type: "Feature",
+		// GeoJSON puts longitude first
+		Geometry:   geoJSONPoint{Type: "Point", Coordinates: [2]float64{c.Longitude, c.Latitude}},
+		Properties: make(map[string]interface{}, len(g.columns)),
+	}
+	for _, col := range g.columns {
+		feature.Properties[col.name] = col.value(c)
+	}
+
+	data, err := json.Marshal(feature)
+	if err != nil {
+		return err
+	}
+	if g.count > 0 {
+		data = append([]byte{','}, data...)
+	}
+	g.count++
+	_, err = g.w.Write(data)
+	return err
+}
+
+func (g *geoJSONCaseWriter) end() error {
+	_, err := io.WriteString(g.w, "]}\n")
+	return err
+}
+
+// kmlCaseWriter writes a KML document of placemarks
+type kmlCaseWriter struct {
+	w       io.Writer
+	columns []caseExportColumn
+}
+
+func (k *kmlCaseWriter) begin() error {
+	_, err := io.WriteString(k.w, xml.Header+
+		`<kml xmlns="http://www.opengis.net/kml/2.2"><Document><name>Cases</name>`+"\n")
+	return err
+}
+
+func (k *kmlCaseWriter) write(c *entity.Case) error {
+	var b strings.Builder
+	b.WriteString("<Placemark><name>")
+	xmlEscape(&b, c.Title)
+	b.WriteString("</name><ExtendedData>")
+	for _, col := range k.columns {
+		value := col.value(c)
+		if value == nil {
+			continue
+		}
+		b.WriteString(`<Data name="`)
+		b.WriteString(col.name)
+		b.WriteString(`"><value>`)
+		xmlEscape(&b, formatExportValue(value))
+		b.WriteString("</value></Data>")
+	}
+	// KML puts longitude first
+	fmt.Fprintf(&b, "</ExtendedData><Point><coordinates>%s,%s</coordinates></Point></Placemark>\n",
+		strconv.FormatFloat(c.Longitude, 'f', -1, 64), strconv.FormatFloat(c.Latitude, 'f', -1, 64))
+
+	_, err := io.WriteString(k.w, b.String())
+	return err
+}
+
+func (k *kmlCaseWriter) end() error {
+	_, err := io.WriteString(k.w, "</Document></kml>\n")
+	return err
+}
+
+func xmlEscape(b *strings.Builder, s string) {
+	_ = xml.EscapeText(b, []byte(s))
+}
+
+// csvCaseWriter writes a CSV file with a header row
+type csvCaseWriter struct {
+	w       io.Writer
+	csv     *csv.Writer
+	columns []caseExportColumn
+}
+
+func (w *csvCaseWriter) begin() error {
+	// The byte order mark makes Excel read the file as UTF-8
+	if _, err := io.WriteString(w.w, "\ufeff"); err != nil {
+		return err
+	}
+
+	header := make([]string, 0, len(w.columns)+2)
+	header = append(header, "latitude", "longitude")
+	for _, col := range w.columns {
+		header = append(header, col.name)
+	}
+	return w.csv.Write(header)
+}
+
+func (w *csvCaseWriter) write(c *entity.Case) error {
+	record := make([]string, 0, len(w.columns)+2)
+	record = append(record, formatExportValue(c.Latitude), formatExportValue(c.Longitude))
+	for _, col := range w.columns {
+		value := col.value(c)
+		text := formatExportValue(value)
+		// Text from reporters must not run as a spreadsheet formula
+		if _, isText := value.(string); isText && strings.ContainsAny(text[:min(len(text), 1)], "=+-@\t\r") {
+			text = "'" + text
+		}
+		record = append(record, text)
+	}
+	// The csv writer buffers; the handler flushes it through Flush
+	return w.csv.Write(record)
+}
+
+func (w *csvCaseWriter) end() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"bamboo-rescue/internal/domain/entity"
 	"bamboo-rescue/internal/handler/dto/request"
 	dto "bamboo-rescue/internal/handler/dto/response"
 	"bamboo-rescue/internal/middleware"
@@ -14,13 +17,15 @@ import (
 
 // CaseHandler handles case requests
 type CaseHandler struct {
-	caseService service.CaseService
+	caseService      service.CaseService
+	alertZoneService service.AlertZoneService
 }
 
 // NewCaseHandler creates a new CaseHandler
-func NewCaseHandler(caseService service.CaseService) *CaseHandler {
+func NewCaseHandler(caseService service.CaseService, alertZoneService service.AlertZoneService) *CaseHandler {
 	return &CaseHandler{
-		caseService: caseService,
+		caseService:      caseService,
+		alertZoneService: alertZoneService,
 	}
 }
 
@@ -54,7 +59,8 @@ func (h *CaseHandler) Create(c *gin.Context) {
 
 // GetByID handles get case by ID
 // @Summary Get case by ID
-// @Description Get detailed information about a case
+// @Description Get detailed information about a case. Sensitive media is only
+// @Description visible to the reporter and volunteers; others get a blurred placeholder.
 // @Tags Cases
 // @Security BearerAuth
 // @Produce json
@@ -70,7 +76,7 @@ func (h *CaseHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	caseEntity, err := h.caseService.GetByID(c.Request.Context(), id)
+	caseEntity, err := h.caseService.GetByIDForViewer(c.Request.Context(), id, middleware.GetUserID(c))
 	if err != nil {
 		response.Error(c, err)
 		return
@@ -88,6 +94,9 @@ func (h *CaseHandler) GetByID(c *gin.Context) {
 // @Param type query string false "Case type filter"
 // @Param status query string false "Status filter"
 // @Param urgency query string false "Urgency filter"
+// @Param province query string false "Province filter"
+// @Param district query string false "District filter"
+// @Param ward query string false "Ward filter"
 // @Param page query int false "Page number"
 // @Param limit query int false "Limit per page"
 // @Success 200 {object} response.Response{data=[]dto.CaseResponse}
@@ -110,6 +119,40 @@ func (h *CaseHandler) GetCases(c *gin.Context) {
 	response.SuccessWithMeta(c, dto.ToCaseListResponse(cases), meta)
 }
 
+// GetAreaStats handles case counts by administrative area
+// @Summary Get case stats by area
+// @Description Count total, active and resolved cases per province, district or ward
+// @Tags Cases
+// @Produce json
+// @Param group_by query string false "province (default), district or ward"
+// @Param province query string false "Only count cases in this province"
+// @Param district query string false "Only count cases in this district"
+// @Success 200 {object} response.Response{data=[]dto.CaseAreaStatsResponse}
+// @Failure 400 {object} response.Response
+// @Router /cases/stats/areas [get]
+func (h *CaseHandler) GetAreaStats(c *gin.Context) {
+	var req request.GetCaseAreaStatsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	switch req.GroupBy {
+	case "", "province", "district", "ward":
+	default:
+		response.Error(c, middleware.NewAppError("VALIDATION_ERROR", "group_by must be province, district or ward", 400))
+		return
+	}
+
+	stats, err := h.caseService.GetAreaStats(c.Request.Context(), &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, dto.ToCaseAreaStatsResponse(stats))
+}
+
 // GetNearby handles get nearby cases
 // @Summary Get nearby cases
 // @Description Get cases near a location
@@ -121,7 +164,9 @@ func (h *CaseHandler) GetCases(c *gin.Context) {
 // @Param radius_km query number false "Radius in km (default 10)"
 // @Param case_type query string false "Case type filter"
 // @Param status query string false "Status filter"
+// @Param include_alert_zones query bool false "Also return the active alert zones in the radius, wrapping the cases in an object"
 // @Success 200 {object} response.Response{data=[]dto.CaseNearbyResponse}
+// @Success 200 {object} response.Response{data=dto.NearbyCasesResponse} "With include_alert_zones"
 // @Failure 400 {object} response.Response
 // @Router /cases/nearby [get]
 func (h *CaseHandler) GetNearby(c *gin.Context) {
@@ -136,8 +181,173 @@ func (h *CaseHandler) GetNearby(c *gin.Context) {
 		response.Error(c, err)
 		return
 	}
+	if !req.IncludeAlertZones {
+		response.Success(c, http.StatusOK, dto.ToCaseNearbyListResponse(cases))
+		return
+	}
+
+	radiusKm := req.RadiusKm
+	if radiusKm <= 0 {
+		radiusKm = 10
+	}
+	zones, err := h.alertZoneService.GetActive(c.Request.Context(), entity.NewBoundingBox(req.Latitude, req.Longitude, float64(radiusKm)))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, dto.NearbyCasesResponse{
+		Cases:      dto.ToCaseNearbyListResponse(cases),
+		AlertZones: dto.ToAlertZoneListResponse(zones),
+	})
+}
+
+// GetInBounds handles the case map viewport
+// @Summary Get cases in a map viewport
+// @Description Get the active cases in a bounding box. Up to zoom 14 they are grouped into grid clusters with counts by type and urgency; closer in, up to 500 cases are returned, most urgent first. The active alert zones in the box are returned with them.
+// @Tags Cases
+// @Produce json
+// @Param bbox query string true "Viewport as west,south,east,north"
+// @Param zoom query int true "Map zoom level (0-22)"
+// @Param types query []string false "Case type filter"
+// @Success 200 {object} response.Response{data=dto.CaseMapResponse}
+// @Failure 400 {object} response.Response
+// @Router /cases/in-bounds [get]
+func (h *CaseHandler) GetInBounds(c *gin.Context) {
+	var req request.GetCasesInBoundsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	caseMap, err := h.caseService.GetInBounds(c.Request.Context(), &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	// The service has already checked the box
+	bbox, _ := req.BoundingBox()
+	zones, err := h.alertZoneService.GetActive(c.Request.Context(), &bbox)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp := dto.ToCaseMapResponse(caseMap)
+	resp.AlertZones = dto.ToAlertZoneListResponse(zones)
+	response.Success(c, http.StatusOK, resp)
+}
+
+// exportFlushEvery is how many cases are written between flushes of an
+// export to the client
+const exportFlushEvery = 100
+
+// exportWriteWindow is how long an export may go without a flush. Each flush
+// extends the deadline, so a large export outlives the server-wide write
+// timeout while a stalled one is still cut off.
+const exportWriteWindow = time.Minute
+
+// Export handles case export
+// @Summary Export cases
+// @Description Download the cases matching the filters as GeoJSON, KML or CSV, with the details of each case type. Reporter contact details and medical needs are only included for coordinators. The file is streamed; a file cut off early is missing its closing and should be retried.
+// @Tags Cases
+// @Produce application/geo+json
+// @Produce application/vnd.google-earth.kml+xml
+// @Produce text/csv
+// @Param format path string true "Export format" Enums(geojson, kml, csv)
+// @Param q query string false "Search in title, description and address"
+// @Param type query string false "Case type filter"
+// @Param status query string false "Status filter"
+// @Param urgency query string false "Urgency filter"
+// @Param province query string false "Province filter"
+// @Param district query string false "District filter"
+// @Param ward query string false "Ward filter"
+// @Param from query string false "Created on or after (YYYY-MM-DD or RFC 3339)"
+// @Param to query string false "Created on or before (YYYY-MM-DD or RFC 3339)"
+// @Param bbox query string false "Area as west,south,east,north"
+// @Param event_id query string false "Disaster event ID"
+// @Success 200 {file} file
+// @Failure 400 {object} response.Response
+// @Router /cases/export/{format} [get]
+func (h *CaseHandler) Export(c *gin.Context) {
+	format, ok := caseExportFormats[c.Param("format")]
+	if !ok {
+		response.Error(c, middleware.NewAppError("VALIDATION_ERROR", "format must be geojson, kml or csv", 400))
+		return
+	}
+
+	var req request.ExportCasesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	rc := http.NewResponseController(c.Writer)
+	extendDeadline := func() {
+		_ = rc.SetWriteDeadline(time.Now().Add(exportWriteWindow))
+	}
+	extendDeadline()
+
+	writer := format.newWriter(c.Writer, middleware.IsCoordinator(c))
+	flush := func() {
+		if w, ok := writer.(*csvCaseWriter); ok {
+			w.csv.Flush()
+		}
+		c.Writer.Flush()
+		extendDeadline()
+	}
+
+	// Headers are sent with the first case, so a bad filter still gets a
+	// JSON error
+	started := false
+	start := func() error {
+		started = true
+		c.Header("Content-Type", format.contentType)
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="cases-%s.%s"`,
+			time.Now().UTC().Format("20060102"), format.extension))
+		c.Status(http.StatusOK)
+		return writer.begin()
+	}
+
+	written := 0
+	err := h.caseService.ExportCases(c.Request.Context(), &req, func(cs *entity.Case) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := writer.write(cs); err != nil {
+			return err
+		}
+		written++
+		if written%exportFlushEvery == 0 {
+			flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if !started {
+			response.Error(c, err)
+			return
+		}
+		// The status is already sent; the document is left unclosed so the
+		// download shows as broken rather than complete
+		flush()
+		c.Abort()
+		return
+	}
 
-	response.Success(c, http.StatusOK, dto.ToCaseNearbyListResponse(cases))
+	if !started {
+		if err := start(); err != nil {
+			c.Abort()
+			return
+		}
+	}
+	if err := writer.end(); err != nil {
+		c.Abort()
+		return
+	}
+	flush()
 }
 
 // GetMyCases handles get user's reported cases
@@ -208,6 +418,40 @@ func (h *CaseHandler) GetMyVolunteerCases(c *gin.Context) {
 	response.SuccessWithMeta(c, dto.ToCaseListResponse(cases), meta)
 }
 
+// GetMyFollowedCases handles get cases the user follows
+// @Summary Get my followed cases
+// @Description Get cases the current user follows, most recently followed first
+// @Tags Cases
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {object} response.Response{data=[]dto.CaseResponse}
+// @Failure 401 {object} response.Response
+// @Router /cases/my-followed-cases [get]
+func (h *CaseHandler) GetMyFollowedCases(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == nil {
+		response.Error(c, middleware.ErrUnauthorized)
+		return
+	}
+
+	var req request.PaginationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	cases, total, err := h.caseService.GetFollowedCases(c.Request.Context(), *userID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	meta := response.NewMeta(req.GetDefaultPage(), req.GetDefaultLimit(), total)
+	response.SuccessWithMeta(c, dto.ToCaseListResponse(cases), meta)
+}
+
 // Update handles case update
 // @Summary Update a case
 // @Description Update an existing case
@@ -357,6 +601,136 @@ func (h *CaseHandler) Withdraw(c *gin.Context) {
 	response.Success(c, http.StatusOK, gin.H{"message": "Withdrawn from case successfully"})
 }
 
+// Dismiss handles volunteer dismissing a case
+// @Summary Dismiss a case
+// @Description Turn a case down so no more notifications about it are sent
+// @Tags Cases
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Case ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /cases/{id}/dismiss [post]
+func (h *CaseHandler) Dismiss(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == nil {
+		response.Error(c, middleware.ErrUnauthorized)
+		return
+	}
+
+	idStr := c.Param("id")
+	caseID, err := uuid.Parse(idStr)
+	if err != nil {
+		response.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Invalid case ID", 400))
+		return
+	}
+
+	if err := h.caseService.Dismiss(c.Request.Context(), caseID, *userID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, gin.H{"message": "Case dismissed successfully"})
+}
+
+// TakeAction handles accepting or dismissing a case from a push notification
+// @Summary Act on a case from a notification
+// @Description Accept or dismiss a case with the action token from a new case push, without signing in
+// @Tags Cases
+// @Accept json
+// @Produce json
+// @Param request body request.CaseActionRequest true "Case action request"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /cases/actions [post]
+func (h *CaseHandler) TakeAction(c *gin.Context) {
+	var req request.CaseActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	claims, err := h.caseService.TakeAction(c.Request.Context(), &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	message := "Case accepted successfully"
+	if claims.Action == entity.CaseActionDismiss {
+		message = "Case dismissed successfully"
+	}
+	response.Success(c, http.StatusOK, gin.H{
+		"message": message,
+		"caseId":  claims.CaseID,
+		"action":  claims.Action,
+	})
+}
+
+// Follow handles following a case
+// @Summary Follow a case
+// @Description Get notified about a case's updates, comments and status changes
+// @Tags Cases
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Case ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /cases/{id}/follow [post]
+func (h *CaseHandler) Follow(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == nil {
+		response.Error(c, middleware.ErrUnauthorized)
+		return
+	}
+
+	caseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Invalid case ID", 400))
+		return
+	}
+
+	if err := h.caseService.Follow(c.Request.Context(), caseID, *userID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, gin.H{"message": "Case followed successfully"})
+}
+
+// Unfollow handles unfollowing a case
+// @Summary Unfollow a case
+// @Description Stop notifications about a case
+// @Tags Cases
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Case ID"
+// @Success 200 {object} response.Response
+// @Router /cases/{id}/follow [delete]
+func (h *CaseHandler) Unfollow(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == nil {
+		response.Error(c, middleware.ErrUnauthorized)
+		return
+	}
+
+	caseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Invalid case ID", 400))
+		return
+	}
+
+	if err := h.caseService.Unfollow(c.Request.Context(), caseID, *userID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, gin.H{"message": "Case unfollowed successfully"})
+}
+
 // UpdateVolunteerStatus handles updating volunteer status
 // @Summary Update volunteer status
 // @Description Update volunteer status (arrived, completed)
@@ -0,0 +1,364 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/handler/dto/request"
+	dto "bamboo-rescue/internal/handler/dto/response"
+	"bamboo-rescue/internal/middleware"
+	"bamboo-rescue/internal/service"
+	"bamboo-rescue/pkg/response"
+)
+
+// DisasterEventHandler handles disaster event requests
+type DisasterEventHandler struct {
+	eventService service.DisasterEventService
+	caseService  service.CaseService
+}
+
+// NewDisasterEventHandler creates a new DisasterEventHandler
+func NewDisasterEventHandler(eventService service.DisasterEventService, caseService service.CaseService) *DisasterEventHandler {
+	return &DisasterEventHandler{
+		eventService: eventService,
+		caseService:  caseService,
+	}
+}
+
+// Create handles disaster event creation
+// @Summary Create a disaster event
+// @Description Declare a large incident, such as a typhoon over a city, to group its cases. Cases reported inside the area during the event's time span are assigned to it, including those reported before it was declared. The event's dispatch settings override the defaults for its cases. The creator and coordinator_ids own the event. Coordinators only.
+// @Tags Disaster Events
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.CreateDisasterEventRequest true "Disaster event"
+// @Success 201 {object} response.Response{data=dto.DisasterEventResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /events [post]
+func (h *DisasterEventHandler) Create(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == nil {
+		response.Error(c, middleware.ErrUnauthorized)
+		return
+	}
+
+	var req request.CreateDisasterEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	event, err := h.eventService.Create(c.Request.Context(), *userID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Created(c, dto.ToDisasterEventResponse(event))
+}
+
+// List handles listing disaster events
+// @Summary Get disaster events
+// @Description Get disaster events, latest started first
+// @Tags Disaster Events
+// @Produce json
+// @Param status query string false "active or closed"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} response.Response{data=[]dto.DisasterEventResponse}
+// @Failure 400 {object} response.Response
+// @Router /events [get]
+func (h *DisasterEventHandler) List(c *gin.Context) {
+	var req request.GetDisasterEventsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+	if req.Status != nil && !req.Status.IsValid() {
+		response.Error(c, middleware.NewAppError("VALIDATION_ERROR", "status must be active or closed", 400))
+		return
+	}
+
+	events, total, err := h.eventService.List(c.Request.Context(), &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	meta := response.NewMeta(req.GetDefaultPage(), req.GetDefaultLimit(), total)
+	response.SuccessWithMeta(c, dto.ToDisasterEventListResponse(events), meta)
+}
+
+// GetByID handles get disaster event by ID
+// @Summary Get disaster event
+// @Description Get a disaster event with its area, time span and dispatch settings
+// @Tags Disaster Events
+// @Produce json
+// @Param id path string true "Event ID"
+// @Success 200 {object} response.Response{data=dto.DisasterEventResponse}
+// @Failure 404 {object} response.Response
+// @Router /events/{id} [get]
+func (h *DisasterEventHandler) GetByID(c *gin.Context) {
+	id, ok := parseEventID(c)
+	if !ok {
+		return
+	}
+
+	event, err := h.eventService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, dto.ToDisasterEventResponse(event))
+}
+
+// Update handles disaster event update
+// @Summary Update disaster event
+// @Description Change an event's details, status, area, time span, dispatch settings or coordinators. Widening the area or span, or reopening the event, assigns the cases it now takes in. A new volunteer limit applies to the event's open cases too. Only the event's coordinators.
+// @Tags Disaster Events
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param request body request.UpdateDisasterEventRequest true "Fields to change"
+// @Success 200 {object} response.Response{data=dto.DisasterEventResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /events/{id} [put]
+func (h *DisasterEventHandler) Update(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == nil {
+		response.Error(c, middleware.ErrUnauthorized)
+		return
+	}
+
+	id, ok := parseEventID(c)
+	if !ok {
+		return
+	}
+
+	var req request.UpdateDisasterEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	event, err := h.eventService.Update(c.Request.Context(), id, *userID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, dto.ToDisasterEventResponse(event))
+}
+
+// GetCases handles listing an event's cases
+// @Summary Get disaster event cases
+// @Description Get the cases grouped under an event, with the filters of the case list
+// @Tags Disaster Events
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param q query string false "Search query"
+// @Param type query string false "Case type filter"
+// @Param status query string false "Status filter"
+// @Param urgency query string false "Urgency filter"
+// @Param province query string false "Province filter"
+// @Param district query string false "District filter"
+// @Param ward query string false "Ward filter"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} response.Response{data=[]dto.CaseResponse}
+// @Failure 404 {object} response.Response
+// @Router /events/{id}/cases [get]
+func (h *DisasterEventHandler) GetCases(c *gin.Context) {
+	id, ok := parseEventID(c)
+	if !ok {
+		return
+	}
+
+	var req request.GetCasesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	if _, err := h.eventService.GetByID(c.Request.Context(), id); err != nil {
+		response.Error(c, err)
+		return
+	}
+	req.EventID = &id
+
+	cases, total, err := h.caseService.GetCases(c.Request.Context(), &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	meta := response.NewMeta(req.GetDefaultPage(), req.GetDefaultLimit(), total)
+	response.SuccessWithMeta(c, dto.ToCaseListResponse(cases), meta)
+}
+
+// GetStats handles an event's case counts
+// @Summary Get disaster event stats
+// @Description Count an event's cases in all, active and resolved, by status, type and urgency, and the volunteers working on them
+// @Tags Disaster Events
+// @Produce json
+// @Param id path string true "Event ID"
+// @Success 200 {object} response.Response{data=dto.DisasterEventStatsResponse}
+// @Failure 404 {object} response.Response
+// @Router /events/{id}/stats [get]
+func (h *DisasterEventHandler) GetStats(c *gin.Context) {
+	id, ok := parseEventID(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.eventService.GetStats(c.Request.Context(), id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, dto.ToDisasterEventStatsResponse(stats))
+}
+
+// GetMap handles the map viewport of an event
+// @Summary Get disaster event cases in a map viewport
+// @Description Get an event's active cases in a bounding box, clustered up to zoom 14 as on the case map. The event's bounds make a good first viewport.
+// @Tags Disaster Events
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param bbox query string true "Viewport as west,south,east,north"
+// @Param zoom query int true "Map zoom level (0-22)"
+// @Param types query []string false "Case type filter"
+// @Success 200 {object} response.Response{data=dto.CaseMapResponse}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /events/{id}/map [get]
+func (h *DisasterEventHandler) GetMap(c *gin.Context) {
+	id, ok := parseEventID(c)
+	if !ok {
+		return
+	}
+
+	var req request.GetCasesInBoundsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	if _, err := h.eventService.GetByID(c.Request.Context(), id); err != nil {
+		response.Error(c, err)
+		return
+	}
+	req.EventID = &id
+
+	caseMap, err := h.caseService.GetInBounds(c.Request.Context(), &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, dto.ToCaseMapResponse(caseMap))
+}
+
+// LinkCases handles grouping cases under an event by hand
+// @Summary Link cases to disaster event
+// @Description Group cases under an event, moving them from any other event the caller also coordinates. They take the event's volunteer limit. Only the event's coordinators.
+// @Tags Disaster Events
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param request body request.LinkEventCasesRequest true "Cases to link"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /events/{id}/cases [post]
+func (h *DisasterEventHandler) LinkCases(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == nil {
+		response.Error(c, middleware.ErrUnauthorized)
+		return
+	}
+
+	id, ok := parseEventID(c)
+	if !ok {
+		return
+	}
+
+	var req request.LinkEventCasesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+	if len(req.CaseIDs) == 0 || len(req.CaseIDs) > 500 {
+		response.Error(c, middleware.NewAppError("VALIDATION_ERROR", "case_ids must have 1 to 500 cases", 400))
+		return
+	}
+
+	if err := h.eventService.LinkCases(c.Request.Context(), id, *userID, req.CaseIDs); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, gin.H{"message": "Cases linked successfully"})
+}
+
+// UnlinkCase handles taking a case out of an event
+// @Summary Unlink case from disaster event
+// @Description Take a case out of an event. It keeps the volunteer limit the event gave it. Only the event's coordinators.
+// @Tags Disaster Events
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Event ID"
+// @Param caseId path string true "Case ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /events/{id}/cases/{caseId} [delete]
+func (h *DisasterEventHandler) UnlinkCase(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == nil {
+		response.Error(c, middleware.ErrUnauthorized)
+		return
+	}
+
+	id, ok := parseEventID(c)
+	if !ok {
+		return
+	}
+	caseID, err := uuid.Parse(c.Param("caseId"))
+	if err != nil {
+		response.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Invalid case ID", 400))
+		return
+	}
+
+	if err := h.eventService.UnlinkCase(c.Request.Context(), id, *userID, caseID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, gin.H{"message": "Case unlinked successfully"})
+}
+
+// parseEventID parses the event ID in the path, responding with an error
+// if it is malformed
+func parseEventID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Invalid event ID", 400))
+		return uuid.Nil, false
+	}
+	return id, true
+}
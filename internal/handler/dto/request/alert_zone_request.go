@@ -0,0 +1,39 @@
+package request
+
+import (
+	"time"
+
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/domain/enum"
+)
+
+// CreateAlertZoneRequest represents alert zone creation request
+type CreateAlertZoneRequest struct {
+	Title    string             `json:"title" validate:"required,min=3,max=200"`
+	Message  *string            `json:"message" validate:"omitempty,max=2000"`
+	Severity enum.AlertSeverity `json:"severity" validate:"required,oneof=advisory warning emergency"`
+	// Polygon is the zone's outline; the last point joins back to the first
+	Polygon   []entity.GeoPoint `json:"polygon" validate:"required,min=3,max=500"`
+	ExpiresAt time.Time         `json:"expires_at" validate:"required"`
+	// Broadcast alerts everyone inside the zone as soon as it is created
+	Broadcast bool `json:"broadcast"`
+}
+
+// GetAlertZonesRequest represents an active alert zone query
+type GetAlertZonesRequest struct {
+	// BBox, when set, limits the zones to those meeting west,south,east,north
+	BBox string `form:"bbox"`
+}
+
+// BoundingBox returns the area to list zones in, nil for everywhere, and
+// false if BBox is malformed
+func (r *GetAlertZonesRequest) BoundingBox() (*entity.BoundingBox, bool) {
+	if r.BBox == "" {
+		return nil, true
+	}
+	bbox, ok := parseBBox(r.BBox)
+	if !ok {
+		return nil, false
+	}
+	return &bbox, true
+}
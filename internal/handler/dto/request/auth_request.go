@@ -13,6 +13,7 @@ type RegisterRequest struct {
 	Phone       *string `json:"phone" validate:"omitempty,min=10,max=20"`
 	Password    string  `json:"password" validate:"required,min=6"`
 	DisplayName string  `json:"display_name" validate:"required,min=2,max=100"`
+	Locale      string  `json:"locale" validate:"omitempty,oneof=vi en"`
 }
 
 // OAuthRequest represents OAuth login request body
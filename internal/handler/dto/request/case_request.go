@@ -1,20 +1,33 @@
 package request
 
-import "bamboo-rescue/internal/domain/enum"
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/domain/enum"
+)
 
 // CreateCaseRequest represents case creation request
 type CreateCaseRequest struct {
-	CaseType      enum.CaseType     `json:"case_type" validate:"required,oneof=animal flood accident"`
-	Urgency       enum.UrgencyLevel `json:"urgency" validate:"required,oneof=low medium high critical"`
-	Latitude      float64           `json:"latitude" validate:"required,min=-90,max=90"`
-	Longitude     float64           `json:"longitude" validate:"required,min=-180,max=180"`
-	Address       *string           `json:"address"`
-	LocationNote  *string           `json:"location_note" validate:"omitempty,max=500"`
-	Title         string            `json:"title" validate:"required,min=5,max=200"`
-	Description   *string           `json:"description"`
-	ReporterName  *string           `json:"reporter_name" validate:"omitempty,max=100"`
-	ReporterPhone string            `json:"reporter_phone" validate:"required,min=10,max=20"`
-	IsAnonymous   bool              `json:"is_anonymous"`
+	CaseType  enum.CaseType     `json:"case_type" validate:"required,oneof=animal flood accident"`
+	Urgency   enum.UrgencyLevel `json:"urgency" validate:"required,oneof=low medium high critical"`
+	Latitude  float64           `json:"latitude" validate:"omitempty,min=-90,max=90"`
+	Longitude float64           `json:"longitude" validate:"omitempty,min=-180,max=180"`
+	// Location, when set, gives the position as coordinates, a plus code or
+	// a map link, and Latitude and Longitude only help place short codes.
+	// Without it Latitude and Longitude are required.
+	Location      *string `json:"location" validate:"omitempty,max=2048"`
+	Address       *string `json:"address"`
+	LocationNote  *string `json:"location_note" validate:"omitempty,max=500"`
+	Title         string  `json:"title" validate:"required,min=5,max=200"`
+	Description   *string `json:"description"`
+	ReporterName  *string `json:"reporter_name" validate:"omitempty,max=100"`
+	ReporterPhone string  `json:"reporter_phone" validate:"required,min=10,max=20"`
+	IsAnonymous   bool    `json:"is_anonymous"`
 
 	// Animal details
 	AnimalType           *enum.AnimalType      `json:"animal_type" validate:"omitempty,oneof=dog cat bird other"`
@@ -50,21 +63,163 @@ type CreateCaseRequest struct {
 
 // GetNearbyCasesRequest represents nearby cases query request
 type GetNearbyCasesRequest struct {
-	Latitude  float64           `form:"lat" validate:"required,min=-90,max=90"`
-	Longitude float64           `form:"lng" validate:"required,min=-180,max=180"`
-	RadiusKm  int               `form:"radius" validate:"omitempty,min=1,max=100"`
-	Types     []enum.CaseType   `form:"types"`
-	Limit     int               `form:"limit" validate:"omitempty,min=1,max=100"`
+	Latitude  float64         `form:"lat" validate:"required,min=-90,max=90"`
+	Longitude float64         `form:"lng" validate:"required,min=-180,max=180"`
+	RadiusKm  int             `form:"radius" validate:"omitempty,min=1,max=100"`
+	Types     []enum.CaseType `form:"types"`
+	Limit     int             `form:"limit" validate:"omitempty,min=1,max=100"`
+	// IncludeAlertZones also returns the active alert zones in the radius
+	IncludeAlertZones bool `form:"include_alert_zones"`
+}
+
+// GetCasesInBoundsRequest represents a case map viewport query
+type GetCasesInBoundsRequest struct {
+	// BBox is the viewport as west,south,east,north in degrees
+	BBox  string          `form:"bbox" validate:"required"`
+	Zoom  *int            `form:"zoom" validate:"required,min=0,max=22"`
+	Types []enum.CaseType `form:"types"`
+	// EventID limits the map to one disaster event's cases. It is set from
+	// the path of event-scoped routes.
+	EventID *uuid.UUID `form:"-"`
+}
+
+// BoundingBox parses BBox
+func (r *GetCasesInBoundsRequest) BoundingBox() (entity.BoundingBox, bool) {
+	return parseBBox(r.BBox)
+}
+
+// parseBBox parses west,south,east,north. A west edge east of the east
+// edge crosses the antimeridian.
+func parseBBox(s string) (entity.BoundingBox, bool) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return entity.BoundingBox{}, false
+	}
+	var edges [4]float64
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || math.IsNaN(v) {
+			return entity.BoundingBox{}, false
+		}
+		edges[i] = v
+	}
+
+	bbox := entity.BoundingBox{MinLng: edges[0], MinLat: edges[1], MaxLng: edges[2], MaxLat: edges[3]}
+	if bbox.MinLat < -90 || bbox.MaxLat > 90 || bbox.MinLat > bbox.MaxLat ||
+		bbox.MinLng < -180 || bbox.MinLng > 180 || bbox.MaxLng < -180 || bbox.MaxLng > 180 {
+		return entity.BoundingBox{}, false
+	}
+	return bbox, true
+}
+
+// ExportCasesRequest represents a case export query. It takes the filters
+// of GetCasesRequest, a creation date range and a bounding box.
+type ExportCasesRequest struct {
+	Query    string             `form:"q"`
+	Type     *enum.CaseType     `form:"type"`
+	Status   *enum.CaseStatus   `form:"status"`
+	Urgency  *enum.UrgencyLevel `form:"urgency"`
+	Province string             `form:"province"`
+	District string             `form:"district"`
+	Ward     string             `form:"ward"`
+	// From and To are dates (2006-01-02) or RFC 3339 times. A bare To date
+	// includes that whole day.
+	From string `form:"from"`
+	To   string `form:"to"`
+	// BBox is west,south,east,north in degrees
+	BBox string `form:"bbox"`
+	// EventID limits the export to one disaster event's cases
+	EventID string `form:"event_id"`
+}
+
+// Area returns the administrative units to filter by
+func (r *ExportCasesRequest) Area() entity.AdminArea {
+	return entity.Address{Province: r.Province, District: r.District, Ward: r.Ward}.Area()
+}
+
+// Period parses From and To; a nil bound is open
+func (r *ExportCasesRequest) Period() (from, to *time.Time, ok bool) {
+	if r.From != "" {
+		t, _, ok := parseDateOrTime(r.From)
+		if !ok {
+			return nil, nil, false
+		}
+		from = &t
+	}
+	if r.To != "" {
+		t, dateOnly, ok := parseDateOrTime(r.To)
+		if !ok {
+			return nil, nil, false
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		to = &t
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, false
+	}
+	return from, to, true
+}
+
+// BoundingBox parses BBox, which is optional
+func (r *ExportCasesRequest) BoundingBox() (*entity.BoundingBox, bool) {
+	if r.BBox == "" {
+		return nil, true
+	}
+	bbox, ok := parseBBox(r.BBox)
+	if !ok {
+		return nil, false
+	}
+	return &bbox, true
 }
 
+// Event parses EventID, which is optional
+func (r *ExportCasesRequest) Event() (*uuid.UUID, bool) {
+	if r.EventID == "" {
+		return nil, true
+	}
+	id, err := uuid.Parse(r.EventID)
+	if err != nil {
+		return nil, false
+	}
+	return &id, true
+}
+
+// parseDateOrTime parses an RFC 3339 time, or a date taken as midnight in
+// Vietnam
+func parseDateOrTime(s string) (t time.Time, dateOnly bool, ok bool) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, true
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, vietnamTime); err == nil {
+		return t, true, true
+	}
+	return time.Time{}, false, false
+}
+
+// vietnamTime is UTC+7, which has no daylight saving
+var vietnamTime = time.FixedZone("ICT", 7*60*60)
+
 // GetCasesRequest represents cases list query with search and pagination
 type GetCasesRequest struct {
-	Query   string          `form:"q"`
-	Type    *enum.CaseType  `form:"type"`
-	Status  *enum.CaseStatus `form:"status"`
-	Urgency *enum.UrgencyLevel `form:"urgency"`
-	Page    int             `form:"page" validate:"omitempty,min=1"`
-	Limit   int             `form:"limit" validate:"omitempty,min=1,max=100"`
+	Query    string             `form:"q"`
+	Type     *enum.CaseType     `form:"type"`
+	Status   *enum.CaseStatus   `form:"status"`
+	Urgency  *enum.UrgencyLevel `form:"urgency"`
+	Province string             `form:"province"`
+	District string             `form:"district"`
+	Ward     string             `form:"ward"`
+	Page     int                `form:"page" validate:"omitempty,min=1"`
+	Limit    int                `form:"limit" validate:"omitempty,min=1,max=100"`
+	// EventID limits the list to one disaster event's cases. It is set from
+	// the path of event-scoped routes.
+	EventID *uuid.UUID `form:"-"`
+}
+
+// Area returns the administrative units to filter by
+func (r *GetCasesRequest) Area() entity.AdminArea {
+	return entity.Address{Province: r.Province, District: r.District, Ward: r.Ward}.Area()
 }
 
 // GetDefaultPage returns the page number or default
@@ -88,6 +243,31 @@ func (r *GetCasesRequest) GetOffset() int {
 	return (r.GetDefaultPage() - 1) * r.GetDefaultLimit()
 }
 
+// GetCaseAreaStatsRequest represents a case count breakdown query. Counts
+// are per unit of GroupBy, optionally within a province or district.
+type GetCaseAreaStatsRequest struct {
+	GroupBy  string `form:"group_by" validate:"omitempty,oneof=province district ward"`
+	Province string `form:"province"`
+	District string `form:"district"`
+}
+
+// Tier returns the administrative tier to group by, provinces by default
+func (r *GetCaseAreaStatsRequest) Tier() entity.AdminTier {
+	switch r.GroupBy {
+	case "district":
+		return entity.AdminTierDistrict
+	case "ward":
+		return entity.AdminTierWard
+	default:
+		return entity.AdminTierProvince
+	}
+}
+
+// Area returns the administrative units to count within
+func (r *GetCaseAreaStatsRequest) Area() entity.AdminArea {
+	return entity.Address{Province: r.Province, District: r.District}.Area()
+}
+
 // UpdateCaseRequest represents case update request
 type UpdateCaseRequest struct {
 	Title        *string            `json:"title" validate:"omitempty,min=5,max=200"`
@@ -102,6 +282,17 @@ type UpdateCaseRequest struct {
 type AcceptCaseRequest struct {
 	Latitude  *float64 `json:"latitude" validate:"omitempty,min=-90,max=90"`
 	Longitude *float64 `json:"longitude" validate:"omitempty,min=-180,max=180"`
+
+	// TravelMode is how the volunteer gets to the case, driving by default
+	TravelMode *enum.TravelMode `json:"travel_mode" validate:"omitempty,oneof=driving walking"`
+}
+
+// CaseActionRequest represents a case action taken from a push notification,
+// authorized by the action token it carried instead of a session
+type CaseActionRequest struct {
+	Token     string   `json:"token" validate:"required"`
+	Latitude  *float64 `json:"latitude" validate:"omitempty,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" validate:"omitempty,min=-180,max=180"`
 }
 
 // UpdateVolunteerStatusRequest represents volunteer status update request
@@ -0,0 +1,107 @@
+package request
+
+import (
+	"testing"
+	"time"
+
+	"bamboo-rescue/internal/domain/entity"
+)
+
+func TestParseBBox(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   entity.BoundingBox
+		wantOK bool
+	}{
+		{name: "west south east north", input: "105.7,20.9,106.0,21.1", want: entity.BoundingBox{MinLng: 105.7, MinLat: 20.9, MaxLng: 106, MaxLat: 21.1}, wantOK: true},
+		{name: "spaces around edges", input: " 105.7 , 20.9 , 106.0 , 21.1 ", want: entity.BoundingBox{MinLng: 105.7, MinLat: 20.9, MaxLng: 106, MaxLat: 21.1}, wantOK: true},
+		{name: "whole world", input: "-180,-90,180,90", want: entity.BoundingBox{MinLng: -180, MinLat: -90, MaxLng: 180, MaxLat: 90}, wantOK: true},
+		{name: "crosses the antimeridian", input: "179,-18,-179,-17", want: entity.BoundingBox{MinLng: 179, MinLat: -18, MaxLng: -179, MaxLat: -17}, wantOK: true},
+		{name: "empty", input: ""},
+		{name: "three edges", input: "105.7,20.9,106.0"},
+		{name: "five edges", input: "105.7,20.9,106.0,21.1,0"},
+		{name: "not a number", input: "105.7,south,106.0,21.1"},
+		{name: "nan", input: "NaN,20.9,106.0,21.1"},
+		{name: "infinite", input: "-Inf,20.9,106.0,21.1"},
+		{name: "south past the pole", input: "105.7,-91,106.0,21.1"},
+		{name: "north past the pole", input: "105.7,20.9,106.0,91"},
+		{name: "south above north", input: "105.7,21.1,106.0,20.9"},
+		{name: "west out of range", input: "-181,20.9,106.0,21.1"},
+		{name: "east out of range", input: "105.7,20.9,181,21.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseBBox(tt.input)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("parseBBox(%q) = %+v, %v, want %+v, %v", tt.input, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestExportCasesRequestBoundingBox(t *testing.T) {
+	if bbox, ok := (&ExportCasesRequest{}).BoundingBox(); !ok || bbox != nil {
+		t.Errorf("BoundingBox() without bbox = %+v, %v, want nil, true", bbox, ok)
+	}
+	if bbox, ok := (&ExportCasesRequest{BBox: "1,2,3"}).BoundingBox(); ok || bbox != nil {
+		t.Errorf("BoundingBox() with a bad bbox = %+v, %v, want nil, false", bbox, ok)
+	}
+	want := entity.BoundingBox{MinLng: 105, MinLat: 20, MaxLng: 106, MaxLat: 21}
+	if bbox, ok := (&ExportCasesRequest{BBox: "105,20,106,21"}).BoundingBox(); !ok || bbox == nil || *bbox != want {
+		t.Errorf("BoundingBox() = %+v, %v, want %+v, true", bbox, ok, want)
+	}
+}
+
+func TestExportCasesRequestPeriod(t *testing.T) {
+	utc := func(s string) *time.Time {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			panic(err)
+		}
+		return &t
+	}
+
+	tests := []struct {
+		name     string
+		from, to string
+		wantFrom *time.Time
+		wantTo   *time.Time
+		wantOK   bool
+	}{
+		{name: "open on both ends", wantOK: true},
+		// Dates are midnight in Vietnam, 17:00 UTC the day before
+		{name: "from date", from: "2024-09-07", wantFrom: utc("2024-09-06T17:00:00Z"), wantOK: true},
+		{name: "to date includes the whole day", to: "2024-09-07", wantTo: utc("2024-09-07T17:00:00Z"), wantOK: true},
+		{name: "one day", from: "2024-09-07", to: "2024-09-07", wantFrom: utc("2024-09-06T17:00:00Z"), wantTo: utc("2024-09-07T17:00:00Z"), wantOK: true},
+		{name: "to date at month end", to: "2024-02-29", wantTo: utc("2024-02-29T17:00:00Z"), wantOK: true},
+		{name: "rfc 3339 times", from: "2024-09-07T08:00:00+07:00", to: "2024-09-07T12:30:00Z", wantFrom: utc("2024-09-07T01:00:00Z"), wantTo: utc("2024-09-07T12:30:00Z"), wantOK: true},
+		{name: "to time is exact", to: "2024-09-07T00:00:00+07:00", wantTo: utc("2024-09-06T17:00:00Z"), wantOK: true},
+		{name: "date and time mixed", from: "2024-09-07", to: "2024-09-07T12:00:00+07:00", wantFrom: utc("2024-09-06T17:00:00Z"), wantTo: utc("2024-09-07T05:00:00Z"), wantOK: true},
+		{name: "from equals to", from: "2024-09-07T00:00:00Z", to: "2024-09-07T00:00:00Z"},
+		{name: "from after to", from: "2024-09-08", to: "2024-09-07"},
+		{name: "from after to time", from: "2024-09-07T12:00:00+07:00", to: "2024-09-07T04:00:00Z"},
+		{name: "invalid from", from: "07/09/2024"},
+		{name: "invalid to", to: "yesterday"},
+		{name: "invalid date", from: "2024-02-30"},
+		{name: "time without zone", to: "2024-09-07T12:00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ExportCasesRequest{From: tt.from, To: tt.to}
+			from, to, ok := r.Period()
+			if ok != tt.wantOK || !sameTime(from, tt.wantFrom) || !sameTime(to, tt.wantTo) {
+				t.Errorf("Period() = %v, %v, %v, want %v, %v, %v", from, to, ok, tt.wantFrom, tt.wantTo, tt.wantOK)
+			}
+		})
+	}
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
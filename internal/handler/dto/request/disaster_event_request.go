@@ -0,0 +1,54 @@
+package request
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/domain/enum"
+)
+
+// CreateDisasterEventRequest represents disaster event creation request
+type CreateDisasterEventRequest struct {
+	Name        string  `json:"name" validate:"required,min=3,max=200"`
+	Description *string `json:"description" validate:"omitempty,max=5000"`
+	// Area is the event's outline; the last point joins back to the first
+	Area []entity.GeoPoint `json:"area" validate:"required,min=3,max=500"`
+	// StartsAt defaults to now; cases reported before it are not assigned
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+	// Dispatch settings for the event's cases
+	NotifyRadiusKm *int `json:"notify_radius_km" validate:"omitempty,min=1,max=100"`
+	MaxVolunteers  *int `json:"max_volunteers" validate:"omitempty,min=1,max=50"`
+	// CoordinatorIDs are coordinators who own the event with its creator
+	CoordinatorIDs []uuid.UUID `json:"coordinator_ids" validate:"omitempty,max=50"`
+}
+
+// UpdateDisasterEventRequest represents disaster event update request. Only
+// the fields sent are changed.
+type UpdateDisasterEventRequest struct {
+	Name        *string           `json:"name" validate:"omitempty,min=3,max=200"`
+	Description *string           `json:"description" validate:"omitempty,max=5000"`
+	Status      *enum.EventStatus `json:"status" validate:"omitempty,oneof=active closed"`
+	Area        []entity.GeoPoint `json:"area" validate:"omitempty,min=3,max=500"`
+	StartsAt    *time.Time        `json:"starts_at"`
+	EndsAt      *time.Time        `json:"ends_at"`
+	// ClearEndsAt reopens the event's time span
+	ClearEndsAt bool `json:"clear_ends_at"`
+	// Dispatch settings; zero puts one back to the default
+	NotifyRadiusKm *int `json:"notify_radius_km" validate:"omitempty,min=0,max=100"`
+	MaxVolunteers  *int `json:"max_volunteers" validate:"omitempty,min=0,max=50"`
+	// CoordinatorIDs, when sent, replaces the event's coordinators
+	CoordinatorIDs []uuid.UUID `json:"coordinator_ids" validate:"omitempty,min=1,max=50"`
+}
+
+// GetDisasterEventsRequest represents disaster event list query
+type GetDisasterEventsRequest struct {
+	Status *enum.EventStatus `form:"status" validate:"omitempty,oneof=active closed"`
+	PaginationRequest
+}
+
+// LinkEventCasesRequest represents a request to group cases under an event
+type LinkEventCasesRequest struct {
+	CaseIDs []uuid.UUID `json:"case_ids" validate:"required,min=1,max=500"`
+}
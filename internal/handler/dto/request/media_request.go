@@ -0,0 +1,29 @@
+package request
+
+import "github.com/google/uuid"
+
+// CreateUploadURLRequest represents a presigned upload URL request
+type CreateUploadURLRequest struct {
+	CaseID      uuid.UUID `json:"case_id" validate:"required"`
+	FileName    string    `json:"file_name" validate:"required,max=255"`
+	ContentType string    `json:"content_type" validate:"required"`
+	FileSize    int64     `json:"file_size" validate:"required,min=1"`
+	IsSensitive bool      `json:"is_sensitive"`
+}
+
+// FinalizeUploadRequest represents a request to register a directly uploaded file
+type FinalizeUploadRequest struct {
+	CaseID   uuid.UUID `json:"case_id" validate:"required"`
+	Key      string    `json:"key" validate:"required"`
+	FileName string    `json:"file_name" validate:"omitempty,max=255"`
+}
+
+// CreateTusUploadRequest represents a tus upload creation, parsed from the
+// Upload-Length and Upload-Metadata headers
+type CreateTusUploadRequest struct {
+	CaseID       uuid.UUID `json:"case_id" validate:"required"`
+	FileName     string    `json:"filename" validate:"required,max=255"`
+	ContentType  string    `json:"filetype" validate:"required"`
+	UploadLength int64     `json:"upload_length" validate:"required,min=1"`
+	IsSensitive  bool      `json:"sensitive"`
+}
@@ -1,17 +1,19 @@
 package request
 
-// GetNotificationsRequest represents notification list request
-type GetNotificationsRequest struct {
-	Page  int `form:"page" validate:"omitempty,min=1"`
-	Limit int `form:"limit" validate:"omitempty,min=1,max=100"`
-}
+import (
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/domain/enum"
+)
 
-// GetDefaultPage returns the page number or default
-func (r *GetNotificationsRequest) GetDefaultPage() int {
-	if r.Page <= 0 {
-		return 1
-	}
-	return r.Page
+// GetNotificationsRequest represents notification list request. Pages are
+// continued with the cursor returned by the previous page.
+type GetNotificationsRequest struct {
+	Limit    int                    `form:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor   string                 `form:"cursor"`
+	Type     *enum.NotificationType `form:"type"`
+	CaseID   string                 `form:"case_id" validate:"omitempty,uuid"`
+	Read     *bool                  `form:"read"`
+	Archived bool                   `form:"archived"`
 }
 
 // GetDefaultLimit returns the limit or default
@@ -22,9 +24,9 @@ func (r *GetNotificationsRequest) GetDefaultLimit() int {
 	return r.Limit
 }
 
-// GetOffset calculates the offset for pagination
-func (r *GetNotificationsRequest) GetOffset() int {
-	return (r.GetDefaultPage() - 1) * r.GetDefaultLimit()
+// NotificationIDsRequest represents a bulk action on notifications
+type NotificationIDsRequest struct {
+	IDs []uuid.UUID `json:"ids" validate:"required,min=1,max=100"`
 }
 
 // GeocodeReverseRequest represents reverse geocoding request
@@ -33,10 +35,13 @@ type GeocodeReverseRequest struct {
 	Longitude float64 `form:"lng" validate:"required,min=-180,max=180"`
 }
 
-// GeocodeSearchRequest represents geocoding search request
+// GeocodeSearchRequest represents geocoding search request. The
+// requester's location, when given, ranks nearby results first.
 type GeocodeSearchRequest struct {
-	Query string `form:"q" validate:"required,min=2"`
-	Limit int    `form:"limit" validate:"omitempty,min=1,max=10"`
+	Query     string   `form:"query" validate:"required,min=2"`
+	Limit     int      `form:"limit" validate:"omitempty,min=1,max=10"`
+	Latitude  *float64 `form:"latitude" validate:"omitempty,min=-90,max=90"`
+	Longitude *float64 `form:"longitude" validate:"omitempty,min=-180,max=180"`
 }
 
 // GetDefaultLimit returns the limit or default
@@ -46,3 +51,11 @@ func (r *GeocodeSearchRequest) GetDefaultLimit() int {
 	}
 	return r.Limit
 }
+
+// ParseLocationRequest represents a request to read a shared position.
+// The requester's location, when given, places short plus codes.
+type ParseLocationRequest struct {
+	Input     string   `form:"input" validate:"required,max=2048"`
+	Latitude  *float64 `form:"latitude" validate:"omitempty,min=-90,max=90"`
+	Longitude *float64 `form:"longitude" validate:"omitempty,min=-180,max=180"`
+}
@@ -0,0 +1,8 @@
+package request
+
+// GetOutboxJobsRequest represents a query for stuck outbox jobs
+type GetOutboxJobsRequest struct {
+	PaginationRequest
+	Status string `form:"status"`
+	Kind   string `form:"kind"`
+}
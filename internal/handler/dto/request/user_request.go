@@ -4,9 +4,10 @@ import "bamboo-rescue/internal/domain/enum"
 
 // UpdateUserRequest represents user profile update request
 type UpdateUserRequest struct {
-	DisplayName *string `json:"display_name" validate:"omitempty,min=2,max=100"`
-	Phone       *string `json:"phone" validate:"omitempty,min=10,max=20"`
-	AvatarURL   *string `json:"avatar_url" validate:"omitempty,url"`
+	DisplayName *string      `json:"display_name" validate:"omitempty,min=2,max=100"`
+	Phone       *string      `json:"phone" validate:"omitempty,min=10,max=20"`
+	AvatarURL   *string      `json:"avatar_url" validate:"omitempty,url"`
+	Locale      *enum.Locale `json:"locale" validate:"omitempty,oneof=vi en"`
 }
 
 // UpdateLocationRequest represents location update request
@@ -29,6 +30,11 @@ type UpdatePreferencesRequest struct {
 	UseCurrentLocation   *bool            `json:"use_current_location"`
 	QuietHoursStart      *string          `json:"quiet_hours_start" validate:"omitempty"`
 	QuietHoursEnd        *string          `json:"quiet_hours_end" validate:"omitempty"`
+	EmailEnabled         *bool            `json:"email_enabled"`
+	EmailDigestEnabled   *bool            `json:"email_digest_enabled"`
+	// NotificationChannels turns channels on or off per notification type,
+	// e.g. {"case_update": {"push": false}}. Omitted entries are unchanged.
+	NotificationChannels map[enum.NotificationType]map[enum.NotificationChannel]bool `json:"notification_channels" validate:"omitempty,dive,keys,oneof=new_case_nearby case_accepted case_update case_comment case_resolved volunteer_joined system,endkeys,dive,keys,oneof=push in_app email,endkeys"`
 }
 
 // LocationRequest represents a location in request
@@ -39,7 +45,22 @@ type LocationRequest struct {
 
 // RegisterPushTokenRequest represents push token registration request
 type RegisterPushTokenRequest struct {
-	Token    string              `json:"token" validate:"required"`
+	// Token is the FCM token on ios and android
+	Token    string              `json:"token" validate:"required_unless=Platform web"`
 	Platform enum.DevicePlatform `json:"platform" validate:"required,oneof=ios android web"`
 	DeviceID *string             `json:"device_id"`
+	// Subscription is required on web
+	Subscription *WebPushSubscriptionRequest `json:"subscription" validate:"required_if=Platform web"`
+}
+
+// WebPushSubscriptionRequest is a browser push subscription exactly as
+// returned by PushSubscription.toJSON()
+type WebPushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint" validate:"required,url"`
+	// ExpirationTime is in milliseconds since the epoch
+	ExpirationTime *int64 `json:"expirationTime"`
+	Keys           struct {
+		P256dh string `json:"p256dh" validate:"required"`
+		Auth   string `json:"auth" validate:"required"`
+	} `json:"keys"`
 }
@@ -0,0 +1,87 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/domain/enum"
+)
+
+// AlertZoneResponse represents an alert zone in response
+type AlertZoneResponse struct {
+	ID              uuid.UUID          `json:"id"`
+	Title           string             `json:"title"`
+	Message         *string            `json:"message,omitempty"`
+	Severity        enum.AlertSeverity `json:"severity"`
+	Polygon         []GeoPointResponse `json:"polygon"`
+	Bounds          BoundsResponse     `json:"bounds"`
+	Active          bool               `json:"active"`
+	ExpiresAt       time.Time          `json:"expiresAt"`
+	EndedAt         *time.Time         `json:"endedAt,omitempty"`
+	LastBroadcastAt *time.Time         `json:"lastBroadcastAt,omitempty"`
+	CreatedAt       time.Time          `json:"createdAt"`
+}
+
+// AlertZoneTagResponse represents an alert zone a case was tagged with
+type AlertZoneTagResponse struct {
+	ID       uuid.UUID          `json:"id"`
+	Title    string             `json:"title"`
+	Severity enum.AlertSeverity `json:"severity"`
+}
+
+// NearbyCasesResponse represents nearby cases together with the active
+// alert zones around them
+type NearbyCasesResponse struct {
+	Cases      []CaseNearbyResponse `json:"cases"`
+	AlertZones []AlertZoneResponse  `json:"alertZones"`
+}
+
+// ToAlertZoneResponse converts entity to response
+func ToAlertZoneResponse(z *entity.AlertZone) *AlertZoneResponse {
+	if z == nil {
+		return nil
+	}
+
+	polygon := make([]GeoPointResponse, len(z.Polygon))
+	for i, p := range z.Polygon {
+		polygon[i] = GeoPointResponse{Latitude: p.Latitude, Longitude: p.Longitude}
+	}
+
+	return &AlertZoneResponse{
+		ID:       z.ID,
+		Title:    z.Title,
+		Message:  z.Message,
+		Severity: z.Severity,
+		Polygon:  polygon,
+		Bounds: BoundsResponse{
+			South: z.MinLat,
+			West:  z.MinLng,
+			North: z.MaxLat,
+			East:  z.MaxLng,
+		},
+		Active:          z.IsActive(time.Now()),
+		ExpiresAt:       z.ExpiresAt,
+		EndedAt:         z.EndedAt,
+		LastBroadcastAt: z.LastBroadcastAt,
+		CreatedAt:       z.CreatedAt,
+	}
+}
+
+// ToAlertZoneListResponse converts entities to response
+func ToAlertZoneListResponse(zones []entity.AlertZone) []AlertZoneResponse {
+	result := make([]AlertZoneResponse, len(zones))
+	for i := range zones {
+		result[i] = *ToAlertZoneResponse(&zones[i])
+	}
+	return result
+}
+
+// ToAlertZoneTagResponse converts entity to response
+func ToAlertZoneTagResponse(z *entity.AlertZone) *AlertZoneTagResponse {
+	return &AlertZoneTagResponse{
+		ID:       z.ID,
+		Title:    z.Title,
+		Severity: z.Severity,
+	}
+}
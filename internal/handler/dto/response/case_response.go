@@ -6,6 +6,7 @@ import (
 	"github.com/google/uuid"
 	"bamboo-rescue/internal/domain/entity"
 	"bamboo-rescue/internal/domain/enum"
+	"bamboo-rescue/pkg/olc"
 )
 
 // CaseResponse represents a case in response
@@ -15,7 +16,11 @@ type CaseResponse struct {
 	Status          enum.CaseStatus           `json:"status"`
 	Urgency         enum.UrgencyLevel         `json:"urgency"`
 	Location        GeoPointResponse          `json:"location"`
+	PlusCode        string                    `json:"plusCode"`
 	Address         *string                   `json:"address,omitempty"`
+	Province        *string                   `json:"province,omitempty"`
+	District        *string                   `json:"district,omitempty"`
+	Ward            *string                   `json:"ward,omitempty"`
 	LocationNote    *string                   `json:"locationNote,omitempty"`
 	Title           string                    `json:"title"`
 	Description     *string                   `json:"description,omitempty"`
@@ -25,6 +30,7 @@ type CaseResponse struct {
 	IsAnonymous     bool                      `json:"isAnonymous"`
 	VolunteerCount  int                       `json:"volunteerCount"`
 	MaxVolunteers   int                       `json:"maxVolunteers"`
+	EventID         *uuid.UUID                `json:"eventId,omitempty"`
 	CreatedAt       time.Time                 `json:"createdAt"`
 	UpdatedAt       time.Time                 `json:"updatedAt"`
 	AcceptedAt      *time.Time                `json:"acceptedAt,omitempty"`
@@ -34,6 +40,7 @@ type CaseResponse struct {
 	AccidentDetails *AccidentDetailsResponse  `json:"accidentDetails,omitempty"`
 	Media           []MediaResponse           `json:"media,omitempty"`
 	Volunteers      []VolunteerResponse       `json:"volunteers,omitempty"`
+	AlertZones      []AlertZoneTagResponse    `json:"alertZones,omitempty"`
 }
 
 // AnimalDetailsResponse represents animal details in response
@@ -79,6 +86,9 @@ type MediaResponse struct {
 	MediaType    enum.MediaType `json:"mediaType"`
 	URL          string         `json:"url"`
 	ThumbnailURL *string        `json:"thumbnailUrl,omitempty"`
+	IsSensitive  bool           `json:"isSensitive"`
+	Redacted     bool           `json:"redacted,omitempty"`
+	ExpiresAt    *time.Time     `json:"expiresAt,omitempty"`
 	CreatedAt    time.Time      `json:"createdAt"`
 }
 
@@ -90,6 +100,10 @@ type VolunteerResponse struct {
 	VolunteerAvatar *string              `json:"volunteerAvatar,omitempty"`
 	Status          enum.VolunteerStatus `json:"status"`
 	DistanceKm      *float64             `json:"distanceKm,omitempty"`
+	TravelMode      *enum.TravelMode     `json:"travelMode,omitempty"`
+	RouteDistanceKm *float64             `json:"routeDistanceKm,omitempty"`
+	EtaSeconds      *int                 `json:"etaSeconds,omitempty"`
+	EtaEstimated    bool                 `json:"etaEstimated"`
 	AcceptedAt      time.Time            `json:"acceptedAt"`
 	ArrivedAt       *time.Time           `json:"arrivedAt,omitempty"`
 	CompletedAt     *time.Time           `json:"completedAt,omitempty"`
@@ -138,7 +152,11 @@ func ToCaseResponse(c *entity.Case) *CaseResponse {
 			Latitude:  c.Latitude,
 			Longitude: c.Longitude,
 		},
+		PlusCode:       olc.Encode(c.Latitude, c.Longitude, 10),
 		Address:        c.Address,
+		Province:       c.Province,
+		District:       c.District,
+		Ward:           c.Ward,
 		LocationNote:   c.LocationNote,
 		Title:          c.Title,
 		Description:    c.Description,
@@ -148,6 +166,7 @@ func ToCaseResponse(c *entity.Case) *CaseResponse {
 		IsAnonymous:    c.IsAnonymous,
 		VolunteerCount: c.VolunteerCount,
 		MaxVolunteers:  c.MaxVolunteers,
+		EventID:        c.EventID,
 		CreatedAt:      c.CreatedAt,
 		UpdatedAt:      c.UpdatedAt,
 		AcceptedAt:     c.AcceptedAt,
@@ -206,6 +225,9 @@ func ToCaseResponse(c *entity.Case) *CaseResponse {
 				MediaType:    m.MediaType,
 				URL:          m.URL,
 				ThumbnailURL: m.ThumbnailURL,
+				IsSensitive:  m.IsSensitive,
+				Redacted:     m.Redacted,
+				ExpiresAt:    m.URLExpiresAt,
 				CreatedAt:    m.CreatedAt,
 			}
 		}
@@ -216,14 +238,18 @@ func ToCaseResponse(c *entity.Case) *CaseResponse {
 		resp.Volunteers = make([]VolunteerResponse, len(c.Volunteers))
 		for i, v := range c.Volunteers {
 			vr := VolunteerResponse{
-				ID:          v.ID,
-				VolunteerID: v.VolunteerID,
-				Status:      v.Status,
-				DistanceKm:  v.DistanceKm,
-				AcceptedAt:  v.AcceptedAt,
-				ArrivedAt:   v.ArrivedAt,
-				CompletedAt: v.CompletedAt,
-				Note:        v.Note,
+				ID:              v.ID,
+				VolunteerID:     v.VolunteerID,
+				Status:          v.Status,
+				DistanceKm:      v.DistanceKm,
+				TravelMode:      v.TravelMode,
+				RouteDistanceKm: v.RouteDistanceKm,
+				EtaSeconds:      v.EtaSeconds,
+				EtaEstimated:    v.EtaEstimated,
+				AcceptedAt:      v.AcceptedAt,
+				ArrivedAt:       v.ArrivedAt,
+				CompletedAt:     v.CompletedAt,
+				Note:            v.Note,
 			}
 			if v.Volunteer != nil {
 				vr.VolunteerName = v.Volunteer.DisplayName
@@ -233,6 +259,14 @@ func ToCaseResponse(c *entity.Case) *CaseResponse {
 		}
 	}
 
+	// Convert alert zones
+	if len(c.AlertZones) > 0 {
+		resp.AlertZones = make([]AlertZoneTagResponse, len(c.AlertZones))
+		for i := range c.AlertZones {
+			resp.AlertZones[i] = *ToAlertZoneTagResponse(&c.AlertZones[i])
+		}
+	}
+
 	return resp
 }
 
@@ -311,14 +345,18 @@ func ToVolunteerResponse(v *entity.CaseVolunteer) *VolunteerResponse {
 	}
 
 	vr := &VolunteerResponse{
-		ID:          v.ID,
-		VolunteerID: v.VolunteerID,
-		Status:      v.Status,
-		DistanceKm:  v.DistanceKm,
-		AcceptedAt:  v.AcceptedAt,
-		ArrivedAt:   v.ArrivedAt,
-		CompletedAt: v.CompletedAt,
-		Note:        v.Note,
+		ID:              v.ID,
+		VolunteerID:     v.VolunteerID,
+		Status:          v.Status,
+		DistanceKm:      v.DistanceKm,
+		TravelMode:      v.TravelMode,
+		RouteDistanceKm: v.RouteDistanceKm,
+		EtaSeconds:      v.EtaSeconds,
+		EtaEstimated:    v.EtaEstimated,
+		AcceptedAt:      v.AcceptedAt,
+		ArrivedAt:       v.ArrivedAt,
+		CompletedAt:     v.CompletedAt,
+		Note:            v.Note,
 	}
 
 	if v.Volunteer != nil {
@@ -396,3 +434,114 @@ func ToCommentListResponse(comments []entity.CaseComment, caseReporterID *uuid.U
 	}
 	return result
 }
+
+// CaseAreaStatsResponse represents case counts for one administrative unit
+type CaseAreaStatsResponse struct {
+	Area     string `json:"area"`
+	Total    int64  `json:"total"`
+	Active   int64  `json:"active"`
+	Resolved int64  `json:"resolved"`
+}
+
+// ToCaseAreaStatsResponse converts area stats to response
+func ToCaseAreaStatsResponse(stats []entity.CaseAreaStats) []CaseAreaStatsResponse {
+	result := make([]CaseAreaStatsResponse, len(stats))
+	for i, s := range stats {
+		result[i] = CaseAreaStatsResponse{
+			Area:     s.Area,
+			Total:    s.Total,
+			Active:   s.Active,
+			Resolved: s.Resolved,
+		}
+	}
+	return result
+}
+
+// CaseMapResponse represents what the case map shows in a viewport: cases
+// when zoomed in, or clusters of them when Clustered
+type CaseMapResponse struct {
+	Clustered bool                  `json:"clustered"`
+	Total     int64                 `json:"total"`
+	Cases     []CaseMarkerResponse  `json:"cases"`
+	Clusters  []CaseClusterResponse `json:"clusters"`
+	// AlertZones are the active alert zones in the viewport
+	AlertZones []AlertZoneResponse `json:"alertZones"`
+}
+
+// CaseMarkerResponse represents a single case on the map
+type CaseMarkerResponse struct {
+	ID             uuid.UUID         `json:"id"`
+	CaseType       enum.CaseType     `json:"caseType"`
+	Title          string            `json:"title"`
+	Urgency        enum.UrgencyLevel `json:"urgency"`
+	Status         enum.CaseStatus   `json:"status"`
+	VolunteerCount int               `json:"volunteerCount"`
+	CreatedAt      time.Time         `json:"createdAt"`
+	Location       GeoPointResponse  `json:"location"`
+}
+
+// CaseClusterResponse represents a cluster of cases on the map. CaseID is
+// set when it holds a single case.
+type CaseClusterResponse struct {
+	Location  GeoPointResponse            `json:"location"`
+	Bounds    BoundsResponse              `json:"bounds"`
+	Count     int64                       `json:"count"`
+	ByType    map[enum.CaseType]int64     `json:"byType"`
+	ByUrgency map[enum.UrgencyLevel]int64 `json:"byUrgency"`
+	CaseID    *uuid.UUID                  `json:"caseId,omitempty"`
+}
+
+// BoundsResponse represents a bounding box
+type BoundsResponse struct {
+	South float64 `json:"south"`
+	West  float64 `json:"west"`
+	North float64 `json:"north"`
+	East  float64 `json:"east"`
+}
+
+// ToCaseMapResponse converts a case map to response
+func ToCaseMapResponse(m *entity.CaseMap) *CaseMapResponse {
+	resp := &CaseMapResponse{
+		Clustered: m.Clustered,
+		Total:     m.Total,
+		Cases:     make([]CaseMarkerResponse, len(m.Cases)),
+		Clusters:  make([]CaseClusterResponse, len(m.Clusters)),
+	}
+
+	for i, c := range m.Cases {
+		resp.Cases[i] = CaseMarkerResponse{
+			ID:             c.ID,
+			CaseType:       c.CaseType,
+			Title:          c.Title,
+			Urgency:        c.Urgency,
+			Status:         c.Status,
+			VolunteerCount: c.VolunteerCount,
+			CreatedAt:      c.CreatedAt,
+			Location: GeoPointResponse{
+				Latitude:  c.Latitude,
+				Longitude: c.Longitude,
+			},
+		}
+	}
+
+	for i, c := range m.Clusters {
+		resp.Clusters[i] = CaseClusterResponse{
+			Location: GeoPointResponse{
+				Latitude:  c.Latitude,
+				Longitude: c.Longitude,
+			},
+			Bounds: BoundsResponse{
+				South: c.Bounds.MinLat,
+				West:  c.Bounds.MinLng,
+				North: c.Bounds.MaxLat,
+				East:  c.Bounds.MaxLng,
+			},
+			Count:     c.Count,
+			ByType:    c.ByType,
+			ByUrgency: c.ByUrgency,
+			CaseID:    c.CaseID,
+		}
+	}
+
+	return resp
+}
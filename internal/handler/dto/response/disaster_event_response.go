@@ -0,0 +1,97 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/domain/enum"
+)
+
+// DisasterEventResponse represents a disaster event in response
+type DisasterEventResponse struct {
+	ID             uuid.UUID          `json:"id"`
+	Name           string             `json:"name"`
+	Description    *string            `json:"description,omitempty"`
+	Status         enum.EventStatus   `json:"status"`
+	Area           []GeoPointResponse `json:"area"`
+	Bounds         BoundsResponse     `json:"bounds"`
+	StartsAt       time.Time          `json:"startsAt"`
+	EndsAt         *time.Time         `json:"endsAt,omitempty"`
+	NotifyRadiusKm *int               `json:"notifyRadiusKm,omitempty"`
+	MaxVolunteers  *int               `json:"maxVolunteers,omitempty"`
+	CoordinatorIDs []uuid.UUID        `json:"coordinatorIds"`
+	CreatedBy      *uuid.UUID         `json:"createdBy,omitempty"`
+	CreatedAt      time.Time          `json:"createdAt"`
+	UpdatedAt      time.Time          `json:"updatedAt"`
+}
+
+// DisasterEventStatsResponse represents the case counts of a disaster event
+type DisasterEventStatsResponse struct {
+	Total      int64                       `json:"total"`
+	Active     int64                       `json:"active"`
+	Resolved   int64                       `json:"resolved"`
+	ByStatus   map[enum.CaseStatus]int64   `json:"byStatus"`
+	ByType     map[enum.CaseType]int64     `json:"byType"`
+	ByUrgency  map[enum.UrgencyLevel]int64 `json:"byUrgency"`
+	Volunteers int64                       `json:"volunteers"`
+}
+
+// ToDisasterEventResponse converts entity to response
+func ToDisasterEventResponse(e *entity.DisasterEvent) *DisasterEventResponse {
+	if e == nil {
+		return nil
+	}
+
+	area := make([]GeoPointResponse, len(e.Area))
+	for i, p := range e.Area {
+		area[i] = GeoPointResponse{Latitude: p.Latitude, Longitude: p.Longitude}
+	}
+
+	return &DisasterEventResponse{
+		ID:          e.ID,
+		Name:        e.Name,
+		Description: e.Description,
+		Status:      e.Status,
+		Area:        area,
+		Bounds: BoundsResponse{
+			South: e.MinLat,
+			West:  e.MinLng,
+			North: e.MaxLat,
+			East:  e.MaxLng,
+		},
+		StartsAt:       e.StartsAt,
+		EndsAt:         e.EndsAt,
+		NotifyRadiusKm: e.NotifyRadiusKm,
+		MaxVolunteers:  e.MaxVolunteers,
+		CoordinatorIDs: e.CoordinatorIDs(),
+		CreatedBy:      e.CreatedBy,
+		CreatedAt:      e.CreatedAt,
+		UpdatedAt:      e.UpdatedAt,
+	}
+}
+
+// ToDisasterEventListResponse converts entities to response
+func ToDisasterEventListResponse(events []entity.DisasterEvent) []DisasterEventResponse {
+	result := make([]DisasterEventResponse, len(events))
+	for i := range events {
+		result[i] = *ToDisasterEventResponse(&events[i])
+	}
+	return result
+}
+
+// ToDisasterEventStatsResponse converts event stats to response
+func ToDisasterEventStatsResponse(s *entity.DisasterEventStats) *DisasterEventStatsResponse {
+	if s == nil {
+		return nil
+	}
+	return &DisasterEventStatsResponse{
+		Total:      s.Total,
+		Active:     s.Active,
+		Resolved:   s.Resolved,
+		ByStatus:   s.ByStatus,
+		ByType:     s.ByType,
+		ByUrgency:  s.ByUrgency,
+		Volunteers: s.Volunteers,
+	}
+}
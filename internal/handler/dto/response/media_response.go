@@ -0,0 +1,34 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/domain/entity"
+)
+
+// MediaUploadURLResponse represents a presigned direct upload slot
+type MediaUploadURLResponse struct {
+	MediaID   uuid.UUID         `json:"mediaId"`
+	Key       string            `json:"key"`
+	UploadURL string            `json:"uploadUrl"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
+// ToMediaUploadURLResponse converts entity to response
+func ToMediaUploadURLResponse(u *entity.MediaUploadURL) *MediaUploadURLResponse {
+	if u == nil {
+		return nil
+	}
+
+	return &MediaUploadURLResponse{
+		MediaID:   u.MediaID,
+		Key:       u.Key,
+		UploadURL: u.UploadURL,
+		Method:    u.Method,
+		Headers:   u.Headers,
+		ExpiresAt: u.ExpiresAt,
+	}
+}
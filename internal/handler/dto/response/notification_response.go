@@ -16,6 +16,7 @@ type NotificationResponse struct {
 	Body             *string               `json:"body,omitempty"`
 	CaseID           *uuid.UUID            `json:"caseId,omitempty"`
 	IsRead           bool                  `json:"isRead"`
+	ArchivedAt       *time.Time            `json:"archivedAt,omitempty"`
 	CreatedAt        time.Time             `json:"createdAt"`
 }
 
@@ -32,6 +33,7 @@ func ToNotificationResponse(n *entity.Notification) *NotificationResponse {
 		Body:             n.Body,
 		CaseID:           n.CaseID,
 		IsRead:           n.IsRead,
+		ArchivedAt:       n.ArchivedAt,
 		CreatedAt:        n.CreatedAt,
 	}
 }
@@ -45,18 +47,54 @@ func ToNotificationListResponse(notifications []entity.Notification) []Notificat
 	return result
 }
 
-// NotificationListResponse represents notification list with unread count
+// NotificationListResponse represents a page of notifications with the
+// unread count. NextCursor is empty on the last page.
 type NotificationListResponse struct {
 	Notifications []NotificationResponse `json:"notifications"`
 	UnreadCount   int64                  `json:"unreadCount"`
+	NextCursor    string                 `json:"nextCursor,omitempty"`
 }
 
 // GeocodeResponse represents a geocoding result
 type GeocodeResponse struct {
-	Address   string           `json:"address"`
-	Location  GeoPointResponse `json:"location"`
-	PlaceID   string           `json:"placeId,omitempty"`
-	PlaceType string           `json:"placeType,omitempty"`
+	Address    string           `json:"address"`
+	Components AddressResponse  `json:"components"`
+	Location   GeoPointResponse `json:"location"`
+	PlaceID    string           `json:"placeId,omitempty"`
+	PlaceType  string           `json:"placeType,omitempty"`
+}
+
+// ParsedLocationResponse represents a position read from a shared location
+type ParsedLocationResponse struct {
+	Location GeoPointResponse `json:"location"`
+	Format   string           `json:"format"`
+	PlusCode string           `json:"plusCode"`
+}
+
+// AddressResponse represents structured address components
+type AddressResponse struct {
+	HouseNumber string `json:"houseNumber,omitempty"`
+	Road        string `json:"road,omitempty"`
+	Ward        string `json:"ward,omitempty"`
+	District    string `json:"district,omitempty"`
+	Province    string `json:"province,omitempty"`
+	Postcode    string `json:"postcode,omitempty"`
+	Country     string `json:"country,omitempty"`
+	CountryCode string `json:"countryCode,omitempty"`
+}
+
+// ToAddressResponse converts entity to response
+func ToAddressResponse(a entity.Address) AddressResponse {
+	return AddressResponse{
+		HouseNumber: a.HouseNumber,
+		Road:        a.Road,
+		Ward:        a.Ward,
+		District:    a.District,
+		Province:    a.Province,
+		Postcode:    a.Postcode,
+		Country:     a.Country,
+		CountryCode: a.CountryCode,
+	}
 }
 
 // MediaUploadResponse represents media upload result
@@ -66,6 +104,7 @@ type MediaUploadResponse struct {
 	ThumbnailURL *string        `json:"thumbnailUrl,omitempty"`
 	MediaType    enum.MediaType `json:"mediaType"`
 	FileSize     int64          `json:"fileSize"`
+	IsSensitive  bool           `json:"isSensitive"`
 }
 
 // SuccessMessageResponse represents a simple success response
@@ -0,0 +1,83 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/domain/enum"
+)
+
+// OutboxJobResponse represents an outbox job in response
+type OutboxJobResponse struct {
+	ID          uuid.UUID            `json:"id"`
+	Kind        string               `json:"kind"`
+	Payload     json.RawMessage      `json:"payload"`
+	Status      enum.OutboxJobStatus `json:"status"`
+	Attempts    int                  `json:"attempts"`
+	MaxAttempts int                  `json:"maxAttempts"`
+	RunAt       time.Time            `json:"runAt"`
+	LockedAt    *time.Time           `json:"lockedAt,omitempty"`
+	LastError   *string              `json:"lastError,omitempty"`
+	CompletedAt *time.Time           `json:"completedAt,omitempty"`
+	CreatedAt   time.Time            `json:"createdAt"`
+	UpdatedAt   time.Time            `json:"updatedAt"`
+}
+
+// OutboxStatsResponse represents outbox job counts in response
+type OutboxStatsResponse struct {
+	Pending    int64      `json:"pending"`
+	Retrying   int64      `json:"retrying"`
+	Processing int64      `json:"processing"`
+	Stale      int64      `json:"stale"`
+	Dead       int64      `json:"dead"`
+	OldestDue  *time.Time `json:"oldestDue,omitempty"`
+}
+
+// ToOutboxJobResponse converts entity to response
+func ToOutboxJobResponse(j *entity.OutboxJob) *OutboxJobResponse {
+	if j == nil {
+		return nil
+	}
+
+	return &OutboxJobResponse{
+		ID:          j.ID,
+		Kind:        j.Kind,
+		Payload:     j.Payload,
+		Status:      j.Status,
+		Attempts:    j.Attempts,
+		MaxAttempts: j.MaxAttempts,
+		RunAt:       j.RunAt,
+		LockedAt:    j.LockedAt,
+		LastError:   j.LastError,
+		CompletedAt: j.CompletedAt,
+		CreatedAt:   j.CreatedAt,
+		UpdatedAt:   j.UpdatedAt,
+	}
+}
+
+// ToOutboxJobListResponse converts a slice of jobs to response
+func ToOutboxJobListResponse(jobs []entity.OutboxJob) []OutboxJobResponse {
+	result := make([]OutboxJobResponse, len(jobs))
+	for i, j := range jobs {
+		result[i] = *ToOutboxJobResponse(&j)
+	}
+	return result
+}
+
+// ToOutboxStatsResponse converts entity to response
+func ToOutboxStatsResponse(s *entity.OutboxStats) *OutboxStatsResponse {
+	if s == nil {
+		return nil
+	}
+
+	return &OutboxStatsResponse{
+		Pending:    s.Pending,
+		Retrying:   s.Retrying,
+		Processing: s.Processing,
+		Stale:      s.Stale,
+		Dead:       s.Dead,
+		OldestDue:  s.OldestDue,
+	}
+}
@@ -0,0 +1,6 @@
+package response
+
+// VAPIDPublicKeyResponse represents the web push application server key
+type VAPIDPublicKeyResponse struct {
+	PublicKey string `json:"publicKey"`
+}
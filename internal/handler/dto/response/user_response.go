@@ -22,6 +22,7 @@ type UserResponse struct {
 	TotalCasesReported int               `json:"totalCasesReported"`
 	TotalCasesResolved int               `json:"totalCasesResolved"`
 	IsActive           bool              `json:"isActive"`
+	Locale             enum.Locale       `json:"locale"`
 	CreatedAt          time.Time         `json:"createdAt"`
 	UpdatedAt          time.Time         `json:"updatedAt"`
 }
@@ -50,6 +51,7 @@ func ToUserResponse(u *entity.User) *UserResponse {
 		TotalCasesReported: u.TotalCasesReported,
 		TotalCasesResolved: u.TotalCasesResolved,
 		IsActive:           u.IsActive,
+		Locale:             u.Locale.OrDefault(),
 		CreatedAt:          u.CreatedAt,
 		UpdatedAt:          u.UpdatedAt,
 	}
@@ -66,17 +68,20 @@ func ToUserResponse(u *entity.User) *UserResponse {
 
 // UserPreferencesResponse represents user preferences in response
 type UserPreferencesResponse struct {
-	ID                   uuid.UUID         `json:"id"`
-	UserID               uuid.UUID         `json:"userId"`
-	PushEnabled          bool              `json:"pushEnabled"`
-	CaseTypes            []enum.CaseType   `json:"caseTypes"`
-	NotificationRadiusKm int               `json:"notificationRadiusKm"`
-	CenterLocation       *GeoPointResponse `json:"centerLocation,omitempty"`
-	UseCurrentLocation   bool              `json:"useCurrentLocation"`
-	QuietHoursStart      *string           `json:"quietHoursStart,omitempty"`
-	QuietHoursEnd        *string           `json:"quietHoursEnd,omitempty"`
-	CreatedAt            time.Time         `json:"createdAt"`
-	UpdatedAt            time.Time         `json:"updatedAt"`
+	ID                   uuid.UUID            `json:"id"`
+	UserID               uuid.UUID            `json:"userId"`
+	PushEnabled          bool                 `json:"pushEnabled"`
+	CaseTypes            []enum.CaseType      `json:"caseTypes"`
+	NotificationRadiusKm int                  `json:"notificationRadiusKm"`
+	CenterLocation       *GeoPointResponse    `json:"centerLocation,omitempty"`
+	UseCurrentLocation   bool                 `json:"useCurrentLocation"`
+	QuietHoursStart      *string              `json:"quietHoursStart,omitempty"`
+	QuietHoursEnd        *string              `json:"quietHoursEnd,omitempty"`
+	EmailEnabled         bool                 `json:"emailEnabled"`
+	EmailDigestEnabled   bool                 `json:"emailDigestEnabled"`
+	NotificationChannels entity.ChannelMatrix `json:"notificationChannels"`
+	CreatedAt            time.Time            `json:"createdAt"`
+	UpdatedAt            time.Time            `json:"updatedAt"`
 }
 
 // ToUserPreferencesResponse converts entity to response
@@ -99,6 +104,9 @@ func ToUserPreferencesResponse(p *entity.UserPreferences) *UserPreferencesRespon
 		UseCurrentLocation:   p.UseCurrentLocation,
 		QuietHoursStart:      p.QuietHoursStart,
 		QuietHoursEnd:        p.QuietHoursEnd,
+		EmailEnabled:         p.EmailEnabled,
+		EmailDigestEnabled:   p.EmailDigestEnabled,
+		NotificationChannels: p.NotificationChannels.Effective(),
 		CreatedAt:            p.CreatedAt,
 		UpdatedAt:            p.UpdatedAt,
 	}
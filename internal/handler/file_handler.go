@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"bamboo-rescue/internal/middleware"
+	pkgresponse "bamboo-rescue/pkg/response"
+	"bamboo-rescue/pkg/storage"
+)
+
+// FileHandler serves objects kept by a storage backend that has no public
+// endpoint of its own, such as local disk
+type FileHandler struct {
+	storageClient   storage.Client
+	files           storage.FileServer
+	transferTimeout time.Duration
+}
+
+// NewFileHandler creates a new FileHandler. It returns nil when the storage
+// client serves its own files.
+func NewFileHandler(storageClient storage.Client, transferTimeout time.Duration) *FileHandler {
+	files, ok := storageClient.(storage.FileServer)
+	if !ok {
+		return nil
+	}
+
+	return &FileHandler{
+		storageClient:   storageClient,
+		files:           files,
+		transferTimeout: transferTimeout,
+	}
+}
+
+// Serve handles file download
+// @Summary Download file
+// @Description Serve a stored media file. Supports range requests and conditional GETs.
+// @Description Private files need the expires and signature parameters of a signed URL.
+// @Tags Files
+// @Produce octet-stream
+// @Param key path string true "Object key"
+// @Success 200 {file} binary
+// @Success 206 {file} binary
+// @Failure 403 {object} pkgresponse.Response
+// @Failure 404 {object} pkgresponse.Response
+// @Router /files/{key} [get]
+func (h *FileHandler) Serve(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	expiresAt, err := h.files.VerifyDownload(key, c.Request.URL.Query())
+	if err != nil {
+		pkgresponse.Error(c, middleware.NewAppError("INVALID_SIGNATURE", "File URL is invalid or expired", 403))
+		return
+	}
+
+	f, info, err := h.files.Open(key)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			pkgresponse.Error(c, middleware.NewAppError("FILE_NOT_FOUND", "File not found", 404))
+			return
+		}
+		pkgresponse.Error(c, err)
+		return
+	}
+	defer f.Close()
+	h.extendDeadlines(c)
+
+	// Keys embed a fresh UUID per upload, so content never changes under a
+	// URL; signed URLs may only be cached until they expire
+	if expiresAt.IsZero() {
+		c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", max(0, int(time.Until(expiresAt).Seconds()))))
+	}
+	c.Header("ETag", fmt.Sprintf(`"%x-%x"`, info.LastModified.UnixNano(), info.Size))
+	if info.ContentType != "" {
+		c.Header("Content-Type", info.ContentType)
+	}
+
+	http.ServeContent(c.Writer, c.Request, "", info.LastModified, f)
+}
+
+// Put handles a direct upload through a signed URL
+// @Summary Upload file via signed URL
+// @Description Receive a file uploaded to a URL issued by /media/upload-url
+// @Tags Files
+// @Accept octet-stream
+// @Param key path string true "Object key"
+// @Success 200
+// @Failure 400 {object} pkgresponse.Response
+// @Failure 403 {object} pkgresponse.Response
+// @Router /files/{key} [put]
+func (h *FileHandler) Put(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	allowed, err := h.files.VerifyUpload(key, c.Request.URL.Query())
+	if err != nil {
+		pkgresponse.Error(c, middleware.NewAppError("INVALID_SIGNATURE", "Upload URL is invalid or expired", 403))
+		return
+	}
+
+	if c.Request.ContentLength != allowed.Size {
+		pkgresponse.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Content-Length does not match signed size", 400))
+		return
+	}
+	if c.ContentType() != allowed.ContentType {
+		pkgresponse.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Content-Type does not match signed type", 400))
+		return
+	}
+	h.extendDeadlines(c)
+
+	if _, err := h.storageClient.Put(c.Request.Context(), key, c.Request.Body, allowed.Size, storage.PutOptions{
+		ContentType: allowed.ContentType,
+	}); err != nil {
+		pkgresponse.Error(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// extendDeadlines lets a large file over a slow link outlive the server-wide
+// timeouts
+func (h *FileHandler) extendDeadlines(c *gin.Context) {
+	if h.transferTimeout <= 0 {
+		return
+	}
+	rc := http.NewResponseController(c.Writer)
+	deadline := time.Now().Add(h.transferTimeout)
+	_ = rc.SetReadDeadline(deadline)
+	_ = rc.SetWriteDeadline(deadline)
+}
@@ -5,6 +5,8 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/handler/dto/request"
 	"bamboo-rescue/internal/handler/dto/response"
 	"bamboo-rescue/internal/middleware"
 	"bamboo-rescue/internal/service"
@@ -13,13 +15,15 @@ import (
 
 // GeocodeHandler handles geocoding requests
 type GeocodeHandler struct {
-	geocodeService service.GeocodeService
+	geocodeService  service.GeocodeService
+	locationService service.LocationService
 }
 
 // NewGeocodeHandler creates a new GeocodeHandler
-func NewGeocodeHandler(geocodeService service.GeocodeService) *GeocodeHandler {
+func NewGeocodeHandler(geocodeService service.GeocodeService, locationService service.LocationService) *GeocodeHandler {
 	return &GeocodeHandler{
-		geocodeService: geocodeService,
+		geocodeService:  geocodeService,
+		locationService: locationService,
 	}
 }
 
@@ -60,51 +64,122 @@ func (h *GeocodeHandler) ReverseGeocode(c *gin.Context) {
 		return
 	}
 
-	pkgresponse.Success(c, http.StatusOK, response.GeocodeResponse{
-		Address: result.Address,
-		Location: response.GeoPointResponse{
-			Latitude:  result.Location.Latitude,
-			Longitude: result.Location.Longitude,
-		},
-		PlaceID:   result.PlaceID,
-		PlaceType: result.PlaceType,
-	})
+	pkgresponse.Success(c, http.StatusOK, toGeocodeResponse(result))
 }
 
 // SearchAddress handles address search
 // @Summary Search address
-// @Description Search for addresses
+// @Description Search for addresses, ranking those near the given location first
 // @Tags Geocode
 // @Produce json
 // @Param query query string true "Search query"
+// @Param limit query int false "Maximum results (default 5, max 10)"
+// @Param latitude query number false "Requester latitude"
+// @Param longitude query number false "Requester longitude"
 // @Success 200 {object} pkgresponse.Response{data=[]response.GeocodeResponse}
 // @Failure 400 {object} pkgresponse.Response
 // @Router /geocode/search [get]
 func (h *GeocodeHandler) SearchAddress(c *gin.Context) {
-	query := c.Query("query")
-	if query == "" {
+	var req request.GeocodeSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		pkgresponse.ValidationError(c, err)
+		return
+	}
+
+	if req.Query == "" {
 		pkgresponse.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Query is required", 400))
 		return
 	}
+	if req.Limit > 10 {
+		pkgresponse.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Limit must be at most 10", 400))
+		return
+	}
+
+	opts := service.SearchOptions{Limit: req.GetDefaultLimit()}
+	if req.Latitude != nil && req.Longitude != nil {
+		if *req.Latitude < -90 || *req.Latitude > 90 || *req.Longitude < -180 || *req.Longitude > 180 {
+			pkgresponse.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Invalid location", 400))
+			return
+		}
+		opts.Near = entity.NewGeoPoint(*req.Latitude, *req.Longitude)
+	}
 
-	results, err := h.geocodeService.SearchAddress(c.Request.Context(), query)
+	results, err := h.geocodeService.SearchAddress(c.Request.Context(), req.Query, opts)
 	if err != nil {
 		pkgresponse.Error(c, err)
 		return
 	}
 
 	responses := make([]response.GeocodeResponse, len(results))
-	for i, r := range results {
-		responses[i] = response.GeocodeResponse{
-			Address: r.Address,
-			Location: response.GeoPointResponse{
-				Latitude:  r.Location.Latitude,
-				Longitude: r.Location.Longitude,
-			},
-			PlaceID:   r.PlaceID,
-			PlaceType: r.PlaceType,
-		}
+	for i := range results {
+		responses[i] = toGeocodeResponse(&results[i])
 	}
 
 	pkgresponse.Success(c, http.StatusOK, responses)
 }
+
+// ParseLocation handles reading a shared location
+// @Summary Parse location
+// @Description Read a position given as decimal or DMS coordinates, a full or short plus code, or a Google Maps, Apple Maps, OpenStreetMap, Waze or geo: link. A short plus code is placed near the town written after it, e.g. "7MP9+XW Huế", or else near the given location.
+// @Tags Geocode
+// @Produce json
+// @Param input query string true "Coordinates, plus code or map link"
+// @Param latitude query number false "Requester latitude"
+// @Param longitude query number false "Requester longitude"
+// @Success 200 {object} pkgresponse.Response{data=response.ParsedLocationResponse}
+// @Failure 400 {object} pkgresponse.Response
+// @Router /geocode/parse [get]
+func (h *GeocodeHandler) ParseLocation(c *gin.Context) {
+	var req request.ParseLocationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		pkgresponse.ValidationError(c, err)
+		return
+	}
+
+	if req.Input == "" {
+		pkgresponse.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Input is required", 400))
+		return
+	}
+	if len(req.Input) > 2048 {
+		pkgresponse.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Input must be at most 2048 characters", 400))
+		return
+	}
+
+	var ref *entity.GeoPoint
+	if req.Latitude != nil && req.Longitude != nil {
+		if *req.Latitude < -90 || *req.Latitude > 90 || *req.Longitude < -180 || *req.Longitude > 180 {
+			pkgresponse.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Invalid location", 400))
+			return
+		}
+		ref = entity.NewGeoPoint(*req.Latitude, *req.Longitude)
+	}
+
+	parsed, err := h.locationService.Parse(c.Request.Context(), req.Input, ref)
+	if err != nil {
+		pkgresponse.Error(c, err)
+		return
+	}
+
+	pkgresponse.Success(c, http.StatusOK, response.ParsedLocationResponse{
+		Location: response.GeoPointResponse{
+			Latitude:  parsed.Location.Latitude,
+			Longitude: parsed.Location.Longitude,
+		},
+		Format:   string(parsed.Format),
+		PlusCode: parsed.PlusCode,
+	})
+}
+
+// toGeocodeResponse converts a geocoding result to response
+func toGeocodeResponse(r *service.GeocodeResult) response.GeocodeResponse {
+	return response.GeocodeResponse{
+		Address:    r.Address,
+		Components: response.ToAddressResponse(r.Components),
+		Location: response.GeoPointResponse{
+			Latitude:  r.Location.Latitude,
+			Longitude: r.Location.Longitude,
+		},
+		PlaceID:   r.PlaceID,
+		PlaceType: r.PlaceType,
+	}
+}
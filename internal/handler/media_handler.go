@@ -2,9 +2,11 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"bamboo-rescue/internal/handler/dto/request"
 	"bamboo-rescue/internal/handler/dto/response"
 	"bamboo-rescue/internal/middleware"
 	"bamboo-rescue/internal/service"
@@ -32,6 +34,7 @@ func NewMediaHandler(mediaService service.MediaService) *MediaHandler {
 // @Produce json
 // @Param case_id formData string true "Case ID"
 // @Param file formData file true "Media file"
+// @Param is_sensitive formData bool false "Serve only through signed URLs (default true for accident cases)"
 // @Success 201 {object} pkgresponse.Response{data=response.MediaUploadResponse}
 // @Failure 400 {object} pkgresponse.Response
 // @Failure 401 {object} pkgresponse.Response
@@ -55,7 +58,7 @@ func (h *MediaHandler) Upload(c *gin.Context) {
 		return
 	}
 
-	result, err := h.mediaService.Upload(c.Request.Context(), file, caseID)
+	result, err := h.mediaService.Upload(c.Request.Context(), file, caseID, isSensitiveForm(c))
 	if err != nil {
 		pkgresponse.Error(c, err)
 		return
@@ -67,6 +70,7 @@ func (h *MediaHandler) Upload(c *gin.Context) {
 		ThumbnailURL: result.ThumbnailURL,
 		MediaType:    result.MediaType,
 		FileSize:     result.FileSize,
+		IsSensitive:  result.IsSensitive,
 	})
 }
 
@@ -79,6 +83,7 @@ func (h *MediaHandler) Upload(c *gin.Context) {
 // @Produce json
 // @Param case_id formData string true "Case ID"
 // @Param files formData file true "Media files"
+// @Param is_sensitive formData bool false "Serve only through signed URLs (default true for accident cases)"
 // @Success 201 {object} pkgresponse.Response{data=[]response.MediaUploadResponse}
 // @Failure 400 {object} pkgresponse.Response
 // @Failure 401 {object} pkgresponse.Response
@@ -108,7 +113,7 @@ func (h *MediaHandler) UploadMultiple(c *gin.Context) {
 		return
 	}
 
-	results, err := h.mediaService.UploadMultiple(c.Request.Context(), files, caseID)
+	results, err := h.mediaService.UploadMultiple(c.Request.Context(), files, caseID, isSensitiveForm(c))
 	if err != nil {
 		pkgresponse.Error(c, err)
 		return
@@ -122,12 +127,84 @@ func (h *MediaHandler) UploadMultiple(c *gin.Context) {
 			ThumbnailURL: r.ThumbnailURL,
 			MediaType:    r.MediaType,
 			FileSize:     r.FileSize,
+			IsSensitive:  r.IsSensitive,
 		}
 	}
 
 	pkgresponse.Success(c, http.StatusCreated, responses)
 }
 
+// CreateUploadURL handles presigned upload URL creation
+// @Summary Create direct upload URL
+// @Description Get a short-lived presigned URL to upload a media file directly to storage
+// @Tags Media
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.CreateUploadURLRequest true "Upload details"
+// @Success 201 {object} pkgresponse.Response{data=response.MediaUploadURLResponse}
+// @Failure 400 {object} pkgresponse.Response
+// @Failure 401 {object} pkgresponse.Response
+// @Router /media/upload-url [post]
+func (h *MediaHandler) CreateUploadURL(c *gin.Context) {
+	var req request.CreateUploadURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkgresponse.ValidationError(c, err)
+		return
+	}
+
+	upload, err := h.mediaService.CreateUploadURL(c.Request.Context(), &req)
+	if err != nil {
+		pkgresponse.Error(c, err)
+		return
+	}
+
+	pkgresponse.Success(c, http.StatusCreated, response.ToMediaUploadURLResponse(upload))
+}
+
+// FinalizeUpload handles registration of a directly uploaded file
+// @Summary Finalize direct upload
+// @Description Verify a file uploaded via presigned URL and attach it to the case
+// @Tags Media
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.FinalizeUploadRequest true "Uploaded file"
+// @Success 201 {object} pkgresponse.Response{data=response.MediaUploadResponse}
+// @Failure 400 {object} pkgresponse.Response
+// @Failure 401 {object} pkgresponse.Response
+// @Failure 404 {object} pkgresponse.Response
+// @Failure 409 {object} pkgresponse.Response
+// @Router /media/finalize [post]
+func (h *MediaHandler) FinalizeUpload(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == nil {
+		pkgresponse.Error(c, middleware.ErrUnauthorized)
+		return
+	}
+
+	var req request.FinalizeUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkgresponse.ValidationError(c, err)
+		return
+	}
+
+	result, err := h.mediaService.FinalizeUpload(c.Request.Context(), &req, *userID)
+	if err != nil {
+		pkgresponse.Error(c, err)
+		return
+	}
+
+	pkgresponse.Success(c, http.StatusCreated, response.MediaUploadResponse{
+		ID:           result.ID,
+		URL:          result.URL,
+		ThumbnailURL: result.ThumbnailURL,
+		MediaType:    result.MediaType,
+		FileSize:     result.FileSize,
+		IsSensitive:  result.IsSensitive,
+	})
+}
+
 // Delete handles media deletion
 // @Summary Delete media
 // @Description Delete a media file
@@ -154,3 +231,9 @@ func (h *MediaHandler) Delete(c *gin.Context) {
 
 	pkgresponse.Success(c, http.StatusOK, gin.H{"message": "Media deleted successfully"})
 }
+
+// isSensitiveForm reads the optional is_sensitive form field
+func isSensitiveForm(c *gin.Context) bool {
+	sensitive, _ := strconv.ParseBool(c.PostForm("is_sensitive"))
+	return sensitive
+}
@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"bamboo-rescue/internal/handler/dto/request"
 	"bamboo-rescue/internal/handler/dto/response"
 	"bamboo-rescue/internal/middleware"
 	"bamboo-rescue/internal/service"
@@ -25,13 +27,18 @@ func NewNotificationHandler(notificationService service.NotificationService) *No
 
 // GetNotifications handles get user notifications
 // @Summary Get notifications
-// @Description Get notifications for the current user
+// @Description Get notifications for the current user, newest first. Pass the returned nextCursor to get the next page.
 // @Tags Notifications
 // @Security BearerAuth
 // @Produce json
 // @Param limit query int false "Limit (default 20)"
-// @Param offset query int false "Offset (default 0)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param type query string false "Notification type"
+// @Param case_id query string false "Case ID"
+// @Param read query bool false "Read state"
+// @Param archived query bool false "List archived notifications instead"
 // @Success 200 {object} pkgresponse.Response{data=response.NotificationListResponse}
+// @Failure 400 {object} pkgresponse.Response
 // @Failure 401 {object} pkgresponse.Response
 // @Router /notifications [get]
 func (h *NotificationHandler) GetNotifications(c *gin.Context) {
@@ -41,29 +48,33 @@ func (h *NotificationHandler) GetNotifications(c *gin.Context) {
 		return
 	}
 
-	limit := 20
-	offset := 0
-	if l := c.Query("limit"); l != "" {
-		if _, err := c.GetQuery("limit"); err {
-			limit = 20
-		}
+	var req request.GetNotificationsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		pkgresponse.ValidationError(c, err)
+		return
 	}
-	if o := c.Query("offset"); o != "" {
-		if _, err := c.GetQuery("offset"); err {
-			offset = 0
-		}
+
+	notifications, next, err := h.notificationService.GetByUser(c.Request.Context(), *userID, &req)
+	if err != nil {
+		pkgresponse.Error(c, err)
+		return
 	}
 
-	notifications, unreadCount, err := h.notificationService.GetByUser(c.Request.Context(), *userID, limit, offset)
+	unreadCount, err := h.notificationService.GetUnreadCount(c.Request.Context(), *userID)
 	if err != nil {
 		pkgresponse.Error(c, err)
 		return
 	}
 
-	pkgresponse.Success(c, http.StatusOK, response.NotificationListResponse{
+	result := response.NotificationListResponse{
 		Notifications: response.ToNotificationListResponse(notifications),
 		UnreadCount:   unreadCount,
-	})
+	}
+	if next != nil {
+		result.NextCursor = next.String()
+	}
+
+	pkgresponse.Success(c, http.StatusOK, result)
 }
 
 // GetUnreadCount handles get unread notification count
@@ -147,3 +158,107 @@ func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
 
 	pkgresponse.Success(c, http.StatusOK, gin.H{"message": "All notifications marked as read"})
 }
+
+// MarkManyAsRead handles marking several notifications as read
+// @Summary Mark notifications as read
+// @Description Mark the listed notifications as read; IDs that are not the user's are skipped
+// @Tags Notifications
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.NotificationIDsRequest true "Notification IDs"
+// @Success 200 {object} pkgresponse.Response
+// @Failure 400 {object} pkgresponse.Response
+// @Failure 401 {object} pkgresponse.Response
+// @Router /notifications/read [put]
+func (h *NotificationHandler) MarkManyAsRead(c *gin.Context) {
+	h.bulk(c, h.notificationService.MarkManyAsRead, "Notifications marked as read")
+}
+
+// Archive handles archiving notifications
+// @Summary Archive notifications
+// @Description Move the listed notifications out of the inbox, marking them read; IDs that are not the user's are skipped
+// @Tags Notifications
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.NotificationIDsRequest true "Notification IDs"
+// @Success 200 {object} pkgresponse.Response
+// @Failure 400 {object} pkgresponse.Response
+// @Failure 401 {object} pkgresponse.Response
+// @Router /notifications/archive [put]
+func (h *NotificationHandler) Archive(c *gin.Context) {
+	h.bulk(c, h.notificationService.Archive, "Notifications archived")
+}
+
+// DeleteMany handles deleting notifications
+// @Summary Delete notifications
+// @Description Delete the listed notifications; IDs that are not the user's are skipped
+// @Tags Notifications
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.NotificationIDsRequest true "Notification IDs"
+// @Success 200 {object} pkgresponse.Response
+// @Failure 400 {object} pkgresponse.Response
+// @Failure 401 {object} pkgresponse.Response
+// @Router /notifications [delete]
+func (h *NotificationHandler) DeleteMany(c *gin.Context) {
+	h.bulk(c, h.notificationService.Delete, "Notifications deleted")
+}
+
+// Delete handles deleting a notification
+// @Summary Delete notification
+// @Description Delete a specific notification
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Notification ID"
+// @Success 200 {object} pkgresponse.Response
+// @Failure 401 {object} pkgresponse.Response
+// @Failure 404 {object} pkgresponse.Response
+// @Router /notifications/{id} [delete]
+func (h *NotificationHandler) Delete(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == nil {
+		pkgresponse.Error(c, middleware.ErrUnauthorized)
+		return
+	}
+
+	idStr := c.Param("id")
+	notificationID, err := uuid.Parse(idStr)
+	if err != nil {
+		pkgresponse.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Invalid notification ID", 400))
+		return
+	}
+
+	if err := h.notificationService.DeleteOne(c.Request.Context(), *userID, notificationID); err != nil {
+		pkgresponse.Error(c, err)
+		return
+	}
+
+	pkgresponse.Success(c, http.StatusOK, gin.H{"message": "Notification deleted"})
+}
+
+// bulk applies a change to the notifications listed in the request body
+func (h *NotificationHandler) bulk(c *gin.Context, apply func(context.Context, uuid.UUID, []uuid.UUID) (int64, error), message string) {
+	userID := middleware.GetUserID(c)
+	if userID == nil {
+		pkgresponse.Error(c, middleware.ErrUnauthorized)
+		return
+	}
+
+	var req request.NotificationIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkgresponse.ValidationError(c, err)
+		return
+	}
+
+	count, err := apply(c.Request.Context(), *userID, req.IDs)
+	if err != nil {
+		pkgresponse.Error(c, err)
+		return
+	}
+
+	pkgresponse.Success(c, http.StatusOK, gin.H{"message": message, "count": count})
+}
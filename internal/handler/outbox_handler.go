@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/handler/dto/request"
+	"bamboo-rescue/internal/handler/dto/response"
+	"bamboo-rescue/internal/middleware"
+	"bamboo-rescue/internal/service"
+	pkgresponse "bamboo-rescue/pkg/response"
+)
+
+// OutboxHandler handles admin requests for background jobs
+type OutboxHandler struct {
+	outboxService service.OutboxService
+}
+
+// NewOutboxHandler creates a new OutboxHandler
+func NewOutboxHandler(outboxService service.OutboxService) *OutboxHandler {
+	return &OutboxHandler{
+		outboxService: outboxService,
+	}
+}
+
+// GetJobs handles listing stuck jobs
+// @Summary List stuck jobs
+// @Description List dead, retrying and stale background jobs, oldest first. Filter by status to see any jobs in that state.
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Job status (pending, processing, done, dead)"
+// @Param kind query string false "Job kind"
+// @Param page query int false "Page number"
+// @Param limit query int false "Limit per page"
+// @Success 200 {object} pkgresponse.Response{data=[]response.OutboxJobResponse}
+// @Failure 401 {object} pkgresponse.Response
+// @Failure 403 {object} pkgresponse.Response
+// @Router /admin/outbox/jobs [get]
+func (h *OutboxHandler) GetJobs(c *gin.Context) {
+	var req request.GetOutboxJobsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		pkgresponse.ValidationError(c, err)
+		return
+	}
+
+	jobs, total, err := h.outboxService.GetStuckJobs(c.Request.Context(), &req)
+	if err != nil {
+		pkgresponse.Error(c, err)
+		return
+	}
+
+	meta := pkgresponse.NewMeta(req.GetDefaultPage(), req.GetDefaultLimit(), total)
+	pkgresponse.SuccessWithMeta(c, response.ToOutboxJobListResponse(jobs), meta)
+}
+
+// GetStats handles outbox statistics
+// @Summary Get job statistics
+// @Description Count background jobs by state
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} pkgresponse.Response{data=response.OutboxStatsResponse}
+// @Failure 401 {object} pkgresponse.Response
+// @Failure 403 {object} pkgresponse.Response
+// @Router /admin/outbox/stats [get]
+func (h *OutboxHandler) GetStats(c *gin.Context) {
+	stats, err := h.outboxService.GetStats(c.Request.Context())
+	if err != nil {
+		pkgresponse.Error(c, err)
+		return
+	}
+
+	pkgresponse.Success(c, http.StatusOK, response.ToOutboxStatsResponse(stats))
+}
+
+// RetryJob handles requeueing a job
+// @Summary Retry job
+// @Description Requeue a dead or retrying job to run now with a fresh attempt budget
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} pkgresponse.Response{data=response.OutboxJobResponse}
+// @Failure 401 {object} pkgresponse.Response
+// @Failure 403 {object} pkgresponse.Response
+// @Failure 404 {object} pkgresponse.Response
+// @Failure 409 {object} pkgresponse.Response
+// @Router /admin/outbox/jobs/{id}/retry [post]
+func (h *OutboxHandler) RetryJob(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		pkgresponse.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Invalid job ID", 400))
+		return
+	}
+
+	job, err := h.outboxService.RetryJob(c.Request.Context(), id)
+	if err != nil {
+		pkgresponse.Error(c, err)
+		return
+	}
+
+	pkgresponse.Success(c, http.StatusOK, response.ToOutboxJobResponse(job))
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"bamboo-rescue/internal/handler/dto/response"
+	"bamboo-rescue/internal/middleware"
+	"bamboo-rescue/internal/service"
+	pkgresponse "bamboo-rescue/pkg/response"
+)
+
+// PushHandler handles push delivery configuration requests
+type PushHandler struct {
+	webPushService service.WebPushService
+}
+
+// NewPushHandler creates a new PushHandler
+func NewPushHandler(webPushService service.WebPushService) *PushHandler {
+	return &PushHandler{
+		webPushService: webPushService,
+	}
+}
+
+// GetVAPIDPublicKey returns the key browsers subscribe to web push with
+// @Summary Get VAPID public key
+// @Description Get the applicationServerKey for PushManager.subscribe
+// @Tags Push
+// @Produce json
+// @Success 200 {object} pkgresponse.Response{data=response.VAPIDPublicKeyResponse}
+// @Failure 503 {object} pkgresponse.Response
+// @Router /push-tokens/vapid-public-key [get]
+func (h *PushHandler) GetVAPIDPublicKey(c *gin.Context) {
+	key := h.webPushService.PublicKey()
+	if key == "" {
+		pkgresponse.Error(c, middleware.NewAppError("WEB_PUSH_DISABLED", "Web push is not configured", http.StatusServiceUnavailable))
+		return
+	}
+
+	pkgresponse.Success(c, http.StatusOK, response.VAPIDPublicKeyResponse{PublicKey: key})
+}
@@ -0,0 +1,273 @@
+package handler
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/handler/dto/request"
+	"bamboo-rescue/internal/middleware"
+	"bamboo-rescue/internal/service"
+	pkgresponse "bamboo-rescue/pkg/response"
+)
+
+const tusExtensions = "creation,termination,expiration"
+
+// TusHandler handles resumable uploads over the tus protocol
+type TusHandler struct {
+	tusService   service.TusService
+	chunkTimeout time.Duration
+}
+
+// NewTusHandler creates a new TusHandler
+func NewTusHandler(tusService service.TusService, chunkTimeout time.Duration) *TusHandler {
+	return &TusHandler{
+		tusService:   tusService,
+		chunkTimeout: chunkTimeout,
+	}
+}
+
+// Options handles tus capability discovery
+// @Summary Tus discovery
+// @Description Report supported tus version, extensions and maximum upload size
+// @Tags Media
+// @Success 204
+// @Router /media/tus [options]
+func (h *TusHandler) Options(c *gin.Context) {
+	c.Header("Tus-Version", middleware.TusVersion)
+	c.Header("Tus-Extension", tusExtensions)
+	c.Header("Tus-Max-Size", strconv.FormatInt(h.tusService.MaxSize(), 10))
+	c.Status(http.StatusNoContent)
+}
+
+// Create handles tus upload creation
+// @Summary Create resumable upload
+// @Description Start a resumable upload. Upload-Metadata must carry case_id, filename and filetype, and may set sensitive to "true".
+// The upload ID becomes the media ID once the upload completes.
+// @Tags Media
+// @Security BearerAuth
+// @Param Tus-Resumable header string true "Protocol version (1.0.0)"
+// @Param Upload-Length header int true "Total size in bytes"
+// @Param Upload-Metadata header string true "tus metadata: case_id, filename, filetype, sensitive"
+// @Success 201
+// @Failure 400 {object} pkgresponse.Response
+// @Failure 401 {object} pkgresponse.Response
+// @Failure 413 {object} pkgresponse.Response
+// @Router /media/tus [post]
+func (h *TusHandler) Create(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == nil {
+		pkgresponse.Error(c, middleware.ErrUnauthorized)
+		return
+	}
+
+	length, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || length <= 0 {
+		pkgresponse.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Invalid Upload-Length", 400))
+		return
+	}
+
+	metadata, err := parseTusMetadata(c.GetHeader("Upload-Metadata"))
+	if err != nil {
+		pkgresponse.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Invalid Upload-Metadata", 400))
+		return
+	}
+
+	caseID, err := uuid.Parse(metadata["case_id"])
+	if err != nil {
+		pkgresponse.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Invalid case ID", 400))
+		return
+	}
+
+	req := request.CreateTusUploadRequest{
+		CaseID:       caseID,
+		FileName:     metadata["filename"],
+		ContentType:  metadata["filetype"],
+		UploadLength: length,
+		IsSensitive:  metadata["sensitive"] == "true",
+	}
+
+	upload, err := h.tusService.Create(c.Request.Context(), &req, *userID)
+	if err != nil {
+		pkgresponse.Error(c, err)
+		return
+	}
+
+	c.Header("Location", strings.TrimSuffix(c.Request.URL.Path, "/")+"/"+upload.ID.String())
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.Status(http.StatusCreated)
+}
+
+// Head handles tus upload offset lookup
+// @Summary Get resumable upload offset
+// @Description Report how many bytes of the upload the server holds
+// @Tags Media
+// @Security BearerAuth
+// @Param id path string true "Upload ID"
+// @Param Tus-Resumable header string true "Protocol version (1.0.0)"
+// @Success 200
+// @Failure 404 {object} pkgresponse.Response
+// @Router /media/tus/{id} [head]
+func (h *TusHandler) Head(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == nil {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	uploadID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	upload, err := h.tusService.Get(c.Request.Context(), uploadID, *userID)
+	if err != nil {
+		// HEAD responses carry no body
+		if appErr, ok := err.(*middleware.AppError); ok {
+			c.Status(appErr.StatusCode)
+			return
+		}
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	setTusUploadHeaders(c, upload)
+	c.Status(http.StatusOK)
+}
+
+// Patch handles tus chunk upload
+// @Summary Upload resumable chunk
+// @Description Append bytes to a resumable upload at Upload-Offset
+// @Tags Media
+// @Security BearerAuth
+// @Accept application/offset+octet-stream
+// @Param id path string true "Upload ID"
+// @Param Tus-Resumable header string true "Protocol version (1.0.0)"
+// @Param Upload-Offset header int true "Offset of this chunk"
+// @Success 204
+// @Failure 404 {object} pkgresponse.Response
+// @Failure 409 {object} pkgresponse.Response
+// @Failure 415 {object} pkgresponse.Response
+// @Router /media/tus/{id} [patch]
+func (h *TusHandler) Patch(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == nil {
+		pkgresponse.Error(c, middleware.ErrUnauthorized)
+		return
+	}
+
+	uploadID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		pkgresponse.Error(c, middleware.NewAppError("UPLOAD_NOT_FOUND", "Upload not found", 404))
+		return
+	}
+
+	if c.ContentType() != "application/offset+octet-stream" {
+		pkgresponse.Error(c, middleware.NewAppError("INVALID_CONTENT_TYPE",
+			"Content-Type must be application/offset+octet-stream", 415))
+		return
+	}
+
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		pkgresponse.Error(c, middleware.NewAppError("VALIDATION_ERROR", "Invalid Upload-Offset", 400))
+		return
+	}
+
+	if c.Request.ContentLength > h.tusService.MaxSize() {
+		pkgresponse.Error(c, middleware.NewAppError("UPLOAD_TOO_LARGE", "Chunk exceeds maximum upload size", 413))
+		return
+	}
+
+	// Large chunks over slow links outlive the server-wide timeouts
+	if h.chunkTimeout > 0 {
+		rc := http.NewResponseController(c.Writer)
+		deadline := time.Now().Add(h.chunkTimeout)
+		_ = rc.SetReadDeadline(deadline)
+		_ = rc.SetWriteDeadline(deadline)
+	}
+
+	upload, err := h.tusService.WriteChunk(c.Request.Context(), uploadID, *userID, offset, c.Request.Body)
+	if upload != nil {
+		setTusUploadHeaders(c, upload)
+	}
+	if err != nil {
+		pkgresponse.Error(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Delete handles tus upload termination
+// @Summary Terminate resumable upload
+// @Description Abort a resumable upload and discard received bytes
+// @Tags Media
+// @Security BearerAuth
+// @Param id path string true "Upload ID"
+// @Param Tus-Resumable header string true "Protocol version (1.0.0)"
+// @Success 204
+// @Failure 404 {object} pkgresponse.Response
+// @Router /media/tus/{id} [delete]
+func (h *TusHandler) Delete(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == nil {
+		pkgresponse.Error(c, middleware.ErrUnauthorized)
+		return
+	}
+
+	uploadID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		pkgresponse.Error(c, middleware.NewAppError("UPLOAD_NOT_FOUND", "Upload not found", 404))
+		return
+	}
+
+	if err := h.tusService.Terminate(c.Request.Context(), uploadID, *userID); err != nil {
+		pkgresponse.Error(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func setTusUploadHeaders(c *gin.Context, upload *entity.MediaUpload) {
+	c.Header("Upload-Offset", strconv.FormatInt(upload.UploadOffset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(upload.UploadLength, 10))
+	if !upload.IsComplete() {
+		c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	}
+}
+
+// parseTusMetadata decodes "key base64value,key base64value" pairs
+func parseTusMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if header == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		fields := strings.Fields(pair)
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, middleware.NewAppError("VALIDATION_ERROR", "Invalid Upload-Metadata", 400)
+		}
+
+		value := ""
+		if len(fields) == 2 {
+			decoded, err := base64.StdEncoding.DecodeString(fields[1])
+			if err != nil {
+				return nil, err
+			}
+			value = string(decoded)
+		}
+		metadata[fields[0]] = value
+	}
+
+	return metadata, nil
+}
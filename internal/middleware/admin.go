@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"bamboo-rescue/pkg/response"
+)
+
+// CoordinatorKey is the context key set for coordinators
+const CoordinatorKey = "isCoordinator"
+
+// RequireAdmin allows only the listed users through. It must run after Auth.
+func RequireAdmin(adminIDs []string) gin.HandlerFunc {
+	admins := parseUserIDs(adminIDs)
+
+	return func(c *gin.Context) {
+		userID := GetUserID(c)
+		if userID == nil || !admins[*userID] {
+			response.Forbidden(c, "Admin access required")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireCoordinator allows only the users in the given lists through and
+// marks them as coordinators. It must run after Auth.
+func RequireCoordinator(idLists ...[]string) gin.HandlerFunc {
+	coordinators := mergeUserIDs(idLists)
+
+	return func(c *gin.Context) {
+		userID := GetUserID(c)
+		if userID == nil || !coordinators[*userID] {
+			response.Forbidden(c, "Coordinator access required")
+			c.Abort()
+			return
+		}
+
+		c.Set(CoordinatorKey, true)
+		c.Next()
+	}
+}
+
+// DetectCoordinator marks requests from the listed users as coordinators,
+// letting everyone else through too. It must run after Auth or OptionalAuth.
+func DetectCoordinator(idLists ...[]string) gin.HandlerFunc {
+	coordinators := mergeUserIDs(idLists)
+
+	return func(c *gin.Context) {
+		if userID := GetUserID(c); userID != nil && coordinators[*userID] {
+			c.Set(CoordinatorKey, true)
+		}
+		c.Next()
+	}
+}
+
+// IsCoordinator reports whether DetectCoordinator marked the request
+func IsCoordinator(c *gin.Context) bool {
+	return c.GetBool(CoordinatorKey)
+}
+
+func parseUserIDs(ids []string) map[uuid.UUID]bool {
+	parsed := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		if u, err := uuid.Parse(id); err == nil {
+			parsed[u] = true
+		}
+	}
+	return parsed
+}
+
+func mergeUserIDs(idLists [][]string) map[uuid.UUID]bool {
+	merged := make(map[uuid.UUID]bool)
+	for _, ids := range idLists {
+		for id := range parseUserIDs(ids) {
+			merged[id] = true
+		}
+	}
+	return merged
+}
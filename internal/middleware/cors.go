@@ -26,6 +26,7 @@ func DefaultCORSConfig() CORSConfig {
 			"PUT",
 			"PATCH",
 			"DELETE",
+			"HEAD",
 			"OPTIONS",
 		},
 		AllowHeaders: []string{
@@ -34,10 +35,22 @@ func DefaultCORSConfig() CORSConfig {
 			"Content-Type",
 			"X-Requested-With",
 			"X-Request-ID",
+			"Tus-Resumable",
+			"Upload-Length",
+			"Upload-Metadata",
+			"Upload-Offset",
 		},
 		ExposeHeaders: []string{
 			"Content-Length",
 			"Content-Type",
+			"Location",
+			"Tus-Resumable",
+			"Tus-Version",
+			"Tus-Extension",
+			"Tus-Max-Size",
+			"Upload-Length",
+			"Upload-Offset",
+			"Upload-Expires",
 		},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -14,19 +14,27 @@ import (
 type AppError = apperror.AppError
 
 var (
-	ErrNotFound           = apperror.ErrNotFound
-	ErrUnauthorized       = apperror.ErrUnauthorized
-	ErrForbidden          = apperror.ErrForbidden
-	ErrBadRequest         = apperror.ErrBadRequest
-	ErrConflict           = apperror.ErrConflict
-	ErrInternalServer     = apperror.ErrInternalServer
-	ErrValidation         = apperror.ErrValidation
-	ErrInvalidToken       = apperror.ErrInvalidToken
-	ErrUserNotFound       = apperror.ErrUserNotFound
-	ErrCaseNotFound       = apperror.ErrCaseNotFound
-	ErrInvalidCredentials = apperror.ErrInvalidCredentials
-	ErrEmailExists        = apperror.ErrEmailExists
-	ErrPhoneExists        = apperror.ErrPhoneExists
+	ErrNotFound             = apperror.ErrNotFound
+	ErrUnauthorized         = apperror.ErrUnauthorized
+	ErrForbidden            = apperror.ErrForbidden
+	ErrBadRequest           = apperror.ErrBadRequest
+	ErrConflict             = apperror.ErrConflict
+	ErrInternalServer       = apperror.ErrInternalServer
+	ErrValidation           = apperror.ErrValidation
+	ErrInvalidToken         = apperror.ErrInvalidToken
+	ErrUserNotFound         = apperror.ErrUserNotFound
+	ErrCaseNotFound         = apperror.ErrCaseNotFound
+	ErrNotificationNotFound = apperror.ErrNotificationNotFound
+	ErrInvalidCredentials   = apperror.ErrInvalidCredentials
+	ErrEmailExists          = apperror.ErrEmailExists
+	ErrPhoneExists          = apperror.ErrPhoneExists
+	ErrGeocodeUnavailable   = apperror.ErrGeocodeUnavailable
+	ErrLocationUnrecognized = apperror.ErrLocationUnrecognized
+	ErrLocationAmbiguous    = apperror.ErrLocationAmbiguous
+	ErrAlertZoneNotFound    = apperror.ErrAlertZoneNotFound
+	ErrAlertZoneInactive    = apperror.ErrAlertZoneInactive
+	ErrEventNotFound        = apperror.ErrEventNotFound
+	ErrEventNotCoordinator  = apperror.ErrEventNotCoordinator
 )
 
 func NewAppError(code string, message string, status int) *AppError {
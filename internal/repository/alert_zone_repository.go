@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/domain/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AlertZoneRepository defines the interface for alert zone data access
+type AlertZoneRepository interface {
+	Create(ctx context.Context, zone *entity.AlertZone) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.AlertZone, error)
+	Update(ctx context.Context, zone *entity.AlertZone) error
+	// GetActive returns the zones in force at now whose bounding box meets
+	// bbox, or all of them for a nil bbox, most severe first
+	GetActive(ctx context.Context, bbox *entity.BoundingBox, now time.Time) ([]entity.AlertZone, error)
+	// GetActiveContaining returns the zones in force at now that a point
+	// lies inside
+	GetActiveContaining(ctx context.Context, lat, lng float64, now time.Time) ([]entity.AlertZone, error)
+	// TagCase records that a case lies inside the given zones
+	TagCase(ctx context.Context, caseID uuid.UUID, zoneIDs []uuid.UUID) error
+}
+
+type alertZoneRepository struct {
+	db *gorm.DB
+}
+
+// NewAlertZoneRepository creates a new AlertZoneRepository
+func NewAlertZoneRepository(db interface{}) AlertZoneRepository {
+	return &alertZoneRepository{db: db.(*gorm.DB)}
+}
+
+func (r *alertZoneRepository) Create(ctx context.Context, zone *entity.AlertZone) error {
+	if zone.ID == uuid.Nil {
+		zone.ID = uuid.New()
+	}
+	return withContext(ctx, r.db).Create(zone).Error
+}
+
+func (r *alertZoneRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.AlertZone, error) {
+	var zone entity.AlertZone
+	err := withContext(ctx, r.db).
+		First(&zone, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &zone, nil
+}
+
+func (r *alertZoneRepository) Update(ctx context.Context, zone *entity.AlertZone) error {
+	return withContext(ctx, r.db).Save(zone).Error
+}
+
+func (r *alertZoneRepository) GetActive(ctx context.Context, bbox *entity.BoundingBox, now time.Time) ([]entity.AlertZone, error) {
+	query := r.active(ctx, now)
+	if bbox != nil {
+		query = query.Where("min_lat <= ? AND max_lat >= ?", bbox.MaxLat, bbox.MinLat)
+		if bbox.MinLng <= bbox.MaxLng {
+			query = query.Where("min_lng <= ? AND max_lng >= ?", bbox.MaxLng, bbox.MinLng)
+		} else {
+			// The box crosses the antimeridian; zones never do
+			query = query.Where("(max_lng >= ? OR min_lng <= ?)", bbox.MinLng, bbox.MaxLng)
+		}
+	}
+
+	var zones []entity.AlertZone
+	err := query.
+		Order("CASE severity WHEN 'emergency' THEN 3 WHEN 'warning' THEN 2 ELSE 1 END DESC").
+		Order("created_at DESC").
+		Find(&zones).Error
+	return zones, err
+}
+
+func (r *alertZoneRepository) GetActiveContaining(ctx context.Context, lat, lng float64, now time.Time) ([]entity.AlertZone, error) {
+	// Bounding box filter first, then the exact polygon test
+	var candidates []entity.AlertZone
+	err := r.active(ctx, now).
+		Where("? BETWEEN min_lat AND max_lat", lat).
+		Where("? BETWEEN min_lng AND max_lng", lng).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	zones := candidates[:0]
+	for _, z := range candidates {
+		if z.Polygon.Contains(lat, lng) {
+			zones = append(zones, z)
+		}
+	}
+	return zones, nil
+}
+
+func (r *alertZoneRepository) TagCase(ctx context.Context, caseID uuid.UUID, zoneIDs []uuid.UUID) error {
+	if len(zoneIDs) == 0 {
+		return nil
+	}
+	tags := make([]entity.CaseAlertZone, len(zoneIDs))
+	for i, id := range zoneIDs {
+		tags[i] = entity.CaseAlertZone{CaseID: caseID, AlertZoneID: id}
+	}
+	return withContext(ctx, r.db).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&tags).Error
+}
+
+// active selects the zones that have not been ended or expired by now
+func (r *alertZoneRepository) active(ctx context.Context, now time.Time) *gorm.DB {
+	return withContext(ctx, r.db).
+		Model(&entity.AlertZone{}).
+		Where("ended_at IS NULL AND expires_at > ?", now)
+}
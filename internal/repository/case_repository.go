@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"math"
 	"sort"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	"bamboo-rescue/internal/domain/entity"
 	"bamboo-rescue/internal/domain/enum"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // CaseRepository defines the interface for case data access
@@ -17,32 +19,119 @@ type CaseRepository interface {
 	Create(ctx context.Context, c *entity.Case) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Case, error)
 	GetByIDWithDetails(ctx context.Context, id uuid.UUID) (*entity.Case, error)
+	// GetByIDs returns the cases among ids that exist, in no set order
+	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.Case, error)
 	GetNearby(ctx context.Context, lat, lng float64, radiusKm int, types []enum.CaseType, limit int) ([]entity.CaseNearby, error)
-	GetCases(ctx context.Context, query string, caseType *enum.CaseType, status *enum.CaseStatus, urgency *enum.UrgencyLevel, limit, offset int) ([]entity.Case, int64, error)
+	GetCases(ctx context.Context, query string, caseType *enum.CaseType, status *enum.CaseStatus, urgency *enum.UrgencyLevel, area entity.AdminArea, eventID *uuid.UUID, limit, offset int) ([]entity.Case, int64, error)
 	Update(ctx context.Context, c *entity.Case) error
 	UpdateStatus(ctx context.Context, id uuid.UUID, status enum.CaseStatus) error
+	// Resolve marks a case resolved, reporting false if it already was
+	Resolve(ctx context.Context, id uuid.UUID) (bool, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 
+	// Areas
+	// SetArea stores the administrative units of a case, and its address
+	// if it has none yet
+	SetArea(ctx context.Context, id uuid.UUID, address string, area entity.AdminArea) error
+	// GetAreaStats counts cases per unit of the given tier within area
+	GetAreaStats(ctx context.Context, tier entity.AdminTier, area entity.AdminArea) ([]entity.CaseAreaStats, error)
+
+	// Map
+	// GetInBounds returns up to limit active cases in bbox, most urgent
+	// first, and how many there are in all. A non-nil eventID keeps only
+	// that event's cases.
+	GetInBounds(ctx context.Context, bbox entity.BoundingBox, types []enum.CaseType, eventID *uuid.UUID, limit int) ([]entity.Case, int64, error)
+	// GetClustersInBounds groups the active cases in bbox by cells of a grid
+	// cellDeg degrees across
+	GetClustersInBounds(ctx context.Context, bbox entity.BoundingBox, types []enum.CaseType, eventID *uuid.UUID, cellDeg float64) ([]entity.CaseCluster, error)
+
+	// Disaster events
+	// FindUnassignedInBounds returns up to limit cases in bbox with no event,
+	// created in [from, to) with a nil to open, ordered by ID after afterID.
+	// Only their ID and position are loaded.
+	FindUnassignedInBounds(ctx context.Context, bbox entity.BoundingBox, from time.Time, to *time.Time, afterID uuid.UUID, limit int) ([]entity.Case, error)
+	// SetEvent groups cases under an event, or none for a nil eventID. A
+	// non-nil maxVolunteers becomes their volunteer limit, though never below
+	// the volunteers they already have.
+	SetEvent(ctx context.Context, caseIDs []uuid.UUID, eventID *uuid.UUID, maxVolunteers *int) error
+	// SetEventMaxVolunteers sets the volunteer limit of an event's active
+	// cases, though never below the volunteers they already have
+	SetEventMaxVolunteers(ctx context.Context, eventID uuid.UUID, maxVolunteers int) error
+	// GetEventStats sums up the cases of an event
+	GetEventStats(ctx context.Context, eventID uuid.UUID) (*entity.DisasterEventStats, error)
+
+	// Export
+	// StreamCases calls fn with each case matching filter and its type
+	// details, oldest first, loading them a batch at a time
+	StreamCases(ctx context.Context, filter CaseExportFilter, fn func(*entity.Case) error) error
+
 	// Volunteers
 	AddVolunteer(ctx context.Context, cv *entity.CaseVolunteer) error
 	GetVolunteer(ctx context.Context, caseID, volunteerID uuid.UUID) (*entity.CaseVolunteer, error)
 	UpdateVolunteerStatus(ctx context.Context, caseID, volunteerID uuid.UUID, status enum.VolunteerStatus) error
-	ReactivateVolunteer(ctx context.Context, caseID, volunteerID uuid.UUID, latitude, longitude *float64, distanceKm *float64) error
+	// ReactivateVolunteer rejoins a withdrawn volunteer with the position and
+	// route in cv
+	ReactivateVolunteer(ctx context.Context, cv *entity.CaseVolunteer) error
 	RemoveVolunteer(ctx context.Context, caseID, volunteerID uuid.UUID) error
 	GetVolunteersByCaseID(ctx context.Context, caseID uuid.UUID) ([]entity.CaseVolunteer, error)
 
 	// Updates/Timeline
 	CreateUpdate(ctx context.Context, update *entity.CaseUpdate) error
+	GetUpdateByID(ctx context.Context, id uuid.UUID) (*entity.CaseUpdate, error)
 	GetUpdates(ctx context.Context, caseID uuid.UUID, limit, offset int) ([]entity.CaseUpdate, int64, error)
+	// GetActivityForUser returns updates by others since since on cases the
+	// user reported or volunteers on, newest first
+	GetActivityForUser(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]CaseActivity, error)
 
 	// Comments
 	CreateComment(ctx context.Context, comment *entity.CaseComment) error
+	GetCommentByID(ctx context.Context, id uuid.UUID) (*entity.CaseComment, error)
 	GetCommentsByCaseID(ctx context.Context, caseID uuid.UUID, limit, offset int) ([]entity.CaseComment, int64, error)
 	DeleteComment(ctx context.Context, commentID, userID uuid.UUID) error
 
 	// User cases
 	GetUserReportedCases(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Case, int64, error)
 	GetUserAcceptedCases(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Case, int64, error)
+
+	// Followers
+	// Follow subscribes a user to a case; following twice is a no-op
+	Follow(ctx context.Context, caseID, userID uuid.UUID) error
+	Unfollow(ctx context.Context, caseID, userID uuid.UUID) error
+	// GetFollowers returns the active users following a case
+	GetFollowers(ctx context.Context, caseID uuid.UUID) ([]entity.User, error)
+	GetFollowedCases(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Case, int64, error)
+
+	// Dismissals
+	// Dismiss records that a user turned a case down; dismissing twice is a no-op
+	Dismiss(ctx context.Context, caseID, userID uuid.UUID) error
+	Undismiss(ctx context.Context, caseID, userID uuid.UUID) error
+	// GetDismissedUserIDs returns the users who dismissed a case
+	GetDismissedUserIDs(ctx context.Context, caseID uuid.UUID) (map[uuid.UUID]bool, error)
+}
+
+// CaseExportFilter selects the cases to export. Zero fields do not filter.
+type CaseExportFilter struct {
+	Query   string
+	Type    *enum.CaseType
+	Status  *enum.CaseStatus
+	Urgency *enum.UrgencyLevel
+	Area    entity.AdminArea
+	// From and To bound the creation time, To exclusive
+	From    *time.Time
+	To      *time.Time
+	BBox    *entity.BoundingBox
+	EventID *uuid.UUID
+}
+
+// exportBatchSize is how many cases StreamCases loads at a time
+const exportBatchSize = 500
+
+// CaseActivity is a case update together with the title of its case and
+// the name of the user who made it
+type CaseActivity struct {
+	entity.CaseUpdate
+	CaseTitle string  `gorm:"column:case_title"`
+	ActorName *string `gorm:"column:actor_name"`
 }
 
 type caseRepository struct {
@@ -60,7 +149,7 @@ func (r *caseRepository) Create(ctx context.Context, c *entity.Case) error {
 		c.ID = uuid.New()
 	}
 
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return withContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
 		// Create the main case (omit associations to handle them manually)
 		if err := tx.Omit("AnimalDetails", "FloodDetails", "AccidentDetails", "Media", "Volunteers", "Updates").Create(c).Error; err != nil {
 			return err
@@ -114,7 +203,7 @@ func (r *caseRepository) Create(ctx context.Context, c *entity.Case) error {
 
 func (r *caseRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Case, error) {
 	var c entity.Case
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		First(&c, "id = ?", id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -125,15 +214,24 @@ func (r *caseRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Cas
 	return &c, nil
 }
 
+func (r *caseRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.Case, error) {
+	var cases []entity.Case
+	err := withContext(ctx, r.db).
+		Where("id IN ?", ids).
+		Find(&cases).Error
+	return cases, err
+}
+
 func (r *caseRepository) GetByIDWithDetails(ctx context.Context, id uuid.UUID) (*entity.Case, error) {
 	var c entity.Case
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		Preload("AnimalDetails").
 		Preload("FloodDetails").
 		Preload("AccidentDetails").
 		Preload("Media").
 		Preload("Volunteers").
 		Preload("Volunteers.Volunteer").
+		Preload("AlertZones").
 		First(&c, "id = ?", id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -149,7 +247,7 @@ func (r *caseRepository) GetNearby(ctx context.Context, lat, lng float64, radius
 	bbox := entity.NewBoundingBox(lat, lng, float64(radiusKm))
 
 	// Build query with bounding box filter
-	query := r.db.WithContext(ctx).
+	query := withContext(ctx, r.db).
 		Model(&entity.Case{}).
 		Select("id, case_type, title, urgency, status, volunteer_count, created_at, latitude, longitude").
 		Where("status IN ?", []string{"pending", "accepted", "in_progress"}).
@@ -217,11 +315,11 @@ func (r *caseRepository) GetNearby(ctx context.Context, lat, lng float64, radius
 	return nearby, nil
 }
 
-func (r *caseRepository) GetCases(ctx context.Context, query string, caseType *enum.CaseType, status *enum.CaseStatus, urgency *enum.UrgencyLevel, limit, offset int) ([]entity.Case, int64, error) {
+func (r *caseRepository) GetCases(ctx context.Context, query string, caseType *enum.CaseType, status *enum.CaseStatus, urgency *enum.UrgencyLevel, area entity.AdminArea, eventID *uuid.UUID, limit, offset int) ([]entity.Case, int64, error) {
 	var cases []entity.Case
 	var total int64
 
-	db := r.db.WithContext(ctx).Model(&entity.Case{})
+	db := withContext(ctx, r.db).Model(&entity.Case{})
 
 	// Apply search query - search in title and address
 	if query != "" {
@@ -244,13 +342,21 @@ func (r *caseRepository) GetCases(ctx context.Context, query string, caseType *e
 		db = db.Where("urgency = ?", *urgency)
 	}
 
+	// Apply area filters
+	db = whereArea(db, area)
+
+	// Apply event filter
+	if eventID != nil {
+		db = db.Where("event_id = ?", *eventID)
+	}
+
 	// Count total
 	if err := db.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Get paginated results
-	if err := db.Order("created_at DESC").Limit(limit).Offset(offset).Find(&cases).Error; err != nil {
+	if err := db.Preload("Media").Order("created_at DESC").Limit(limit).Offset(offset).Find(&cases).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -258,7 +364,8 @@ func (r *caseRepository) GetCases(ctx context.Context, query string, caseType *e
 }
 
 func (r *caseRepository) Update(ctx context.Context, c *entity.Case) error {
-	return r.db.WithContext(ctx).Save(c).Error
+	// Alert zone tags are only written by the alert zone repository
+	return withContext(ctx, r.db).Omit("AlertZones").Save(c).Error
 }
 
 func (r *caseRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status enum.CaseStatus) error {
@@ -273,14 +380,26 @@ func (r *caseRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status
 		updates["resolved_at"] = time.Now()
 	}
 
-	return r.db.WithContext(ctx).
+	return withContext(ctx, r.db).
 		Model(&entity.Case{}).
 		Where("id = ?", id).
 		Updates(updates).Error
 }
 
+func (r *caseRepository) Resolve(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := withContext(ctx, r.db).
+		Model(&entity.Case{}).
+		Where("id = ? AND status <> ?", id, enum.CaseStatusResolved).
+		Updates(map[string]interface{}{
+			"status":      enum.CaseStatusResolved,
+			"resolved_at": time.Now(),
+			"updated_at":  time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
 func (r *caseRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return withContext(ctx, r.db).
 		Model(&entity.Case{}).
 		Where("id = ?", id).
 		Update("status", enum.CaseStatusCancelled).Error
@@ -292,7 +411,7 @@ func (r *caseRepository) AddVolunteer(ctx context.Context, cv *entity.CaseVolunt
 		cv.ID = uuid.New()
 	}
 
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return withContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
 		// Add volunteer
 		if err := tx.Create(cv).Error; err != nil {
 			return err
@@ -317,7 +436,7 @@ func (r *caseRepository) AddVolunteer(ctx context.Context, cv *entity.CaseVolunt
 
 func (r *caseRepository) GetVolunteer(ctx context.Context, caseID, volunteerID uuid.UUID) (*entity.CaseVolunteer, error) {
 	var cv entity.CaseVolunteer
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		Preload("Volunteer").
 		First(&cv, "case_id = ? AND volunteer_id = ?", caseID, volunteerID).Error
 	if err != nil {
@@ -341,40 +460,44 @@ func (r *caseRepository) UpdateVolunteerStatus(ctx context.Context, caseID, volu
 		updates["completed_at"] = time.Now()
 	}
 
-	return r.db.WithContext(ctx).
+	return withContext(ctx, r.db).
 		Model(&entity.CaseVolunteer{}).
 		Where("case_id = ? AND volunteer_id = ?", caseID, volunteerID).
 		Updates(updates).Error
 }
 
-func (r *caseRepository) ReactivateVolunteer(ctx context.Context, caseID, volunteerID uuid.UUID, latitude, longitude *float64, distanceKm *float64) error {
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+func (r *caseRepository) ReactivateVolunteer(ctx context.Context, cv *entity.CaseVolunteer) error {
+	return withContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
 		// Update volunteer status back to accepted and update location info
 		updates := map[string]interface{}{
 			"status":             enum.VolunteerStatusAccepted,
 			"accepted_at":        time.Now(),
-			"accepted_latitude":  latitude,
-			"accepted_longitude": longitude,
-			"distance_km":        distanceKm,
+			"accepted_latitude":  cv.AcceptedLatitude,
+			"accepted_longitude": cv.AcceptedLongitude,
+			"distance_km":        cv.DistanceKm,
+			"travel_mode":        cv.TravelMode,
+			"route_distance_km":  cv.RouteDistanceKm,
+			"eta_seconds":        cv.EtaSeconds,
+			"eta_estimated":      cv.EtaEstimated,
 			"arrived_at":         nil,
 			"completed_at":       nil,
 		}
 
 		if err := tx.Model(&entity.CaseVolunteer{}).
-			Where("case_id = ? AND volunteer_id = ?", caseID, volunteerID).
+			Where("case_id = ? AND volunteer_id = ?", cv.CaseID, cv.VolunteerID).
 			Updates(updates).Error; err != nil {
 			return err
 		}
 
 		// Increment volunteer count
 		return tx.Model(&entity.Case{}).
-			Where("id = ?", caseID).
+			Where("id = ?", cv.CaseID).
 			UpdateColumn("volunteer_count", gorm.Expr("volunteer_count + 1")).Error
 	})
 }
 
 func (r *caseRepository) RemoveVolunteer(ctx context.Context, caseID, volunteerID uuid.UUID) error {
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return withContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
 		// Update volunteer status to withdrawn
 		if err := tx.Model(&entity.CaseVolunteer{}).
 			Where("case_id = ? AND volunteer_id = ?", caseID, volunteerID).
@@ -391,7 +514,7 @@ func (r *caseRepository) RemoveVolunteer(ctx context.Context, caseID, volunteerI
 
 func (r *caseRepository) GetVolunteersByCaseID(ctx context.Context, caseID uuid.UUID) ([]entity.CaseVolunteer, error) {
 	var volunteers []entity.CaseVolunteer
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		Preload("Volunteer").
 		Where("case_id = ?", caseID).
 		Order("accepted_at ASC").
@@ -403,14 +526,27 @@ func (r *caseRepository) CreateUpdate(ctx context.Context, update *entity.CaseUp
 	if update.ID == uuid.Nil {
 		update.ID = uuid.New()
 	}
-	return r.db.WithContext(ctx).Create(update).Error
+	return withContext(ctx, r.db).Create(update).Error
+}
+
+func (r *caseRepository) GetUpdateByID(ctx context.Context, id uuid.UUID) (*entity.CaseUpdate, error) {
+	var update entity.CaseUpdate
+	err := withContext(ctx, r.db).
+		First(&update, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &update, nil
 }
 
 func (r *caseRepository) GetUpdates(ctx context.Context, caseID uuid.UUID, limit, offset int) ([]entity.CaseUpdate, int64, error) {
 	var updates []entity.CaseUpdate
 	var total int64
 
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		Model(&entity.CaseUpdate{}).
 		Where("case_id = ?", caseID).
 		Count(&total).Error
@@ -418,7 +554,7 @@ func (r *caseRepository) GetUpdates(ctx context.Context, caseID uuid.UUID, limit
 		return nil, 0, err
 	}
 
-	err = r.db.WithContext(ctx).
+	err = withContext(ctx, r.db).
 		Preload("User").
 		Where("case_id = ?", caseID).
 		Order("created_at DESC").
@@ -429,11 +565,30 @@ func (r *caseRepository) GetUpdates(ctx context.Context, caseID uuid.UUID, limit
 	return updates, total, err
 }
 
+func (r *caseRepository) GetActivityForUser(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]CaseActivity, error) {
+	var activity []CaseActivity
+	err := withContext(ctx, r.db).
+		Table("case_updates cu").
+		Select("cu.*, c.title AS case_title, u.display_name AS actor_name").
+		Joins("JOIN cases c ON c.id = cu.case_id").
+		Joins("LEFT JOIN users u ON u.id = cu.user_id").
+		Where("cu.created_at >= ?", since).
+		Where("cu.user_id IS DISTINCT FROM ?", userID).
+		Where(`c.reporter_id = ? OR EXISTS (
+			SELECT 1 FROM case_volunteers cv
+			WHERE cv.case_id = c.id AND cv.volunteer_id = ? AND cv.status <> ?)`,
+			userID, userID, enum.VolunteerStatusWithdrawn).
+		Order("cu.created_at DESC").
+		Limit(limit).
+		Find(&activity).Error
+	return activity, err
+}
+
 func (r *caseRepository) GetUserReportedCases(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Case, int64, error) {
 	var cases []entity.Case
 	var total int64
 
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		Model(&entity.Case{}).
 		Where("reporter_id = ?", userID).
 		Count(&total).Error
@@ -441,7 +596,7 @@ func (r *caseRepository) GetUserReportedCases(ctx context.Context, userID uuid.U
 		return nil, 0, err
 	}
 
-	err = r.db.WithContext(ctx).
+	err = withContext(ctx, r.db).
 		Where("reporter_id = ?", userID).
 		Order("created_at DESC").
 		Limit(limit).
@@ -454,7 +609,7 @@ func (r *caseRepository) GetUserReportedCases(ctx context.Context, userID uuid.U
 func (r *caseRepository) GetUserAcceptedCases(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Case, int64, error) {
 	var total int64
 
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		Model(&entity.CaseVolunteer{}).
 		Where("volunteer_id = ?", userID).
 		Count(&total).Error
@@ -463,7 +618,7 @@ func (r *caseRepository) GetUserAcceptedCases(ctx context.Context, userID uuid.U
 	}
 
 	var caseIDs []uuid.UUID
-	err = r.db.WithContext(ctx).
+	err = withContext(ctx, r.db).
 		Model(&entity.CaseVolunteer{}).
 		Select("case_id").
 		Where("volunteer_id = ?", userID).
@@ -480,7 +635,7 @@ func (r *caseRepository) GetUserAcceptedCases(ctx context.Context, userID uuid.U
 	}
 
 	var cases []entity.Case
-	err = r.db.WithContext(ctx).
+	err = withContext(ctx, r.db).
 		Where("id IN ?", caseIDs).
 		Find(&cases).Error
 
@@ -491,14 +646,27 @@ func (r *caseRepository) CreateComment(ctx context.Context, comment *entity.Case
 	if comment.ID == uuid.Nil {
 		comment.ID = uuid.New()
 	}
-	return r.db.WithContext(ctx).Create(comment).Error
+	return withContext(ctx, r.db).Create(comment).Error
+}
+
+func (r *caseRepository) GetCommentByID(ctx context.Context, id uuid.UUID) (*entity.CaseComment, error) {
+	var comment entity.CaseComment
+	err := withContext(ctx, r.db).
+		First(&comment, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &comment, nil
 }
 
 func (r *caseRepository) GetCommentsByCaseID(ctx context.Context, caseID uuid.UUID, limit, offset int) ([]entity.CaseComment, int64, error) {
 	var comments []entity.CaseComment
 	var total int64
 
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		Model(&entity.CaseComment{}).
 		Where("case_id = ?", caseID).
 		Count(&total).Error
@@ -506,7 +674,7 @@ func (r *caseRepository) GetCommentsByCaseID(ctx context.Context, caseID uuid.UU
 		return nil, 0, err
 	}
 
-	err = r.db.WithContext(ctx).
+	err = withContext(ctx, r.db).
 		Preload("User").
 		Where("case_id = ?", caseID).
 		Order("created_at ASC").
@@ -518,7 +686,7 @@ func (r *caseRepository) GetCommentsByCaseID(ctx context.Context, caseID uuid.UU
 }
 
 func (r *caseRepository) DeleteComment(ctx context.Context, commentID, userID uuid.UUID) error {
-	result := r.db.WithContext(ctx).
+	result := withContext(ctx, r.db).
 		Where("id = ? AND user_id = ?", commentID, userID).
 		Delete(&entity.CaseComment{})
 	if result.Error != nil {
@@ -530,6 +698,446 @@ func (r *caseRepository) DeleteComment(ctx context.Context, commentID, userID uu
 	return nil
 }
 
+func (r *caseRepository) Follow(ctx context.Context, caseID, userID uuid.UUID) error {
+	return withContext(ctx, r.db).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&entity.CaseFollower{CaseID: caseID, UserID: userID}).Error
+}
+
+func (r *caseRepository) Unfollow(ctx context.Context, caseID, userID uuid.UUID) error {
+	return withContext(ctx, r.db).
+		Where("case_id = ? AND user_id = ?", caseID, userID).
+		Delete(&entity.CaseFollower{}).Error
+}
+
+func (r *caseRepository) GetFollowers(ctx context.Context, caseID uuid.UUID) ([]entity.User, error) {
+	var users []entity.User
+	err := withContext(ctx, r.db).
+		Joins("JOIN case_followers cf ON cf.user_id = users.id").
+		Where("cf.case_id = ?", caseID).
+		Where("users.is_active = true").
+		Find(&users).Error
+	return users, err
+}
+
+func (r *caseRepository) GetFollowedCases(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Case, int64, error) {
+	var cases []entity.Case
+	var total int64
+
+	err := withContext(ctx, r.db).
+		Model(&entity.CaseFollower{}).
+		Where("user_id = ?", userID).
+		Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = withContext(ctx, r.db).
+		Joins("JOIN case_followers cf ON cf.case_id = cases.id").
+		Where("cf.user_id = ?", userID).
+		Order("cf.created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&cases).Error
+
+	return cases, total, err
+}
+
+func (r *caseRepository) Dismiss(ctx context.Context, caseID, userID uuid.UUID) error {
+	return withContext(ctx, r.db).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&entity.CaseDismissal{CaseID: caseID, UserID: userID}).Error
+}
+
+func (r *caseRepository) Undismiss(ctx context.Context, caseID, userID uuid.UUID) error {
+	return withContext(ctx, r.db).
+		Where("case_id = ? AND user_id = ?", caseID, userID).
+		Delete(&entity.CaseDismissal{}).Error
+}
+
+func (r *caseRepository) GetDismissedUserIDs(ctx context.Context, caseID uuid.UUID) (map[uuid.UUID]bool, error) {
+	var userIDs []uuid.UUID
+	err := withContext(ctx, r.db).
+		Model(&entity.CaseDismissal{}).
+		Where("case_id = ?", caseID).
+		Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	dismissed := make(map[uuid.UUID]bool, len(userIDs))
+	for _, id := range userIDs {
+		dismissed[id] = true
+	}
+	return dismissed, nil
+}
+
+func (r *caseRepository) SetArea(ctx context.Context, id uuid.UUID, address string, area entity.AdminArea) error {
+	updates := map[string]interface{}{
+		"province":   nullIfEmpty(area.Province),
+		"district":   nullIfEmpty(area.District),
+		"ward":       nullIfEmpty(area.Ward),
+		"updated_at": time.Now(),
+	}
+	// Never overwrite an address the reporter typed in
+	if address != "" {
+		updates["address"] = gorm.Expr("COALESCE(address, ?)", address)
+	}
+
+	return withContext(ctx, r.db).
+		Model(&entity.Case{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+// areaColumns maps each administrative tier to its column on cases
+var areaColumns = map[entity.AdminTier]string{
+	entity.AdminTierProvince: "province",
+	entity.AdminTierDistrict: "district",
+	entity.AdminTierWard:     "ward",
+}
+
+func (r *caseRepository) GetAreaStats(ctx context.Context, tier entity.AdminTier, area entity.AdminArea) ([]entity.CaseAreaStats, error) {
+	column, ok := areaColumns[tier]
+	if !ok {
+		return nil, errors.New("unknown administrative tier")
+	}
+
+	var stats []entity.CaseAreaStats
+	db := withContext(ctx, r.db).Model(&entity.Case{})
+	err := whereArea(db, area).
+		Select("COALESCE("+column+", '') AS area, "+
+			"COUNT(*) AS total, "+
+			"COUNT(*) FILTER (WHERE status IN ?) AS active, "+
+			"COUNT(*) FILTER (WHERE status = ?) AS resolved",
+			[]enum.CaseStatus{enum.CaseStatusPending, enum.CaseStatusAccepted, enum.CaseStatusInProgress},
+			enum.CaseStatusResolved,
+		).
+		Group("1").
+		Order("total DESC, area").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (r *caseRepository) GetInBounds(ctx context.Context, bbox entity.BoundingBox, types []enum.CaseType, eventID *uuid.UUID, limit int) ([]entity.Case, int64, error) {
+	var cases []entity.Case
+	var total int64
+
+	db := whereActiveInBounds(withContext(ctx, r.db).Model(&entity.Case{}), bbox, types, eventID)
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := db.
+		Select("id, case_type, title, urgency, status, volunteer_count, created_at, latitude, longitude").
+		Order("CASE urgency WHEN 'critical' THEN 0 WHEN 'high' THEN 1 WHEN 'medium' THEN 2 ELSE 3 END, created_at DESC").
+		Limit(limit).
+		Find(&cases).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return cases, total, nil
+}
+
+// caseClusterRow is one type and urgency within a grid cell
+type caseClusterRow struct {
+	CellY    int64
+	CellX    int64
+	CaseType enum.CaseType
+	Urgency  enum.UrgencyLevel
+	Count    int64
+	SumLat   float64
+	SumLng   float64
+	MinLat   float64
+	MaxLat   float64
+	MinLng   float64
+	MaxLng   float64
+	CaseID   string
+}
+
+func (r *caseRepository) GetClustersInBounds(ctx context.Context, bbox entity.BoundingBox, types []enum.CaseType, eventID *uuid.UUID, cellDeg float64) ([]entity.CaseCluster, error) {
+	if cellDeg <= 0 {
+		return nil, errors.New("cluster cell size must be positive")
+	}
+
+	// Cells are counted from 0,0 so they stay put as the viewport pans.
+	// Grouping by type and urgency too gives the breakdowns in one pass.
+	var rows []caseClusterRow
+	db := whereActiveInBounds(withContext(ctx, r.db).Model(&entity.Case{}), bbox, types, eventID)
+	err := db.
+		Select("FLOOR(latitude / ?)::bigint AS cell_y, "+
+			"FLOOR(longitude / ?)::bigint AS cell_x, "+
+			"case_type, urgency, "+
+			"COUNT(*) AS count, "+
+			"SUM(latitude) AS sum_lat, SUM(longitude) AS sum_lng, "+
+			"MIN(latitude) AS min_lat, MAX(latitude) AS max_lat, "+
+			"MIN(longitude) AS min_lng, MAX(longitude) AS max_lng, "+
+			"MIN(id::text) AS case_id",
+			cellDeg, cellDeg,
+		).
+		Group("1, 2, 3, 4").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	type cell struct{ y, x int64 }
+	index := make(map[cell]int)
+	var clusters []entity.CaseCluster
+	var sumLat, sumLng []float64
+	for _, row := range rows {
+		key := cell{row.CellY, row.CellX}
+		i, ok := index[key]
+		if !ok {
+			i = len(clusters)
+			index[key] = i
+			clusters = append(clusters, entity.CaseCluster{
+				Bounds:    entity.BoundingBox{MinLat: row.MinLat, MaxLat: row.MaxLat, MinLng: row.MinLng, MaxLng: row.MaxLng},
+				ByType:    make(map[enum.CaseType]int64),
+				ByUrgency: make(map[enum.UrgencyLevel]int64),
+			})
+			sumLat = append(sumLat, 0)
+			sumLng = append(sumLng, 0)
+		}
+
+		c := &clusters[i]
+		c.Count += row.Count
+		c.ByType[row.CaseType] += row.Count
+		c.ByUrgency[row.Urgency] += row.Count
+		c.Bounds.MinLat = math.Min(c.Bounds.MinLat, row.MinLat)
+		c.Bounds.MaxLat = math.Max(c.Bounds.MaxLat, row.MaxLat)
+		c.Bounds.MinLng = math.Min(c.Bounds.MinLng, row.MinLng)
+		c.Bounds.MaxLng = math.Max(c.Bounds.MaxLng, row.MaxLng)
+		sumLat[i] += row.SumLat
+		sumLng[i] += row.SumLng
+		if row.Count == 1 {
+			if id, err := uuid.Parse(row.CaseID); err == nil {
+				c.CaseID = &id
+			}
+		}
+	}
+
+	for i := range clusters {
+		c := &clusters[i]
+		c.Latitude = sumLat[i] / float64(c.Count)
+		c.Longitude = sumLng[i] / float64(c.Count)
+		if c.Count > 1 {
+			c.CaseID = nil
+		}
+	}
+
+	// Biggest first, so clients short on space can draw those
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].Count > clusters[j].Count
+	})
+	return clusters, nil
+}
+
+func (r *caseRepository) StreamCases(ctx context.Context, filter CaseExportFilter, fn func(*entity.Case) error) error {
+	// Pages are keyed on the last case seen rather than offset, so each
+	// batch is an index seek however deep the export is
+	var last *entity.Case
+	for {
+		db := withContext(ctx, r.db).Model(&entity.Case{})
+		if filter.Query != "" {
+			searchPattern := "%" + filter.Query + "%"
+			db = db.Where("title ILIKE ? OR address ILIKE ?", searchPattern, searchPattern)
+		}
+		if filter.Type != nil {
+			db = db.Where("case_type = ?", *filter.Type)
+		}
+		if filter.Status != nil {
+			db = db.Where("status = ?", *filter.Status)
+		}
+		if filter.Urgency != nil {
+			db = db.Where("urgency = ?", *filter.Urgency)
+		}
+		db = whereArea(db, filter.Area)
+		if filter.From != nil {
+			db = db.Where("created_at >= ?", *filter.From)
+		}
+		if filter.To != nil {
+			db = db.Where("created_at < ?", *filter.To)
+		}
+		if filter.BBox != nil {
+			db = whereBounds(db, *filter.BBox)
+		}
+		if filter.EventID != nil {
+			db = db.Where("event_id = ?", *filter.EventID)
+		}
+		if last != nil {
+			db = db.Where("(created_at, id) > (?, ?)", last.CreatedAt, last.ID)
+		}
+
+		var batch []entity.Case
+		err := db.
+			Preload("AnimalDetails").
+			Preload("FloodDetails").
+			Preload("AccidentDetails").
+			Order("created_at, id").
+			Limit(exportBatchSize).
+			Find(&batch).Error
+		if err != nil {
+			return err
+		}
+
+		for i := range batch {
+			if err := fn(&batch[i]); err != nil {
+				return err
+			}
+		}
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+		last = &batch[len(batch)-1]
+	}
+}
+
+func (r *caseRepository) FindUnassignedInBounds(ctx context.Context, bbox entity.BoundingBox, from time.Time, to *time.Time, afterID uuid.UUID, limit int) ([]entity.Case, error) {
+	db := whereBounds(withContext(ctx, r.db).Model(&entity.Case{}), bbox).
+		Where("event_id IS NULL").
+		Where("created_at >= ?", from).
+		Where("id > ?", afterID)
+	if to != nil {
+		db = db.Where("created_at < ?", *to)
+	}
+
+	var cases []entity.Case
+	err := db.
+		Select("id, latitude, longitude").
+		Order("id").
+		Limit(limit).
+		Find(&cases).Error
+	return cases, err
+}
+
+func (r *caseRepository) SetEvent(ctx context.Context, caseIDs []uuid.UUID, eventID *uuid.UUID, maxVolunteers *int) error {
+	if len(caseIDs) == 0 {
+		return nil
+	}
+	updates := map[string]interface{}{
+		"event_id":   eventID,
+		"updated_at": time.Now(),
+	}
+	if maxVolunteers != nil {
+		updates["max_volunteers"] = gorm.Expr("GREATEST(?, volunteer_count)", *maxVolunteers)
+	}
+	return withContext(ctx, r.db).
+		Model(&entity.Case{}).
+		Where("id IN ?", caseIDs).
+		Updates(updates).Error
+}
+
+func (r *caseRepository) SetEventMaxVolunteers(ctx context.Context, eventID uuid.UUID, maxVolunteers int) error {
+	return withContext(ctx, r.db).
+		Model(&entity.Case{}).
+		Where("event_id = ?", eventID).
+		Where("status IN ?", []enum.CaseStatus{enum.CaseStatusPending, enum.CaseStatusAccepted, enum.CaseStatusInProgress}).
+		Updates(map[string]interface{}{
+			"max_volunteers": gorm.Expr("GREATEST(?, volunteer_count)", maxVolunteers),
+			"updated_at":     time.Now(),
+		}).Error
+}
+
+// caseCountRow is the count of an event's cases of one status, type and
+// urgency
+type caseCountRow struct {
+	Status   enum.CaseStatus
+	CaseType enum.CaseType
+	Urgency  enum.UrgencyLevel
+	Count    int64
+}
+
+func (r *caseRepository) GetEventStats(ctx context.Context, eventID uuid.UUID) (*entity.DisasterEventStats, error) {
+	var rows []caseCountRow
+	err := withContext(ctx, r.db).
+		Model(&entity.Case{}).
+		Select("status, case_type, urgency, COUNT(*) AS count").
+		Where("event_id = ?", eventID).
+		Group("1, 2, 3").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &entity.DisasterEventStats{
+		ByStatus:  make(map[enum.CaseStatus]int64),
+		ByType:    make(map[enum.CaseType]int64),
+		ByUrgency: make(map[enum.UrgencyLevel]int64),
+	}
+	for _, row := range rows {
+		stats.Total += row.Count
+		if row.Status.IsActive() {
+			stats.Active += row.Count
+		} else if row.Status == enum.CaseStatusResolved {
+			stats.Resolved += row.Count
+		}
+		stats.ByStatus[row.Status] += row.Count
+		stats.ByType[row.CaseType] += row.Count
+		stats.ByUrgency[row.Urgency] += row.Count
+	}
+
+	err = withContext(ctx, r.db).
+		Model(&entity.CaseVolunteer{}).
+		Joins("JOIN cases ON cases.id = case_volunteers.case_id").
+		Where("cases.event_id = ?", eventID).
+		Where("case_volunteers.status <> ?", enum.VolunteerStatusWithdrawn).
+		Distinct("case_volunteers.volunteer_id").
+		Count(&stats.Volunteers).Error
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// whereActiveInBounds restricts a cases query to active cases of types in
+// bbox, and to one event's cases for a non-nil eventID
+func whereActiveInBounds(db *gorm.DB, bbox entity.BoundingBox, types []enum.CaseType, eventID *uuid.UUID) *gorm.DB {
+	db = whereBounds(db.Where("status IN ?", []enum.CaseStatus{enum.CaseStatusPending, enum.CaseStatusAccepted, enum.CaseStatusInProgress}), bbox)
+	if len(types) > 0 {
+		db = db.Where("case_type IN ?", types)
+	}
+	if eventID != nil {
+		db = db.Where("event_id = ?", *eventID)
+	}
+	return db
+}
+
+// whereBounds restricts a cases query to bbox. A box whose west edge is
+// east of its east edge crosses the antimeridian.
+func whereBounds(db *gorm.DB, bbox entity.BoundingBox) *gorm.DB {
+	db = db.Where("latitude BETWEEN ? AND ?", bbox.MinLat, bbox.MaxLat)
+	if bbox.MinLng <= bbox.MaxLng {
+		return db.Where("longitude BETWEEN ? AND ?", bbox.MinLng, bbox.MaxLng)
+	}
+	return db.Where("(longitude >= ? OR longitude <= ?)", bbox.MinLng, bbox.MaxLng)
+}
+
+// whereArea restricts a cases query to the non-empty units of area,
+// ignoring case
+func whereArea(db *gorm.DB, area entity.AdminArea) *gorm.DB {
+	if area.Province != "" {
+		db = db.Where("LOWER(province) = LOWER(?)", area.Province)
+	}
+	if area.District != "" {
+		db = db.Where("LOWER(district) = LOWER(?)", area.District)
+	}
+	if area.Ward != "" {
+		db = db.Where("LOWER(ward) = LOWER(?)", area.Ward)
+	}
+	return db
+}
+
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
 func stringPtr(s string) *string {
 	return &s
 }
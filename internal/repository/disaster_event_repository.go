@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/domain/enum"
+	"gorm.io/gorm"
+)
+
+// DisasterEventRepository defines the interface for disaster event data access
+type DisasterEventRepository interface {
+	// Create saves an event together with its coordinators
+	Create(ctx context.Context, event *entity.DisasterEvent) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.DisasterEvent, error)
+	// Update saves an event's own fields; coordinators are set with
+	// SetCoordinators
+	Update(ctx context.Context, event *entity.DisasterEvent) error
+	// SetCoordinators replaces the coordinators of an event
+	SetCoordinators(ctx context.Context, eventID uuid.UUID, userIDs []uuid.UUID) error
+	// List returns events with the given status, or all for a nil status,
+	// newest first
+	List(ctx context.Context, status *enum.EventStatus, limit, offset int) ([]entity.DisasterEvent, int64, error)
+	// GetActiveContaining returns the active events whose area holds a point
+	// and whose time span holds t, latest started first
+	GetActiveContaining(ctx context.Context, lat, lng float64, t time.Time) ([]entity.DisasterEvent, error)
+}
+
+type disasterEventRepository struct {
+	db *gorm.DB
+}
+
+// NewDisasterEventRepository creates a new DisasterEventRepository
+func NewDisasterEventRepository(db interface{}) DisasterEventRepository {
+	return &disasterEventRepository{db: db.(*gorm.DB)}
+}
+
+func (r *disasterEventRepository) Create(ctx context.Context, event *entity.DisasterEvent) error {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	for i := range event.Coordinators {
+		event.Coordinators[i].EventID = event.ID
+	}
+	return withContext(ctx, r.db).Create(event).Error
+}
+
+func (r *disasterEventRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.DisasterEvent, error) {
+	var event entity.DisasterEvent
+	err := withContext(ctx, r.db).
+		Preload("Coordinators").
+		First(&event, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r *disasterEventRepository) Update(ctx context.Context, event *entity.DisasterEvent) error {
+	return withContext(ctx, r.db).Omit("Coordinators").Save(event).Error
+}
+
+func (r *disasterEventRepository) SetCoordinators(ctx context.Context, eventID uuid.UUID, userIDs []uuid.UUID) error {
+	db := withContext(ctx, r.db)
+	if err := db.Where("event_id = ?", eventID).Delete(&entity.DisasterEventCoordinator{}).Error; err != nil {
+		return err
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+	coordinators := make([]entity.DisasterEventCoordinator, len(userIDs))
+	for i, id := range userIDs {
+		coordinators[i] = entity.DisasterEventCoordinator{EventID: eventID, UserID: id}
+	}
+	return db.Create(&coordinators).Error
+}
+
+func (r *disasterEventRepository) List(ctx context.Context, status *enum.EventStatus, limit, offset int) ([]entity.DisasterEvent, int64, error) {
+	var events []entity.DisasterEvent
+	var total int64
+
+	db := withContext(ctx, r.db).Model(&entity.DisasterEvent{})
+	if status != nil {
+		db = db.Where("status = ?", *status)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := db.
+		Preload("Coordinators").
+		Order("starts_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&events).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+func (r *disasterEventRepository) GetActiveContaining(ctx context.Context, lat, lng float64, t time.Time) ([]entity.DisasterEvent, error) {
+	// Bounding box filter first, then the exact polygon test
+	var candidates []entity.DisasterEvent
+	err := withContext(ctx, r.db).
+		Where("status = ?", enum.EventStatusActive).
+		Where("starts_at <= ? AND (ends_at IS NULL OR ends_at > ?)", t, t).
+		Where("? BETWEEN min_lat AND max_lat", lat).
+		Where("? BETWEEN min_lng AND max_lng", lng).
+		Order("starts_at DESC").
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	events := candidates[:0]
+	for _, e := range candidates {
+		if e.Area.Contains(lat, lng) {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"bamboo-rescue/internal/domain/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GeocodeCacheRepository defines the interface for geocode cache data access
+type GeocodeCacheRepository interface {
+	// Get returns the entry for key, fresh or not
+	Get(ctx context.Context, key string) (*entity.GeocodeCacheEntry, error)
+	// Put creates or replaces the entry for its key
+	Put(ctx context.Context, entry *entity.GeocodeCacheEntry) error
+}
+
+type geocodeCacheRepository struct {
+	db *gorm.DB
+}
+
+// NewGeocodeCacheRepository creates a new GeocodeCacheRepository
+func NewGeocodeCacheRepository(db interface{}) GeocodeCacheRepository {
+	return &geocodeCacheRepository{db: db.(*gorm.DB)}
+}
+
+func (r *geocodeCacheRepository) Get(ctx context.Context, key string) (*entity.GeocodeCacheEntry, error) {
+	var entry entity.GeocodeCacheEntry
+	err := withContext(ctx, r.db).
+		First(&entry, "key = ?", key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *geocodeCacheRepository) Put(ctx context.Context, entry *entity.GeocodeCacheEntry) error {
+	return withContext(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "key"}},
+			DoUpdates: clause.AssignmentColumns([]string{"result", "expires_at", "updated_at"}),
+		}).
+		Create(entry).Error
+}
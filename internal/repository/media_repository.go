@@ -29,19 +29,19 @@ func NewMediaRepository(db interface{}) MediaRepository {
 }
 
 func (r *mediaRepository) Create(ctx context.Context, media *entity.CaseMedia) error {
-	return r.db.WithContext(ctx).Create(media).Error
+	return withContext(ctx, r.db).Create(media).Error
 }
 
 func (r *mediaRepository) CreateBatch(ctx context.Context, media []entity.CaseMedia) error {
 	if len(media) == 0 {
 		return nil
 	}
-	return r.db.WithContext(ctx).Create(&media).Error
+	return withContext(ctx, r.db).Create(&media).Error
 }
 
 func (r *mediaRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.CaseMedia, error) {
 	var media entity.CaseMedia
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		First(&media, "id = ?", id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -54,7 +54,7 @@ func (r *mediaRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Ca
 
 func (r *mediaRepository) GetByCaseID(ctx context.Context, caseID uuid.UUID) ([]entity.CaseMedia, error) {
 	var media []entity.CaseMedia
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		Where("case_id = ?", caseID).
 		Order("created_at ASC").
 		Find(&media).Error
@@ -62,11 +62,11 @@ func (r *mediaRepository) GetByCaseID(ctx context.Context, caseID uuid.UUID) ([]
 }
 
 func (r *mediaRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return withContext(ctx, r.db).
 		Delete(&entity.CaseMedia{}, "id = ?", id).Error
 }
 
 func (r *mediaRepository) DeleteByCaseID(ctx context.Context, caseID uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return withContext(ctx, r.db).
 		Delete(&entity.CaseMedia{}, "case_id = ?", caseID).Error
 }
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/domain/entity"
+	"gorm.io/gorm"
+)
+
+// MediaUploadRepository defines the interface for resumable upload data access
+type MediaUploadRepository interface {
+	Create(ctx context.Context, upload *entity.MediaUpload) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.MediaUpload, error)
+	Update(ctx context.Context, upload *entity.MediaUpload) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetExpired(ctx context.Context, before time.Time, limit int) ([]entity.MediaUpload, error)
+
+	// Parts
+	AddPart(ctx context.Context, part *entity.MediaUploadPart) error
+	GetParts(ctx context.Context, uploadID uuid.UUID) ([]entity.MediaUploadPart, error)
+}
+
+type mediaUploadRepository struct {
+	db *gorm.DB
+}
+
+// NewMediaUploadRepository creates a new MediaUploadRepository
+func NewMediaUploadRepository(db interface{}) MediaUploadRepository {
+	return &mediaUploadRepository{db: db.(*gorm.DB)}
+}
+
+func (r *mediaUploadRepository) Create(ctx context.Context, upload *entity.MediaUpload) error {
+	return withContext(ctx, r.db).Create(upload).Error
+}
+
+func (r *mediaUploadRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.MediaUpload, error) {
+	var upload entity.MediaUpload
+	err := withContext(ctx, r.db).
+		First(&upload, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &upload, nil
+}
+
+func (r *mediaUploadRepository) Update(ctx context.Context, upload *entity.MediaUpload) error {
+	return withContext(ctx, r.db).Save(upload).Error
+}
+
+func (r *mediaUploadRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return withContext(ctx, r.db).Delete(&entity.MediaUpload{}, "id = ?", id).Error
+}
+
+func (r *mediaUploadRepository) GetExpired(ctx context.Context, before time.Time, limit int) ([]entity.MediaUpload, error) {
+	var uploads []entity.MediaUpload
+	err := withContext(ctx, r.db).
+		Where("completed_at IS NULL AND expires_at < ?", before).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&uploads).Error
+	return uploads, err
+}
+
+func (r *mediaUploadRepository) AddPart(ctx context.Context, part *entity.MediaUploadPart) error {
+	return withContext(ctx, r.db).Create(part).Error
+}
+
+func (r *mediaUploadRepository) GetParts(ctx context.Context, uploadID uuid.UUID) ([]entity.MediaUploadPart, error) {
+	var parts []entity.MediaUploadPart
+	err := withContext(ctx, r.db).
+		Where("upload_id = ?", uploadID).
+		Order("part_number ASC").
+		Find(&parts).Error
+	return parts, err
+}
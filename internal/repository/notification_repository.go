@@ -3,10 +3,13 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/domain/enum"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // NotificationRepository defines the interface for notification data access
@@ -14,12 +17,35 @@ type NotificationRepository interface {
 	Create(ctx context.Context, notification *entity.Notification) error
 	CreateBatch(ctx context.Context, notifications []entity.Notification) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Notification, error)
-	GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Notification, int64, error)
+	// GetByUserID returns up to limit of the user's notifications matching
+	// filter, newest first
+	GetByUserID(ctx context.Context, userID uuid.UUID, filter NotificationFilter, limit int) ([]entity.Notification, error)
 	GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, error)
-	MarkAsRead(ctx context.Context, id uuid.UUID) error
 	MarkAllAsRead(ctx context.Context, userID uuid.UUID) error
 	MarkAsPushed(ctx context.Context, id uuid.UUID) error
-	Delete(ctx context.Context, id uuid.UUID) error
+
+	// Bulk changes only touch the listed notifications the user owns and
+	// return how many that was
+	MarkAsRead(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error)
+	Archive(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error)
+	Delete(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error)
+
+	// Push throttling
+	// LockPushThrottle returns the user's throttle row locked for update,
+	// creating it with a window starting at now. Must run in a transaction.
+	LockPushThrottle(ctx context.Context, userID uuid.UUID, now time.Time) (*entity.PushThrottle, error)
+	SavePushThrottle(ctx context.Context, throttle *entity.PushThrottle) error
+}
+
+// NotificationFilter narrows a notification list; zero fields match anything
+type NotificationFilter struct {
+	Type   *enum.NotificationType
+	CaseID *uuid.UUID
+	IsRead *bool
+	// Archived lists archived notifications instead of the inbox
+	Archived bool
+	// After continues the list past the end of a previous page
+	After *entity.NotificationCursor
 }
 
 type notificationRepository struct {
@@ -32,19 +58,19 @@ func NewNotificationRepository(db interface{}) NotificationRepository {
 }
 
 func (r *notificationRepository) Create(ctx context.Context, notification *entity.Notification) error {
-	return r.db.WithContext(ctx).Create(notification).Error
+	return withContext(ctx, r.db).Create(notification).Error
 }
 
 func (r *notificationRepository) CreateBatch(ctx context.Context, notifications []entity.Notification) error {
 	if len(notifications) == 0 {
 		return nil
 	}
-	return r.db.WithContext(ctx).Create(&notifications).Error
+	return withContext(ctx, r.db).Create(&notifications).Error
 }
 
 func (r *notificationRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Notification, error) {
 	var notification entity.Notification
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		First(&notification, "id = ?", id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -55,55 +81,55 @@ func (r *notificationRepository) GetByID(ctx context.Context, id uuid.UUID) (*en
 	return &notification, nil
 }
 
-func (r *notificationRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Notification, int64, error) {
+func (r *notificationRepository) GetByUserID(ctx context.Context, userID uuid.UUID, filter NotificationFilter, limit int) ([]entity.Notification, error) {
 	var notifications []entity.Notification
-	var total int64
 
-	// Count total
-	err := r.db.WithContext(ctx).
-		Model(&entity.Notification{}).
-		Where("user_id = ?", userID).
-		Count(&total).Error
-	if err != nil {
-		return nil, 0, err
+	db := withContext(ctx, r.db).Where("user_id = ?", userID)
+
+	if filter.Archived {
+		db = db.Where("archived_at IS NOT NULL")
+	} else {
+		db = db.Where("archived_at IS NULL")
+	}
+	if filter.Type != nil {
+		db = db.Where("notification_type = ?", *filter.Type)
+	}
+	if filter.CaseID != nil {
+		db = db.Where("case_id = ?", *filter.CaseID)
+	}
+	if filter.IsRead != nil {
+		db = db.Where("is_read = ?", *filter.IsRead)
+	}
+	if filter.After != nil {
+		db = db.Where("(created_at, id) < (?, ?)", filter.After.CreatedAt, filter.After.ID)
 	}
 
-	// Get paginated results
-	err = r.db.WithContext(ctx).
-		Where("user_id = ?", userID).
-		Order("created_at DESC").
+	err := db.
+		Order("created_at DESC, id DESC").
 		Limit(limit).
-		Offset(offset).
 		Find(&notifications).Error
 
-	return notifications, total, err
+	return notifications, err
 }
 
 func (r *notificationRepository) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		Model(&entity.Notification{}).
 		Where("user_id = ? AND is_read = ?", userID, false).
 		Count(&count).Error
 	return count, err
 }
 
-func (r *notificationRepository) MarkAsRead(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).
-		Model(&entity.Notification{}).
-		Where("id = ?", id).
-		Update("is_read", true).Error
-}
-
 func (r *notificationRepository) MarkAllAsRead(ctx context.Context, userID uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return withContext(ctx, r.db).
 		Model(&entity.Notification{}).
 		Where("user_id = ? AND is_read = ?", userID, false).
 		Update("is_read", true).Error
 }
 
 func (r *notificationRepository) MarkAsPushed(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return withContext(ctx, r.db).
 		Model(&entity.Notification{}).
 		Where("id = ?", id).
 		Updates(map[string]interface{}{
@@ -112,7 +138,52 @@ func (r *notificationRepository) MarkAsPushed(ctx context.Context, id uuid.UUID)
 		}).Error
 }
 
-func (r *notificationRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).
-		Delete(&entity.Notification{}, "id = ?", id).Error
+func (r *notificationRepository) MarkAsRead(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error) {
+	result := withContext(ctx, r.db).
+		Model(&entity.Notification{}).
+		Where("user_id = ? AND id IN ? AND is_read = ?", userID, ids, false).
+		Update("is_read", true)
+	return result.RowsAffected, result.Error
+}
+
+// Archive also marks the notifications read, so they stop counting
+// towards the badge
+func (r *notificationRepository) Archive(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error) {
+	result := withContext(ctx, r.db).
+		Model(&entity.Notification{}).
+		Where("user_id = ? AND id IN ? AND archived_at IS NULL", userID, ids).
+		Updates(map[string]interface{}{
+			"is_read":     true,
+			"archived_at": gorm.Expr("NOW()"),
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *notificationRepository) Delete(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error) {
+	result := withContext(ctx, r.db).
+		Where("user_id = ? AND id IN ?", userID, ids).
+		Delete(&entity.Notification{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *notificationRepository) LockPushThrottle(ctx context.Context, userID uuid.UUID, now time.Time) (*entity.PushThrottle, error) {
+	db := withContext(ctx, r.db)
+
+	err := db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&entity.PushThrottle{UserID: userID, WindowStart: now}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var throttle entity.PushThrottle
+	err = db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&throttle, "user_id = ?", userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+func (r *notificationRepository) SavePushThrottle(ctx context.Context, throttle *entity.PushThrottle) error {
+	return withContext(ctx, r.db).Save(throttle).Error
 }
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/domain/enum"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxRepository defines the interface for outbox job data access
+type OutboxRepository interface {
+	Enqueue(ctx context.Context, job *entity.OutboxJob) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.OutboxJob, error)
+
+	// Claim locks up to limit due jobs for processing, including jobs whose
+	// worker has held them longer than lockTimeout
+	Claim(ctx context.Context, limit int, lockTimeout time.Duration) ([]entity.OutboxJob, error)
+	MarkDone(ctx context.Context, id uuid.UUID) error
+	MarkRetry(ctx context.Context, id uuid.UUID, lastError string, runAt time.Time) error
+	MarkDead(ctx context.Context, id uuid.UUID, lastError string) error
+	Requeue(ctx context.Context, id uuid.UUID) error
+	DeleteDoneBefore(ctx context.Context, before time.Time) (int64, error)
+
+	// Admin
+	GetStuck(ctx context.Context, status *enum.OutboxJobStatus, kind string, lockTimeout time.Duration, limit, offset int) ([]entity.OutboxJob, int64, error)
+	GetStats(ctx context.Context, lockTimeout time.Duration) (*entity.OutboxStats, error)
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository creates a new OutboxRepository
+func NewOutboxRepository(db interface{}) OutboxRepository {
+	return &outboxRepository{db: db.(*gorm.DB)}
+}
+
+func (r *outboxRepository) Enqueue(ctx context.Context, job *entity.OutboxJob) error {
+	if job.ID == uuid.Nil {
+		job.ID = uuid.New()
+	}
+	if job.Status == "" {
+		job.Status = enum.OutboxJobStatusPending
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = time.Now()
+	}
+	return withContext(ctx, r.db).Create(job).Error
+}
+
+func (r *outboxRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.OutboxJob, error) {
+	var job entity.OutboxJob
+	err := withContext(ctx, r.db).
+		First(&job, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *outboxRepository) Claim(ctx context.Context, limit int, lockTimeout time.Duration) ([]entity.OutboxJob, error) {
+	var jobs []entity.OutboxJob
+	now := time.Now()
+
+	err := withContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// SKIP LOCKED lets several API instances claim from the same table
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_at < ?)",
+				enum.OutboxJobStatusPending, now,
+				enum.OutboxJobStatusProcessing, now.Add(-lockTimeout)).
+			Order("run_at ASC").
+			Limit(limit).
+			Find(&jobs).Error
+		if err != nil || len(jobs) == 0 {
+			return err
+		}
+
+		ids := make([]uuid.UUID, len(jobs))
+		for i := range jobs {
+			ids[i] = jobs[i].ID
+			jobs[i].Status = enum.OutboxJobStatusProcessing
+			jobs[i].Attempts++
+			jobs[i].LockedAt = &now
+		}
+
+		return tx.Model(&entity.OutboxJob{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"status":    enum.OutboxJobStatusProcessing,
+				"attempts":  gorm.Expr("attempts + 1"),
+				"locked_at": now,
+			}).Error
+	})
+
+	return jobs, err
+}
+
+func (r *outboxRepository) MarkDone(ctx context.Context, id uuid.UUID) error {
+	return withContext(ctx, r.db).
+		Model(&entity.OutboxJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       enum.OutboxJobStatusDone,
+			"locked_at":    nil,
+			"completed_at": time.Now(),
+		}).Error
+}
+
+func (r *outboxRepository) MarkRetry(ctx context.Context, id uuid.UUID, lastError string, runAt time.Time) error {
+	return withContext(ctx, r.db).
+		Model(&entity.OutboxJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     enum.OutboxJobStatusPending,
+			"locked_at":  nil,
+			"last_error": lastError,
+			"run_at":     runAt,
+		}).Error
+}
+
+func (r *outboxRepository) MarkDead(ctx context.Context, id uuid.UUID, lastError string) error {
+	return withContext(ctx, r.db).
+		Model(&entity.OutboxJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     enum.OutboxJobStatusDead,
+			"locked_at":  nil,
+			"last_error": lastError,
+		}).Error
+}
+
+func (r *outboxRepository) Requeue(ctx context.Context, id uuid.UUID) error {
+	return withContext(ctx, r.db).
+		Model(&entity.OutboxJob{}).
+		Where("id = ? AND status <> ?", id, enum.OutboxJobStatusProcessing).
+		Updates(map[string]interface{}{
+			"status":       enum.OutboxJobStatusPending,
+			"attempts":     0,
+			"locked_at":    nil,
+			"completed_at": nil,
+			"run_at":       time.Now(),
+		}).Error
+}
+
+func (r *outboxRepository) DeleteDoneBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := withContext(ctx, r.db).
+		Where("status = ? AND completed_at < ?", enum.OutboxJobStatusDone, before).
+		Delete(&entity.OutboxJob{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *outboxRepository) GetStuck(ctx context.Context, status *enum.OutboxJobStatus, kind string, lockTimeout time.Duration, limit, offset int) ([]entity.OutboxJob, int64, error) {
+	var jobs []entity.OutboxJob
+	var total int64
+
+	db := withContext(ctx, r.db).Model(&entity.OutboxJob{})
+
+	if status != nil {
+		db = db.Where("status = ?", *status)
+	} else {
+		// Stuck: dead, failing and waiting to retry, or held by a worker too long
+		db = db.Where("status = ? OR (status = ? AND attempts > 0) OR (status = ? AND locked_at < ?)",
+			enum.OutboxJobStatusDead,
+			enum.OutboxJobStatusPending,
+			enum.OutboxJobStatusProcessing, time.Now().Add(-lockTimeout))
+	}
+
+	if kind != "" {
+		db = db.Where("kind = ?", kind)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := db.Order("created_at ASC").Limit(limit).Offset(offset).Find(&jobs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return jobs, total, nil
+}
+
+func (r *outboxRepository) GetStats(ctx context.Context, lockTimeout time.Duration) (*entity.OutboxStats, error) {
+	var stats entity.OutboxStats
+	err := withContext(ctx, r.db).
+		Model(&entity.OutboxJob{}).
+		Select(`COUNT(*) FILTER (WHERE status = ? AND attempts = 0) AS pending,
+			COUNT(*) FILTER (WHERE status = ? AND attempts > 0) AS retrying,
+			COUNT(*) FILTER (WHERE status = ?) AS processing,
+			COUNT(*) FILTER (WHERE status = ? AND locked_at < ?) AS stale,
+			COUNT(*) FILTER (WHERE status = ?) AS dead,
+			MIN(run_at) FILTER (WHERE status = ?) AS oldest_due`,
+			enum.OutboxJobStatusPending,
+			enum.OutboxJobStatusPending,
+			enum.OutboxJobStatusProcessing,
+			enum.OutboxJobStatusProcessing, time.Now().Add(-lockTimeout),
+			enum.OutboxJobStatusDead,
+			enum.OutboxJobStatusPending).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
@@ -28,10 +28,19 @@ type UserRepository interface {
 	CreatePreferences(ctx context.Context, prefs *entity.UserPreferences) error
 	UpdatePreferences(ctx context.Context, prefs *entity.UserPreferences) error
 
+	// Email digest
+	// FindDigestRecipients returns users with an email who want the digest
+	// and have not had one queued since dueBefore
+	FindDigestRecipients(ctx context.Context, dueBefore time.Time, limit int) ([]entity.User, error)
+	// MarkDigestQueued records a digest at time at, reporting false if one
+	// was already queued since dueBefore
+	MarkDigestQueued(ctx context.Context, userID uuid.UUID, dueBefore, at time.Time) (bool, error)
+
 	// Push Tokens
 	GetPushTokens(ctx context.Context, userID uuid.UUID) ([]entity.PushToken, error)
 	CreatePushToken(ctx context.Context, token *entity.PushToken) error
 	DeletePushToken(ctx context.Context, token string) error
+	DeactivatePushTokens(ctx context.Context, tokens []string, reason string) error
 	DeletePushTokensByUser(ctx context.Context, userID uuid.UUID) error
 
 	// Stats
@@ -40,12 +49,22 @@ type UserRepository interface {
 	IncrementCasesResolved(ctx context.Context, userID uuid.UUID) error
 
 	// Volunteers
-	FindAvailableVolunteers(ctx context.Context, lat, lng float64, radiusKm int, caseType string, limit int) ([]VolunteerWithDistance, error)
+	// FindAvailableVolunteers returns the volunteers who want to hear of a
+	// case at lat, lng, nearest first. Each is reached within their own
+	// notification radius, and no further than radiusKm; overrideRadius
+	// reaches everyone within radiusKm instead.
+	FindAvailableVolunteers(ctx context.Context, lat, lng float64, radiusKm int, overrideRadius bool, caseType string, limit int) ([]VolunteerWithDistance, error)
+	// FindInBounds returns active users whose last known location lies in
+	// bbox, ordered by ID and starting after afterID, to page through them
+	FindInBounds(ctx context.Context, bbox *entity.BoundingBox, afterID uuid.UUID, limit int) ([]entity.User, error)
 }
 
 // VolunteerWithDistance represents a volunteer with their distance from a location
 type VolunteerWithDistance struct {
-	User       *entity.User
+	User *entity.User
+	// Location is where the distance is measured from: the volunteer's
+	// chosen center or their current position
+	Location   entity.GeoPoint
 	DistanceKm float64
 	PushTokens []entity.PushToken
 }
@@ -63,12 +82,12 @@ func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
 	if user.ID == uuid.Nil {
 		user.ID = uuid.New()
 	}
-	return r.db.WithContext(ctx).Create(user).Error
+	return withContext(ctx, r.db).Create(user).Error
 }
 
 func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
 	var user entity.User
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		Preload("Preferences").
 		First(&user, "id = ?", id).Error
 	if err != nil {
@@ -82,7 +101,7 @@ func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Use
 
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
 	var user entity.User
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		First(&user, "email = ?", email).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -95,7 +114,7 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.
 
 func (r *userRepository) GetByPhone(ctx context.Context, phone string) (*entity.User, error) {
 	var user entity.User
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		First(&user, "phone = ?", phone).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -108,7 +127,7 @@ func (r *userRepository) GetByPhone(ctx context.Context, phone string) (*entity.
 
 func (r *userRepository) GetByOAuth(ctx context.Context, provider, oauthID string) (*entity.User, error) {
 	var user entity.User
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		First(&user, "oauth_provider = ? AND oauth_id = ?", provider, oauthID).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -120,12 +139,12 @@ func (r *userRepository) GetByOAuth(ctx context.Context, provider, oauthID strin
 }
 
 func (r *userRepository) Update(ctx context.Context, user *entity.User) error {
-	return r.db.WithContext(ctx).Save(user).Error
+	return withContext(ctx, r.db).Save(user).Error
 }
 
 func (r *userRepository) UpdateLocation(ctx context.Context, userID uuid.UUID, lat, lng float64) error {
 	now := time.Now()
-	return r.db.WithContext(ctx).
+	return withContext(ctx, r.db).
 		Model(&entity.User{}).
 		Where("id = ?", userID).
 		Updates(map[string]interface{}{
@@ -136,14 +155,14 @@ func (r *userRepository) UpdateLocation(ctx context.Context, userID uuid.UUID, l
 }
 
 func (r *userRepository) UpdateAvailability(ctx context.Context, userID uuid.UUID, isAvailable bool) error {
-	return r.db.WithContext(ctx).
+	return withContext(ctx, r.db).
 		Model(&entity.User{}).
 		Where("id = ?", userID).
 		Update("is_available", isAvailable).Error
 }
 
 func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return withContext(ctx, r.db).
 		Model(&entity.User{}).
 		Where("id = ?", id).
 		Update("is_active", false).Error
@@ -151,7 +170,7 @@ func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
 
 func (r *userRepository) GetPreferences(ctx context.Context, userID uuid.UUID) (*entity.UserPreferences, error) {
 	var prefs entity.UserPreferences
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		First(&prefs, "user_id = ?", userID).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -166,16 +185,38 @@ func (r *userRepository) CreatePreferences(ctx context.Context, prefs *entity.Us
 	if prefs.ID == uuid.Nil {
 		prefs.ID = uuid.New()
 	}
-	return r.db.WithContext(ctx).Create(prefs).Error
+	return withContext(ctx, r.db).Create(prefs).Error
 }
 
 func (r *userRepository) UpdatePreferences(ctx context.Context, prefs *entity.UserPreferences) error {
-	return r.db.WithContext(ctx).Save(prefs).Error
+	return withContext(ctx, r.db).Save(prefs).Error
+}
+
+func (r *userRepository) FindDigestRecipients(ctx context.Context, dueBefore time.Time, limit int) ([]entity.User, error) {
+	var users []entity.User
+	err := withContext(ctx, r.db).
+		Preload("Preferences").
+		Joins("JOIN user_preferences up ON up.user_id = users.id").
+		Where("users.is_active = true AND users.email IS NOT NULL AND users.email <> ''").
+		Where("up.email_enabled = true AND up.email_digest_enabled = true").
+		Where("up.last_digest_at IS NULL OR up.last_digest_at < ?", dueBefore).
+		Order("users.id").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) MarkDigestQueued(ctx context.Context, userID uuid.UUID, dueBefore, at time.Time) (bool, error) {
+	result := withContext(ctx, r.db).
+		Model(&entity.UserPreferences{}).
+		Where("user_id = ? AND (last_digest_at IS NULL OR last_digest_at < ?)", userID, dueBefore).
+		Update("last_digest_at", at)
+	return result.RowsAffected > 0, result.Error
 }
 
 func (r *userRepository) GetPushTokens(ctx context.Context, userID uuid.UUID) ([]entity.PushToken, error) {
 	var tokens []entity.PushToken
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		Where("user_id = ? AND is_active = ?", userID, true).
 		Find(&tokens).Error
 	return tokens, err
@@ -186,33 +227,52 @@ func (r *userRepository) CreatePushToken(ctx context.Context, token *entity.Push
 		token.ID = uuid.New()
 	}
 	// Upsert: if token exists, update it
-	return r.db.WithContext(ctx).
+	return withContext(ctx, r.db).
 		Where("token = ?", token.Token).
 		Assign(map[string]interface{}{
-			"user_id":      token.UserID,
-			"platform":     token.Platform,
-			"device_id":    token.DeviceID,
-			"is_active":    true,
-			"last_used_at": time.Now(),
+			"user_id":             token.UserID,
+			"platform":            token.Platform,
+			"device_id":           token.DeviceID,
+			"p256dh":              token.P256dh,
+			"auth":                token.Auth,
+			"expires_at":          token.ExpiresAt,
+			"is_active":           true,
+			"deactivated_at":      nil,
+			"deactivation_reason": nil,
+			"last_used_at":        time.Now(),
 		}).
 		FirstOrCreate(token).Error
 }
 
 func (r *userRepository) DeletePushToken(ctx context.Context, token string) error {
-	return r.db.WithContext(ctx).
+	return withContext(ctx, r.db).
 		Where("token = ?", token).
 		Delete(&entity.PushToken{}).Error
 }
 
+func (r *userRepository) DeactivatePushTokens(ctx context.Context, tokens []string, reason string) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	return withContext(ctx, r.db).
+		Model(&entity.PushToken{}).
+		Where("token IN ? AND is_active = ?", tokens, true).
+		Updates(map[string]interface{}{
+			"is_active":           false,
+			"deactivated_at":      time.Now(),
+			"deactivation_reason": reason,
+		}).Error
+}
+
 func (r *userRepository) DeletePushTokensByUser(ctx context.Context, userID uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return withContext(ctx, r.db).
 		Where("user_id = ?", userID).
 		Delete(&entity.PushToken{}).Error
 }
 
 func (r *userRepository) GetStats(ctx context.Context, userID uuid.UUID) (*entity.UserStats, error) {
 	var user entity.User
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		Select("total_cases_reported", "total_cases_resolved").
 		First(&user, "id = ?", userID).Error
 	if err != nil {
@@ -221,12 +281,12 @@ func (r *userRepository) GetStats(ctx context.Context, userID uuid.UUID) (*entit
 
 	// Count cases by status for this volunteer
 	var accepted, inProgress int64
-	r.db.WithContext(ctx).
+	withContext(ctx, r.db).
 		Model(&entity.CaseVolunteer{}).
 		Where("volunteer_id = ?", userID).
 		Count(&accepted)
 
-	r.db.WithContext(ctx).
+	withContext(ctx, r.db).
 		Model(&entity.CaseVolunteer{}).
 		Where("volunteer_id = ? AND status IN ?", userID, []string{"accepted", "en_route", "on_site", "handling"}).
 		Count(&inProgress)
@@ -240,27 +300,26 @@ func (r *userRepository) GetStats(ctx context.Context, userID uuid.UUID) (*entit
 }
 
 func (r *userRepository) IncrementCasesReported(ctx context.Context, userID uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return withContext(ctx, r.db).
 		Model(&entity.User{}).
 		Where("id = ?", userID).
 		UpdateColumn("total_cases_reported", gorm.Expr("total_cases_reported + 1")).Error
 }
 
 func (r *userRepository) IncrementCasesResolved(ctx context.Context, userID uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return withContext(ctx, r.db).
 		Model(&entity.User{}).
 		Where("id = ?", userID).
 		UpdateColumn("total_cases_resolved", gorm.Expr("total_cases_resolved + 1")).Error
 }
 
-func (r *userRepository) FindAvailableVolunteers(ctx context.Context, lat, lng float64, radiusKm int, caseType string, limit int) ([]VolunteerWithDistance, error) {
+func (r *userRepository) FindAvailableVolunteers(ctx context.Context, lat, lng float64, radiusKm int, overrideRadius bool, caseType string, limit int) ([]VolunteerWithDistance, error) {
 	// Create bounding box for initial filtering
 	bbox := entity.NewBoundingBox(lat, lng, float64(radiusKm))
 
 	// Get available users with their preferences
 	type UserWithPrefs struct {
 		entity.User
-		PushEnabled          *bool    `gorm:"column:push_enabled"`
 		CaseTypes            []string `gorm:"column:case_types;type:text[]"`
 		NotificationRadiusKm *int     `gorm:"column:notification_radius_km"`
 		CenterLatitude       *float64 `gorm:"column:center_latitude"`
@@ -272,10 +331,9 @@ func (r *userRepository) FindAvailableVolunteers(ctx context.Context, lat, lng f
 
 	var usersWithPrefs []UserWithPrefs
 
-	query := r.db.WithContext(ctx).
+	query := withContext(ctx, r.db).
 		Table("users u").
 		Select(`u.*,
-			up.push_enabled,
 			up.case_types,
 			up.notification_radius_km,
 			up.center_latitude,
@@ -286,7 +344,6 @@ func (r *userRepository) FindAvailableVolunteers(ctx context.Context, lat, lng f
 		Joins("LEFT JOIN user_preferences up ON up.user_id = u.id").
 		Where("u.is_available = true").
 		Where("u.is_active = true").
-		Where("(up.push_enabled IS NULL OR up.push_enabled = true)").
 		Where("u.latitude IS NOT NULL AND u.longitude IS NOT NULL").
 		Where("u.latitude BETWEEN ? AND ?", bbox.MinLat, bbox.MaxLat).
 		Where("u.longitude BETWEEN ? AND ?", bbox.MinLng, bbox.MaxLng)
@@ -323,6 +380,9 @@ func (r *userRepository) FindAvailableVolunteers(ctx context.Context, lat, lng f
 		if uwp.NotificationRadiusKm != nil {
 			userRadiusKm = *uwp.NotificationRadiusKm
 		}
+		if overrideRadius {
+			userRadiusKm = radiusKm
+		}
 
 		if distance > float64(userRadiusKm) {
 			continue
@@ -349,6 +409,7 @@ func (r *userRepository) FindAvailableVolunteers(ctx context.Context, lat, lng f
 
 		volunteers = append(volunteers, VolunteerWithDistance{
 			User:       &user,
+			Location:   *userPoint,
 			DistanceKm: distance,
 			PushTokens: tokens,
 		})
@@ -366,3 +427,16 @@ func (r *userRepository) FindAvailableVolunteers(ctx context.Context, lat, lng f
 
 	return volunteers, nil
 }
+
+func (r *userRepository) FindInBounds(ctx context.Context, bbox *entity.BoundingBox, afterID uuid.UUID, limit int) ([]entity.User, error) {
+	var users []entity.User
+	err := withContext(ctx, r.db).
+		Where("is_active = true").
+		Where("latitude BETWEEN ? AND ?", bbox.MinLat, bbox.MaxLat).
+		Where("longitude BETWEEN ? AND ?", bbox.MinLng, bbox.MaxLng).
+		Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
@@ -19,8 +19,14 @@ type Handlers struct {
 	User         *handler.UserHandler
 	Case         *handler.CaseHandler
 	Media        *handler.MediaHandler
+	Tus          *handler.TusHandler
 	Notification *handler.NotificationHandler
 	Geocode      *handler.GeocodeHandler
+	File         *handler.FileHandler
+	Outbox       *handler.OutboxHandler
+	Push         *handler.PushHandler
+	AlertZone    *handler.AlertZoneHandler
+	Event        *handler.DisasterEventHandler
 }
 
 // Setup initializes the router with all routes
@@ -45,7 +51,15 @@ func Setup(cfg *config.Config, handlers *Handlers, jwtService *jwt.Service, log
 	// Endpoint-specific rate limits
 	endpointLimiter := middleware.NewEndpointRateLimiter(map[string]middleware.RateLimitEndpointConfig{
 		"/api/cases/:id/accept": {Limit: 10, Window: time.Minute},
+		"/api/cases/actions":    {Limit: 10, Window: time.Minute},
 		"/api/media/upload":     {Limit: 20, Window: time.Minute},
+		"/api/media/upload-url": {Limit: 20, Window: time.Minute},
+		// Exports read every matching case
+		"/api/cases/export/:format": {Limit: 5, Window: time.Minute},
+		// Geocoding is public and backed by a rate limited provider
+		"/api/geocode/reverse": {Limit: 30, Window: time.Minute},
+		"/api/geocode/search":  {Limit: 30, Window: time.Minute},
+		"/api/geocode/parse":   {Limit: 30, Window: time.Minute},
 	})
 	r.Use(middleware.RateLimitEndpoint(endpointLimiter))
 
@@ -54,6 +68,13 @@ func Setup(cfg *config.Config, handlers *Handlers, jwtService *jwt.Service, log
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	// Locally stored files, when storage has no public endpoint of its own
+	if handlers.File != nil {
+		r.GET("/files/*key", handlers.File.Serve)
+		r.HEAD("/files/*key", handlers.File.Serve)
+		r.PUT("/files/*key", handlers.File.Put)
+	}
+
 	// Swagger documentation
 	if cfg.IsDevelopment() {
 		r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -94,31 +115,55 @@ func Setup(cfg *config.Config, handlers *Handlers, jwtService *jwt.Service, log
 			cases.GET("", handlers.Case.GetCases)
 			cases.POST("", middleware.OptionalAuth(jwtService), handlers.Case.Create)
 			cases.GET("/nearby", handlers.Case.GetNearby)
-			cases.GET("/:id", handlers.Case.GetByID)
+			cases.GET("/in-bounds", handlers.Case.GetInBounds)
+			cases.GET("/stats/areas", handlers.Case.GetAreaStats)
+			cases.GET("/export/:format", middleware.OptionalAuth(jwtService),
+				middleware.DetectCoordinator(cfg.Admin.UserIDs, cfg.Admin.CoordinatorIDs), handlers.Case.Export)
+			cases.GET("/:id", middleware.OptionalAuth(jwtService), handlers.Case.GetByID)
 			cases.GET("/:id/updates", handlers.Case.GetUpdates)
 			cases.GET("/:id/volunteers", handlers.Case.GetVolunteers)
 			cases.GET("/:id/comments", handlers.Case.GetComments)
+			// Authorized by the action token in the body rather than a session
+			cases.POST("/actions", handlers.Case.TakeAction)
 
 			// Authenticated routes
 			cases.GET("/my-cases", middleware.Auth(jwtService), handlers.Case.GetMyCases)
 			cases.GET("/my-volunteer-cases", middleware.Auth(jwtService), handlers.Case.GetMyVolunteerCases)
+			cases.GET("/my-followed-cases", middleware.Auth(jwtService), handlers.Case.GetMyFollowedCases)
 			cases.PUT("/:id", middleware.Auth(jwtService), handlers.Case.Update)
 			cases.DELETE("/:id", middleware.Auth(jwtService), handlers.Case.Delete)
 			cases.POST("/:id/accept", middleware.Auth(jwtService), handlers.Case.Accept)
 			cases.POST("/:id/withdraw", middleware.Auth(jwtService), handlers.Case.Withdraw)
+			cases.POST("/:id/dismiss", middleware.Auth(jwtService), handlers.Case.Dismiss)
+			cases.POST("/:id/follow", middleware.Auth(jwtService), handlers.Case.Follow)
+			cases.DELETE("/:id/follow", middleware.Auth(jwtService), handlers.Case.Unfollow)
 			cases.PUT("/:id/volunteer-status", middleware.Auth(jwtService), handlers.Case.UpdateVolunteerStatus)
 			cases.POST("/:id/updates", middleware.Auth(jwtService), handlers.Case.CreateUpdate)
 			cases.POST("/:id/comments", middleware.Auth(jwtService), handlers.Case.CreateComment)
 			cases.DELETE("/:id/comments/:commentId", middleware.Auth(jwtService), handlers.Case.DeleteComment)
 		}
 
+		// Resumable upload discovery (tus, public)
+		api.OPTIONS("/media/tus", middleware.TusResumable(), handlers.Tus.Options)
+
 		// Media routes (authenticated)
 		media := api.Group("/media")
 		media.Use(middleware.Auth(jwtService))
 		{
 			media.POST("/upload", handlers.Media.Upload)
 			media.POST("/upload-multiple", handlers.Media.UploadMultiple)
+			media.POST("/upload-url", handlers.Media.CreateUploadURL)
+			media.POST("/finalize", handlers.Media.FinalizeUpload)
 			media.DELETE("/:id", handlers.Media.Delete)
+
+			// Resumable uploads (tus)
+			tus := media.Group("/tus", middleware.TusResumable())
+			{
+				tus.POST("", handlers.Tus.Create)
+				tus.HEAD("/:id", handlers.Tus.Head)
+				tus.PATCH("/:id", handlers.Tus.Patch)
+				tus.DELETE("/:id", handlers.Tus.Delete)
+			}
 		}
 
 		// Notification routes (authenticated)
@@ -129,6 +174,10 @@ func Setup(cfg *config.Config, handlers *Handlers, jwtService *jwt.Service, log
 			notifications.GET("/unread-count", handlers.Notification.GetUnreadCount)
 			notifications.PUT("/:id/read", handlers.Notification.MarkAsRead)
 			notifications.PUT("/read-all", handlers.Notification.MarkAllAsRead)
+			notifications.PUT("/read", handlers.Notification.MarkManyAsRead)
+			notifications.PUT("/archive", handlers.Notification.Archive)
+			notifications.DELETE("", handlers.Notification.DeleteMany)
+			notifications.DELETE("/:id", handlers.Notification.Delete)
 		}
 
 		// Geocode routes (public)
@@ -136,8 +185,12 @@ func Setup(cfg *config.Config, handlers *Handlers, jwtService *jwt.Service, log
 		{
 			geocode.GET("/reverse", handlers.Geocode.ReverseGeocode)
 			geocode.GET("/search", handlers.Geocode.SearchAddress)
+			geocode.GET("/parse", handlers.Geocode.ParseLocation)
 		}
 
+		// Web push key is public so browsers can subscribe before registering
+		api.GET("/push-tokens/vapid-public-key", handlers.Push.GetVAPIDPublicKey)
+
 		// Push token routes (authenticated) - separate endpoint for mobile compatibility
 		pushTokens := api.Group("/push-tokens")
 		pushTokens.Use(middleware.Auth(jwtService))
@@ -145,6 +198,48 @@ func Setup(cfg *config.Config, handlers *Handlers, jwtService *jwt.Service, log
 			pushTokens.POST("", handlers.User.RegisterPushToken)
 			pushTokens.DELETE("/:token", handlers.User.DeletePushToken)
 		}
+
+		// Alert zone routes: public to read, drawn and broadcast by coordinators
+		alertZones := api.Group("/alert-zones")
+		{
+			alertZones.GET("", handlers.AlertZone.GetActive)
+			alertZones.GET("/:id", handlers.AlertZone.GetByID)
+
+			coordinator := alertZones.Group("", middleware.Auth(jwtService),
+				middleware.RequireCoordinator(cfg.Admin.UserIDs, cfg.Admin.CoordinatorIDs))
+			coordinator.POST("", handlers.AlertZone.Create)
+			coordinator.POST("/:id/broadcast", handlers.AlertZone.Broadcast)
+			coordinator.DELETE("/:id", handlers.AlertZone.End)
+		}
+
+		// Disaster event routes: public to read, declared by coordinators and
+		// managed by the event's own, whom the service checks
+		events := api.Group("/events")
+		{
+			events.GET("", handlers.Event.List)
+			events.GET("/:id", handlers.Event.GetByID)
+			events.GET("/:id/cases", handlers.Event.GetCases)
+			events.GET("/:id/stats", handlers.Event.GetStats)
+			events.GET("/:id/map", handlers.Event.GetMap)
+
+			coordinator := events.Group("", middleware.Auth(jwtService),
+				middleware.RequireCoordinator(cfg.Admin.UserIDs, cfg.Admin.CoordinatorIDs))
+			coordinator.POST("", handlers.Event.Create)
+
+			owner := events.Group("", middleware.Auth(jwtService))
+			owner.PUT("/:id", handlers.Event.Update)
+			owner.POST("/:id/cases", handlers.Event.LinkCases)
+			owner.DELETE("/:id/cases/:caseId", handlers.Event.UnlinkCase)
+		}
+
+		// Admin routes
+		admin := api.Group("/admin")
+		admin.Use(middleware.Auth(jwtService), middleware.RequireAdmin(cfg.Admin.UserIDs))
+		{
+			admin.GET("/outbox/jobs", handlers.Outbox.GetJobs)
+			admin.GET("/outbox/stats", handlers.Outbox.GetStats)
+			admin.POST("/outbox/jobs/:id/retry", handlers.Outbox.RetryJob)
+		}
 	}
 
 	return r
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/handler/dto/request"
+	"bamboo-rescue/internal/middleware"
+	"bamboo-rescue/internal/repository"
+	"go.uber.org/zap"
+)
+
+// JobBroadcastAlertZone is the outbox job that alerts everyone inside an
+// alert zone
+const JobBroadcastAlertZone = "alert_zone.broadcast"
+
+// alertZoneJob is the payload of JobBroadcastAlertZone
+type alertZoneJob struct {
+	AlertZoneID uuid.UUID `json:"alert_zone_id"`
+	// AfterID is where this batch of the broadcast starts: users are
+	// paged by ID
+	AfterID uuid.UUID `json:"after_id,omitempty"`
+}
+
+const (
+	// alertZoneMaxDuration bounds how far ahead a zone may expire; longer
+	// alerts are recreated so stale ones do not linger
+	alertZoneMaxDuration = 30 * 24 * time.Hour
+	// alertZoneBroadcastBatch is how many users one broadcast job loads,
+	// small enough to be alerted well within the outbox job timeout
+	alertZoneBroadcastBatch = 200
+)
+
+// AlertZoneService defines the interface for alert zone operations
+type AlertZoneService interface {
+	Create(ctx context.Context, userID uuid.UUID, req *request.CreateAlertZoneRequest) (*entity.AlertZone, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.AlertZone, error)
+	// GetActive returns the zones in force meeting bbox, or everywhere for
+	// a nil bbox, most severe first
+	GetActive(ctx context.Context, bbox *entity.BoundingBox) ([]entity.AlertZone, error)
+	// End lifts a zone before it expires
+	End(ctx context.Context, id uuid.UUID) (*entity.AlertZone, error)
+	// Broadcast queues an alert to every user whose last known location
+	// lies inside the zone
+	Broadcast(ctx context.Context, id uuid.UUID) (*entity.AlertZone, error)
+	// TagCase tags a new case with the zones in force it lies inside
+	TagCase(ctx context.Context, c *entity.Case) error
+}
+
+type alertZoneService struct {
+	alertZoneRepo repository.AlertZoneRepository
+	userRepo      repository.UserRepository
+	tx            repository.Transactor
+	outboxSvc     OutboxService
+	dispatcher    NotificationDispatcher
+	log           *zap.Logger
+}
+
+// NewAlertZoneService creates a new AlertZoneService
+func NewAlertZoneService(
+	alertZoneRepo repository.AlertZoneRepository,
+	userRepo repository.UserRepository,
+	tx repository.Transactor,
+	outboxSvc OutboxService,
+	dispatcher NotificationDispatcher,
+	log *zap.Logger,
+) AlertZoneService {
+	s := &alertZoneService{
+		alertZoneRepo: alertZoneRepo,
+		userRepo:      userRepo,
+		tx:            tx,
+		outboxSvc:     outboxSvc,
+		dispatcher:    dispatcher,
+		log:           log,
+	}
+
+	outboxSvc.Handle(JobBroadcastAlertZone, s.broadcast)
+
+	return s
+}
+
+func (s *alertZoneService) Create(ctx context.Context, userID uuid.UUID, req *request.CreateAlertZoneRequest) (*entity.AlertZone, error) {
+	title := strings.TrimSpace(req.Title)
+	if n := utf8.RuneCountInString(title); n < 3 || n > 200 {
+		return nil, middleware.NewAppError("VALIDATION_ERROR", "title must be 3 to 200 characters", 400)
+	}
+	if !req.Severity.IsValid() {
+		return nil, middleware.NewAppError("VALIDATION_ERROR", "severity must be advisory, warning or emergency", 400)
+	}
+	if req.Message != nil && utf8.RuneCountInString(*req.Message) > 2000 {
+		return nil, middleware.NewAppError("VALIDATION_ERROR", "message must be at most 2000 characters", 400)
+	}
+	polygon, err := entity.Polygon(req.Polygon).Normalize()
+	if err != nil {
+		return nil, middleware.NewAppError("VALIDATION_ERROR",
+			"polygon must have 3 to 500 points in range enclosing an area, and may not cross the antimeridian", 400)
+	}
+	now := time.Now()
+	if !req.ExpiresAt.After(now) || req.ExpiresAt.After(now.Add(alertZoneMaxDuration)) {
+		return nil, middleware.NewAppError("VALIDATION_ERROR", "expires_at must be in the next 30 days", 400)
+	}
+
+	zone := &entity.AlertZone{
+		Title:     title,
+		Message:   req.Message,
+		Severity:  req.Severity,
+		CreatedBy: &userID,
+		ExpiresAt: req.ExpiresAt,
+	}
+	zone.SetPolygon(polygon)
+	if req.Broadcast {
+		zone.LastBroadcastAt = &now
+	}
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.alertZoneRepo.Create(ctx, zone); err != nil {
+			return err
+		}
+		if !req.Broadcast {
+			return nil
+		}
+		return s.outboxSvc.Enqueue(ctx, JobBroadcastAlertZone, alertZoneJob{AlertZoneID: zone.ID})
+	})
+	if err != nil {
+		s.log.Error("Failed to create alert zone", zap.Error(err))
+		return nil, err
+	}
+
+	s.log.Info("Alert zone created",
+		zap.String("alert_zone_id", zone.ID.String()),
+		zap.String("severity", string(zone.Severity)),
+		zap.String("created_by", userID.String()),
+		zap.Bool("broadcast", req.Broadcast),
+	)
+
+	return zone, nil
+}
+
+func (s *alertZoneService) GetByID(ctx context.Context, id uuid.UUID) (*entity.AlertZone, error) {
+	zone, err := s.alertZoneRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if zone == nil {
+		return nil, middleware.ErrAlertZoneNotFound
+	}
+	return zone, nil
+}
+
+func (s *alertZoneService) GetActive(ctx context.Context, bbox *entity.BoundingBox) ([]entity.AlertZone, error) {
+	return s.alertZoneRepo.GetActive(ctx, bbox, time.Now())
+}
+
+func (s *alertZoneService) End(ctx context.Context, id uuid.UUID) (*entity.AlertZone, error) {
+	zone, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !zone.IsActive(now) {
+		return nil, middleware.ErrAlertZoneInactive
+	}
+
+	zone.EndedAt = &now
+	if err := s.alertZoneRepo.Update(ctx, zone); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Alert zone ended", zap.String("alert_zone_id", zone.ID.String()))
+	return zone, nil
+}
+
+func (s *alertZoneService) Broadcast(ctx context.Context, id uuid.UUID) (*entity.AlertZone, error) {
+	zone, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !zone.IsActive(now) {
+		return nil, middleware.ErrAlertZoneInactive
+	}
+
+	zone.LastBroadcastAt = &now
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.alertZoneRepo.Update(ctx, zone); err != nil {
+			return err
+		}
+		return s.outboxSvc.Enqueue(ctx, JobBroadcastAlertZone, alertZoneJob{AlertZoneID: zone.ID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return zone, nil
+}
+
+func (s *alertZoneService) TagCase(ctx context.Context, c *entity.Case) error {
+	zones, err := s.alertZoneRepo.GetActiveContaining(ctx, c.Latitude, c.Longitude, time.Now())
+	if err != nil || len(zones) == 0 {
+		return err
+	}
+
+	ids := make([]uuid.UUID, len(zones))
+	for i := range zones {
+		ids[i] = zones[i].ID
+	}
+	if err := s.alertZoneRepo.TagCase(ctx, c.ID, ids); err != nil {
+		return err
+	}
+	c.AlertZones = zones
+	return nil
+}
+
+// broadcast alerts one batch of the users inside the zone. Users are found
+// by the zone's bounding box, then checked against the polygon. The job for
+// the next batch is queued before this one is alerted, so a retry never
+// reaches the same users twice.
+func (s *alertZoneService) broadcast(ctx context.Context, payload json.RawMessage) error {
+	var job alertZoneJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return PermanentJobError(err)
+	}
+
+	zone, err := s.alertZoneRepo.GetByID(ctx, job.AlertZoneID)
+	if err != nil {
+		return err
+	}
+	if zone == nil || s.dispatcher == nil || !zone.IsActive(time.Now()) {
+		return nil
+	}
+
+	users, err := s.userRepo.FindInBounds(ctx, zone.Bounds(), job.AfterID, alertZoneBroadcastBatch)
+	if err != nil {
+		s.log.Warn("Failed to find users in alert zone", zap.Error(err), zap.String("alert_zone_id", zone.ID.String()))
+		return err
+	}
+	if len(users) == alertZoneBroadcastBatch {
+		next := alertZoneJob{AlertZoneID: zone.ID, AfterID: users[len(users)-1].ID}
+		if err := s.outboxSvc.Enqueue(ctx, JobBroadcastAlertZone, next); err != nil {
+			return err
+		}
+	}
+
+	// As with nearby volunteers, individual failures are not retried: that
+	// would alert everyone already reached a second time
+	notified := 0
+	for i := range users {
+		u := &users[i]
+		if u.Latitude == nil || u.Longitude == nil || !zone.Polygon.Contains(*u.Latitude, *u.Longitude) {
+			continue
+		}
+		if err := s.dispatcher.Dispatch(ctx, u.ID, entity.AlertZoneNotificationPayload(zone, u.Locale)); err != nil {
+			s.log.Warn("Failed to send alert zone notification", zap.Error(err), zap.String("user_id", u.ID.String()))
+			continue
+		}
+		notified++
+	}
+
+	s.log.Info("Broadcast alert zone",
+		zap.String("alert_zone_id", zone.ID.String()),
+		zap.Int("count", notified),
+		zap.Bool("more", len(users) == alertZoneBroadcastBatch),
+	)
+
+	return nil
+}
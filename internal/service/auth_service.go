@@ -85,6 +85,7 @@ func (s *authService) Register(ctx context.Context, req *request.RegisterRequest
 		Role:         enum.UserRoleBoth,
 		IsAvailable:  false,
 		IsActive:     true,
+		Locale:       enum.Locale(req.Locale).OrDefault(),
 	}
 
 	if err := s.userRepo.Create(ctx, user); err != nil {
@@ -99,6 +100,8 @@ func (s *authService) Register(ctx context.Context, req *request.RegisterRequest
 		CaseTypes:            []string{"animal", "flood", "accident"},
 		NotificationRadiusKm: 10,
 		UseCurrentLocation:   true,
+		EmailEnabled:         true,
+		EmailDigestEnabled:   true,
 	}
 	if err := s.userRepo.CreatePreferences(ctx, prefs); err != nil {
 		s.log.Warn("Failed to create user preferences", zap.Error(err))
@@ -203,6 +206,7 @@ func (s *authService) OAuth(ctx context.Context, req *request.OAuthRequest) (*en
 				Role:          enum.UserRoleBoth,
 				IsAvailable:   false,
 				IsActive:      true,
+				Locale:        enum.DefaultLocale,
 			}
 
 			if err := s.userRepo.Create(ctx, user); err != nil {
@@ -217,6 +221,8 @@ func (s *authService) OAuth(ctx context.Context, req *request.OAuthRequest) (*en
 				CaseTypes:            []string{"animal", "flood", "accident"},
 				NotificationRadiusKm: 10,
 				UseCurrentLocation:   true,
+				EmailEnabled:         true,
+				EmailDigestEnabled:   true,
 			}
 			if err := s.userRepo.CreatePreferences(ctx, prefs); err != nil {
 				s.log.Warn("Failed to create user preferences", zap.Error(err))
@@ -2,6 +2,11 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"strings"
 
 	"github.com/google/uuid"
 	"bamboo-rescue/internal/domain/entity"
@@ -9,6 +14,7 @@ import (
 	"bamboo-rescue/internal/handler/dto/request"
 	"bamboo-rescue/internal/middleware"
 	"bamboo-rescue/internal/repository"
+	"bamboo-rescue/pkg/jwt"
 	"go.uber.org/zap"
 )
 
@@ -16,12 +22,25 @@ import (
 type CaseService interface {
 	Create(ctx context.Context, req *request.CreateCaseRequest, userID *uuid.UUID) (*entity.Case, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Case, error)
+	GetByIDForViewer(ctx context.Context, id uuid.UUID, viewerID *uuid.UUID) (*entity.Case, error)
 	GetNearby(ctx context.Context, req *request.GetNearbyCasesRequest) ([]entity.CaseNearby, error)
 	GetCases(ctx context.Context, req *request.GetCasesRequest) ([]entity.Case, int64, error)
+	// GetAreaStats breaks case counts down by administrative unit
+	GetAreaStats(ctx context.Context, req *request.GetCaseAreaStatsRequest) ([]entity.CaseAreaStats, error)
+	// GetInBounds returns what the case map shows in a viewport: the cases
+	// when zoomed in, clusters of them when zoomed out
+	GetInBounds(ctx context.Context, req *request.GetCasesInBoundsRequest) (*entity.CaseMap, error)
+	// ExportCases calls fn with each case matching the export filters,
+	// oldest first, stopping at the first error fn returns
+	ExportCases(ctx context.Context, req *request.ExportCasesRequest, fn func(*entity.Case) error) error
 	Update(ctx context.Context, id uuid.UUID, userID uuid.UUID, req *request.UpdateCaseRequest) (*entity.Case, error)
 	Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
 	Accept(ctx context.Context, caseID, volunteerID uuid.UUID, req *request.AcceptCaseRequest) error
 	Withdraw(ctx context.Context, caseID, volunteerID uuid.UUID) error
+	Dismiss(ctx context.Context, caseID, volunteerID uuid.UUID) error
+	// TakeAction accepts or dismisses a case on behalf of the volunteer a
+	// push action token was issued to
+	TakeAction(ctx context.Context, req *request.CaseActionRequest) (*jwt.ActionClaims, error)
 	UpdateVolunteerStatus(ctx context.Context, caseID, volunteerID uuid.UUID, req *request.UpdateVolunteerStatusRequest) error
 	GetVolunteers(ctx context.Context, caseID uuid.UUID) ([]entity.CaseVolunteer, error)
 	CreateUpdate(ctx context.Context, caseID uuid.UUID, userID uuid.UUID, req *request.CreateCaseUpdateRequest) (*entity.CaseUpdate, error)
@@ -29,45 +48,133 @@ type CaseService interface {
 	GetUserReportedCases(ctx context.Context, userID uuid.UUID, page *request.PaginationRequest) ([]entity.Case, int64, error)
 	GetUserAcceptedCases(ctx context.Context, userID uuid.UUID, page *request.PaginationRequest) ([]entity.Case, int64, error)
 
+	// Followers
+	Follow(ctx context.Context, caseID, userID uuid.UUID) error
+	Unfollow(ctx context.Context, caseID, userID uuid.UUID) error
+	GetFollowedCases(ctx context.Context, userID uuid.UUID, page *request.PaginationRequest) ([]entity.Case, int64, error)
+
 	// Comments
 	CreateComment(ctx context.Context, caseID uuid.UUID, userID uuid.UUID, req *request.CreateCommentRequest) (*entity.CaseComment, error)
 	GetComments(ctx context.Context, caseID uuid.UUID, page *request.PaginationRequest) ([]entity.CaseComment, int64, error)
 	DeleteComment(ctx context.Context, commentID, userID uuid.UUID) error
 }
 
+// Outbox job kinds raised by case changes
+const (
+	JobNotifyNearbyVolunteers = "case.notify_nearby_volunteers"
+	JobNotifyReporterAccepted = "case.notify_reporter_accepted"
+	JobCheckCaseCompletion    = "case.check_completion"
+	JobNotifyCaseFollowers    = "case.notify_followers"
+	JobGeocodeCase            = "case.geocode"
+)
+
+// Reach of the new case notification
+const (
+	// notifyRadiusKm is how far out volunteers are looked for, each then
+	// reached within their own notification radius
+	notifyRadiusKm = 10
+	// notifyMaxVolunteers caps how many volunteers one case notifies
+	notifyMaxVolunteers = 100
+	// eventNotifyMaxVolunteers is the cap for a case in an event with its
+	// own radius, which covers many more people
+	eventNotifyMaxVolunteers = 500
+	// notifyCandidatePool times the cap are looked up by straight-line
+	// distance, then ranked by route before the cap is applied
+	notifyCandidatePool = 3
+)
+
+// caseJob is the payload of case outbox jobs
+type caseJob struct {
+	CaseID      uuid.UUID  `json:"case_id"`
+	VolunteerID *uuid.UUID `json:"volunteer_id,omitempty"`
+	// Set for JobNotifyCaseFollowers: the timeline entry or comment to
+	// announce, and who made it
+	UpdateID  *uuid.UUID `json:"update_id,omitempty"`
+	CommentID *uuid.UUID `json:"comment_id,omitempty"`
+	ActorID   *uuid.UUID `json:"actor_id,omitempty"`
+}
+
 type caseService struct {
-	caseRepo        repository.CaseRepository
-	userRepo        repository.UserRepository
-	notificationSvc NotificationService
-	fcmSvc          FCMService
-	log             *zap.Logger
+	caseRepo     repository.CaseRepository
+	userRepo     repository.UserRepository
+	tx           repository.Transactor
+	outboxSvc    OutboxService
+	dispatcher   NotificationDispatcher
+	mediaSvc     MediaService
+	geocodeSvc   GeocodeService
+	locationSvc  LocationService
+	routingSvc   RoutingService
+	alertZoneSvc AlertZoneService
+	eventSvc     DisasterEventService
+	jwtSvc       *jwt.Service
+	log          *zap.Logger
 }
 
 // NewCaseService creates a new CaseService
 func NewCaseService(
 	caseRepo repository.CaseRepository,
 	userRepo repository.UserRepository,
-	notificationSvc NotificationService,
-	fcmSvc FCMService,
+	tx repository.Transactor,
+	outboxSvc OutboxService,
+	dispatcher NotificationDispatcher,
+	mediaSvc MediaService,
+	geocodeSvc GeocodeService,
+	locationSvc LocationService,
+	routingSvc RoutingService,
+	alertZoneSvc AlertZoneService,
+	eventSvc DisasterEventService,
+	jwtSvc *jwt.Service,
 	log *zap.Logger,
 ) CaseService {
-	return &caseService{
-		caseRepo:        caseRepo,
-		userRepo:        userRepo,
-		notificationSvc: notificationSvc,
-		fcmSvc:          fcmSvc,
-		log:             log,
-	}
+	s := &caseService{
+		caseRepo:     caseRepo,
+		userRepo:     userRepo,
+		tx:           tx,
+		outboxSvc:    outboxSvc,
+		dispatcher:   dispatcher,
+		mediaSvc:     mediaSvc,
+		geocodeSvc:   geocodeSvc,
+		locationSvc:  locationSvc,
+		routingSvc:   routingSvc,
+		alertZoneSvc: alertZoneSvc,
+		eventSvc:     eventSvc,
+		jwtSvc:       jwtSvc,
+		log:          log,
+	}
+
+	outboxSvc.Handle(JobNotifyNearbyVolunteers, s.notifyNearbyVolunteers)
+	outboxSvc.Handle(JobNotifyReporterAccepted, s.notifyReporterOfAcceptance)
+	outboxSvc.Handle(JobCheckCaseCompletion, s.checkCaseCompletion)
+	outboxSvc.Handle(JobNotifyCaseFollowers, s.notifyFollowers)
+	outboxSvc.Handle(JobGeocodeCase, s.geocodeCase)
+
+	return s
 }
 
 func (s *caseService) Create(ctx context.Context, req *request.CreateCaseRequest, userID *uuid.UUID) (*entity.Case, error) {
+	latitude, longitude := req.Latitude, req.Longitude
+	if req.Location != nil && strings.TrimSpace(*req.Location) != "" {
+		// The reporter's coordinates, if sent, place a short plus code
+		var ref *entity.GeoPoint
+		if latitude != 0 || longitude != 0 {
+			ref = entity.NewGeoPoint(latitude, longitude)
+		}
+		parsed, err := s.locationSvc.Parse(ctx, *req.Location, ref)
+		if err != nil {
+			return nil, err
+		}
+		latitude, longitude = parsed.Location.Latitude, parsed.Location.Longitude
+	} else if latitude == 0 && longitude == 0 {
+		return nil, middleware.NewAppError("VALIDATION_ERROR", "Latitude and longitude or location is required", 400)
+	}
+
 	// Build case entity
 	c := &entity.Case{
-		CaseType:  req.CaseType,
-		Urgency:   req.Urgency,
-		Latitude:  req.Latitude,
-		Longitude: req.Longitude,
-		Address:   req.Address,
+		CaseType:      req.CaseType,
+		Urgency:       req.Urgency,
+		Latitude:      latitude,
+		Longitude:     longitude,
+		Address:       req.Address,
 		LocationNote:  req.LocationNote,
 		Title:         req.Title,
 		Description:   req.Description,
@@ -117,23 +224,45 @@ func (s *caseService) Create(ctx context.Context, req *request.CreateCaseRequest
 		}
 	}
 
-	// Create case
-	if err := s.caseRepo.Create(ctx, c); err != nil {
-		s.log.Error("Failed to create case", zap.Error(err))
-		return nil, err
-	}
+	// Create case, count it for the reporter and queue notifications together
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Cases reported inside a disaster event are grouped under it and
+		// dispatched by its settings
+		if err := s.eventSvc.AssignCase(ctx, c); err != nil {
+			return err
+		}
+
+		if err := s.caseRepo.Create(ctx, c); err != nil {
+			return err
+		}
 
-	// Increment user's reported cases count
-	if userID != nil {
-		go func() {
-			if err := s.userRepo.IncrementCasesReported(context.Background(), *userID); err != nil {
-				s.log.Warn("Failed to increment cases reported", zap.Error(err))
+		if userID != nil {
+			if err := s.userRepo.IncrementCasesReported(ctx, *userID); err != nil {
+				return err
 			}
-		}()
-	}
+			if err := s.caseRepo.Follow(ctx, c.ID, *userID); err != nil {
+				return err
+			}
+		}
+
+		// Cases inside an alert zone are tagged so coordinators see them
+		// together
+		if err := s.alertZoneSvc.TagCase(ctx, c); err != nil {
+			return err
+		}
+
+		// Reporters often send only a GPS pin; the address and the units
+		// coordinators filter by are looked up afterwards
+		if err := s.outboxSvc.Enqueue(ctx, JobGeocodeCase, caseJob{CaseID: c.ID}); err != nil {
+			return err
+		}
 
-	// Notify nearby volunteers asynchronously
-	go s.notifyNearbyVolunteers(c)
+		return s.outboxSvc.Enqueue(ctx, JobNotifyNearbyVolunteers, caseJob{CaseID: c.ID})
+	})
+	if err != nil {
+		s.log.Error("Failed to create case", zap.Error(err))
+		return nil, err
+	}
 
 	s.log.Info("Case created",
 		zap.String("case_id", c.ID.String()),
@@ -155,6 +284,18 @@ func (s *caseService) GetByID(ctx context.Context, id uuid.UUID) (*entity.Case,
 	return c, nil
 }
 
+// GetByIDForViewer returns a case with media URLs resolved for the viewer.
+// Only the reporter and the case's volunteers see sensitive media.
+func (s *caseService) GetByIDForViewer(ctx context.Context, id uuid.UUID, viewerID *uuid.UUID) (*entity.Case, error) {
+	c, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mediaSvc.ResolveURLs(ctx, c.Media, canViewSensitiveMedia(c, viewerID))
+	return c, nil
+}
+
 func (s *caseService) GetNearby(ctx context.Context, req *request.GetNearbyCasesRequest) ([]entity.CaseNearby, error) {
 	// Set defaults
 	radiusKm := req.RadiusKm
@@ -176,14 +317,106 @@ func (s *caseService) GetNearby(ctx context.Context, req *request.GetNearbyCases
 }
 
 func (s *caseService) GetCases(ctx context.Context, req *request.GetCasesRequest) ([]entity.Case, int64, error) {
-	cases, total, err := s.caseRepo.GetCases(ctx, req.Query, req.Type, req.Status, req.Urgency, req.GetDefaultLimit(), req.GetOffset())
+	cases, total, err := s.caseRepo.GetCases(ctx, req.Query, req.Type, req.Status, req.Urgency, req.Area(), req.EventID, req.GetDefaultLimit(), req.GetOffset())
 	if err != nil {
 		s.log.Error("Failed to get cases", zap.Error(err))
 		return nil, 0, err
 	}
+
+	// Public listings never show sensitive media, whoever is asking
+	for i := range cases {
+		s.mediaSvc.ResolveURLs(ctx, cases[i].Media, false)
+	}
 	return cases, total, nil
 }
 
+func (s *caseService) GetAreaStats(ctx context.Context, req *request.GetCaseAreaStatsRequest) ([]entity.CaseAreaStats, error) {
+	stats, err := s.caseRepo.GetAreaStats(ctx, req.Tier(), req.Area())
+	if err != nil {
+		s.log.Error("Failed to get case area stats", zap.Error(err))
+		return nil, err
+	}
+	return stats, nil
+}
+
+// Case map zoom levels are those of web map tiles: at zoom z the world is
+// 2^z tiles of 256 px across
+const (
+	// caseMapClusterMaxZoom is the closest zoom at which cases are clustered
+	caseMapClusterMaxZoom = 14
+	// caseMapMaxCases caps how many cases one viewport returns
+	caseMapMaxCases = 500
+	// caseMapCellsPerTile makes cluster cells a quarter tile, about 64 px
+	caseMapCellsPerTile = 4
+)
+
+func (s *caseService) GetInBounds(ctx context.Context, req *request.GetCasesInBoundsRequest) (*entity.CaseMap, error) {
+	bbox, ok := req.BoundingBox()
+	if !ok {
+		return nil, middleware.NewAppError("VALIDATION_ERROR", "bbox must be west,south,east,north in degrees", 400)
+	}
+	if req.Zoom == nil || *req.Zoom < 0 || *req.Zoom > 22 {
+		return nil, middleware.NewAppError("VALIDATION_ERROR", "zoom must be between 0 and 22", 400)
+	}
+
+	if *req.Zoom > caseMapClusterMaxZoom {
+		cases, total, err := s.caseRepo.GetInBounds(ctx, bbox, req.Types, req.EventID, caseMapMaxCases)
+		if err != nil {
+			return nil, err
+		}
+		return &entity.CaseMap{Cases: cases, Total: total}, nil
+	}
+
+	cellDeg := 360 / math.Exp2(float64(*req.Zoom)) / caseMapCellsPerTile
+	clusters, err := s.caseRepo.GetClustersInBounds(ctx, bbox, req.Types, req.EventID, cellDeg)
+	if err != nil {
+		return nil, err
+	}
+	caseMap := &entity.CaseMap{Clustered: true, Clusters: clusters}
+	for _, c := range clusters {
+		caseMap.Total += c.Count
+	}
+	return caseMap, nil
+}
+
+func (s *caseService) ExportCases(ctx context.Context, req *request.ExportCasesRequest, fn func(*entity.Case) error) error {
+	from, to, ok := req.Period()
+	if !ok {
+		return middleware.NewAppError("VALIDATION_ERROR", "from and to must be dates (YYYY-MM-DD) or RFC 3339 times, from before to", 400)
+	}
+	bbox, ok := req.BoundingBox()
+	if !ok {
+		return middleware.NewAppError("VALIDATION_ERROR", "bbox must be west,south,east,north in degrees", 400)
+	}
+	eventID, ok := req.Event()
+	if !ok {
+		return middleware.NewAppError("VALIDATION_ERROR", "event_id must be a UUID", 400)
+	}
+
+	filter := repository.CaseExportFilter{
+		Query:   strings.TrimSpace(req.Query),
+		Type:    req.Type,
+		Status:  req.Status,
+		Urgency: req.Urgency,
+		Area:    req.Area(),
+		From:    from,
+		To:      to,
+		BBox:    bbox,
+		EventID: eventID,
+	}
+
+	exported := 0
+	err := s.caseRepo.StreamCases(ctx, filter, func(c *entity.Case) error {
+		exported++
+		return fn(c)
+	})
+	if err != nil {
+		s.log.Warn("Case export stopped", zap.Int("exported", exported), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
 func (s *caseService) Update(ctx context.Context, id uuid.UUID, userID uuid.UUID, req *request.UpdateCaseRequest) (*entity.Case, error) {
 	c, err := s.caseRepo.GetByID(ctx, id)
 	if err != nil {
@@ -208,21 +441,19 @@ func (s *caseService) Update(ctx context.Context, id uuid.UUID, userID uuid.UUID
 	if req.Urgency != nil {
 		c.Urgency = *req.Urgency
 	}
-	if req.Status != nil {
+	var statusUpdate *entity.CaseUpdate
+	if req.Status != nil && *req.Status != c.Status {
 		oldStatus := c.Status
 		c.Status = *req.Status
 
 		// Create status change update
-		update := &entity.CaseUpdate{
+		statusUpdate = &entity.CaseUpdate{
 			CaseID:     c.ID,
 			UpdateType: enum.UpdateTypeStatusChange,
 			UserID:     &userID,
 			OldStatus:  &oldStatus,
 			NewStatus:  req.Status,
 		}
-		if err := s.caseRepo.CreateUpdate(ctx, update); err != nil {
-			s.log.Warn("Failed to create status update", zap.Error(err))
-		}
 	}
 	if req.Address != nil {
 		c.Address = req.Address
@@ -231,7 +462,16 @@ func (s *caseService) Update(ctx context.Context, id uuid.UUID, userID uuid.UUID
 		c.LocationNote = req.LocationNote
 	}
 
-	if err := s.caseRepo.Update(ctx, c); err != nil {
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.caseRepo.Update(ctx, c); err != nil {
+			return err
+		}
+		if statusUpdate == nil {
+			return nil
+		}
+		return s.recordUpdate(ctx, statusUpdate)
+	})
+	if err != nil {
 		s.log.Error("Failed to update case", zap.Error(err))
 		return nil, err
 	}
@@ -291,15 +531,35 @@ func (s *caseService) Accept(ctx context.Context, caseID, volunteerID uuid.UUID,
 		return middleware.ErrUserNotFound
 	}
 
-	// Calculate location and distance if provided
-	var latitude, longitude, distanceKm *float64
+	mode := enum.TravelModeDriving
+	if req.TravelMode != nil {
+		if !req.TravelMode.IsValid() {
+			return middleware.NewAppError("VALIDATION_ERROR", "Travel mode must be driving or walking", 400)
+		}
+		mode = *req.TravelMode
+	}
+
+	cv := &entity.CaseVolunteer{
+		CaseID:      caseID,
+		VolunteerID: volunteerID,
+		Status:      enum.VolunteerStatusAccepted,
+	}
+
+	// Calculate location, distance and travel time if provided
 	if req.Latitude != nil && req.Longitude != nil {
-		latitude = req.Latitude
-		longitude = req.Longitude
+		cv.AcceptedLatitude = req.Latitude
+		cv.AcceptedLongitude = req.Longitude
 		caseLocation := c.GetLocation()
 		volunteerLocation := entity.NewGeoPoint(*req.Latitude, *req.Longitude)
 		distance := caseLocation.DistanceKm(volunteerLocation)
-		distanceKm = &distance
+		cv.DistanceKm = &distance
+
+		route := s.routingSvc.Route(ctx, *volunteerLocation, *caseLocation, mode)
+		etaSeconds := int(route.Duration.Seconds())
+		cv.TravelMode = &mode
+		cv.RouteDistanceKm = &route.DistanceKm
+		cv.EtaSeconds = &etaSeconds
+		cv.EtaEstimated = route.Estimated
 	}
 
 	// Check if already accepted
@@ -308,48 +568,54 @@ func (s *caseService) Accept(ctx context.Context, caseID, volunteerID uuid.UUID,
 		return err
 	}
 
-	if existing != nil {
-		// If previously withdrawn, allow rejoin
-		if existing.Status == enum.VolunteerStatusWithdrawn {
-			if err := s.caseRepo.ReactivateVolunteer(ctx, caseID, volunteerID, latitude, longitude, distanceKm); err != nil {
+	if existing != nil && existing.Status != enum.VolunteerStatusWithdrawn {
+		return middleware.NewAppError("ALREADY_ACCEPTED", "You have already accepted this case", 400)
+	}
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if existing != nil {
+			// Previously withdrawn, allow rejoin
+			if err := s.caseRepo.ReactivateVolunteer(ctx, cv); err != nil {
 				s.log.Error("Failed to reactivate volunteer", zap.Error(err))
 				return err
 			}
 		} else {
-			return middleware.NewAppError("ALREADY_ACCEPTED", "You have already accepted this case", 400)
+			// Create new volunteer entry
+			if err := s.caseRepo.AddVolunteer(ctx, cv); err != nil {
+				s.log.Error("Failed to add volunteer", zap.Error(err))
+				return err
+			}
 		}
-	} else {
-		// Create new volunteer entry
-		cv := &entity.CaseVolunteer{
-			CaseID:            caseID,
-			VolunteerID:       volunteerID,
-			Status:            enum.VolunteerStatusAccepted,
-			AcceptedLatitude:  latitude,
-			AcceptedLongitude: longitude,
-			DistanceKm:        distanceKm,
+
+		if err := s.caseRepo.Follow(ctx, caseID, volunteerID); err != nil {
+			return err
+		}
+		if err := s.caseRepo.Undismiss(ctx, caseID, volunteerID); err != nil {
+			return err
 		}
 
-		if err := s.caseRepo.AddVolunteer(ctx, cv); err != nil {
-			s.log.Error("Failed to add volunteer", zap.Error(err))
+		// Create update entry
+		content := volunteer.DisplayName + " đã nhận case này"
+		update := &entity.CaseUpdate{
+			CaseID:     caseID,
+			UpdateType: enum.UpdateTypeVolunteerJoined,
+			UserID:     &volunteerID,
+			Content:    &content,
+		}
+		if err := s.recordUpdate(ctx, update); err != nil {
 			return err
 		}
-	}
 
-	// Create update entry
-	content := volunteer.DisplayName + " đã nhận case này"
-	update := &entity.CaseUpdate{
-		CaseID:     caseID,
-		UpdateType: enum.UpdateTypeVolunteerJoined,
-		UserID:     &volunteerID,
-		Content:    &content,
-	}
-	if err := s.caseRepo.CreateUpdate(ctx, update); err != nil {
-		s.log.Warn("Failed to create update", zap.Error(err))
+		// Notify reporter
+		if c.ReporterID == nil {
+			return nil
+		}
+		return s.outboxSvc.Enqueue(ctx, JobNotifyReporterAccepted, caseJob{CaseID: caseID, VolunteerID: &volunteerID})
+	})
+	if err != nil {
+		return err
 	}
 
-	// Notify reporter
-	go s.notifyReporterOfAcceptance(c, volunteer)
-
 	s.log.Info("Volunteer accepted case",
 		zap.String("case_id", caseID.String()),
 		zap.String("volunteer_id", volunteerID.String()),
@@ -374,11 +640,6 @@ func (s *caseService) Withdraw(ctx context.Context, caseID, volunteerID uuid.UUI
 		volunteerName = volunteer.DisplayName
 	}
 
-	if err := s.caseRepo.RemoveVolunteer(ctx, caseID, volunteerID); err != nil {
-		s.log.Error("Failed to withdraw volunteer", zap.Error(err))
-		return err
-	}
-
 	// Create update entry
 	content := volunteerName + " has left this case"
 	update := &entity.CaseUpdate{
@@ -387,8 +648,16 @@ func (s *caseService) Withdraw(ctx context.Context, caseID, volunteerID uuid.UUI
 		UserID:     &volunteerID,
 		Content:    &content,
 	}
-	if err := s.caseRepo.CreateUpdate(ctx, update); err != nil {
-		s.log.Warn("Failed to create withdraw update", zap.Error(err))
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.caseRepo.RemoveVolunteer(ctx, caseID, volunteerID); err != nil {
+			s.log.Error("Failed to withdraw volunteer", zap.Error(err))
+			return err
+		}
+		return s.recordUpdate(ctx, update)
+	})
+	if err != nil {
+		return err
 	}
 
 	s.log.Info("Volunteer withdrew from case",
@@ -399,6 +668,72 @@ func (s *caseService) Withdraw(ctx context.Context, caseID, volunteerID uuid.UUI
 	return nil
 }
 
+// Dismiss turns a case down so the volunteer hears no more about it
+func (s *caseService) Dismiss(ctx context.Context, caseID, volunteerID uuid.UUID) error {
+	c, err := s.caseRepo.GetByID(ctx, caseID)
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		return middleware.ErrCaseNotFound
+	}
+
+	existing, err := s.caseRepo.GetVolunteer(ctx, caseID, volunteerID)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.Status != enum.VolunteerStatusWithdrawn {
+		return middleware.NewAppError("ALREADY_ACCEPTED", "You have already accepted this case", 400)
+	}
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.caseRepo.Dismiss(ctx, caseID, volunteerID); err != nil {
+			s.log.Error("Failed to dismiss case", zap.Error(err))
+			return err
+		}
+		return s.caseRepo.Unfollow(ctx, caseID, volunteerID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.log.Info("Volunteer dismissed case",
+		zap.String("case_id", caseID.String()),
+		zap.String("volunteer_id", volunteerID.String()),
+	)
+
+	return nil
+}
+
+func (s *caseService) TakeAction(ctx context.Context, req *request.CaseActionRequest) (*jwt.ActionClaims, error) {
+	if req.Token == "" {
+		return nil, middleware.NewAppError("VALIDATION_ERROR", "Action token is required", 400)
+	}
+
+	claims, err := s.jwtSvc.ValidateActionToken(req.Token)
+	if err != nil {
+		return nil, middleware.ErrInvalidToken
+	}
+	volunteerID, _ := claims.UserID()
+
+	switch claims.Action {
+	case entity.CaseActionAccept:
+		err = s.Accept(ctx, claims.CaseID, volunteerID, &request.AcceptCaseRequest{
+			Latitude:  req.Latitude,
+			Longitude: req.Longitude,
+		})
+	case entity.CaseActionDismiss:
+		err = s.Dismiss(ctx, claims.CaseID, volunteerID)
+	default:
+		return nil, middleware.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
 func (s *caseService) UpdateVolunteerStatus(ctx context.Context, caseID, volunteerID uuid.UUID, req *request.UpdateVolunteerStatusRequest) error {
 	cv, err := s.caseRepo.GetVolunteer(ctx, caseID, volunteerID)
 	if err != nil {
@@ -408,11 +743,6 @@ func (s *caseService) UpdateVolunteerStatus(ctx context.Context, caseID, volunte
 		return middleware.NewAppError("NOT_ACCEPTED", "You have not accepted this case", 400)
 	}
 
-	if err := s.caseRepo.UpdateVolunteerStatus(ctx, caseID, volunteerID, req.Status); err != nil {
-		s.log.Error("Failed to update volunteer status", zap.Error(err))
-		return err
-	}
-
 	// Create update entry
 	volunteer, _ := s.userRepo.GetByID(ctx, volunteerID)
 	volunteerName := "Tình nguyện viên"
@@ -432,13 +762,25 @@ func (s *caseService) UpdateVolunteerStatus(ctx context.Context, caseID, volunte
 		UserID:     &volunteerID,
 		Content:    &content,
 	}
-	if err := s.caseRepo.CreateUpdate(ctx, update); err != nil {
-		s.log.Warn("Failed to create update", zap.Error(err))
-	}
 
-	// If completed, check if all volunteers completed and update case status
-	if req.Status == enum.VolunteerStatusCompleted {
-		go s.checkCaseCompletion(caseID, volunteerID)
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.caseRepo.UpdateVolunteerStatus(ctx, caseID, volunteerID, req.Status); err != nil {
+			s.log.Error("Failed to update volunteer status", zap.Error(err))
+			return err
+		}
+
+		if err := s.recordUpdate(ctx, update); err != nil {
+			return err
+		}
+
+		// If completed, check if all volunteers completed and update case status
+		if req.Status != enum.VolunteerStatusCompleted {
+			return nil
+		}
+		return s.outboxSvc.Enqueue(ctx, JobCheckCaseCompletion, caseJob{CaseID: caseID, VolunteerID: &volunteerID})
+	})
+	if err != nil {
+		return err
 	}
 
 	s.log.Info("Volunteer status updated",
@@ -505,7 +847,9 @@ func (s *caseService) CreateUpdate(ctx context.Context, caseID uuid.UUID, userID
 		MediaURLs:  req.MediaURLs,
 	}
 
-	if err := s.caseRepo.CreateUpdate(ctx, update); err != nil {
+	if err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		return s.recordUpdate(ctx, update)
+	}); err != nil {
 		s.log.Error("Failed to create update", zap.Error(err))
 		return nil, err
 	}
@@ -525,55 +869,263 @@ func (s *caseService) GetUserAcceptedCases(ctx context.Context, userID uuid.UUID
 	return s.caseRepo.GetUserAcceptedCases(ctx, userID, page.GetDefaultLimit(), page.GetOffset())
 }
 
+// Follower methods
+
+func (s *caseService) Follow(ctx context.Context, caseID, userID uuid.UUID) error {
+	c, err := s.caseRepo.GetByID(ctx, caseID)
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		return middleware.ErrCaseNotFound
+	}
+
+	// Following a dismissed case takes the dismissal back
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.caseRepo.Follow(ctx, caseID, userID); err != nil {
+			s.log.Error("Failed to follow case", zap.Error(err))
+			return err
+		}
+		return s.caseRepo.Undismiss(ctx, caseID, userID)
+	})
+	return err
+}
+
+func (s *caseService) Unfollow(ctx context.Context, caseID, userID uuid.UUID) error {
+	if err := s.caseRepo.Unfollow(ctx, caseID, userID); err != nil {
+		s.log.Error("Failed to unfollow case", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s *caseService) GetFollowedCases(ctx context.Context, userID uuid.UUID, page *request.PaginationRequest) ([]entity.Case, int64, error) {
+	return s.caseRepo.GetFollowedCases(ctx, userID, page.GetDefaultLimit(), page.GetOffset())
+}
+
 // Helper functions
 
-func (s *caseService) notifyNearbyVolunteers(c *entity.Case) {
-	if s.notificationSvc == nil {
-		return
+// canViewSensitiveMedia reports whether viewerID is the case's reporter or
+// one of its active volunteers. Case must be loaded with its volunteers.
+func canViewSensitiveMedia(c *entity.Case, viewerID *uuid.UUID) bool {
+	if viewerID == nil {
+		return false
+	}
+	if c.ReporterID != nil && *c.ReporterID == *viewerID {
+		return true
+	}
+	for _, v := range c.Volunteers {
+		if v.VolunteerID == *viewerID && v.Status != enum.VolunteerStatusWithdrawn {
+			return true
+		}
 	}
+	return false
+}
+
+// recordUpdate adds a timeline entry and queues telling the case's followers
+// about it. Must run inside a transaction.
+func (s *caseService) recordUpdate(ctx context.Context, update *entity.CaseUpdate) error {
+	if err := s.caseRepo.CreateUpdate(ctx, update); err != nil {
+		return err
+	}
+	return s.outboxSvc.Enqueue(ctx, JobNotifyCaseFollowers, caseJob{
+		CaseID:   update.CaseID,
+		UpdateID: &update.ID,
+		ActorID:  update.UserID,
+	})
+}
 
-	ctx := context.Background()
+// decodeCaseJob loads the case a job refers to. A nil case means it has been
+// deleted since the job was queued.
+func (s *caseService) decodeCaseJob(ctx context.Context, payload json.RawMessage) (*caseJob, *entity.Case, error) {
+	var job caseJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return nil, nil, PermanentJobError(err)
+	}
 
-	// Find nearby volunteers
-	volunteers, err := s.userRepo.FindAvailableVolunteers(ctx, c.Latitude, c.Longitude, 10, string(c.CaseType), 100)
+	c, err := s.caseRepo.GetByID(ctx, job.CaseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &job, c, nil
+}
+
+func (s *caseService) notifyNearbyVolunteers(ctx context.Context, payload json.RawMessage) error {
+	_, c, err := s.decodeCaseJob(ctx, payload)
+	if err != nil || c == nil {
+		return err
+	}
+	if s.dispatcher == nil || !c.Status.IsActive() {
+		return nil
+	}
+
+	// Find nearby volunteers. An event's radius reaches everyone inside it,
+	// whatever their own notification radius.
+	radiusKm, limit := notifyRadiusKm, notifyMaxVolunteers
+	eventRadiusKm, err := s.eventSvc.NotifyRadiusKm(ctx, c, 0)
+	if err != nil {
+		return err
+	}
+	if eventRadiusKm > 0 {
+		radiusKm, limit = eventRadiusKm, eventNotifyMaxVolunteers
+	}
+
+	// The nearest by straight line are not always the quickest to arrive,
+	// so a wider pool is looked up
+	candidates, err := s.userRepo.FindAvailableVolunteers(ctx, c.Latitude, c.Longitude, radiusKm, eventRadiusKm > 0, string(c.CaseType), limit*notifyCandidatePool)
 	if err != nil {
 		s.log.Warn("Failed to find nearby volunteers", zap.Error(err))
-		return
+		return err
 	}
 
-	for _, v := range volunteers {
-		payload := entity.NewCaseNotificationPayload(c, v.DistanceKm)
-		if err := s.fcmSvc.SendToUser(ctx, v.User.ID, payload); err != nil {
+	dismissed, err := s.caseRepo.GetDismissedUserIDs(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+	volunteers := candidates[:0]
+	for _, v := range candidates {
+		if !dismissed[v.User.ID] {
+			volunteers = append(volunteers, v)
+		}
+	}
+
+	// Those who can get there soonest are notified first; a river between
+	// two close points can make a far volunteer the quicker one
+	origins := make([]entity.GeoPoint, len(volunteers))
+	for i := range volunteers {
+		origins[i] = volunteers[i].Location
+	}
+	routes := s.routingSvc.RouteMany(ctx, origins, *c.GetLocation(), enum.TravelModeDriving)
+	order := make([]int, len(volunteers))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return routes[order[i]].Duration < routes[order[j]].Duration
+	})
+	if len(order) > limit {
+		order = order[:limit]
+	}
+
+	// Individual send failures are not retried: that would re-notify
+	// everyone already reached
+	notified := 0
+	for _, i := range order {
+		v := volunteers[i]
+		payload := entity.NewCaseNotificationPayload(c, v.DistanceKm, v.User.Locale)
+		payload.SetEta(routes[i].Duration)
+		s.attachActionTokens(payload, v.User.ID, c.ID)
+		if err := s.dispatcher.Dispatch(ctx, v.User.ID, payload); err != nil {
 			s.log.Warn("Failed to send notification", zap.Error(err), zap.String("user_id", v.User.ID.String()))
+			continue
 		}
+		notified++
 	}
 
 	s.log.Info("Notified nearby volunteers",
 		zap.String("case_id", c.ID.String()),
-		zap.Int("count", len(volunteers)),
+		zap.Int("count", notified),
 	)
+
+	return nil
 }
 
-func (s *caseService) notifyReporterOfAcceptance(c *entity.Case, volunteer *entity.User) {
-	if s.fcmSvc == nil || c.ReporterID == nil {
+// geocodeCase fills in the address and administrative units of a new case
+// from its coordinates. While the geocoder is unavailable the job is retried.
+func (s *caseService) geocodeCase(ctx context.Context, payload json.RawMessage) error {
+	_, c, err := s.decodeCaseJob(ctx, payload)
+	if err != nil || c == nil {
+		return err
+	}
+	if s.geocodeSvc == nil {
+		return nil
+	}
+
+	result, err := s.geocodeSvc.ReverseGeocode(ctx, c.Latitude, c.Longitude)
+	if err != nil {
+		s.log.Warn("Failed to geocode case", zap.Error(err), zap.String("case_id", c.ID.String()))
+		return err
+	}
+
+	area := result.Components.Area()
+	if area.IsZero() && result.Address == "" {
+		// Nothing there to name, e.g. a pin out at sea
+		return nil
+	}
+
+	return s.caseRepo.SetArea(ctx, c.ID, result.Address, area)
+}
+
+// attachActionTokens lets the volunteer accept or dismiss the case from the
+// push itself. Without tokens the push still goes out, just without buttons.
+func (s *caseService) attachActionTokens(payload *entity.NotificationPayload, volunteerID, caseID uuid.UUID) {
+	if s.jwtSvc == nil {
 		return
 	}
+	actions := []string{entity.CaseActionAccept, entity.CaseActionDismiss}
+	tokens := make([]string, len(actions))
+	for i, action := range actions {
+		token, _, err := s.jwtSvc.GenerateActionToken(volunteerID, caseID, action)
+		if err != nil {
+			s.log.Warn("Failed to generate case action token", zap.Error(err))
+			return
+		}
+		tokens[i] = token
+	}
+	for i, action := range actions {
+		payload.SetActionToken(action, tokens[i])
+	}
+}
+
+func (s *caseService) notifyReporterOfAcceptance(ctx context.Context, payload json.RawMessage) error {
+	job, c, err := s.decodeCaseJob(ctx, payload)
+	if err != nil || c == nil {
+		return err
+	}
+	if s.dispatcher == nil || c.ReporterID == nil || job.VolunteerID == nil {
+		return nil
+	}
+
+	volunteer, err := s.userRepo.GetByID(ctx, *job.VolunteerID)
+	if err != nil {
+		return err
+	}
+	if volunteer == nil {
+		return nil
+	}
+
+	reporter, err := s.userRepo.GetByID(ctx, *c.ReporterID)
+	if err != nil {
+		return err
+	}
+	if reporter == nil {
+		return nil
+	}
 
-	ctx := context.Background()
-	payload := entity.CaseAcceptedNotificationPayload(c, volunteer.DisplayName)
-	if err := s.fcmSvc.SendToUser(ctx, *c.ReporterID, payload); err != nil {
+	notification := entity.CaseAcceptedNotificationPayload(c, volunteer.DisplayName, reporter.Locale)
+	if err := s.dispatcher.Dispatch(ctx, *c.ReporterID, notification); err != nil {
 		s.log.Warn("Failed to notify reporter", zap.Error(err))
+		return err
 	}
+
+	return nil
 }
 
-func (s *caseService) checkCaseCompletion(caseID, volunteerID uuid.UUID) {
-	ctx := context.Background()
+func (s *caseService) checkCaseCompletion(ctx context.Context, payload json.RawMessage) error {
+	job, c, err := s.decodeCaseJob(ctx, payload)
+	if err != nil || c == nil {
+		return err
+	}
+	if c.Status == enum.CaseStatusResolved {
+		// Already resolved by an earlier run or another volunteer's job
+		return nil
+	}
 
 	// Get all volunteers
-	volunteers, err := s.caseRepo.GetVolunteersByCaseID(ctx, caseID)
+	volunteers, err := s.caseRepo.GetVolunteersByCaseID(ctx, job.CaseID)
 	if err != nil {
 		s.log.Warn("Failed to get volunteers", zap.Error(err))
-		return
+		return err
 	}
 
 	// Check if all volunteers are completed or withdrawn
@@ -585,24 +1137,151 @@ func (s *caseService) checkCaseCompletion(caseID, volunteerID uuid.UUID) {
 		}
 	}
 
-	if allDone && len(volunteers) > 0 {
-		// Update case status to resolved
-		if err := s.caseRepo.UpdateStatus(ctx, caseID, enum.CaseStatusResolved); err != nil {
-			s.log.Warn("Failed to resolve case", zap.Error(err))
-			return
+	if !allDone || len(volunteers) == 0 {
+		return nil
+	}
+
+	// Resolve and credit volunteers together; only the job that actually
+	// resolves the case credits them, so retries never double-count
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		resolved, err := s.caseRepo.Resolve(ctx, job.CaseID)
+		if err != nil || !resolved {
+			return err
+		}
+
+		oldStatus, newStatus := c.Status, enum.CaseStatusResolved
+		if err := s.recordUpdate(ctx, &entity.CaseUpdate{
+			CaseID:     job.CaseID,
+			UpdateType: enum.UpdateTypeStatusChange,
+			OldStatus:  &oldStatus,
+			NewStatus:  &newStatus,
+		}); err != nil {
+			return err
 		}
 
 		// Increment resolved count for all completed volunteers
 		for _, v := range volunteers {
 			if v.Status == enum.VolunteerStatusCompleted {
 				if err := s.userRepo.IncrementCasesResolved(ctx, v.VolunteerID); err != nil {
-					s.log.Warn("Failed to increment resolved count", zap.Error(err))
+					return err
 				}
 			}
 		}
+		return nil
+	})
+	if err != nil {
+		s.log.Warn("Failed to resolve case", zap.Error(err))
+		return err
+	}
 
-		s.log.Info("Case resolved", zap.String("case_id", caseID.String()))
+	s.log.Info("Case resolved", zap.String("case_id", job.CaseID.String()))
+	return nil
+}
+
+func (s *caseService) notifyFollowers(ctx context.Context, payload json.RawMessage) error {
+	job, c, err := s.decodeCaseJob(ctx, payload)
+	if err != nil || c == nil {
+		return err
+	}
+	if s.dispatcher == nil {
+		return nil
 	}
+
+	build, skipReporter, err := s.followerNotification(ctx, job, c)
+	if err != nil || build == nil {
+		return err
+	}
+
+	followers, err := s.caseRepo.GetFollowers(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+	dismissed, err := s.caseRepo.GetDismissedUserIDs(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+
+	// As with nearby volunteers, individual failures are not retried
+	notified := 0
+	for _, f := range followers {
+		if job.ActorID != nil && f.ID == *job.ActorID {
+			continue
+		}
+		if skipReporter && c.ReporterID != nil && f.ID == *c.ReporterID {
+			continue
+		}
+		if dismissed[f.ID] {
+			continue
+		}
+		if err := s.dispatcher.Dispatch(ctx, f.ID, build(f.Locale)); err != nil {
+			s.log.Warn("Failed to notify follower", zap.Error(err), zap.String("user_id", f.ID.String()))
+			continue
+		}
+		notified++
+	}
+
+	s.log.Info("Notified case followers",
+		zap.String("case_id", c.ID.String()),
+		zap.Int("count", notified),
+	)
+
+	return nil
+}
+
+// followerNotification returns how to build the notification a follower job
+// announces in a given locale, or nil if its subject has since been deleted.
+// skipReporter is set for events the reporter is already told about directly.
+func (s *caseService) followerNotification(ctx context.Context, job *caseJob, c *entity.Case) (build func(enum.Locale) *entity.NotificationPayload, skipReporter bool, err error) {
+	actorName := ""
+	if job.ActorID != nil {
+		actor, err := s.userRepo.GetByID(ctx, *job.ActorID)
+		if err != nil {
+			return nil, false, err
+		}
+		if actor != nil {
+			actorName = actor.DisplayName
+		}
+	}
+
+	if job.CommentID != nil {
+		comment, err := s.caseRepo.GetCommentByID(ctx, *job.CommentID)
+		if err != nil || comment == nil {
+			return nil, false, err
+		}
+		return func(locale enum.Locale) *entity.NotificationPayload {
+			return entity.CaseCommentNotificationPayload(c, actorName, comment.Content, locale)
+		}, false, nil
+	}
+
+	if job.UpdateID == nil {
+		return nil, false, PermanentJobError(errors.New("follower job has neither update nor comment"))
+	}
+	update, err := s.caseRepo.GetUpdateByID(ctx, *job.UpdateID)
+	if err != nil || update == nil {
+		return nil, false, err
+	}
+
+	switch {
+	case update.UpdateType == enum.UpdateTypeVolunteerJoined:
+		// The reporter gets a case accepted notification instead
+		return func(locale enum.Locale) *entity.NotificationPayload {
+			return entity.VolunteerJoinedNotificationPayload(c, actorName, locale)
+		}, true, nil
+	case update.NewStatus != nil && *update.NewStatus == enum.CaseStatusResolved:
+		return func(locale enum.Locale) *entity.NotificationPayload {
+			return entity.CaseResolvedNotificationPayload(c, locale)
+		}, false, nil
+	}
+
+	return func(locale enum.Locale) *entity.NotificationPayload {
+		content := ""
+		if update.Content != nil {
+			content = *update.Content
+		} else if update.NewStatus != nil {
+			content = entity.CaseStatusLabel(*update.NewStatus, locale)
+		}
+		return entity.CaseUpdateNotificationPayload(c, actorName, content, locale)
+	}, false, nil
 }
 
 func getIntOrDefault(ptr *int, def int) int {
@@ -654,7 +1333,17 @@ func (s *caseService) CreateComment(ctx context.Context, caseID uuid.UUID, userI
 		Content: req.Content,
 	}
 
-	if err := s.caseRepo.CreateComment(ctx, comment); err != nil {
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.caseRepo.CreateComment(ctx, comment); err != nil {
+			return err
+		}
+		return s.outboxSvc.Enqueue(ctx, JobNotifyCaseFollowers, caseJob{
+			CaseID:    caseID,
+			CommentID: &comment.ID,
+			ActorID:   &userID,
+		})
+	})
+	if err != nil {
 		s.log.Error("Failed to create comment", zap.Error(err))
 		return nil, err
 	}
@@ -0,0 +1,489 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/domain/enum"
+	"bamboo-rescue/internal/handler/dto/request"
+	"bamboo-rescue/internal/middleware"
+	"bamboo-rescue/internal/repository"
+	"go.uber.org/zap"
+)
+
+// JobAssignEventCases is the outbox job that groups the cases already
+// reported inside an event's area and time span under it
+const JobAssignEventCases = "disaster_event.assign_cases"
+
+// disasterEventJob is the payload of JobAssignEventCases
+type disasterEventJob struct {
+	EventID uuid.UUID `json:"event_id"`
+}
+
+// eventAssignBatch is how many cases assigning an event loads at a time
+const eventAssignBatch = 500
+
+// DisasterEventService defines the interface for disaster event operations
+type DisasterEventService interface {
+	Create(ctx context.Context, userID uuid.UUID, req *request.CreateDisasterEventRequest) (*entity.DisasterEvent, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.DisasterEvent, error)
+	List(ctx context.Context, req *request.GetDisasterEventsRequest) ([]entity.DisasterEvent, int64, error)
+	// Update changes an event. Only its coordinators may.
+	Update(ctx context.Context, id, userID uuid.UUID, req *request.UpdateDisasterEventRequest) (*entity.DisasterEvent, error)
+	// LinkCases groups cases under an event by hand, moving them from any
+	// other event
+	LinkCases(ctx context.Context, id, userID uuid.UUID, caseIDs []uuid.UUID) error
+	// UnlinkCase takes a case out of an event
+	UnlinkCase(ctx context.Context, id, userID, caseID uuid.UUID) error
+	GetStats(ctx context.Context, id uuid.UUID) (*entity.DisasterEventStats, error)
+	// AssignCase places a new case, before it is saved, in the active event
+	// it was reported inside, applying the event's dispatch settings
+	AssignCase(ctx context.Context, c *entity.Case) error
+	// NotifyRadiusKm returns how far from a case volunteers are notified:
+	// its event's radius if it sets one, or def
+	NotifyRadiusKm(ctx context.Context, c *entity.Case, def int) (int, error)
+}
+
+type disasterEventService struct {
+	eventRepo repository.DisasterEventRepository
+	caseRepo  repository.CaseRepository
+	userRepo  repository.UserRepository
+	tx        repository.Transactor
+	outboxSvc OutboxService
+	log       *zap.Logger
+}
+
+// NewDisasterEventService creates a new DisasterEventService
+func NewDisasterEventService(
+	eventRepo repository.DisasterEventRepository,
+	caseRepo repository.CaseRepository,
+	userRepo repository.UserRepository,
+	tx repository.Transactor,
+	outboxSvc OutboxService,
+	log *zap.Logger,
+) DisasterEventService {
+	s := &disasterEventService{
+		eventRepo: eventRepo,
+		caseRepo:  caseRepo,
+		userRepo:  userRepo,
+		tx:        tx,
+		outboxSvc: outboxSvc,
+		log:       log,
+	}
+
+	outboxSvc.Handle(JobAssignEventCases, s.assignCases)
+
+	return s
+}
+
+func (s *disasterEventService) Create(ctx context.Context, userID uuid.UUID, req *request.CreateDisasterEventRequest) (*entity.DisasterEvent, error) {
+	name := strings.TrimSpace(req.Name)
+	if n := utf8.RuneCountInString(name); n < 3 || n > 200 {
+		return nil, middleware.NewAppError("VALIDATION_ERROR", "name must be 3 to 200 characters", 400)
+	}
+	if req.Description != nil && utf8.RuneCountInString(*req.Description) > 5000 {
+		return nil, middleware.NewAppError("VALIDATION_ERROR", "description must be at most 5000 characters", 400)
+	}
+	area, err := entity.Polygon(req.Area).Normalize()
+	if err != nil {
+		return nil, middleware.NewAppError("VALIDATION_ERROR",
+			"area must have 3 to 500 points in range enclosing an area, and may not cross the antimeridian", 400)
+	}
+	startsAt := time.Now()
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+	if req.EndsAt != nil && !req.EndsAt.After(startsAt) {
+		return nil, middleware.NewAppError("VALIDATION_ERROR", "ends_at must be after starts_at", 400)
+	}
+	if err := validateDispatchSettings(req.NotifyRadiusKm, req.MaxVolunteers, 1); err != nil {
+		return nil, err
+	}
+	coordinators, err := s.coordinatorIDs(ctx, userID, req.CoordinatorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &entity.DisasterEvent{
+		ID:             uuid.New(),
+		Name:           name,
+		Description:    req.Description,
+		Status:         enum.EventStatusActive,
+		StartsAt:       startsAt,
+		EndsAt:         req.EndsAt,
+		NotifyRadiusKm: req.NotifyRadiusKm,
+		MaxVolunteers:  req.MaxVolunteers,
+		CreatedBy:      &userID,
+	}
+	event.SetArea(area)
+	event.SetCoordinators(coordinators)
+
+	// Events are often declared once the storm has hit; the cases reported
+	// since it started are grouped in the background
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.eventRepo.Create(ctx, event); err != nil {
+			return err
+		}
+		return s.outboxSvc.Enqueue(ctx, JobAssignEventCases, disasterEventJob{EventID: event.ID})
+	})
+	if err != nil {
+		s.log.Error("Failed to create disaster event", zap.Error(err))
+		return nil, err
+	}
+
+	s.log.Info("Disaster event created",
+		zap.String("event_id", event.ID.String()),
+		zap.String("created_by", userID.String()),
+		zap.Int("coordinators", len(coordinators)),
+	)
+
+	return event, nil
+}
+
+func (s *disasterEventService) GetByID(ctx context.Context, id uuid.UUID) (*entity.DisasterEvent, error) {
+	event, err := s.eventRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, middleware.ErrEventNotFound
+	}
+	return event, nil
+}
+
+func (s *disasterEventService) List(ctx context.Context, req *request.GetDisasterEventsRequest) ([]entity.DisasterEvent, int64, error) {
+	return s.eventRepo.List(ctx, req.Status, req.GetDefaultLimit(), req.GetOffset())
+}
+
+func (s *disasterEventService) Update(ctx context.Context, id, userID uuid.UUID, req *request.UpdateDisasterEventRequest) (*entity.DisasterEvent, error) {
+	event, err := s.getOwned(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A wider area, a longer span or reopening can take in more cases
+	reassign := false
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if n := utf8.RuneCountInString(name); n < 3 || n > 200 {
+			return nil, middleware.NewAppError("VALIDATION_ERROR", "name must be 3 to 200 characters", 400)
+		}
+		event.Name = name
+	}
+	if req.Description != nil {
+		if utf8.RuneCountInString(*req.Description) > 5000 {
+			return nil, middleware.NewAppError("VALIDATION_ERROR", "description must be at most 5000 characters", 400)
+		}
+		event.Description = req.Description
+	}
+	if req.Status != nil {
+		if !req.Status.IsValid() {
+			return nil, middleware.NewAppError("VALIDATION_ERROR", "status must be active or closed", 400)
+		}
+		reassign = reassign || *req.Status != event.Status
+		event.Status = *req.Status
+	}
+	if len(req.Area) > 0 {
+		area, err := entity.Polygon(req.Area).Normalize()
+		if err != nil {
+			return nil, middleware.NewAppError("VALIDATION_ERROR",
+				"area must have 3 to 500 points in range enclosing an area, and may not cross the antimeridian", 400)
+		}
+		event.SetArea(area)
+		reassign = true
+	}
+	if req.StartsAt != nil {
+		event.StartsAt = *req.StartsAt
+		reassign = true
+	}
+	if req.ClearEndsAt {
+		event.EndsAt = nil
+		reassign = true
+	} else if req.EndsAt != nil {
+		event.EndsAt = req.EndsAt
+		reassign = true
+	}
+	if event.EndsAt != nil && !event.EndsAt.After(event.StartsAt) {
+		return nil, middleware.NewAppError("VALIDATION_ERROR", "ends_at must be after starts_at", 400)
+	}
+	if err := validateDispatchSettings(req.NotifyRadiusKm, req.MaxVolunteers, 0); err != nil {
+		return nil, err
+	}
+	if req.NotifyRadiusKm != nil {
+		event.NotifyRadiusKm = nilIfZero(*req.NotifyRadiusKm)
+	}
+	if req.MaxVolunteers != nil {
+		event.MaxVolunteers = nilIfZero(*req.MaxVolunteers)
+	}
+
+	var coordinators []uuid.UUID
+	if req.CoordinatorIDs != nil {
+		// The coordinator making the change keeps ownership, so an event is
+		// never left with nobody able to manage it
+		coordinators, err = s.coordinatorIDs(ctx, userID, req.CoordinatorIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.eventRepo.Update(ctx, event); err != nil {
+			return err
+		}
+		if coordinators != nil {
+			if err := s.eventRepo.SetCoordinators(ctx, event.ID, coordinators); err != nil {
+				return err
+			}
+		}
+		// A new volunteer limit applies to the cases still open too
+		if event.MaxVolunteers != nil && req.MaxVolunteers != nil {
+			if err := s.caseRepo.SetEventMaxVolunteers(ctx, event.ID, *event.MaxVolunteers); err != nil {
+				return err
+			}
+		}
+		if !reassign || event.Status != enum.EventStatusActive {
+			return nil
+		}
+		return s.outboxSvc.Enqueue(ctx, JobAssignEventCases, disasterEventJob{EventID: event.ID})
+	})
+	if err != nil {
+		s.log.Error("Failed to update disaster event", zap.Error(err))
+		return nil, err
+	}
+	if coordinators != nil {
+		event.SetCoordinators(coordinators)
+	}
+
+	s.log.Info("Disaster event updated",
+		zap.String("event_id", event.ID.String()),
+		zap.String("updated_by", userID.String()),
+		zap.String("status", string(event.Status)),
+	)
+
+	return event, nil
+}
+
+func (s *disasterEventService) LinkCases(ctx context.Context, id, userID uuid.UUID, caseIDs []uuid.UUID) error {
+	event, err := s.getOwned(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	cases, err := s.caseRepo.GetByIDs(ctx, caseIDs)
+	if err != nil {
+		return err
+	}
+	found := make(map[uuid.UUID]bool, len(cases))
+	for i := range cases {
+		found[cases[i].ID] = true
+	}
+	for _, caseID := range caseIDs {
+		if !found[caseID] {
+			return middleware.ErrCaseNotFound
+		}
+	}
+
+	// A case is only taken from another event by one of its coordinators too
+	checked := map[uuid.UUID]bool{event.ID: true}
+	for i := range cases {
+		from := cases[i].EventID
+		if from == nil || checked[*from] {
+			continue
+		}
+		checked[*from] = true
+		if _, err := s.getOwned(ctx, *from, userID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.caseRepo.SetEvent(ctx, caseIDs, &event.ID, event.MaxVolunteers); err != nil {
+		s.log.Error("Failed to link cases to disaster event", zap.Error(err))
+		return err
+	}
+
+	s.log.Info("Linked cases to disaster event",
+		zap.String("event_id", event.ID.String()),
+		zap.String("linked_by", userID.String()),
+		zap.Int("count", len(caseIDs)),
+	)
+	return nil
+}
+
+func (s *disasterEventService) UnlinkCase(ctx context.Context, id, userID, caseID uuid.UUID) error {
+	event, err := s.getOwned(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	c, err := s.caseRepo.GetByID(ctx, caseID)
+	if err != nil {
+		return err
+	}
+	if c == nil || c.EventID == nil || *c.EventID != event.ID {
+		return middleware.ErrCaseNotFound
+	}
+
+	// The case keeps the volunteer limit the event gave it
+	return s.caseRepo.SetEvent(ctx, []uuid.UUID{caseID}, nil, nil)
+}
+
+func (s *disasterEventService) GetStats(ctx context.Context, id uuid.UUID) (*entity.DisasterEventStats, error) {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	stats, err := s.caseRepo.GetEventStats(ctx, id)
+	if err != nil {
+		s.log.Error("Failed to get disaster event stats", zap.Error(err))
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (s *disasterEventService) AssignCase(ctx context.Context, c *entity.Case) error {
+	events, err := s.eventRepo.GetActiveContaining(ctx, c.Latitude, c.Longitude, time.Now())
+	if err != nil || len(events) == 0 {
+		return err
+	}
+
+	// Where events overlap the one declared last is taken to be the more
+	// specific
+	event := &events[0]
+	c.EventID = &event.ID
+	if event.MaxVolunteers != nil {
+		c.MaxVolunteers = *event.MaxVolunteers
+	}
+	return nil
+}
+
+func (s *disasterEventService) NotifyRadiusKm(ctx context.Context, c *entity.Case, def int) (int, error) {
+	if c.EventID == nil {
+		return def, nil
+	}
+	event, err := s.eventRepo.GetByID(ctx, *c.EventID)
+	if err != nil {
+		return 0, err
+	}
+	if event == nil || event.NotifyRadiusKm == nil {
+		return def, nil
+	}
+	return *event.NotifyRadiusKm, nil
+}
+
+// getOwned loads an event for a change by one of its coordinators
+func (s *disasterEventService) getOwned(ctx context.Context, id, userID uuid.UUID) (*entity.DisasterEvent, error) {
+	event, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !event.IsCoordinator(userID) {
+		return nil, middleware.ErrEventNotCoordinator
+	}
+	return event, nil
+}
+
+// coordinatorIDs returns the owners of an event: userID and the known users
+// among ids, without repeats
+func (s *disasterEventService) coordinatorIDs(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]uuid.UUID, error) {
+	if len(ids) > 50 {
+		return nil, middleware.NewAppError("VALIDATION_ERROR", "coordinator_ids must have at most 50 users", 400)
+	}
+	coordinators := []uuid.UUID{userID}
+	seen := map[uuid.UUID]bool{userID: true}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		user, err := s.userRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, middleware.NewAppError("VALIDATION_ERROR", "coordinator_ids must be existing users", 400)
+		}
+		coordinators = append(coordinators, id)
+	}
+	return coordinators, nil
+}
+
+// validateDispatchSettings checks an event's dispatch settings, each of
+// which may be nil or at least min
+func validateDispatchSettings(notifyRadiusKm, maxVolunteers *int, min int) error {
+	if notifyRadiusKm != nil && (*notifyRadiusKm < min || *notifyRadiusKm > 100) {
+		return middleware.NewAppError("VALIDATION_ERROR", fmt.Sprintf("notify_radius_km must be %d to 100", min), 400)
+	}
+	if maxVolunteers != nil && (*maxVolunteers < min || *maxVolunteers > 50) {
+		return middleware.NewAppError("VALIDATION_ERROR", fmt.Sprintf("max_volunteers must be %d to 50", min), 400)
+	}
+	return nil
+}
+
+// nilIfZero returns nil for zero, else a pointer to v
+func nilIfZero(v int) *int {
+	if v == 0 {
+		return nil
+	}
+	return &v
+}
+
+// assignCases groups the unassigned cases reported inside an event's area and
+// time span under it. Cases are found by the area's bounding box a batch at a
+// time, then checked against the polygon.
+func (s *disasterEventService) assignCases(ctx context.Context, payload json.RawMessage) error {
+	var job disasterEventJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return PermanentJobError(err)
+	}
+
+	event, err := s.eventRepo.GetByID(ctx, job.EventID)
+	if err != nil {
+		return err
+	}
+	if event == nil || event.Status != enum.EventStatusActive {
+		return nil
+	}
+
+	// Batches already assigned are not picked up again on a retry, as they
+	// no longer lack an event
+	assigned := 0
+	afterID := uuid.Nil
+	for {
+		cases, err := s.caseRepo.FindUnassignedInBounds(ctx, *event.Bounds(), event.StartsAt, event.EndsAt, afterID, eventAssignBatch)
+		if err != nil {
+			s.log.Warn("Failed to find cases for disaster event", zap.Error(err), zap.Int("assigned", assigned))
+			return err
+		}
+
+		var ids []uuid.UUID
+		for i := range cases {
+			if event.Area.Contains(cases[i].Latitude, cases[i].Longitude) {
+				ids = append(ids, cases[i].ID)
+			}
+		}
+		if err := s.caseRepo.SetEvent(ctx, ids, &event.ID, event.MaxVolunteers); err != nil {
+			return err
+		}
+		assigned += len(ids)
+
+		if len(cases) < eventAssignBatch {
+			break
+		}
+		afterID = cases[len(cases)-1].ID
+	}
+
+	s.log.Info("Assigned cases to disaster event",
+		zap.String("event_id", event.ID.String()),
+		zap.Int("count", assigned),
+	)
+
+	return nil
+}
@@ -0,0 +1,504 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	htmltemplate "html/template"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/config"
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/domain/enum"
+	"bamboo-rescue/internal/repository"
+	"bamboo-rescue/pkg/mailer"
+	"go.uber.org/zap"
+)
+
+// Outbox job kinds for email
+const (
+	JobSendEmail  = "email.send"
+	JobSendDigest = "email.digest"
+)
+
+const (
+	// digestScanInterval is how often due digests are looked for
+	digestScanInterval = 5 * time.Minute
+	digestBatchSize    = 100
+	digestMaxCases     = 20
+	digestMaxActivity  = 30
+)
+
+//go:embed templates/email/*.tmpl
+var emailTemplateFS embed.FS
+
+// EmailService defines the interface for the email notification channel
+type EmailService interface {
+	// Notify queues payload as an email to userID, honouring their email
+	// preference. Called inside a transaction, the email commits with it.
+	Notify(ctx context.Context, userID uuid.UUID, payload *entity.NotificationPayload) error
+	// Start begins scheduling the daily digest
+	Start()
+	Shutdown(ctx context.Context) error
+}
+
+// emailJob is the payload of JobSendEmail
+type emailJob struct {
+	UserID       uuid.UUID                   `json:"user_id"`
+	Notification *entity.NotificationPayload `json:"notification"`
+}
+
+// digestJob is the payload of JobSendDigest
+type digestJob struct {
+	UserID uuid.UUID `json:"user_id"`
+	Since  time.Time `json:"since"`
+}
+
+// emailTexts holds the fixed texts of email templates in one locale
+type emailTexts struct {
+	Greeting        string
+	OpenCase        string
+	Footer          string
+	ManagePrefs     string
+	DigestSubject   string
+	DigestIntro     string
+	DigestFooter    string
+	NearbyHeading   string
+	ActivityHeading string
+}
+
+var emailTextCatalog = map[enum.Locale]emailTexts{
+	enum.LocaleVietnamese: {
+		Greeting:        "Xin chào",
+		OpenCase:        "Xem case",
+		Footer:          "Bạn nhận được email này vì đã bật thông báo qua email.",
+		ManagePrefs:     "Cài đặt thông báo",
+		DigestSubject:   "Bản tin cứu hộ hằng ngày",
+		DigestIntro:     "Đây là những gì đã diễn ra trong 24 giờ qua.",
+		DigestFooter:    "Bạn nhận được bản tin này vì đã bật bản tin hằng ngày.",
+		NearbyHeading:   "Case mới gần bạn",
+		ActivityHeading: "Hoạt động trên các case của bạn",
+	},
+	enum.LocaleEnglish: {
+		Greeting:        "Hello",
+		OpenCase:        "View case",
+		Footer:          "You are receiving this email because email notifications are on.",
+		ManagePrefs:     "Notification settings",
+		DigestSubject:   "Your daily rescue digest",
+		DigestIntro:     "Here is what happened in the last 24 hours.",
+		DigestFooter:    "You are receiving this digest because the daily digest is on.",
+		NearbyHeading:   "New cases near you",
+		ActivityHeading: "Activity on your cases",
+	},
+}
+
+type emailTemplates struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+type digestCase struct {
+	Title    string
+	Urgency  enum.UrgencyLevel
+	Distance string
+	Link     string
+}
+
+type digestActivity struct {
+	CaseTitle string
+	Content   string
+	When      string
+	Link      string
+}
+
+type emailService struct {
+	cfg       config.EmailConfig
+	mailer    mailer.Mailer
+	userRepo  repository.UserRepository
+	caseRepo  repository.CaseRepository
+	tx        repository.Transactor
+	outboxSvc OutboxService
+	log       *zap.Logger
+	enabled   bool
+
+	location  *time.Location
+	templates map[string]emailTemplates
+
+	mu      sync.Mutex
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// NewEmailService creates a new EmailService. Email is disabled when no SMTP
+// host is configured.
+func NewEmailService(
+	cfg *config.Config,
+	m mailer.Mailer,
+	userRepo repository.UserRepository,
+	caseRepo repository.CaseRepository,
+	tx repository.Transactor,
+	outboxSvc OutboxService,
+	log *zap.Logger,
+) (EmailService, error) {
+	s := &emailService{
+		cfg:       cfg.Email,
+		mailer:    m,
+		userRepo:  userRepo,
+		caseRepo:  caseRepo,
+		tx:        tx,
+		outboxSvc: outboxSvc,
+		log:       log,
+		enabled:   m != nil && cfg.Email.SMTPHost != "",
+		location:  time.UTC,
+		templates: make(map[string]emailTemplates),
+	}
+
+	if loc, err := time.LoadLocation(cfg.Email.DigestTimezone); err == nil {
+		s.location = loc
+	} else {
+		log.Warn("Unknown digest timezone, using UTC", zap.String("timezone", cfg.Email.DigestTimezone))
+	}
+
+	for _, name := range []string{"notification", "digest"} {
+		html, err := htmltemplate.ParseFS(emailTemplateFS, "templates/email/"+name+".html.tmpl")
+		if err != nil {
+			return nil, err
+		}
+		text, err := texttemplate.ParseFS(emailTemplateFS, "templates/email/"+name+".txt.tmpl")
+		if err != nil {
+			return nil, err
+		}
+		s.templates[name] = emailTemplates{html: html, text: text}
+	}
+
+	outboxSvc.Handle(JobSendEmail, s.sendNotification)
+	outboxSvc.Handle(JobSendDigest, s.sendDigest)
+
+	if !s.enabled {
+		log.Warn("SMTP host not configured, email notifications disabled")
+	}
+
+	return s, nil
+}
+
+func (s *emailService) Notify(ctx context.Context, userID uuid.UUID, payload *entity.NotificationPayload) error {
+	if !s.enabled {
+		return nil
+	}
+	return s.outboxSvc.Enqueue(ctx, JobSendEmail, emailJob{UserID: userID, Notification: payload})
+}
+
+func (s *emailService) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.enabled || s.stop != nil {
+		return
+	}
+
+	s.stop = make(chan struct{})
+	s.stopped = make(chan struct{})
+	go s.scheduleDigests()
+
+	s.log.Info("Email digest scheduler started",
+		zap.Int("hour", s.cfg.DigestHour),
+		zap.String("timezone", s.location.String()),
+	)
+}
+
+func (s *emailService) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	stop, stopped := s.stop, s.stopped
+	s.stop = nil
+	s.mu.Unlock()
+	if stop == nil {
+		return nil
+	}
+
+	close(stop)
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// scheduleDigests queues due digests now and then on every scan interval
+func (s *emailService) scheduleDigests() {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(digestScanInterval)
+	defer ticker.Stop()
+
+	for {
+		s.queueDueDigests()
+
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lastDigestTime returns the most recent scheduled digest time at or before now
+func (s *emailService) lastDigestTime(now time.Time) time.Time {
+	local := now.In(s.location)
+	scheduled := time.Date(local.Year(), local.Month(), local.Day(), s.cfg.DigestHour, 0, 0, 0, s.location)
+	if scheduled.After(local) {
+		scheduled = scheduled.AddDate(0, 0, -1)
+	}
+	return scheduled
+}
+
+// queueDueDigests queues one digest job per user who has not had one since
+// the last scheduled time. Marking and queueing share a transaction, so each
+// user gets one digest per day however many instances are running.
+func (s *emailService) queueDueDigests() {
+	ctx := context.Background()
+	now := time.Now()
+	dueBefore := s.lastDigestTime(now)
+
+	queued := 0
+	for {
+		users, err := s.userRepo.FindDigestRecipients(ctx, dueBefore, digestBatchSize)
+		if err != nil {
+			s.log.Error("Failed to find digest recipients", zap.Error(err))
+			return
+		}
+
+		for _, u := range users {
+			since := now.Add(-24 * time.Hour)
+			if u.Preferences != nil && u.Preferences.LastDigestAt != nil && u.Preferences.LastDigestAt.After(since) {
+				since = *u.Preferences.LastDigestAt
+			}
+
+			err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+				marked, err := s.userRepo.MarkDigestQueued(ctx, u.ID, dueBefore, now)
+				if err != nil || !marked {
+					return err
+				}
+				return s.outboxSvc.Enqueue(ctx, JobSendDigest, digestJob{UserID: u.ID, Since: since})
+			})
+			if err != nil {
+				s.log.Error("Failed to queue digest", zap.String("user_id", u.ID.String()), zap.Error(err))
+				return
+			}
+			queued++
+		}
+
+		if len(users) < digestBatchSize {
+			break
+		}
+	}
+
+	if queued > 0 {
+		s.log.Info("Queued daily digests", zap.Int("count", queued))
+	}
+}
+
+// recipient loads a user who should receive email, or nil if they should not
+func (s *emailService) recipient(ctx context.Context, userID uuid.UUID) (*entity.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsActive || user.Email == nil || *user.Email == "" {
+		return nil, nil
+	}
+	if user.Preferences != nil && !user.Preferences.EmailEnabled {
+		return nil, nil
+	}
+	return user, nil
+}
+
+func (s *emailService) sendNotification(ctx context.Context, payload json.RawMessage) error {
+	var job emailJob
+	if err := json.Unmarshal(payload, &job); err != nil || job.Notification == nil {
+		return PermanentJobError(err)
+	}
+
+	user, err := s.recipient(ctx, job.UserID)
+	if err != nil || user == nil {
+		return err
+	}
+
+	n := job.Notification
+	link := ""
+	if n.CaseID != nil {
+		link = s.caseLink(*n.CaseID)
+	}
+
+	locale := user.Locale.OrDefault()
+	data := map[string]interface{}{
+		"Locale":    locale,
+		"T":         emailTextCatalog[locale],
+		"Name":      user.DisplayName,
+		"Title":     n.Title,
+		"Body":      n.Body,
+		"Link":      link,
+		"PrefsLink": s.link("settings/notifications"),
+	}
+
+	return s.send(ctx, user, n.Title, "notification", data)
+}
+
+func (s *emailService) sendDigest(ctx context.Context, payload json.RawMessage) error {
+	var job digestJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return PermanentJobError(err)
+	}
+
+	user, err := s.recipient(ctx, job.UserID)
+	if err != nil || user == nil {
+		return err
+	}
+	if user.Preferences != nil && !user.Preferences.EmailDigestEnabled {
+		return nil
+	}
+	locale := user.Locale.OrDefault()
+
+	cases, err := s.digestCases(ctx, user, job.Since, locale)
+	if err != nil {
+		return err
+	}
+
+	activity, err := s.caseRepo.GetActivityForUser(ctx, user.ID, job.Since, digestMaxActivity)
+	if err != nil {
+		return err
+	}
+
+	if len(cases) == 0 && len(activity) == 0 {
+		// Nothing happened; an empty digest is just noise
+		return nil
+	}
+
+	items := make([]digestActivity, 0, len(activity))
+	for _, a := range activity {
+		items = append(items, digestActivity{
+			CaseTitle: a.CaseTitle,
+			Content:   activityText(&a),
+			When:      a.CreatedAt.In(s.location).Format("15:04 02/01"),
+			Link:      s.caseLink(a.CaseID),
+		})
+	}
+
+	texts := emailTextCatalog[locale]
+	data := map[string]interface{}{
+		"Locale":    locale,
+		"T":         texts,
+		"Name":      user.DisplayName,
+		"Cases":     cases,
+		"Activity":  items,
+		"PrefsLink": s.link("settings/notifications"),
+	}
+
+	return s.send(ctx, user, texts.DigestSubject, "digest", data)
+}
+
+// digestCases lists open cases created since since within the user's
+// notification area
+func (s *emailService) digestCases(ctx context.Context, user *entity.User, since time.Time, locale enum.Locale) ([]digestCase, error) {
+	prefs := user.Preferences
+	if prefs == nil {
+		return nil, nil
+	}
+
+	center := user.GetLocation()
+	if !prefs.UseCurrentLocation || center == nil {
+		if c := prefs.GetCenterLocation(); c != nil {
+			center = c
+		}
+	}
+	if center == nil {
+		return nil, nil
+	}
+
+	types := make([]enum.CaseType, len(prefs.CaseTypes))
+	for i, t := range prefs.CaseTypes {
+		types[i] = enum.CaseType(t)
+	}
+
+	nearby, err := s.caseRepo.GetNearby(ctx, center.Latitude, center.Longitude, prefs.NotificationRadiusKm, types, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var cases []digestCase
+	for _, c := range nearby {
+		if c.CreatedAt.Before(since) {
+			continue
+		}
+		cases = append(cases, digestCase{
+			Title:    c.Title,
+			Urgency:  c.Urgency,
+			Distance: entity.FormatDistance(c.DistanceKm, locale),
+			Link:     s.caseLink(c.ID),
+		})
+		if len(cases) == digestMaxCases {
+			break
+		}
+	}
+	return cases, nil
+}
+
+// send renders the named template pair and mails it to user. SMTP rejections
+// of the message itself are not retried.
+func (s *emailService) send(ctx context.Context, user *entity.User, subject, name string, data map[string]interface{}) error {
+	tmpl := s.templates[name]
+
+	var html, text bytes.Buffer
+	if err := tmpl.html.Execute(&html, data); err != nil {
+		return PermanentJobError(err)
+	}
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return PermanentJobError(err)
+	}
+
+	err := s.mailer.Send(ctx, &mailer.Message{
+		To:      *user.Email,
+		ToName:  user.DisplayName,
+		Subject: subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+	})
+	if err != nil {
+		s.log.Warn("Failed to send email", zap.String("user_id", user.ID.String()), zap.Error(err))
+		if mailer.IsPermanent(err) {
+			return PermanentJobError(err)
+		}
+		return err
+	}
+
+	s.log.Debug("Email sent", zap.String("user_id", user.ID.String()), zap.String("template", name))
+	return nil
+}
+
+func (s *emailService) link(path string) string {
+	return strings.TrimSuffix(s.cfg.LinkBase, "/") + "/" + path
+}
+
+func (s *emailService) caseLink(caseID uuid.UUID) string {
+	return s.link("cases/" + caseID.String())
+}
+
+// activityText describes a case update for the digest
+func activityText(a *repository.CaseActivity) string {
+	if a.Content != nil && *a.Content != "" {
+		return *a.Content
+	}
+
+	text := ""
+	if a.ActorName != nil {
+		text = *a.ActorName + ": "
+	}
+	if a.NewStatus != nil {
+		return text + string(*a.NewStatus)
+	}
+	return text + string(a.UpdateType)
+}
@@ -3,12 +3,15 @@ package service
 import (
 	"context"
 	"errors"
+	"time"
 
 	firebase "firebase.google.com/go/v4"
+	"firebase.google.com/go/v4/errorutils"
 	"firebase.google.com/go/v4/messaging"
 	"github.com/google/uuid"
 	"bamboo-rescue/internal/config"
 	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/domain/enum"
 	"bamboo-rescue/internal/repository"
 	"go.uber.org/zap"
 	"google.golang.org/api/option"
@@ -22,19 +25,40 @@ type FCMService interface {
 	SendToTokens(ctx context.Context, tokens []string, notification *entity.NotificationPayload) error
 }
 
+// MessagingClient is the subset of the FCM client the service uses, so a
+// fake can stand in for Firebase
+type MessagingClient interface {
+	Send(ctx context.Context, message *messaging.Message) (string, error)
+	SendEachForMulticast(ctx context.Context, message *messaging.MulticastMessage) (*messaging.BatchResponse, error)
+}
+
+// Reasons recorded when a push token is deactivated
+const (
+	tokenReasonUnregistered     = "unregistered"
+	tokenReasonInvalid          = "invalid"
+	tokenReasonSenderIDMismatch = "sender_id_mismatch"
+	tokenReasonExpired          = "expired"
+)
+
 type fcmService struct {
-	client   *messaging.Client
-	userRepo repository.UserRepository
-	log      *zap.Logger
-	enabled  bool
+	client     MessagingClient
+	userRepo   repository.UserRepository
+	log        *zap.Logger
+	enabled    bool
+	maxRetries int
+	retryDelay time.Duration
+	linkBase   string
 }
 
 // NewFCMService creates a new FCMService
 func NewFCMService(cfg *config.Config, userRepo repository.UserRepository, log *zap.Logger) (FCMService, error) {
 	svc := &fcmService{
-		userRepo: userRepo,
-		log:      log,
-		enabled:  false,
+		userRepo:   userRepo,
+		log:        log,
+		enabled:    false,
+		maxRetries: cfg.FCM.MaxRetries,
+		retryDelay: cfg.FCM.RetryBaseDelay,
+		linkBase:   cfg.FCM.DeepLinkBase,
 	}
 
 	if cfg.FCM.CredentialsFile == "" {
@@ -62,6 +86,19 @@ func NewFCMService(cfg *config.Config, userRepo repository.UserRepository, log *
 	return svc, nil
 }
 
+// NewFCMServiceWithClient creates an FCMService that sends through client
+func NewFCMServiceWithClient(cfg *config.Config, client MessagingClient, userRepo repository.UserRepository, log *zap.Logger) FCMService {
+	return &fcmService{
+		client:     client,
+		userRepo:   userRepo,
+		log:        log,
+		enabled:    true,
+		maxRetries: cfg.FCM.MaxRetries,
+		retryDelay: cfg.FCM.RetryBaseDelay,
+		linkBase:   cfg.FCM.DeepLinkBase,
+	}
+}
+
 func ctx() context.Context {
 	return context.Background()
 }
@@ -83,9 +120,15 @@ func (s *fcmService) SendToUser(ctx context.Context, userID uuid.UUID, notificat
 		return nil
 	}
 
-	tokenStrings := make([]string, len(tokens))
-	for i, t := range tokens {
-		tokenStrings[i] = t.Token
+	// Browser subscriptions are delivered by Web Push, not FCM
+	var tokenStrings []string
+	for _, t := range tokens {
+		if t.Platform != enum.DevicePlatformWeb {
+			tokenStrings = append(tokenStrings, t.Token)
+		}
+	}
+	if len(tokenStrings) == 0 {
+		return nil
 	}
 
 	return s.SendToTokens(ctx, tokenStrings, notification)
@@ -105,7 +148,9 @@ func (s *fcmService) SendToUsers(ctx context.Context, userIDs []uuid.UUID, notif
 			continue
 		}
 		for _, t := range tokens {
-			allTokens = append(allTokens, t.Token)
+			if t.Platform != enum.DevicePlatformWeb {
+				allTokens = append(allTokens, t.Token)
+			}
 		}
 	}
 
@@ -122,31 +167,28 @@ func (s *fcmService) SendToToken(ctx context.Context, token string, notification
 	}
 
 	message := &messaging.Message{
-		Token: token,
-		Notification: &messaging.Notification{
-			Title: notification.Title,
-			Body:  notification.Body,
-		},
-		Data: notification.Data,
-		Android: &messaging.AndroidConfig{
-			Priority: "high",
-			Notification: &messaging.AndroidNotification{
-				Sound:       "default",
-				ClickAction: "FLUTTER_NOTIFICATION_CLICK",
-			},
-		},
-		APNS: &messaging.APNSConfig{
-			Payload: &messaging.APNSPayload{
-				Aps: &messaging.Aps{
-					Sound:            "default",
-					ContentAvailable: true,
-				},
-			},
-		},
+		Token:        token,
+		Notification: buildNotification(notification),
+		Data:         notification.ToData(s.linkBase),
+		Android:      androidConfig(notification),
+		APNS:         apnsConfig(notification),
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		_, err = s.client.Send(ctx, message)
+		if err == nil || !isTransientFCMError(err) || attempt >= s.maxRetries {
+			break
+		}
+		if !s.backoff(ctx, attempt) {
+			break
+		}
 	}
 
-	_, err := s.client.Send(ctx, message)
 	if err != nil {
+		if reason, ok := deadTokenReason(err, false); ok {
+			s.deactivateTokens(ctx, []string{token}, reason)
+		}
 		s.log.Error("Failed to send FCM message", zap.Error(err))
 		return err
 	}
@@ -171,44 +213,168 @@ func (s *fcmService) SendToTokens(ctx context.Context, tokens []string, notifica
 			end = len(tokens)
 		}
 
-		batch := tokens[i:end]
+		s.sendBatch(ctx, tokens[i:end], notification)
+	}
+
+	return nil
+}
+
+// sendBatch sends one multicast, retrying tokens that failed transiently and
+// deactivating tokens FCM reports as dead
+func (s *fcmService) sendBatch(ctx context.Context, batch []string, notification *entity.NotificationPayload) {
+	dead := make(map[string][]string)
+	var success, failure int
+
+	pending := batch
+	for attempt := 0; len(pending) > 0; attempt++ {
+		if attempt > 0 && !s.backoff(ctx, attempt-1) {
+			break
+		}
+
 		message := &messaging.MulticastMessage{
-			Tokens: batch,
-			Notification: &messaging.Notification{
-				Title: notification.Title,
-				Body:  notification.Body,
-			},
-			Data: notification.Data,
-			Android: &messaging.AndroidConfig{
-				Priority: "high",
-				Notification: &messaging.AndroidNotification{
-					Sound:       "default",
-					ClickAction: "FLUTTER_NOTIFICATION_CLICK",
-				},
-			},
-			APNS: &messaging.APNSConfig{
-				Payload: &messaging.APNSPayload{
-					Aps: &messaging.Aps{
-						Sound:            "default",
-						ContentAvailable: true,
-					},
-				},
-			},
+			Tokens:       pending,
+			Notification: buildNotification(notification),
+			Data:         notification.ToData(s.linkBase),
+			Android:      androidConfig(notification),
+			APNS:         apnsConfig(notification),
 		}
 
 		response, err := s.client.SendEachForMulticast(ctx, message)
 		if err != nil {
+			if isTransientFCMError(err) && attempt < s.maxRetries {
+				continue
+			}
 			s.log.Error("Failed to send multicast FCM message", zap.Error(err))
-			continue
+			failure += len(pending)
+			break
 		}
 
-		if response.FailureCount > 0 {
-			s.log.Warn("Some FCM messages failed",
-				zap.Int("success", response.SuccessCount),
-				zap.Int("failure", response.FailureCount),
-			)
+		// Invalid-argument only condemns a token when the payload itself
+		// was accepted for some other token
+		payloadOK := response.SuccessCount > 0 || success > 0
+
+		var retry []string
+		for j, r := range response.Responses {
+			if j >= len(pending) {
+				break
+			}
+			token := pending[j]
+
+			switch {
+			case r.Success:
+				success++
+			case isTransientFCMError(r.Error) && attempt < s.maxRetries:
+				retry = append(retry, token)
+			default:
+				failure++
+				if reason, ok := deadTokenReason(r.Error, payloadOK); ok {
+					dead[reason] = append(dead[reason], token)
+				}
+			}
 		}
+		pending = retry
 	}
 
-	return nil
+	for reason, tokens := range dead {
+		s.deactivateTokens(ctx, tokens, reason)
+	}
+
+	if failure > 0 {
+		s.log.Warn("Some FCM messages failed",
+			zap.Int("success", success),
+			zap.Int("failure", failure),
+		)
+	}
+}
+
+// backoff waits before retry attempt+1, doubling the delay each time. It
+// returns false if the context ends first.
+func (s *fcmService) backoff(ctx context.Context, attempt int) bool {
+	delay := s.retryDelay << attempt
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (s *fcmService) deactivateTokens(ctx context.Context, tokens []string, reason string) {
+	if err := s.userRepo.DeactivatePushTokens(context.WithoutCancel(ctx), tokens, reason); err != nil {
+		s.log.Warn("Failed to deactivate push tokens", zap.Int("count", len(tokens)), zap.Error(err))
+		return
+	}
+
+	s.log.Info("Deactivated dead push tokens",
+		zap.Int("count", len(tokens)),
+		zap.String("reason", reason),
+	)
+}
+
+// isTransientFCMError reports whether a send may succeed if retried
+func isTransientFCMError(err error) bool {
+	return messaging.IsUnavailable(err) ||
+		messaging.IsInternal(err) ||
+		messaging.IsQuotaExceeded(err) ||
+		errorutils.IsUnavailable(err) ||
+		errorutils.IsInternal(err) ||
+		errorutils.IsDeadlineExceeded(err)
+}
+
+// deadTokenReason reports whether err means the token will never work again
+func deadTokenReason(err error, payloadOK bool) (string, bool) {
+	switch {
+	case messaging.IsUnregistered(err):
+		return tokenReasonUnregistered, true
+	case messaging.IsSenderIDMismatch(err):
+		return tokenReasonSenderIDMismatch, true
+	case payloadOK && messaging.IsInvalidArgument(err):
+		return tokenReasonInvalid, true
+	default:
+		return "", false
+	}
+}
+
+func buildNotification(notification *entity.NotificationPayload) *messaging.Notification {
+	return &messaging.Notification{
+		Title: notification.Title,
+		Body:  notification.Body,
+	}
+}
+
+// androidConfig sets the collapse key both for undelivered messages and, as
+// the tag, for notifications already on screen
+func androidConfig(notification *entity.NotificationPayload) *messaging.AndroidConfig {
+	key := notification.CollapseKey()
+	return &messaging.AndroidConfig{
+		Priority:    "high",
+		CollapseKey: key,
+		Notification: &messaging.AndroidNotification{
+			Sound:       "default",
+			ClickAction: "FLUTTER_NOTIFICATION_CLICK",
+			Tag:         key,
+		},
+	}
+}
+
+func apnsConfig(notification *entity.NotificationPayload) *messaging.APNSConfig {
+	aps := &messaging.Aps{
+		Sound:            "default",
+		ContentAvailable: true,
+		Badge:            notification.Badge,
+	}
+	if notification.HasActions() {
+		aps.Category = entity.CaseActionsCategory
+	}
+	return &messaging.APNSConfig{
+		Headers: map[string]string{
+			"apns-collapse-id": notification.CollapseKey(),
+		},
+		Payload: &messaging.APNSPayload{
+			Aps: aps,
+		},
+	}
 }
@@ -0,0 +1,421 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	firebase "firebase.google.com/go/v4"
+	"firebase.google.com/go/v4/messaging"
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/config"
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/domain/enum"
+	"bamboo-rescue/internal/repository"
+	"go.uber.org/zap"
+	"google.golang.org/api/option"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fcmError returns the error the Firebase SDK gives for an FCM v1 response
+// carrying code. The SDK's error type is internal, so the test client is
+// pointed at a server that answers with that error.
+func fcmError(t *testing.T, code string) error {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// 400 keeps the SDK from retrying on its own
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"error":{"code":400,"message":"%[1]s","status":"%[1]s","details":[`+
+			`{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"%[1]s"}]}}`, code)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	app, err := firebase.NewApp(ctx, &firebase.Config{ProjectID: "bamboo-rescue-test"},
+		option.WithEndpoint(srv.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("firebase.NewApp() error = %v", err)
+	}
+	client, err := app.Messaging(ctx)
+	if err != nil {
+		t.Fatalf("app.Messaging() error = %v", err)
+	}
+
+	_, err = client.Send(ctx, &messaging.Message{Token: "probe"})
+	if err == nil {
+		t.Fatalf("Send() to the %s server succeeded", code)
+	}
+	return err
+}
+
+// fakeMessaging answers each token from a script of per-attempt errors; a
+// token with no errors left is delivered
+type fakeMessaging struct {
+	// callErrs fail whole multicast calls, one per call, before any token
+	// is tried
+	callErrs []error
+	tokens   map[string][]error
+
+	calls     [][]string
+	callTimes []time.Time
+}
+
+func (f *fakeMessaging) next(token string) error {
+	errs := f.tokens[token]
+	if len(errs) == 0 {
+		return nil
+	}
+	f.tokens[token] = errs[1:]
+	return errs[0]
+}
+
+func (f *fakeMessaging) record(tokens ...string) {
+	f.calls = append(f.calls, tokens)
+	f.callTimes = append(f.callTimes, time.Now())
+}
+
+func (f *fakeMessaging) Send(ctx context.Context, message *messaging.Message) (string, error) {
+	f.record(message.Token)
+	if err := f.next(message.Token); err != nil {
+		return "", err
+	}
+	return "projects/bamboo-rescue-test/messages/1", nil
+}
+
+func (f *fakeMessaging) SendEachForMulticast(ctx context.Context, message *messaging.MulticastMessage) (*messaging.BatchResponse, error) {
+	f.record(append([]string(nil), message.Tokens...)...)
+	if len(f.callErrs) > 0 {
+		err := f.callErrs[0]
+		f.callErrs = f.callErrs[1:]
+		return nil, err
+	}
+
+	response := &messaging.BatchResponse{}
+	for _, token := range message.Tokens {
+		if err := f.next(token); err != nil {
+			response.FailureCount++
+			response.Responses = append(response.Responses, &messaging.SendResponse{Error: err})
+			continue
+		}
+		response.SuccessCount++
+		response.Responses = append(response.Responses, &messaging.SendResponse{Success: true})
+	}
+	return response, nil
+}
+
+// fcmUserRepo serves push tokens and records the ones deactivated, by reason
+type fcmUserRepo struct {
+	repository.UserRepository
+	pushTokens  []entity.PushToken
+	deactivated map[string][]string
+}
+
+func (r *fcmUserRepo) GetPushTokens(ctx context.Context, userID uuid.UUID) ([]entity.PushToken, error) {
+	return r.pushTokens, nil
+}
+
+func (r *fcmUserRepo) DeactivatePushTokens(ctx context.Context, tokens []string, reason string) error {
+	if r.deactivated == nil {
+		r.deactivated = make(map[string][]string)
+	}
+	r.deactivated[reason] = append(r.deactivated[reason], tokens...)
+	return nil
+}
+
+func newTestFCMService(client MessagingClient, userRepo repository.UserRepository, maxRetries int, delay time.Duration) FCMService {
+	cfg := &config.Config{FCM: config.FCMConfig{
+		MaxRetries:     maxRetries,
+		RetryBaseDelay: delay,
+		DeepLinkBase:   "bamboorescue://",
+	}}
+	return NewFCMServiceWithClient(cfg, client, userRepo, zap.NewNop())
+}
+
+func testPushPayload() *entity.NotificationPayload {
+	return &entity.NotificationPayload{Type: enum.NotificationTypeSystem, Title: "Title", Body: "Body"}
+}
+
+func TestFCMDeactivatesDeadTokens(t *testing.T) {
+	unregistered := fcmError(t, "UNREGISTERED")
+	mismatch := fcmError(t, "SENDER_ID_MISMATCH")
+	invalid := fcmError(t, "INVALID_ARGUMENT")
+	unavailable := fcmError(t, "UNAVAILABLE")
+
+	tests := []struct {
+		name   string
+		tokens []string
+		errs   map[string][]error
+		want   map[string][]string
+	}{
+		{
+			name:   "unregistered token",
+			tokens: []string{"ok", "gone"},
+			errs:   map[string][]error{"gone": {unregistered}},
+			want:   map[string][]string{tokenReasonUnregistered: {"gone"}},
+		},
+		{
+			name:   "sender id mismatch",
+			tokens: []string{"ok", "foreign"},
+			errs:   map[string][]error{"foreign": {mismatch}},
+			want:   map[string][]string{tokenReasonSenderIDMismatch: {"foreign"}},
+		},
+		{
+			name:   "invalid argument when another token took the payload",
+			tokens: []string{"ok", "bad"},
+			errs:   map[string][]error{"bad": {invalid}},
+			want:   map[string][]string{tokenReasonInvalid: {"bad"}},
+		},
+		{
+			name:   "invalid argument for every token blames the payload",
+			tokens: []string{"bad1", "bad2"},
+			errs:   map[string][]error{"bad1": {invalid}, "bad2": {invalid}},
+		},
+		{
+			name:   "transient failures keep the token",
+			tokens: []string{"ok", "busy"},
+			errs:   map[string][]error{"busy": {unavailable, unavailable, unavailable}},
+		},
+		{
+			name:   "each reason recorded separately",
+			tokens: []string{"ok", "gone", "foreign", "bad"},
+			errs:   map[string][]error{"gone": {unregistered}, "foreign": {mismatch}, "bad": {invalid}},
+			want: map[string][]string{
+				tokenReasonUnregistered:     {"gone"},
+				tokenReasonSenderIDMismatch: {"foreign"},
+				tokenReasonInvalid:          {"bad"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeMessaging{tokens: tt.errs}
+			users := &fcmUserRepo{}
+			svc := newTestFCMService(client, users, 2, time.Millisecond)
+
+			if err := svc.SendToTokens(context.Background(), tt.tokens, testPushPayload()); err != nil {
+				t.Fatalf("SendToTokens() error = %v", err)
+			}
+
+			for _, tokens := range users.deactivated {
+				sort.Strings(tokens)
+			}
+			if len(tt.want) == 0 && len(users.deactivated) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(users.deactivated, tt.want) {
+				t.Fatalf("deactivated = %v, want %v", users.deactivated, tt.want)
+			}
+		})
+	}
+}
+
+func TestFCMSendToTokenDeactivatesUnregistered(t *testing.T) {
+	client := &fakeMessaging{tokens: map[string][]error{"gone": {fcmError(t, "UNREGISTERED")}}}
+	users := &fcmUserRepo{}
+	svc := newTestFCMService(client, users, 2, time.Millisecond)
+
+	if err := svc.SendToToken(context.Background(), "gone", testPushPayload()); err == nil {
+		t.Fatal("SendToToken() error = nil, want the FCM error")
+	}
+	if len(client.calls) != 1 {
+		t.Fatalf("sent %d times, want 1", len(client.calls))
+	}
+	want := map[string][]string{tokenReasonUnregistered: {"gone"}}
+	if !reflect.DeepEqual(users.deactivated, want) {
+		t.Fatalf("deactivated = %v, want %v", users.deactivated, want)
+	}
+}
+
+func TestFCMRetriesTransientErrors(t *testing.T) {
+	const delay = 5 * time.Millisecond
+	unavailable := fcmError(t, "UNAVAILABLE")
+	internal := fcmError(t, "INTERNAL")
+
+	tests := []struct {
+		name       string
+		maxRetries int
+		errs       map[string][]error
+		// wantCalls is the tokens sent in each multicast call
+		wantCalls [][]string
+	}{
+		{
+			name:       "only the failed tokens are retried",
+			maxRetries: 3,
+			errs:       map[string][]error{"busy": {unavailable, internal}},
+			wantCalls:  [][]string{{"ok", "busy"}, {"busy"}, {"busy"}},
+		},
+		{
+			name:       "retries stop at the limit",
+			maxRetries: 2,
+			errs:       map[string][]error{"busy": {unavailable, unavailable, unavailable, unavailable}},
+			wantCalls:  [][]string{{"ok", "busy"}, {"busy"}, {"busy"}},
+		},
+		{
+			name:       "no retries configured",
+			maxRetries: 0,
+			errs:       map[string][]error{"busy": {unavailable}},
+			wantCalls:  [][]string{{"ok", "busy"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeMessaging{tokens: tt.errs}
+			users := &fcmUserRepo{}
+			svc := newTestFCMService(client, users, tt.maxRetries, delay)
+
+			if err := svc.SendToTokens(context.Background(), []string{"ok", "busy"}, testPushPayload()); err != nil {
+				t.Fatalf("SendToTokens() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(client.calls, tt.wantCalls) {
+				t.Fatalf("calls = %v, want %v", client.calls, tt.wantCalls)
+			}
+			// Each retry waits twice as long as the one before
+			for i := 1; i < len(client.callTimes); i++ {
+				wait := delay << (i - 1)
+				if gap := client.callTimes[i].Sub(client.callTimes[i-1]); gap < wait {
+					t.Fatalf("retry %d after %v, want at least %v", i, gap, wait)
+				}
+			}
+			if len(users.deactivated) != 0 {
+				t.Fatalf("deactivated = %v, want none", users.deactivated)
+			}
+		})
+	}
+}
+
+func TestFCMSendToTokenRetriesTransientErrors(t *testing.T) {
+	unavailable := fcmError(t, "UNAVAILABLE")
+	client := &fakeMessaging{tokens: map[string][]error{"busy": {unavailable, unavailable}}}
+	svc := newTestFCMService(client, &fcmUserRepo{}, 3, time.Millisecond)
+
+	if err := svc.SendToToken(context.Background(), "busy", testPushPayload()); err != nil {
+		t.Fatalf("SendToToken() error = %v", err)
+	}
+	if len(client.calls) != 3 {
+		t.Fatalf("sent %d times, want 3", len(client.calls))
+	}
+}
+
+func TestFCMMulticastCallError(t *testing.T) {
+	unavailable := fcmError(t, "UNAVAILABLE")
+	invalid := fcmError(t, "INVALID_ARGUMENT")
+
+	tests := []struct {
+		name       string
+		maxRetries int
+		callErrs   []error
+		wantCalls  int
+	}{
+		{
+			name:       "transient call error is retried",
+			maxRetries: 2,
+			callErrs:   []error{unavailable},
+			wantCalls:  2,
+		},
+		{
+			name:       "transient call errors give up at the limit",
+			maxRetries: 2,
+			callErrs:   []error{unavailable, unavailable, unavailable},
+			wantCalls:  3,
+		},
+		{
+			name:       "permanent call error is not retried",
+			maxRetries: 2,
+			callErrs:   []error{invalid},
+			wantCalls:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeMessaging{callErrs: tt.callErrs, tokens: map[string][]error{}}
+			users := &fcmUserRepo{}
+			svc := newTestFCMService(client, users, tt.maxRetries, time.Millisecond)
+
+			tokens := []string{"a", "b"}
+			if err := svc.SendToTokens(context.Background(), tokens, testPushPayload()); err != nil {
+				t.Fatalf("SendToTokens() error = %v", err)
+			}
+
+			if len(client.calls) != tt.wantCalls {
+				t.Fatalf("made %d calls, want %d", len(client.calls), tt.wantCalls)
+			}
+			for i, call := range client.calls {
+				if !reflect.DeepEqual(call, tokens) {
+					t.Fatalf("call %d sent %v, want the whole batch %v", i, call, tokens)
+				}
+			}
+			// A failed call says nothing about the tokens themselves
+			if len(users.deactivated) != 0 {
+				t.Fatalf("deactivated = %v, want none", users.deactivated)
+			}
+		})
+	}
+}
+
+func TestFCMSendToUserSkipsWebTokens(t *testing.T) {
+	client := &fakeMessaging{tokens: map[string][]error{}}
+	users := &fcmUserRepo{pushTokens: []entity.PushToken{
+		{Token: "android", Platform: enum.DevicePlatformAndroid, IsActive: true},
+		{Token: "https://push.example/sub", Platform: enum.DevicePlatformWeb, IsActive: true},
+		{Token: "ios", Platform: enum.DevicePlatformIOS, IsActive: true},
+	}}
+	svc := newTestFCMService(client, users, 0, time.Millisecond)
+
+	if err := svc.SendToUser(context.Background(), uuid.New(), testPushPayload()); err != nil {
+		t.Fatalf("SendToUser() error = %v", err)
+	}
+	want := [][]string{{"android", "ios"}}
+	if !reflect.DeepEqual(client.calls, want) {
+		t.Fatalf("calls = %v, want %v", client.calls, want)
+	}
+}
+
+func TestGetPushTokensOnlyActive(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1 sslmode=disable"}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	var stmt *gorm.Statement
+	err = db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		stmt = tx.Statement
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	userID := uuid.New()
+	if _, err := repository.NewUserRepository(db).GetPushTokens(context.Background(), userID); err != nil {
+		t.Fatalf("GetPushTokens() error = %v", err)
+	}
+	if stmt == nil {
+		t.Fatal("GetPushTokens() ran no query")
+	}
+
+	sql := stmt.SQL.String()
+	if !strings.Contains(sql, "is_active = $2") {
+		t.Fatalf("query %q does not filter on is_active", sql)
+	}
+	if len(stmt.Vars) != 2 || stmt.Vars[0] != userID || stmt.Vars[1] != true {
+		t.Fatalf("query vars = %v, want [%s true]", stmt.Vars, userID)
+	}
+}
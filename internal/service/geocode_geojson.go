@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"bamboo-rescue/internal/config"
+	"bamboo-rescue/internal/domain/entity"
+	"go.uber.org/zap"
+)
+
+// Photon and Pelias both answer with GeoJSON feature collections, with
+// their own set of address properties
+
+// geoJSONCollection is a GeoJSON FeatureCollection of points
+type geoJSONCollection[P any] struct {
+	Features []struct {
+		Geometry struct {
+			// Coordinates are longitude first
+			Coordinates []float64 `json:"coordinates"`
+		} `json:"geometry"`
+		Properties P `json:"properties"`
+	} `json:"features"`
+}
+
+// joinAddressParts builds a display address from its non-empty parts,
+// dropping repeats such as a city that is also its own province
+func joinAddressParts(parts ...string) string {
+	seen := make(map[string]bool, len(parts))
+	kept := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part == "" || seen[part] {
+			continue
+		}
+		seen[part] = true
+		kept = append(kept, part)
+	}
+	return strings.Join(kept, ", ")
+}
+
+// allowedCountry reports whether a result's country is in codes. An empty
+// list allows every country.
+func allowedCountry(codes []string, code string) bool {
+	if len(codes) == 0 {
+		return true
+	}
+	for _, c := range codes {
+		if strings.EqualFold(c, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// photonProvider geocodes with a Photon server
+type photonProvider struct {
+	baseURL      string
+	client       *geocodeHTTPClient
+	countryCodes []string
+}
+
+func newPhotonProvider(cfg *config.Config, log *zap.Logger) *photonProvider {
+	baseURL := cfg.Photon.URL
+	if baseURL == "" {
+		baseURL = "https://photon.komoot.io"
+	}
+
+	return &photonProvider{
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		client:       newGeocodeHTTPClient(GeocodeProviderPhoton, cfg.Nominatim.UserAgent, cfg.Photon.RequestsPerSecond, cfg.Geocode.ProviderTimeout, log),
+		countryCodes: cfg.Geocode.CountryCodes,
+	}
+}
+
+// PhotonProperties represents the properties of a Photon feature
+type PhotonProperties struct {
+	OSMID       int64  `json:"osm_id"`
+	OSMType     string `json:"osm_type"`
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	HouseNumber string `json:"housenumber"`
+	Street      string `json:"street"`
+	Locality    string `json:"locality"`
+	District    string `json:"district"`
+	City        string `json:"city"`
+	County      string `json:"county"`
+	State       string `json:"state"`
+	Postcode    string `json:"postcode"`
+	Country     string `json:"country"`
+	CountryCode string `json:"countrycode"`
+}
+
+// toAddress maps Photon's properties onto ward, district and province
+func (p PhotonProperties) toAddress() entity.Address {
+	addr := entity.Address{
+		HouseNumber: p.HouseNumber,
+		Road:        p.Street,
+		Postcode:    p.Postcode,
+		Country:     p.Country,
+		CountryCode: strings.ToUpper(p.CountryCode),
+	}
+
+	if addr.CountryCode != "VN" {
+		addr.Ward = firstNonEmpty(p.Locality, p.District)
+		addr.District = firstNonEmpty(p.City, p.County)
+		addr.Province = p.State
+		return addr
+	}
+
+	classifyVNUnits(&addr, p.Locality, p.District, p.City, p.County, p.State)
+	if addr.Province == "" {
+		addr.Province = p.State
+	}
+	return addr
+}
+
+func (p PhotonProperties) toResult(coordinates []float64) GeocodeResult {
+	result := GeocodeResult{
+		Address: joinAddressParts(
+			p.Name,
+			strings.TrimSpace(p.HouseNumber+" "+p.Street),
+			p.Locality, p.District, p.City, p.County, p.State, p.Country,
+		),
+		Components: p.toAddress(),
+		PlaceType:  p.Type,
+	}
+	if p.OSMID != 0 {
+		result.PlaceID = p.OSMType + strconv.FormatInt(p.OSMID, 10)
+	}
+	if len(coordinates) == 2 {
+		result.Location = entity.GeoPoint{Latitude: coordinates[1], Longitude: coordinates[0]}
+	}
+	return result
+}
+
+func (p *photonProvider) Name() string {
+	return GeocodeProviderPhoton
+}
+
+func (p *photonProvider) Degraded() bool {
+	return false
+}
+
+func (p *photonProvider) Reverse(ctx context.Context, lat, lng float64) (*GeocodeResult, error) {
+	reqURL := fmt.Sprintf("%s/reverse?lat=%f&lon=%f&limit=1", p.baseURL, lat, lng)
+
+	var collection geoJSONCollection[PhotonProperties]
+	if err := p.client.getJSON(ctx, reqURL, &collection); err != nil {
+		return nil, err
+	}
+
+	result := GeocodeResult{}
+	if len(collection.Features) > 0 {
+		result = collection.Features[0].Properties.toResult(nil)
+	}
+	result.Location = entity.GeoPoint{Latitude: lat, Longitude: lng}
+	return &result, nil
+}
+
+func (p *photonProvider) Search(ctx context.Context, query string, near *entity.GeoPoint) ([]GeocodeResult, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	// Ask for more than needed, as other countries are filtered out here
+	params.Set("limit", strconv.Itoa(maxSearchResults*2))
+	if near != nil {
+		params.Set("lat", strconv.FormatFloat(near.Latitude, 'f', -1, 64))
+		params.Set("lon", strconv.FormatFloat(near.Longitude, 'f', -1, 64))
+	}
+	reqURL := p.baseURL + "/api?" + params.Encode()
+
+	var collection geoJSONCollection[PhotonProperties]
+	if err := p.client.getJSON(ctx, reqURL, &collection); err != nil {
+		return nil, err
+	}
+
+	results := make([]GeocodeResult, 0, len(collection.Features))
+	for _, f := range collection.Features {
+		if !allowedCountry(p.countryCodes, f.Properties.CountryCode) {
+			continue
+		}
+		results = append(results, f.Properties.toResult(f.Geometry.Coordinates))
+		if len(results) == maxSearchResults {
+			break
+		}
+	}
+	return results, nil
+}
+
+// peliasProvider geocodes with a Pelias server
+type peliasProvider struct {
+	baseURL      string
+	apiKey       string
+	client       *geocodeHTTPClient
+	countryCodes []string
+}
+
+func newPeliasProvider(cfg *config.Config, log *zap.Logger) *peliasProvider {
+	return &peliasProvider{
+		baseURL:      strings.TrimSuffix(cfg.Pelias.URL, "/"),
+		apiKey:       cfg.Pelias.APIKey,
+		client:       newGeocodeHTTPClient(GeocodeProviderPelias, cfg.Nominatim.UserAgent, cfg.Pelias.RequestsPerSecond, cfg.Geocode.ProviderTimeout, log),
+		countryCodes: cfg.Geocode.CountryCodes,
+	}
+}
+
+// PeliasProperties represents the properties of a Pelias feature
+type PeliasProperties struct {
+	GID           string `json:"gid"`
+	Layer         string `json:"layer"`
+	Label         string `json:"label"`
+	HouseNumber   string `json:"housenumber"`
+	Street        string `json:"street"`
+	PostalCode    string `json:"postalcode"`
+	Neighbourhood string `json:"neighbourhood"`
+	Borough       string `json:"borough"`
+	Locality      string `json:"locality"`
+	LocalAdmin    string `json:"localadmin"`
+	County        string `json:"county"`
+	Region        string `json:"region"`
+	Country       string `json:"country"`
+	CountryCode   string `json:"country_code"`
+}
+
+// toAddress maps Pelias' properties onto ward, district and province
+func (p PeliasProperties) toAddress() entity.Address {
+	addr := entity.Address{
+		HouseNumber: p.HouseNumber,
+		Road:        p.Street,
+		Postcode:    p.PostalCode,
+		Country:     p.Country,
+		CountryCode: strings.ToUpper(p.CountryCode),
+	}
+
+	if addr.CountryCode != "VN" {
+		addr.Ward = firstNonEmpty(p.Neighbourhood, p.Borough)
+		addr.District = firstNonEmpty(p.Locality, p.LocalAdmin, p.County)
+		addr.Province = p.Region
+		return addr
+	}
+
+	classifyVNUnits(&addr, p.Neighbourhood, p.LocalAdmin, p.Borough, p.Locality, p.County, p.Region)
+	if addr.Province == "" {
+		addr.Province = p.Region
+	}
+	return addr
+}
+
+func (p PeliasProperties) toResult(coordinates []float64) GeocodeResult {
+	result := GeocodeResult{
+		Address:    p.Label,
+		Components: p.toAddress(),
+		PlaceID:    p.GID,
+		PlaceType:  p.Layer,
+	}
+	if len(coordinates) == 2 {
+		result.Location = entity.GeoPoint{Latitude: coordinates[1], Longitude: coordinates[0]}
+	}
+	return result
+}
+
+func (p *peliasProvider) Name() string {
+	return GeocodeProviderPelias
+}
+
+func (p *peliasProvider) Degraded() bool {
+	return false
+}
+
+func (p *peliasProvider) Reverse(ctx context.Context, lat, lng float64) (*GeocodeResult, error) {
+	params := p.params()
+	params.Set("point.lat", strconv.FormatFloat(lat, 'f', -1, 64))
+	params.Set("point.lon", strconv.FormatFloat(lng, 'f', -1, 64))
+	params.Set("size", "1")
+
+	var collection geoJSONCollection[PeliasProperties]
+	if err := p.client.getJSON(ctx, p.baseURL+"/v1/reverse?"+params.Encode(), &collection); err != nil {
+		return nil, err
+	}
+
+	result := GeocodeResult{}
+	if len(collection.Features) > 0 {
+		result = collection.Features[0].Properties.toResult(nil)
+	}
+	result.Location = entity.GeoPoint{Latitude: lat, Longitude: lng}
+	return &result, nil
+}
+
+func (p *peliasProvider) Search(ctx context.Context, query string, near *entity.GeoPoint) ([]GeocodeResult, error) {
+	params := p.params()
+	params.Set("text", query)
+	params.Set("size", strconv.Itoa(maxSearchResults))
+	if len(p.countryCodes) > 0 {
+		params.Set("boundary.country", strings.Join(p.countryCodes, ","))
+	}
+	if near != nil {
+		params.Set("focus.point.lat", strconv.FormatFloat(near.Latitude, 'f', -1, 64))
+		params.Set("focus.point.lon", strconv.FormatFloat(near.Longitude, 'f', -1, 64))
+	}
+
+	var collection geoJSONCollection[PeliasProperties]
+	if err := p.client.getJSON(ctx, p.baseURL+"/v1/search?"+params.Encode(), &collection); err != nil {
+		return nil, err
+	}
+
+	results := make([]GeocodeResult, len(collection.Features))
+	for i, f := range collection.Features {
+		results[i] = f.Properties.toResult(f.Geometry.Coordinates)
+	}
+	return results, nil
+}
+
+// params starts a query with the API key, if any
+func (p *peliasProvider) params() url.Values {
+	params := url.Values{}
+	if p.apiKey != "" {
+		params.Set("api_key", p.apiKey)
+	}
+	return params
+}
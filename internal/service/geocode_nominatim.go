@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"bamboo-rescue/internal/config"
+	"bamboo-rescue/internal/domain/entity"
+	"go.uber.org/zap"
+)
+
+// nominatimProvider geocodes with a Nominatim server
+type nominatimProvider struct {
+	baseURL      string
+	client       *geocodeHTTPClient
+	countryCodes []string
+	searchBiasKm float64
+}
+
+func newNominatimProvider(cfg *config.Config, log *zap.Logger) *nominatimProvider {
+	baseURL := cfg.Nominatim.URL
+	if baseURL == "" {
+		baseURL = "https://nominatim.openstreetmap.org"
+	}
+
+	// The public server allows one request per second
+	limit := cfg.Nominatim.RequestsPerSecond
+	if limit <= 0 {
+		limit = 1
+	}
+
+	userAgent := cfg.Nominatim.UserAgent
+	if userAgent == "" {
+		userAgent = "RescueApp/1.0"
+	}
+
+	return &nominatimProvider{
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		client:       newGeocodeHTTPClient(GeocodeProviderNominatim, userAgent, limit, cfg.Nominatim.MaxWait, log),
+		countryCodes: cfg.Geocode.CountryCodes,
+		searchBiasKm: cfg.Geocode.SearchBiasKm,
+	}
+}
+
+// NominatimReverseResponse represents Nominatim reverse geocoding response
+type NominatimReverseResponse struct {
+	PlaceID     int              `json:"place_id"`
+	Lat         string           `json:"lat"`
+	Lon         string           `json:"lon"`
+	DisplayName string           `json:"display_name"`
+	Type        string           `json:"type"`
+	Address     NominatimAddress `json:"address"`
+}
+
+// NominatimAddress represents address components from Nominatim. Which keys
+// are set depends on how the area is mapped in OpenStreetMap.
+type NominatimAddress struct {
+	HouseNumber   string `json:"house_number"`
+	Road          string `json:"road"`
+	Hamlet        string `json:"hamlet"`
+	Neighbourhood string `json:"neighbourhood"`
+	Quarter       string `json:"quarter"`
+	Suburb        string `json:"suburb"`
+	Village       string `json:"village"`
+	Town          string `json:"town"`
+	CityDistrict  string `json:"city_district"`
+	District      string `json:"district"`
+	County        string `json:"county"`
+	City          string `json:"city"`
+	State         string `json:"state"`
+	Province      string `json:"province"`
+	Region        string `json:"region"`
+	Postcode      string `json:"postcode"`
+	Country       string `json:"country"`
+	CountryCode   string `json:"country_code"`
+}
+
+// NominatimSearchResponse represents Nominatim search response
+type NominatimSearchResponse struct {
+	PlaceID     int              `json:"place_id"`
+	Lat         string           `json:"lat"`
+	Lon         string           `json:"lon"`
+	DisplayName string           `json:"display_name"`
+	Type        string           `json:"type"`
+	Address     NominatimAddress `json:"address"`
+}
+
+// toAddress maps Nominatim's components onto ward, district and province
+func (a NominatimAddress) toAddress() entity.Address {
+	addr := entity.Address{
+		HouseNumber: a.HouseNumber,
+		Road:        a.Road,
+		Postcode:    a.Postcode,
+		Country:     a.Country,
+		CountryCode: strings.ToUpper(a.CountryCode),
+	}
+
+	if addr.CountryCode != "VN" {
+		addr.Ward = firstNonEmpty(a.Suburb, a.Quarter, a.Neighbourhood, a.Village, a.Hamlet)
+		addr.District = firstNonEmpty(a.City, a.Town, a.CityDistrict, a.District, a.County)
+		addr.Province = firstNonEmpty(a.State, a.Province, a.Region)
+		return addr
+	}
+
+	classifyVNUnits(&addr, a.Quarter, a.Village, a.Town, a.Suburb, a.CityDistrict, a.District, a.County, a.City, a.State, a.Province)
+	if addr.Province == "" {
+		addr.Province = firstNonEmpty(a.State, a.Province)
+	}
+	return addr
+}
+
+func (p *nominatimProvider) Name() string {
+	return GeocodeProviderNominatim
+}
+
+func (p *nominatimProvider) Degraded() bool {
+	return false
+}
+
+func (p *nominatimProvider) Reverse(ctx context.Context, lat, lng float64) (*GeocodeResult, error) {
+	reqURL := fmt.Sprintf("%s/reverse?format=json&lat=%f&lon=%f&addressdetails=1",
+		p.baseURL, lat, lng)
+
+	var result NominatimReverseResponse
+	if err := p.client.getJSON(ctx, reqURL, &result); err != nil {
+		return nil, err
+	}
+
+	return &GeocodeResult{
+		Address:    result.DisplayName,
+		Components: result.Address.toAddress(),
+		Location: entity.GeoPoint{
+			Latitude:  lat,
+			Longitude: lng,
+		},
+		PlaceID:   fmt.Sprintf("%d", result.PlaceID),
+		PlaceType: result.Type,
+	}, nil
+}
+
+func (p *nominatimProvider) Search(ctx context.Context, query string, near *entity.GeoPoint) ([]GeocodeResult, error) {
+	params := url.Values{}
+	params.Set("format", "json")
+	params.Set("q", query)
+	params.Set("limit", strconv.Itoa(maxSearchResults))
+	params.Set("addressdetails", "1")
+	if len(p.countryCodes) > 0 {
+		params.Set("countrycodes", strings.Join(p.countryCodes, ","))
+	}
+	if near != nil && p.searchBiasKm > 0 {
+		// Without bounded=1 the box only ranks results, it does not filter
+		viewbox := entity.NewBoundingBox(near.Latitude, near.Longitude, p.searchBiasKm)
+		params.Set("viewbox", fmt.Sprintf("%f,%f,%f,%f", viewbox.MinLng, viewbox.MaxLat, viewbox.MaxLng, viewbox.MinLat))
+	}
+	reqURL := p.baseURL + "/search?" + params.Encode()
+
+	var results []NominatimSearchResponse
+	if err := p.client.getJSON(ctx, reqURL, &results); err != nil {
+		return nil, err
+	}
+
+	geocodeResults := make([]GeocodeResult, len(results))
+	for i, r := range results {
+		var lat, lng float64
+		fmt.Sscanf(r.Lat, "%f", &lat)
+		fmt.Sscanf(r.Lon, "%f", &lng)
+
+		geocodeResults[i] = GeocodeResult{
+			Address:    r.DisplayName,
+			Components: r.Address.toAddress(),
+			Location: entity.GeoPoint{
+				Latitude:  lat,
+				Longitude: lng,
+			},
+			PlaceID:   fmt.Sprintf("%d", r.PlaceID),
+			PlaceType: r.Type,
+		}
+	}
+
+	return geocodeResults, nil
+}
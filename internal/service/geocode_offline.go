@@ -0,0 +1,253 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"bamboo-rescue/internal/domain/entity"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+//go:embed gazetteer/vn.csv
+var vnGazetteer []byte
+
+// offlineMaxProvinceKm is how far a point may be from the nearest province
+// seat and still be taken to lie in that province
+const offlineMaxProvinceKm = 150
+
+// gazetteerPlace is a reference point from the bundled gazetteer
+type gazetteerPlace struct {
+	name      string
+	tier      entity.AdminTier
+	province  string
+	location  entity.GeoPoint
+	radiusKm  float64
+	folded    string // name without diacritics, for matching
+	foldedKey string // folded name without its type word, e.g. "ba dinh"
+}
+
+// offlineProvider geocodes from a bundled list of Vietnamese provinces and
+// districts when no online provider is reachable. It only knows which
+// province, and in large cities which district, a point lies in.
+type offlineProvider struct {
+	places []gazetteerPlace
+}
+
+func newOfflineProvider() (*offlineProvider, error) {
+	r := csv.NewReader(bytes.NewReader(vnGazetteer))
+	r.Comment = '#'
+	r.FieldsPerRecord = 6
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read gazetteer: %w", err)
+	}
+
+	places := make([]gazetteerPlace, 0, len(records))
+	for _, rec := range records {
+		lat, err := strconv.ParseFloat(rec[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("gazetteer %s: %w", rec[0], err)
+		}
+		lng, err := strconv.ParseFloat(rec[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("gazetteer %s: %w", rec[0], err)
+		}
+
+		place := gazetteerPlace{
+			name:     rec[0],
+			province: rec[2],
+			location: entity.GeoPoint{Latitude: lat, Longitude: lng},
+			folded:   foldVietnamese(rec[0]),
+		}
+		place.foldedKey = stripUnitType(place.folded)
+
+		switch rec[1] {
+		case "province":
+			place.tier = entity.AdminTierProvince
+		case "district":
+			place.tier = entity.AdminTierDistrict
+			if place.radiusKm, err = strconv.ParseFloat(rec[5], 64); err != nil {
+				return nil, fmt.Errorf("gazetteer %s: %w", rec[0], err)
+			}
+		default:
+			return nil, fmt.Errorf("gazetteer %s: unknown tier %q", rec[0], rec[1])
+		}
+		places = append(places, place)
+	}
+
+	return &offlineProvider{places: places}, nil
+}
+
+func (p *offlineProvider) Name() string {
+	return GeocodeProviderOffline
+}
+
+func (p *offlineProvider) Degraded() bool {
+	return true
+}
+
+func (p *offlineProvider) Reverse(ctx context.Context, lat, lng float64) (*GeocodeResult, error) {
+	result := &GeocodeResult{
+		Location: entity.GeoPoint{Latitude: lat, Longitude: lng},
+	}
+
+	province := p.nearest(lat, lng, entity.AdminTierProvince, offlineMaxProvinceKm)
+	if province == nil {
+		return result, nil
+	}
+	addr := entity.Address{
+		Province:    province.province,
+		Country:     "Việt Nam",
+		CountryCode: "VN",
+	}
+	place := province
+
+	if district := p.nearest(lat, lng, entity.AdminTierDistrict, 0); district != nil && district.province == province.province {
+		addr.District = district.name
+		place = district
+	}
+
+	result.Address = joinAddressParts(addr.District, addr.Province, addr.Country)
+	result.Components = addr
+	result.PlaceID = "gazetteer:" + place.name
+	result.PlaceType = tierPlaceType(place.tier)
+	return result, nil
+}
+
+func (p *offlineProvider) Search(ctx context.Context, query string, near *entity.GeoPoint) ([]GeocodeResult, error) {
+	folded := " " + foldVietnamese(query) + " "
+
+	type match struct {
+		place *gazetteerPlace
+		score int
+	}
+	var matches []match
+	for i := range p.places {
+		place := &p.places[i]
+		// Bare keys like the "1" of "Quận 1" are too short to go by
+		score := 0
+		if strings.Contains(folded, " "+place.folded+" ") {
+			score = len(place.folded)
+		} else if len(place.foldedKey) >= 3 && strings.Contains(folded, " "+place.foldedKey+" ") {
+			score = len(place.foldedKey)
+		}
+		if score == 0 {
+			continue
+		}
+		// Districts are more specific than the provinces they lie in
+		if place.tier == entity.AdminTierDistrict {
+			score += 100
+		}
+		matches = append(matches, match{place: place, score: score})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		if near != nil {
+			return near.DistanceKm(&matches[i].place.location) < near.DistanceKm(&matches[j].place.location)
+		}
+		return false
+	})
+	if len(matches) > maxSearchResults {
+		matches = matches[:maxSearchResults]
+	}
+
+	results := make([]GeocodeResult, len(matches))
+	for i, m := range matches {
+		addr := entity.Address{
+			Province:    m.place.province,
+			Country:     "Việt Nam",
+			CountryCode: "VN",
+		}
+		if m.place.tier == entity.AdminTierDistrict {
+			addr.District = m.place.name
+		}
+		// Former provinces keep their name, e.g. "Bình Dương, Thành phố
+		// Hồ Chí Minh", but a current one is not named twice
+		name := m.place.name
+		if m.place.foldedKey == stripUnitType(foldVietnamese(m.place.province)) {
+			name = ""
+		}
+		results[i] = GeocodeResult{
+			Address:    joinAddressParts(name, m.place.province, addr.Country),
+			Components: addr,
+			Location:   m.place.location,
+			PlaceID:    "gazetteer:" + m.place.name,
+			PlaceType:  tierPlaceType(m.place.tier),
+		}
+	}
+	return results, nil
+}
+
+// nearest returns the place of tier closest to a point, if it is within
+// maxKm, or for districts within their own radius when maxKm is 0
+func (p *offlineProvider) nearest(lat, lng float64, tier entity.AdminTier, maxKm float64) *gazetteerPlace {
+	var best *gazetteerPlace
+	bestKm := 0.0
+	for i := range p.places {
+		place := &p.places[i]
+		if place.tier != tier {
+			continue
+		}
+		km := entity.DistanceKmBetween(lat, lng, place.location.Latitude, place.location.Longitude)
+		limit := maxKm
+		if limit == 0 {
+			limit = place.radiusKm
+		}
+		if km > limit {
+			continue
+		}
+		if best == nil || km < bestKm {
+			best, bestKm = place, km
+		}
+	}
+	return best
+}
+
+func tierPlaceType(tier entity.AdminTier) string {
+	if tier == entity.AdminTierDistrict {
+		return "district"
+	}
+	return "province"
+}
+
+// foldVietnamese lowercases s and strips its diacritics and punctuation,
+// so "Thành phố Hồ Chí Minh" and "thanh pho ho chi minh" match
+func foldVietnamese(s string) string {
+	// Transformers keep state, so each call needs its own
+	stripMarks := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(stripMarks, strings.ToLower(s))
+	if err != nil {
+		folded = strings.ToLower(s)
+	}
+	// đ is a letter of its own rather than d with a mark
+	folded = strings.ReplaceAll(folded, "đ", "d")
+	return strings.Join(strings.FieldsFunc(folded, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// unitTypes are the folded type words unit names start with
+var unitTypes = []string{"thanh pho ", "tinh ", "quan ", "huyen ", "thi xa ", "tp "}
+
+// stripUnitType drops the type word from a folded unit name
+func stripUnitType(folded string) string {
+	for _, t := range unitTypes {
+		if rest, ok := strings.CutPrefix(folded, t); ok {
+			return rest
+		}
+	}
+	return folded
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"bamboo-rescue/internal/config"
+	"bamboo-rescue/internal/domain/entity"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// Geocoding provider names selectable through configuration
+const (
+	GeocodeProviderNominatim = "nominatim"
+	GeocodeProviderPhoton    = "photon"
+	GeocodeProviderPelias    = "pelias"
+	GeocodeProviderOffline   = "offline"
+)
+
+// GeocodeProvider turns coordinates into addresses and back. Finding
+// nothing is not an error: a provider that answers with no results is
+// healthy.
+type GeocodeProvider interface {
+	Name() string
+	Reverse(ctx context.Context, lat, lng float64) (*GeocodeResult, error)
+	// Search returns up to maxSearchResults matches for query, ranking
+	// those near near first when it is set
+	Search(ctx context.Context, query string, near *entity.GeoPoint) ([]GeocodeResult, error)
+	// Degraded reports whether results are coarse stand-ins that should
+	// neither be cached nor preferred over stale ones
+	Degraded() bool
+}
+
+// errGeocodeThrottled means a provider's rate limit left no turn in time.
+// It says nothing about the provider's health.
+var errGeocodeThrottled = errors.New("geocode provider rate limit reached")
+
+// NewGeocodeProviders creates the configured providers in order
+func NewGeocodeProviders(cfg *config.Config, log *zap.Logger) ([]GeocodeProvider, error) {
+	names := cfg.Geocode.Providers
+	if len(names) == 0 {
+		names = []string{GeocodeProviderNominatim}
+	}
+
+	providers := make([]GeocodeProvider, 0, len(names))
+	for _, name := range names {
+		switch strings.ToLower(name) {
+		case GeocodeProviderNominatim:
+			providers = append(providers, newNominatimProvider(cfg, log))
+		case GeocodeProviderPhoton:
+			providers = append(providers, newPhotonProvider(cfg, log))
+		case GeocodeProviderPelias:
+			if cfg.Pelias.URL == "" {
+				return nil, errors.New("pelias geocoding requires PELIAS_URL")
+			}
+			providers = append(providers, newPeliasProvider(cfg, log))
+		case GeocodeProviderOffline:
+			offline, err := newOfflineProvider()
+			if err != nil {
+				return nil, err
+			}
+			providers = append(providers, offline)
+		default:
+			return nil, fmt.Errorf("unknown geocoding provider %q", name)
+		}
+	}
+	return providers, nil
+}
+
+// geocodeHTTPClient makes rate limited JSON GET requests to one provider
+type geocodeHTTPClient struct {
+	name       string
+	userAgent  string
+	httpClient *http.Client
+	limiter    *rate.Limiter // nil when unlimited
+	maxWait    time.Duration
+	log        *zap.Logger
+}
+
+func newGeocodeHTTPClient(name, userAgent string, requestsPerSecond float64, maxWait time.Duration, log *zap.Logger) *geocodeHTTPClient {
+	c := &geocodeHTTPClient{
+		name:      name,
+		userAgent: userAgent,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		maxWait: maxWait,
+		log:     log,
+	}
+	// The limiter is per process, so the rate should be split between
+	// instances sharing the same account
+	if requestsPerSecond > 0 {
+		c.limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), 1)
+	}
+	return c
+}
+
+// getJSON waits for a turn under the rate limit, then fetches reqURL and
+// decodes the response body into out
+func (c *geocodeHTTPClient) getJSON(ctx context.Context, reqURL string, out interface{}) error {
+	if c.limiter != nil {
+		waitCtx, cancel := context.WithTimeout(ctx, c.maxWait)
+		err := c.limiter.Wait(waitCtx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("%s: %w", c.name, errGeocodeThrottled)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return err
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.log.Error("Failed to call geocoding provider", zap.String("provider", c.name), zap.Error(err))
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", c.name, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		c.log.Error("Failed to decode geocoding response", zap.String("provider", c.name), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// classifyVNUnits fills the ward, district and province of addr from
+// unit names whose keys vary from place to place but whose names say what
+// they are. Earlier names win.
+func classifyVNUnits(addr *entity.Address, names ...string) {
+	for _, name := range names {
+		switch entity.VNAdminTier(name) {
+		case entity.AdminTierWard:
+			if addr.Ward == "" {
+				addr.Ward = name
+			}
+		case entity.AdminTierDistrict:
+			if addr.District == "" {
+				addr.District = name
+			}
+		case entity.AdminTierProvince:
+			if addr.Province == "" {
+				addr.Province = name
+			}
+		}
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -2,164 +2,302 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
-	"net/url"
+	"math"
+	"strings"
 	"time"
 
 	"bamboo-rescue/internal/config"
 	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/middleware"
+	"bamboo-rescue/internal/repository"
+	"bamboo-rescue/pkg/breaker"
+	"bamboo-rescue/pkg/clock"
+	"bamboo-rescue/pkg/lru"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 )
 
 // GeocodeService defines the interface for geocoding operations
 type GeocodeService interface {
 	ReverseGeocode(ctx context.Context, lat, lng float64) (*GeocodeResult, error)
-	SearchAddress(ctx context.Context, query string) ([]GeocodeResult, error)
+	SearchAddress(ctx context.Context, query string, opts SearchOptions) ([]GeocodeResult, error)
 }
 
 // GeocodeResult represents a geocoding result
 type GeocodeResult struct {
-	Address   string          `json:"address"`
-	Location  entity.GeoPoint `json:"location"`
-	PlaceID   string          `json:"place_id"`
-	PlaceType string          `json:"place_type"`
+	Address    string          `json:"address"`
+	Components entity.Address  `json:"components"`
+	Location   entity.GeoPoint `json:"location"`
+	PlaceID    string          `json:"place_id"`
+	PlaceType  string          `json:"place_type"`
 }
 
-type geocodeService struct {
-	nominatimURL string
-	httpClient   *http.Client
-	log          *zap.Logger
+// SearchOptions tunes an address search
+type SearchOptions struct {
+	// Limit caps the number of results, up to maxSearchResults
+	Limit int
+	// Near biases results toward the requester's location
+	Near *entity.GeoPoint
 }
 
-// NewGeocodeService creates a new GeocodeService
-func NewGeocodeService(cfg *config.Config, log *zap.Logger) GeocodeService {
-	nominatimURL := cfg.Nominatim.URL
-	if nominatimURL == "" {
-		nominatimURL = "https://nominatim.openstreetmap.org"
-	}
+// maxSearchResults is how many results a search asks for. Smaller limits
+// are cut from the same cached list.
+const maxSearchResults = 10
 
-	return &geocodeService{
-		nominatimURL: nominatimURL,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		log: log,
-	}
+// geocodeCacheVersion prefixes cache keys. Bump it when GeocodeResult
+// changes so entries cached in the old shape are not served.
+const geocodeCacheVersion = "v2"
+
+// geocodeService answers from a two-tier cache, in memory and in the
+// database, and only asks a provider on a miss. Providers are tried in
+// order, skipping those whose breaker is open, and concurrent identical
+// lookups share one call.
+type geocodeService struct {
+	providers []*geocodeBackend
+	cfg       config.GeocodeConfig
+	cacheRepo repository.GeocodeCacheRepository
+	cache     *lru.Cache[string, *entity.GeocodeCacheEntry]
+	lookups   singleflight.Group
+	log       *zap.Logger
 }
 
-// NominatimReverseResponse represents Nominatim reverse geocoding response
-type NominatimReverseResponse struct {
-	PlaceID     int     `json:"place_id"`
-	Lat         string  `json:"lat"`
-	Lon         string  `json:"lon"`
-	DisplayName string  `json:"display_name"`
-	Type        string  `json:"type"`
-	Address     Address `json:"address"`
+// geocodeBackend is a provider with its circuit breaker
+type geocodeBackend struct {
+	GeocodeProvider
+	breaker *breaker.Breaker
 }
 
-// Address represents address components from Nominatim
-type Address struct {
-	Road        string `json:"road"`
-	Suburb      string `json:"suburb"`
-	City        string `json:"city"`
-	State       string `json:"state"`
-	Postcode    string `json:"postcode"`
-	Country     string `json:"country"`
-	CountryCode string `json:"country_code"`
+// geocodeFetch is the outcome of a lookup shared between callers
+type geocodeFetch struct {
+	raw      []byte
+	degraded bool
 }
 
-// NominatimSearchResponse represents Nominatim search response
-type NominatimSearchResponse struct {
-	PlaceID     int    `json:"place_id"`
-	Lat         string `json:"lat"`
-	Lon         string `json:"lon"`
-	DisplayName string `json:"display_name"`
-	Type        string `json:"type"`
+// NewGeocodeService creates a new GeocodeService
+func NewGeocodeService(cfg *config.Config, providers []GeocodeProvider, cacheRepo repository.GeocodeCacheRepository, log *zap.Logger) GeocodeService {
+	backends := make([]*geocodeBackend, len(providers))
+	for i, p := range providers {
+		backends[i] = &geocodeBackend{
+			GeocodeProvider: p,
+			breaker:         breaker.New(cfg.Geocode.BreakerThreshold, cfg.Geocode.BreakerCooldown, clock.Real),
+		}
+	}
+
+	geocodeCfg := cfg.Geocode
+	if geocodeCfg.ProviderTimeout <= 0 {
+		geocodeCfg.ProviderTimeout = 8 * time.Second
+	}
+
+	return &geocodeService{
+		providers: backends,
+		cfg:       geocodeCfg,
+		cacheRepo: cacheRepo,
+		cache:     lru.New[string, *entity.GeocodeCacheEntry](cfg.Geocode.CacheSize),
+		log:       log,
+	}
 }
 
 func (s *geocodeService) ReverseGeocode(ctx context.Context, lat, lng float64) (*GeocodeResult, error) {
-	reqURL := fmt.Sprintf("%s/reverse?format=json&lat=%f&lon=%f&addressdetails=1",
-		s.nominatimURL, lat, lng)
+	// Nearby points share a cache entry and a lookup
+	precision := s.cfg.CoordPrecision
+	roundedLat, roundedLng := roundCoord(lat, precision), roundCoord(lng, precision)
+	key := fmt.Sprintf("%s:reverse:%.*f,%.*f", geocodeCacheVersion, precision, roundedLat, precision, roundedLng)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	result, err := cachedLookup(ctx, s, key, s.cfg.ReverseTTL, func(ctx context.Context, p GeocodeProvider) (*GeocodeResult, error) {
+		return p.Reverse(ctx, roundedLat, roundedLng)
+	})
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("User-Agent", "RescueApp/1.0")
 
-	resp, err := s.httpClient.Do(req)
+	located := *result
+	located.Location = entity.GeoPoint{Latitude: lat, Longitude: lng}
+	return &located, nil
+}
+
+func (s *geocodeService) SearchAddress(ctx context.Context, query string, opts SearchOptions) ([]GeocodeResult, error) {
+	normalized := normalizeQuery(query)
+	keyInput := normalized
+
+	var near *entity.GeoPoint
+	if opts.Near != nil && s.cfg.SearchBiasKm > 0 {
+		// Rounded to ~11 km so requesters in the same area share a cache entry
+		near = entity.NewGeoPoint(roundCoord(opts.Near.Latitude, 1), roundCoord(opts.Near.Longitude, 1))
+		keyInput += fmt.Sprintf("|%.1f,%.1f", near.Latitude, near.Longitude)
+	}
+
+	sum := sha256.Sum256([]byte(keyInput))
+	key := geocodeCacheVersion + ":search:" + hex.EncodeToString(sum[:])
+
+	results, err := cachedLookup(ctx, s, key, s.cfg.SearchTTL, func(ctx context.Context, p GeocodeProvider) ([]GeocodeResult, error) {
+		return p.Search(ctx, normalized, near)
+	})
 	if err != nil {
-		s.log.Error("Failed to call Nominatim reverse API", zap.Error(err))
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("nominatim returned status %d", resp.StatusCode)
+	limit := opts.Limit
+	if limit <= 0 || limit > maxSearchResults {
+		limit = maxSearchResults
 	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
 
-	var result NominatimReverseResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		s.log.Error("Failed to decode Nominatim response", zap.Error(err))
-		return nil, err
+// cachedLookup returns the cached result for key, asking the providers
+// and caching their result when there is no fresh one. If they all fail, or
+// only a degraded provider answers, a stale result is served instead.
+func cachedLookup[T any](ctx context.Context, s *geocodeService, key string, ttl time.Duration, fetch func(context.Context, GeocodeProvider) (T, error)) (T, error) {
+	var result T
+
+	cached := s.getCached(ctx, key)
+	if cached != nil && cached.IsFresh(time.Now()) {
+		if err := json.Unmarshal([]byte(cached.Result), &result); err == nil {
+			return result, nil
+		}
+	}
+
+	// The call is shared with every concurrent lookup of the same key, so
+	// it must outlive the caller that started it. A caller that gives up
+	// still gets the stale result, if any.
+	ch := s.lookups.DoChan(key, func() (interface{}, error) {
+		return s.fetchAndCache(context.WithoutCancel(ctx), key, ttl, func(ctx context.Context, p GeocodeProvider) (interface{}, error) {
+			return fetch(ctx, p)
+		})
+	})
+
+	var err error
+	var degraded *T
+	select {
+	case res := <-ch:
+		err = res.Err
+		if err == nil {
+			fetched := res.Val.(*geocodeFetch)
+			if err = json.Unmarshal(fetched.raw, &result); err == nil {
+				if !fetched.degraded || cached == nil {
+					return result, nil
+				}
+				degraded = &result
+				err = errors.New("only a degraded provider answered")
+			}
+		}
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if cached != nil {
+		s.log.Warn("Serving stale geocode result", zap.String("key", key), zap.Error(err))
+		var stale T
+		if err := json.Unmarshal([]byte(cached.Result), &stale); err == nil {
+			return stale, nil
+		}
+	}
+	if degraded != nil {
+		return *degraded, nil
 	}
 
-	return &GeocodeResult{
-		Address: result.DisplayName,
-		Location: entity.GeoPoint{
-			Latitude:  lat,
-			Longitude: lng,
-		},
-		PlaceID:   fmt.Sprintf("%d", result.PlaceID),
-		PlaceType: result.Type,
-	}, nil
+	s.log.Error("Geocode lookup failed", zap.String("key", key), zap.Error(err))
+	return result, middleware.ErrGeocodeUnavailable
 }
 
-func (s *geocodeService) SearchAddress(ctx context.Context, query string) ([]GeocodeResult, error) {
-	reqURL := fmt.Sprintf("%s/search?format=json&q=%s&limit=10",
-		s.nominatimURL, url.QueryEscape(query))
+// getCached returns the entry for key from memory, or else from the
+// database. Cache read failures count as misses.
+func (s *geocodeService) getCached(ctx context.Context, key string) *entity.GeocodeCacheEntry {
+	if entry, ok := s.cache.Get(key); ok {
+		return entry
+	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	entry, err := s.cacheRepo.Get(ctx, key)
 	if err != nil {
-		return nil, err
+		s.log.Warn("Failed to read geocode cache", zap.Error(err))
+		return nil
 	}
-	req.Header.Set("User-Agent", "RescueApp/1.0")
+	if entry != nil {
+		s.cache.Add(key, entry)
+	}
+	return entry
+}
 
-	resp, err := s.httpClient.Do(req)
+// fetchAndCache asks the providers in turn and caches the first answer,
+// JSON encoded, in both tiers. Answers from degraded providers are not
+// cached.
+func (s *geocodeService) fetchAndCache(ctx context.Context, key string, ttl time.Duration, fetch func(context.Context, GeocodeProvider) (interface{}, error)) (interface{}, error) {
+	result, provider, err := s.failover(ctx, fetch)
 	if err != nil {
-		s.log.Error("Failed to call Nominatim search API", zap.Error(err))
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("nominatim returned status %d", resp.StatusCode)
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	if provider.Degraded() {
+		return &geocodeFetch{raw: raw, degraded: true}, nil
 	}
 
-	var results []NominatimSearchResponse
-	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
-		s.log.Error("Failed to decode Nominatim response", zap.Error(err))
-		return nil, err
+	entry := &entity.GeocodeCacheEntry{
+		Key:       key,
+		Result:    string(raw),
+		ExpiresAt: time.Now().Add(ttl),
 	}
+	s.cache.Add(key, entry)
+	if err := s.cacheRepo.Put(ctx, entry); err != nil {
+		s.log.Warn("Failed to write geocode cache", zap.Error(err))
+	}
+
+	return &geocodeFetch{raw: raw}, nil
+}
+
+// failover calls fetch with each provider whose breaker allows it until
+// one succeeds. Running out of rate limit fails over without counting
+// against the provider.
+func (s *geocodeService) failover(ctx context.Context, fetch func(context.Context, GeocodeProvider) (interface{}, error)) (interface{}, GeocodeProvider, error) {
+	err := errors.New("no geocoding provider available")
+	for _, p := range s.providers {
+		if !p.breaker.Allow() {
+			continue
+		}
 
-	geocodeResults := make([]GeocodeResult, len(results))
-	for i, r := range results {
-		var lat, lng float64
-		fmt.Sscanf(r.Lat, "%f", &lat)
-		fmt.Sscanf(r.Lon, "%f", &lng)
-
-		geocodeResults[i] = GeocodeResult{
-			Address: r.DisplayName,
-			Location: entity.GeoPoint{
-				Latitude:  lat,
-				Longitude: lng,
-			},
-			PlaceID:   fmt.Sprintf("%d", r.PlaceID),
-			PlaceType: r.Type,
+		callCtx, cancel := context.WithTimeout(ctx, s.cfg.ProviderTimeout)
+		var result interface{}
+		result, err = fetch(callCtx, p.GeocodeProvider)
+		cancel()
+		if err == nil {
+			p.breaker.Success()
+			return result, p.GeocodeProvider, nil
 		}
+
+		if errors.Is(err, errGeocodeThrottled) {
+			s.log.Debug("Geocoding provider busy, failing over", zap.String("provider", p.Name()))
+			continue
+		}
+		if p.breaker.Failure() {
+			s.log.Warn("Geocoding provider taken out of rotation",
+				zap.String("provider", p.Name()),
+				zap.Duration("cooldown", s.cfg.BreakerCooldown),
+				zap.Error(err),
+			)
+			continue
+		}
+		s.log.Warn("Geocoding provider failed, failing over", zap.String("provider", p.Name()), zap.Error(err))
 	}
+	return nil, nil, err
+}
+
+// roundCoord rounds a coordinate to the given number of decimal places
+func roundCoord(v float64, precision int) float64 {
+	scale := math.Pow10(precision)
+	return math.Round(v*scale) / scale
+}
 
-	return geocodeResults, nil
+// normalizeQuery folds case and whitespace so trivially different searches
+// share a cache entry
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
 }
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/middleware"
+	"bamboo-rescue/pkg/geoparse"
+	"bamboo-rescue/pkg/olc"
+	"go.uber.org/zap"
+)
+
+// LocationService reads positions shared as text
+type LocationService interface {
+	// Parse reads a position written as coordinates, a plus code or a map
+	// link. A short plus code is recovered near the town written after it,
+	// or else near ref, such as the reporter's own position.
+	Parse(ctx context.Context, input string, ref *entity.GeoPoint) (*ParsedLocation, error)
+}
+
+// ParsedLocation is a position read from text
+type ParsedLocation struct {
+	Location entity.GeoPoint
+	Format   geoparse.Format
+	// PlusCode is the full plus code of the position
+	PlusCode string
+}
+
+// maxShortLinkHops bounds how many redirects are followed from a short link
+const maxShortLinkHops = 5
+
+type locationService struct {
+	geocodeSvc GeocodeService
+	httpClient *http.Client
+	log        *zap.Logger
+}
+
+// NewLocationService creates a new LocationService
+func NewLocationService(geocodeSvc GeocodeService, log *zap.Logger) LocationService {
+	return &locationService{
+		geocodeSvc: geocodeSvc,
+		httpClient: &http.Client{
+			Timeout: 5 * time.Second,
+			// Redirects are followed by hand, to check each host
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		log: log,
+	}
+}
+
+func (s *locationService) Parse(ctx context.Context, input string, ref *entity.GeoPoint) (*ParsedLocation, error) {
+	parsed, err := geoparse.Parse(input)
+	if err == nil && parsed.ShortLink != "" {
+		var link string
+		if link, err = s.followShortLink(ctx, parsed.ShortLink); err == nil {
+			parsed, err = geoparse.Parse(link)
+		}
+		// A link that still points at a shortener went somewhere unexpected
+		if err == nil && parsed.ShortLink != "" {
+			err = geoparse.ErrUnrecognized
+		}
+	}
+	if err != nil {
+		if errors.Is(err, geoparse.ErrOutOfRange) {
+			return nil, middleware.NewAppError("VALIDATION_ERROR", "Coordinates are out of range", 400)
+		}
+		return nil, middleware.ErrLocationUnrecognized
+	}
+
+	plusCode := parsed.PlusCode
+	if parsed.ShortCode != "" {
+		if plusCode, err = s.recoverShortCode(ctx, parsed, ref); err != nil {
+			return nil, err
+		}
+		area, err := olc.Decode(plusCode)
+		if err != nil {
+			return nil, middleware.ErrLocationUnrecognized
+		}
+		parsed.Latitude, parsed.Longitude = area.Center()
+	}
+	if plusCode == "" {
+		plusCode = olc.Encode(parsed.Latitude, parsed.Longitude, 10)
+	}
+
+	return &ParsedLocation{
+		Location: entity.GeoPoint{Latitude: parsed.Latitude, Longitude: parsed.Longitude},
+		Format:   parsed.Format,
+		PlusCode: plusCode,
+	}, nil
+}
+
+// recoverShortCode completes a short plus code near its locality, or near
+// ref when it has none
+func (s *locationService) recoverShortCode(ctx context.Context, parsed *geoparse.Result, ref *entity.GeoPoint) (string, error) {
+	if parsed.Locality != "" {
+		results, err := s.geocodeSvc.SearchAddress(ctx, parsed.Locality, SearchOptions{Limit: 1, Near: ref})
+		if err != nil {
+			return "", err
+		}
+		if len(results) == 0 {
+			return "", middleware.NewAppError("LOCALITY_NOT_FOUND", "Could not find the place given with the plus code", 400)
+		}
+		ref = &results[0].Location
+	}
+	if ref == nil {
+		return "", middleware.ErrLocationAmbiguous
+	}
+
+	code, err := olc.RecoverNearest(parsed.ShortCode, ref.Latitude, ref.Longitude)
+	if err != nil {
+		return "", middleware.ErrLocationUnrecognized
+	}
+	return code, nil
+}
+
+// followShortLink returns the map link a short link redirects to. Only
+// known shorteners are contacted.
+func (s *locationService) followShortLink(ctx context.Context, link string) (string, error) {
+	for hop := 0; hop < maxShortLinkHops; hop++ {
+		current, err := url.Parse(link)
+		if err != nil || current.Scheme != "https" || !geoparse.IsShortLinkHost(current.Hostname()) {
+			return link, nil
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+		if err != nil {
+			return "", err
+		}
+		resp, err := s.httpClient.Do(req)
+		if err != nil {
+			s.log.Warn("Failed to follow map short link", zap.String("url", link), zap.Error(err))
+			return "", err
+		}
+		resp.Body.Close()
+
+		location := resp.Header.Get("Location")
+		if resp.StatusCode < 300 || resp.StatusCode >= 400 || location == "" {
+			return "", errors.New("short link did not redirect")
+		}
+		next, err := current.Parse(location)
+		if err != nil {
+			return "", err
+		}
+		link = next.String()
+	}
+
+	if u, err := url.Parse(link); err == nil && geoparse.IsShortLinkHost(u.Hostname()) {
+		return "", errors.New("too many short link redirects")
+	}
+	return link, nil
+}
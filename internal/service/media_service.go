@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -10,34 +11,64 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"bamboo-rescue/internal/config"
 	"bamboo-rescue/internal/domain/entity"
 	"bamboo-rescue/internal/domain/enum"
+	"bamboo-rescue/internal/handler/dto/request"
 	"bamboo-rescue/internal/middleware"
 	"bamboo-rescue/internal/repository"
+	"bamboo-rescue/pkg/imaging"
 	"bamboo-rescue/pkg/storage"
 	"go.uber.org/zap"
 )
 
 // MediaService defines the interface for media operations
 type MediaService interface {
-	Upload(ctx context.Context, file *multipart.FileHeader, caseID uuid.UUID) (*entity.MediaUploadResult, error)
-	UploadMultiple(ctx context.Context, files []*multipart.FileHeader, caseID uuid.UUID) ([]entity.MediaUploadResult, error)
+	Upload(ctx context.Context, file *multipart.FileHeader, caseID uuid.UUID, sensitive bool) (*entity.MediaUploadResult, error)
+	UploadMultiple(ctx context.Context, files []*multipart.FileHeader, caseID uuid.UUID, sensitive bool) ([]entity.MediaUploadResult, error)
 	Delete(ctx context.Context, mediaID uuid.UUID) error
 	GetByCaseID(ctx context.Context, caseID uuid.UUID) ([]entity.CaseMedia, error)
+
+	// Direct-to-storage uploads
+	CreateUploadURL(ctx context.Context, req *request.CreateUploadURLRequest) (*entity.MediaUploadURL, error)
+	FinalizeUpload(ctx context.Context, req *request.FinalizeUploadRequest, userID uuid.UUID) (*entity.MediaUploadResult, error)
+	AttachStoredObject(ctx context.Context, caseID, mediaID uuid.UUID, key, fileName string, mediaType enum.MediaType, size int64, uploadedBy *uuid.UUID) (*entity.MediaUploadResult, error)
+
+	// ResolveURLs replaces stored URLs with what a viewer may see: signed
+	// URLs for private objects, and placeholders for sensitive media the
+	// viewer is not allowed to see
+	ResolveURLs(ctx context.Context, media []entity.CaseMedia, canViewSensitive bool)
 }
 
 type mediaService struct {
-	mediaRepo     repository.MediaRepository
-	storageClient storage.Client
-	log           *zap.Logger
+	mediaRepo       repository.MediaRepository
+	caseRepo        repository.CaseRepository
+	storageClient   storage.Client
+	presignExpiry   time.Duration
+	private         bool
+	signedURLExpiry time.Duration
+	log             *zap.Logger
 }
 
 // NewMediaService creates a new MediaService
-func NewMediaService(mediaRepo repository.MediaRepository, storageClient storage.Client, log *zap.Logger) MediaService {
+func NewMediaService(cfg *config.Config, mediaRepo repository.MediaRepository, caseRepo repository.CaseRepository, storageClient storage.Client, log *zap.Logger) MediaService {
+	presignExpiry := cfg.S3.PresignExpiry
+	if presignExpiry <= 0 {
+		presignExpiry = 15 * time.Minute
+	}
+	signedURLExpiry := cfg.Storage.SignedURLExpiry
+	if signedURLExpiry <= 0 {
+		signedURLExpiry = 15 * time.Minute
+	}
+
 	return &mediaService{
-		mediaRepo:     mediaRepo,
-		storageClient: storageClient,
-		log:           log,
+		mediaRepo:       mediaRepo,
+		caseRepo:        caseRepo,
+		storageClient:   storageClient,
+		presignExpiry:   presignExpiry,
+		private:         cfg.Storage.Private,
+		signedURLExpiry: signedURLExpiry,
+		log:             log,
 	}
 }
 
@@ -58,7 +89,7 @@ var allowedVideoTypes = map[string]bool{
 
 const maxFileSize = 50 * 1024 * 1024 // 50MB
 
-func (s *mediaService) Upload(ctx context.Context, file *multipart.FileHeader, caseID uuid.UUID) (*entity.MediaUploadResult, error) {
+func (s *mediaService) Upload(ctx context.Context, file *multipart.FileHeader, caseID uuid.UUID, sensitive bool) (*entity.MediaUploadResult, error) {
 	// Validate file size
 	if file.Size > maxFileSize {
 		return nil, middleware.NewAppError("FILE_TOO_LARGE", "File size exceeds 50MB limit", 400)
@@ -66,15 +97,16 @@ func (s *mediaService) Upload(ctx context.Context, file *multipart.FileHeader, c
 
 	// Determine media type
 	ext := strings.ToLower(filepath.Ext(file.Filename))
-	var mediaType enum.MediaType
-	if allowedImageTypes[ext] {
-		mediaType = enum.MediaTypeImage
-	} else if allowedVideoTypes[ext] {
-		mediaType = enum.MediaTypeVideo
-	} else {
+	mediaType, ok := mediaTypeFromExt(ext)
+	if !ok {
 		return nil, middleware.NewAppError("INVALID_FILE_TYPE", "File type not allowed", 400)
 	}
 
+	sensitive, err := s.resolveSensitivity(ctx, caseID, sensitive)
+	if err != nil {
+		return nil, err
+	}
+
 	// Open file
 	src, err := file.Open()
 	if err != nil {
@@ -83,68 +115,51 @@ func (s *mediaService) Upload(ctx context.Context, file *multipart.FileHeader, c
 	}
 	defer src.Close()
 
-	// Read file content
-	content, err := io.ReadAll(src)
-	if err != nil {
-		s.log.Error("Failed to read file content", zap.Error(err))
-		return nil, err
+	// Render the placeholder from the upload itself before streaming it out
+	var placeholder *string
+	if mediaType == enum.MediaTypeImage {
+		placeholder = s.renderPlaceholder(src, file.Filename)
+		if _, err := src.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
 	}
 
 	// Generate unique filename
 	mediaID := uuid.New()
-	filename := fmt.Sprintf("cases/%s/%s%s", caseID.String(), mediaID.String(), ext)
+	filename := mediaKey(caseID, mediaID, ext, sensitive)
 
-	// Upload to storage
-	fileURL, err := s.storageClient.Upload(ctx, filename, content, file.Header.Get("Content-Type"))
-	if err != nil {
+	// Stream to storage
+	if _, err := s.storageClient.Put(ctx, filename, src, file.Size, storage.PutOptions{
+		ContentType: file.Header.Get("Content-Type"),
+	}); err != nil {
 		s.log.Error("Failed to upload file to storage", zap.Error(err))
 		return nil, err
 	}
 
-	// Generate thumbnail for images
-	var thumbnailURL *string
-	if mediaType == enum.MediaTypeImage {
-		thumbFilename := fmt.Sprintf("cases/%s/%s_thumb%s", caseID.String(), mediaID.String(), ext)
-		// TODO: Implement actual thumbnail generation
-		// For now, use the same URL
-		thumbURL := fileURL
-		thumbnailURL = &thumbURL
-		_ = thumbFilename
-	}
-
-	// Save to database
-	media := &entity.CaseMedia{
-		ID:           mediaID,
-		CaseID:       caseID,
-		MediaType:    mediaType,
-		URL:          fileURL,
-		ThumbnailURL: thumbnailURL,
-		FileName:     file.Filename,
-		FileSize:     file.Size,
-		CreatedAt:    time.Now(),
-	}
-
-	if err := s.mediaRepo.Create(ctx, media); err != nil {
-		s.log.Error("Failed to save media record", zap.Error(err))
+	result, err := s.attach(ctx, &entity.CaseMedia{
+		ID:          mediaID,
+		CaseID:      caseID,
+		MediaType:   mediaType,
+		StorageKey:  filename,
+		FileName:    file.Filename,
+		FileSize:    file.Size,
+		IsSensitive: sensitive,
+		Placeholder: placeholder,
+	})
+	if err != nil {
 		// Try to delete uploaded file
 		_ = s.storageClient.Delete(ctx, filename)
 		return nil, err
 	}
 
-	return &entity.MediaUploadResult{
-		ID:           mediaID,
-		URL:          fileURL,
-		ThumbnailURL: thumbnailURL,
-		MediaType:    mediaType,
-		FileSize:     file.Size,
-	}, nil
+	return result, nil
 }
 
-func (s *mediaService) UploadMultiple(ctx context.Context, files []*multipart.FileHeader, caseID uuid.UUID) ([]entity.MediaUploadResult, error) {
+func (s *mediaService) UploadMultiple(ctx context.Context, files []*multipart.FileHeader, caseID uuid.UUID, sensitive bool) ([]entity.MediaUploadResult, error) {
 	results := make([]entity.MediaUploadResult, 0, len(files))
 
 	for _, file := range files {
-		result, err := s.Upload(ctx, file, caseID)
+		result, err := s.Upload(ctx, file, caseID, sensitive)
 		if err != nil {
 			s.log.Warn("Failed to upload file", zap.String("filename", file.Filename), zap.Error(err))
 			continue
@@ -165,14 +180,9 @@ func (s *mediaService) Delete(ctx context.Context, mediaID uuid.UUID) error {
 	}
 
 	// Delete from storage
-	if err := s.storageClient.Delete(ctx, media.URL); err != nil {
-		s.log.Warn("Failed to delete file from storage", zap.Error(err))
-	}
-
-	// Delete thumbnail if exists
-	if media.ThumbnailURL != nil {
-		if err := s.storageClient.Delete(ctx, *media.ThumbnailURL); err != nil {
-			s.log.Warn("Failed to delete thumbnail from storage", zap.Error(err))
+	if media.StorageKey != "" {
+		if err := s.storageClient.Delete(ctx, media.StorageKey); err != nil {
+			s.log.Warn("Failed to delete file from storage", zap.Error(err))
 		}
 	}
 
@@ -188,3 +198,268 @@ func (s *mediaService) Delete(ctx context.Context, mediaID uuid.UUID) error {
 func (s *mediaService) GetByCaseID(ctx context.Context, caseID uuid.UUID) ([]entity.CaseMedia, error) {
 	return s.mediaRepo.GetByCaseID(ctx, caseID)
 }
+
+func (s *mediaService) CreateUploadURL(ctx context.Context, req *request.CreateUploadURLRequest) (*entity.MediaUploadURL, error) {
+	if req.CaseID == uuid.Nil {
+		return nil, middleware.NewAppError("VALIDATION_ERROR", "Case ID is required", 400)
+	}
+	if req.FileSize <= 0 {
+		return nil, middleware.NewAppError("VALIDATION_ERROR", "File size is required", 400)
+	}
+
+	// Extension and MIME type must agree on the kind of media
+	ext := strings.ToLower(filepath.Ext(req.FileName))
+	mediaType, ok := mediaTypeFromExt(ext)
+	if !ok || !entity.IsAllowedMediaType(req.ContentType) || entity.GetMediaTypeFromMIME(req.ContentType) != mediaType {
+		return nil, middleware.NewAppError("INVALID_FILE_TYPE", "File type not allowed", 400)
+	}
+
+	if req.FileSize > entity.GetMaxSizeForType(mediaType) {
+		return nil, middleware.NewAppError("FILE_TOO_LARGE",
+			fmt.Sprintf("File size exceeds %dMB limit", entity.GetMaxSizeForType(mediaType)/(1024*1024)), 400)
+	}
+
+	sensitive, err := s.resolveSensitivity(ctx, req.CaseID, req.IsSensitive)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaID := uuid.New()
+	key := mediaKey(req.CaseID, mediaID, ext, sensitive)
+
+	upload, err := s.storageClient.PresignUpload(ctx, key, req.ContentType, req.FileSize, s.presignExpiry)
+	if err != nil {
+		s.log.Error("Failed to presign upload", zap.Error(err))
+		return nil, err
+	}
+
+	return &entity.MediaUploadURL{
+		MediaID:   mediaID,
+		Key:       key,
+		UploadURL: upload.URL,
+		Method:    upload.Method,
+		Headers:   upload.Headers,
+		ExpiresAt: upload.ExpiresAt,
+	}, nil
+}
+
+func (s *mediaService) FinalizeUpload(ctx context.Context, req *request.FinalizeUploadRequest, userID uuid.UUID) (*entity.MediaUploadResult, error) {
+	mediaID, ext, err := parseMediaKey(req.CaseID, req.Key)
+	if err != nil {
+		return nil, middleware.NewAppError("VALIDATION_ERROR", "Invalid upload key", 400)
+	}
+
+	mediaType, ok := mediaTypeFromExt(ext)
+	if !ok {
+		return nil, middleware.NewAppError("INVALID_FILE_TYPE", "File type not allowed", 400)
+	}
+
+	existing, err := s.mediaRepo.GetByID(ctx, mediaID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, middleware.NewAppError("ALREADY_FINALIZED", "Upload has already been finalized", 409)
+	}
+
+	// Verify what actually landed in storage before trusting it
+	info, err := s.storageClient.Stat(ctx, req.Key)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, middleware.NewAppError("UPLOAD_NOT_FOUND", "Uploaded file not found", 404)
+		}
+		return nil, err
+	}
+
+	if !entity.IsAllowedMediaType(info.ContentType) || entity.GetMediaTypeFromMIME(info.ContentType) != mediaType {
+		_ = s.storageClient.Delete(ctx, req.Key)
+		return nil, middleware.NewAppError("INVALID_FILE_TYPE", "File type not allowed", 400)
+	}
+	if info.Size <= 0 || info.Size > entity.GetMaxSizeForType(mediaType) {
+		_ = s.storageClient.Delete(ctx, req.Key)
+		return nil, middleware.NewAppError("FILE_TOO_LARGE", "Uploaded file exceeds size limit", 400)
+	}
+
+	fileName := req.FileName
+	if fileName == "" {
+		fileName = filepath.Base(req.Key)
+	}
+
+	return s.AttachStoredObject(ctx, req.CaseID, mediaID, req.Key, fileName, mediaType, info.Size, &userID)
+}
+
+// AttachStoredObject records an object already written to storage as case
+// media. Objects under the private prefix are recorded as sensitive.
+func (s *mediaService) AttachStoredObject(ctx context.Context, caseID, mediaID uuid.UUID, key, fileName string, mediaType enum.MediaType, size int64, uploadedBy *uuid.UUID) (*entity.MediaUploadResult, error) {
+	var placeholder *string
+	if mediaType == enum.MediaTypeImage {
+		if body, _, err := s.storageClient.Get(ctx, key); err == nil {
+			placeholder = s.renderPlaceholder(body, key)
+			body.Close()
+		} else {
+			s.log.Warn("Failed to read media for placeholder", zap.String("key", key), zap.Error(err))
+		}
+	}
+
+	result, err := s.attach(ctx, &entity.CaseMedia{
+		ID:          mediaID,
+		CaseID:      caseID,
+		MediaType:   mediaType,
+		StorageKey:  key,
+		FileName:    fileName,
+		FileSize:    size,
+		UploadedBy:  uploadedBy,
+		IsSensitive: strings.HasPrefix(key, storage.PrivatePrefix),
+		Placeholder: placeholder,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Stored media attached",
+		zap.String("media_id", mediaID.String()),
+		zap.String("case_id", caseID.String()),
+		zap.Int64("size", size),
+	)
+
+	return result, nil
+}
+
+// attach saves a media record for a stored object and returns the result
+// shown to the uploader, who may always see what they uploaded
+func (s *mediaService) attach(ctx context.Context, media *entity.CaseMedia) (*entity.MediaUploadResult, error) {
+	media.URL = s.storageClient.GetURL(media.StorageKey)
+	if media.MediaType == enum.MediaTypeImage {
+		// TODO: Implement actual thumbnail generation
+		thumbnailURL := media.URL
+		media.ThumbnailURL = &thumbnailURL
+	}
+	media.CreatedAt = time.Now()
+
+	if err := s.mediaRepo.Create(ctx, media); err != nil {
+		s.log.Error("Failed to save media record", zap.Error(err))
+		return nil, err
+	}
+
+	resolved := []entity.CaseMedia{*media}
+	s.ResolveURLs(ctx, resolved, true)
+
+	return &entity.MediaUploadResult{
+		ID:           media.ID,
+		URL:          resolved[0].URL,
+		ThumbnailURL: resolved[0].ThumbnailURL,
+		MediaType:    media.MediaType,
+		FileSize:     media.FileSize,
+		IsSensitive:  media.IsSensitive,
+	}, nil
+}
+
+func (s *mediaService) ResolveURLs(ctx context.Context, media []entity.CaseMedia, canViewSensitive bool) {
+	for i := range media {
+		m := &media[i]
+
+		if m.IsSensitive && !canViewSensitive {
+			redactMedia(m)
+			continue
+		}
+		if !s.private && !m.IsSensitive {
+			continue
+		}
+
+		signed, err := s.storageClient.PresignGet(ctx, m.StorageKey, s.signedURLExpiry)
+		if err != nil {
+			s.log.Warn("Failed to sign media URL", zap.String("media_id", m.ID.String()), zap.Error(err))
+			redactMedia(m)
+			continue
+		}
+
+		expiresAt := time.Now().Add(s.signedURLExpiry)
+		m.URL = signed
+		m.URLExpiresAt = &expiresAt
+		if m.ThumbnailURL != nil {
+			m.ThumbnailURL = &signed
+		}
+	}
+}
+
+// resolveSensitivity checks the case exists and applies its default: photos
+// of accident scenes are sensitive unless stated otherwise
+func (s *mediaService) resolveSensitivity(ctx context.Context, caseID uuid.UUID, requested bool) (bool, error) {
+	c, err := s.caseRepo.GetByID(ctx, caseID)
+	if err != nil {
+		return false, err
+	}
+	if c == nil {
+		return false, middleware.ErrCaseNotFound
+	}
+
+	return requested || sensitiveByDefault(c), nil
+}
+
+// renderPlaceholder returns a blurred data URI for an image, or nil if the
+// image cannot be decoded
+func (s *mediaService) renderPlaceholder(r io.Reader, name string) *string {
+	placeholder, err := imaging.Placeholder(r)
+	if err != nil {
+		s.log.Debug("No placeholder for media", zap.String("file", name), zap.Error(err))
+		return nil
+	}
+	return &placeholder
+}
+
+// sensitiveByDefault reports whether media on a case is sensitive when the
+// uploader does not say
+func sensitiveByDefault(c *entity.Case) bool {
+	return c.CaseType == enum.CaseTypeAccident
+}
+
+// redactMedia swaps a media item's URLs for its placeholder
+func redactMedia(m *entity.CaseMedia) {
+	m.URL = ""
+	m.ThumbnailURL = nil
+	if m.Placeholder != nil {
+		m.URL = *m.Placeholder
+		m.ThumbnailURL = m.Placeholder
+	}
+	m.URLExpiresAt = nil
+	m.Redacted = true
+}
+
+// mediaTypeFromExt maps a lowercase file extension to its media type
+func mediaTypeFromExt(ext string) (enum.MediaType, bool) {
+	if allowedImageTypes[ext] {
+		return enum.MediaTypeImage, true
+	}
+	if allowedVideoTypes[ext] {
+		return enum.MediaTypeVideo, true
+	}
+	return "", false
+}
+
+// mediaKey builds the storage key for a case media file. Sensitive media is
+// kept under the private prefix, which is never publicly readable.
+func mediaKey(caseID, mediaID uuid.UUID, ext string, sensitive bool) string {
+	key := fmt.Sprintf("cases/%s/%s%s", caseID.String(), mediaID.String(), ext)
+	if sensitive {
+		key = storage.PrivatePrefix + key
+	}
+	return key
+}
+
+// parseMediaKey validates a key built by mediaKey and extracts its media ID and extension
+func parseMediaKey(caseID uuid.UUID, key string) (uuid.UUID, string, error) {
+	prefix := fmt.Sprintf("cases/%s/", caseID.String())
+	key = strings.TrimPrefix(key, storage.PrivatePrefix)
+	if !strings.HasPrefix(key, prefix) {
+		return uuid.Nil, "", errors.New("key does not belong to case")
+	}
+
+	name := strings.TrimPrefix(key, prefix)
+	ext := strings.ToLower(filepath.Ext(name))
+	mediaID, err := uuid.Parse(strings.TrimSuffix(name, filepath.Ext(name)))
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+
+	return mediaID, ext, nil
+}
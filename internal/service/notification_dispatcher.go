@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/config"
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/domain/enum"
+	"bamboo-rescue/internal/repository"
+	"bamboo-rescue/pkg/clock"
+	"go.uber.org/zap"
+)
+
+// JobSendPushSummary is the outbox job that pushes a summary of the
+// notifications push throttling held back
+const JobSendPushSummary = "notification.push_summary"
+
+// pushSummaryJob is the payload of JobSendPushSummary
+type pushSummaryJob struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// NotificationDispatcher delivers a notification over every channel the
+// recipient allows for its type: the in-app inbox, push and email. It is the
+// one place notification preferences are enforced.
+//
+// Pushes are also rate limited per user: past the budget they are held back
+// and rolled into one summary push, except for critical cases.
+type NotificationDispatcher interface {
+	Dispatch(ctx context.Context, userID uuid.UUID, notification *entity.NotificationPayload) error
+}
+
+type notificationDispatcher struct {
+	cfg              config.NotifyConfig
+	userRepo         repository.UserRepository
+	notificationRepo repository.NotificationRepository
+	tx               repository.Transactor
+	outboxSvc        OutboxService
+	pushSvc          PushService
+	emailSvc         EmailService
+	clock            clock.Clock
+	log              *zap.Logger
+}
+
+// NewNotificationDispatcher creates a new NotificationDispatcher. pushSvc and
+// emailSvc may be nil to leave that channel out.
+func NewNotificationDispatcher(
+	cfg *config.Config,
+	userRepo repository.UserRepository,
+	notificationRepo repository.NotificationRepository,
+	tx repository.Transactor,
+	outboxSvc OutboxService,
+	pushSvc PushService,
+	emailSvc EmailService,
+	clk clock.Clock,
+	log *zap.Logger,
+) NotificationDispatcher {
+	d := &notificationDispatcher{
+		cfg:              cfg.Notify,
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		tx:               tx,
+		outboxSvc:        outboxSvc,
+		pushSvc:          pushSvc,
+		emailSvc:         emailSvc,
+		clock:            clk,
+		log:              log,
+	}
+
+	outboxSvc.Handle(JobSendPushSummary, d.sendPushSummary)
+
+	return d
+}
+
+// Dispatch records and queues the notification first, then pushes it. An
+// error means nothing was sent, so callers may safely retry; push failures
+// are only logged because a retry would duplicate the inbox entry and email.
+func (d *notificationDispatcher) Dispatch(ctx context.Context, userID uuid.UUID, notification *entity.NotificationPayload) error {
+	user, err := d.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsActive {
+		return nil
+	}
+
+	prefs := user.Preferences
+	if prefs == nil {
+		// Users who never saved preferences get the defaults
+		prefs = &entity.UserPreferences{PushEnabled: true, EmailEnabled: true}
+	}
+
+	var inbox *entity.Notification
+	err = d.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if prefs.AllowsChannel(notification.Type, enum.NotificationChannelInApp) {
+			inbox = newInboxNotification(userID, notification)
+			if err := d.notificationRepo.Create(ctx, inbox); err != nil {
+				return err
+			}
+		}
+		if d.emailSvc != nil && prefs.AllowsChannel(notification.Type, enum.NotificationChannelEmail) {
+			return d.emailSvc.Notify(ctx, userID, notification)
+		}
+		return nil
+	})
+	if err != nil {
+		d.log.Error("Failed to record notification", zap.String("user_id", userID.String()), zap.Error(err))
+		return err
+	}
+
+	if d.pushSvc == nil || !prefs.AllowsChannel(notification.Type, enum.NotificationChannelPush) {
+		return nil
+	}
+
+	admitted, err := d.admitPush(ctx, userID, notification)
+	if err != nil {
+		// Fail open: a missed rate limit is better than a missed alert
+		d.log.Warn("Failed to check push budget", zap.String("user_id", userID.String()), zap.Error(err))
+	} else if !admitted {
+		return nil
+	}
+
+	if err := d.pushSvc.SendToUser(ctx, userID, d.withBadge(ctx, userID, notification)); err != nil {
+		d.log.Warn("Failed to push notification", zap.String("user_id", userID.String()), zap.Error(err))
+		return nil
+	}
+
+	if inbox != nil {
+		if err := d.notificationRepo.MarkAsPushed(ctx, inbox.ID); err != nil {
+			d.log.Warn("Failed to mark notification as pushed", zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// newInboxNotification builds the in-app copy of a notification
+func newInboxNotification(userID uuid.UUID, n *entity.NotificationPayload) *entity.Notification {
+	notification := &entity.Notification{
+		UserID:           userID,
+		NotificationType: n.Type,
+		Title:            n.Title,
+		CaseID:           n.CaseID,
+	}
+	if n.Body != "" {
+		body := n.Body
+		notification.Body = &body
+	}
+	return notification
+}
+
+// withBadge returns a copy of the notification carrying the user's unread
+// count for the app icon badge. Without the count the push goes out as is.
+func (d *notificationDispatcher) withBadge(ctx context.Context, userID uuid.UUID, notification *entity.NotificationPayload) *entity.NotificationPayload {
+	unread, err := d.notificationRepo.GetUnreadCount(ctx, userID)
+	if err != nil {
+		d.log.Warn("Failed to count unread notifications", zap.String("user_id", userID.String()), zap.Error(err))
+		return notification
+	}
+
+	badge := int(unread)
+	withBadge := *notification
+	withBadge.Badge = &badge
+	return &withBadge
+}
+
+// admitPush charges a push to the user's budget, reporting false if it must
+// be held back for the next summary instead
+func (d *notificationDispatcher) admitPush(ctx context.Context, userID uuid.UUID, notification *entity.NotificationPayload) (bool, error) {
+	if d.cfg.PushBudget <= 0 {
+		return true, nil
+	}
+	bypass := notification.IsCritical()
+
+	admitted := false
+	err := d.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		now := d.clock.Now()
+		throttle, err := d.notificationRepo.LockPushThrottle(ctx, userID, now)
+		if err != nil {
+			return err
+		}
+
+		admitted = throttle.Admit(now, d.cfg.PushBudget, d.cfg.PushWindow, bypass)
+		if !admitted && throttle.Hold(notification.Type, now, d.cfg.SummaryDelay) {
+			job := pushSummaryJob{UserID: userID}
+			if err := d.outboxSvc.EnqueueAt(ctx, JobSendPushSummary, job, *throttle.SummaryDueAt); err != nil {
+				return err
+			}
+		}
+
+		return d.notificationRepo.SavePushThrottle(ctx, throttle)
+	})
+	return admitted, err
+}
+
+// sendPushSummary pushes one notification standing in for everything held
+// back since the last summary. The summary itself is not rate limited.
+func (d *notificationDispatcher) sendPushSummary(ctx context.Context, payload json.RawMessage) error {
+	var job pushSummaryJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return PermanentJobError(err)
+	}
+
+	var held entity.NotificationCounts
+	err := d.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		throttle, err := d.notificationRepo.LockPushThrottle(ctx, job.UserID, d.clock.Now())
+		if err != nil {
+			return err
+		}
+		held = throttle.TakeHeld()
+		return d.notificationRepo.SavePushThrottle(ctx, throttle)
+	})
+	if err != nil || held.Total() == 0 {
+		return err
+	}
+
+	user, err := d.userRepo.GetByID(ctx, job.UserID)
+	if err != nil {
+		return err
+	}
+	if d.pushSvc == nil || user == nil || !user.IsActive {
+		return nil
+	}
+	if user.Preferences != nil && !user.Preferences.PushEnabled {
+		return nil
+	}
+
+	summary := entity.PushSummaryNotificationPayload(held, user.Locale)
+	if err := d.pushSvc.SendToUser(ctx, job.UserID, d.withBadge(ctx, job.UserID, summary)); err != nil {
+		d.log.Warn("Failed to push notification summary", zap.String("user_id", job.UserID.String()), zap.Error(err))
+		return nil
+	}
+
+	d.log.Info("Pushed notification summary",
+		zap.String("user_id", job.UserID.String()),
+		zap.Int("held", held.Total()),
+	)
+
+	return nil
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/config"
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/domain/enum"
+	"bamboo-rescue/internal/repository"
+	"bamboo-rescue/pkg/clock"
+	"go.uber.org/zap"
+)
+
+// dispatcherUserRepo returns one active user with default preferences
+type dispatcherUserRepo struct {
+	repository.UserRepository
+	user *entity.User
+}
+
+func (r *dispatcherUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	if id != r.user.ID {
+		return nil, nil
+	}
+	return r.user, nil
+}
+
+// dispatcherNotificationRepo keeps the inbox and push throttle in memory
+type dispatcherNotificationRepo struct {
+	repository.NotificationRepository
+	throttle *entity.PushThrottle
+}
+
+func (r *dispatcherNotificationRepo) Create(ctx context.Context, n *entity.Notification) error {
+	n.ID = uuid.New()
+	return nil
+}
+
+func (r *dispatcherNotificationRepo) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return 0, nil
+}
+
+func (r *dispatcherNotificationRepo) MarkAsPushed(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func (r *dispatcherNotificationRepo) LockPushThrottle(ctx context.Context, userID uuid.UUID, now time.Time) (*entity.PushThrottle, error) {
+	if r.throttle == nil {
+		r.throttle = &entity.PushThrottle{UserID: userID, WindowStart: now}
+	}
+	copied := *r.throttle
+	return &copied, nil
+}
+
+func (r *dispatcherNotificationRepo) SavePushThrottle(ctx context.Context, throttle *entity.PushThrottle) error {
+	copied := *throttle
+	r.throttle = &copied
+	return nil
+}
+
+// inlineTransactor runs the work without a database
+type inlineTransactor struct{}
+
+func (inlineTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// queuedJob is a job recorded by recordingOutbox
+type queuedJob struct {
+	kind    string
+	payload json.RawMessage
+	runAt   time.Time
+}
+
+// recordingOutbox records queued jobs so a test can check and run them
+type recordingOutbox struct {
+	OutboxService
+	handlers map[string]OutboxHandler
+	jobs     []queuedJob
+}
+
+func (o *recordingOutbox) Handle(kind string, handler OutboxHandler) {
+	if o.handlers == nil {
+		o.handlers = make(map[string]OutboxHandler)
+	}
+	o.handlers[kind] = handler
+}
+
+func (o *recordingOutbox) Enqueue(ctx context.Context, kind string, payload interface{}) error {
+	return o.EnqueueAt(ctx, kind, payload, time.Time{})
+}
+
+func (o *recordingOutbox) EnqueueAt(ctx context.Context, kind string, payload interface{}, runAt time.Time) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	o.jobs = append(o.jobs, queuedJob{kind: kind, payload: data, runAt: runAt})
+	return nil
+}
+
+// recordingPush records the pushes sent
+type recordingPush struct {
+	sent []*entity.NotificationPayload
+}
+
+func (p *recordingPush) SendToUser(ctx context.Context, userID uuid.UUID, n *entity.NotificationPayload) error {
+	p.sent = append(p.sent, n)
+	return nil
+}
+
+func (p *recordingPush) SendToUsers(ctx context.Context, userIDs []uuid.UUID, n *entity.NotificationPayload) error {
+	for range userIDs {
+		p.sent = append(p.sent, n)
+	}
+	return nil
+}
+
+func TestDispatcherPushBudget(t *testing.T) {
+	const (
+		budget       = 2
+		window       = time.Hour
+		summaryDelay = 15 * time.Minute
+	)
+	low, critical := enum.UrgencyLow, enum.UrgencyCritical
+
+	// step dispatches one notification after advancing the clock
+	type step struct {
+		advance time.Duration
+		urgency *enum.UrgencyLevel
+		// wantPushed is whether it goes out at once
+		wantPushed bool
+		// wantSummaryAt is when a summary is queued by this step, if one is
+		wantSummaryAt time.Duration
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+		// wantHeld is what the summary job pushes in the end
+		wantHeld int
+	}{
+		{
+			name: "pushes past the budget are held for one summary",
+			steps: []step{
+				{urgency: &low, wantPushed: true},
+				{advance: time.Minute, urgency: &low, wantPushed: true},
+				{advance: time.Minute, urgency: &low, wantSummaryAt: 2*time.Minute + summaryDelay},
+				{advance: time.Minute, urgency: &low},
+			},
+			wantHeld: 2,
+		},
+		{
+			name: "critical pushes go out past the budget",
+			steps: []step{
+				{urgency: &low, wantPushed: true},
+				{urgency: &low, wantPushed: true},
+				{urgency: &critical, wantPushed: true},
+				{advance: time.Minute, urgency: &low, wantSummaryAt: time.Minute + summaryDelay},
+			},
+			wantHeld: 1,
+		},
+		{
+			name: "a new window restores the budget",
+			steps: []step{
+				{urgency: &low, wantPushed: true},
+				{urgency: &low, wantPushed: true},
+				{advance: window, urgency: &low, wantPushed: true},
+				{urgency: &low, wantPushed: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			epoch := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+			clk := clock.NewFake(epoch)
+			user := &entity.User{ID: uuid.New(), IsActive: true, Locale: enum.LocaleEnglish}
+			notifications := &dispatcherNotificationRepo{}
+			outbox := &recordingOutbox{}
+			push := &recordingPush{}
+			cfg := &config.Config{Notify: config.NotifyConfig{
+				PushBudget:   budget,
+				PushWindow:   window,
+				SummaryDelay: summaryDelay,
+			}}
+
+			d := NewNotificationDispatcher(cfg, &dispatcherUserRepo{user: user}, notifications,
+				inlineTransactor{}, outbox, push, nil, clk, zap.NewNop())
+
+			for i, s := range tt.steps {
+				clk.Advance(s.advance)
+				pushed, queued := len(push.sent), len(outbox.jobs)
+
+				payload := &entity.NotificationPayload{Type: enum.NotificationTypeNewCaseNearby, Title: "Case", Urgency: s.urgency}
+				if err := d.Dispatch(context.Background(), user.ID, payload); err != nil {
+					t.Fatalf("step %d: Dispatch() error = %v", i, err)
+				}
+
+				if got := len(push.sent) > pushed; got != s.wantPushed {
+					t.Fatalf("step %d: pushed = %v, want %v", i, got, s.wantPushed)
+				}
+				newJobs := outbox.jobs[queued:]
+				if s.wantSummaryAt == 0 {
+					if len(newJobs) != 0 {
+						t.Fatalf("step %d: queued %d jobs, want none", i, len(newJobs))
+					}
+					continue
+				}
+				if len(newJobs) != 1 || newJobs[0].kind != JobSendPushSummary {
+					t.Fatalf("step %d: queued %v, want one %s job", i, newJobs, JobSendPushSummary)
+				}
+				want := epoch.Add(s.wantSummaryAt)
+				if !newJobs[0].runAt.Equal(want) {
+					t.Fatalf("step %d: summary queued for %v, want %v", i, newJobs[0].runAt, want)
+				}
+				if due := notifications.throttle.SummaryDueAt; due == nil || !due.Equal(newJobs[0].runAt) {
+					t.Fatalf("step %d: SummaryDueAt = %v, want the job's run time %v", i, due, newJobs[0].runAt)
+				}
+			}
+
+			if tt.wantHeld == 0 {
+				return
+			}
+
+			// Run the summary when it falls due
+			var summary *queuedJob
+			for i := range outbox.jobs {
+				if outbox.jobs[i].kind == JobSendPushSummary {
+					summary = &outbox.jobs[i]
+				}
+			}
+			clk.Advance(summary.runAt.Sub(clk.Now()))
+			pushed := len(push.sent)
+			if err := outbox.handlers[JobSendPushSummary](context.Background(), summary.payload); err != nil {
+				t.Fatalf("summary job error = %v", err)
+			}
+			if len(push.sent) != pushed+1 {
+				t.Fatalf("summary job pushed %d, want 1", len(push.sent)-pushed)
+			}
+			got := push.sent[len(push.sent)-1]
+			want := entity.SummaryLine(enum.NotificationTypeNewCaseNearby, tt.wantHeld, user.Locale)
+			if got.Collapse != "summary" || got.Title != want {
+				t.Fatalf("summary push = %q (collapse %q), want %q", got.Title, got.Collapse, want)
+			}
+			if held := notifications.throttle.Held.Total(); held != 0 || notifications.throttle.SummaryDueAt != nil {
+				t.Fatalf("after summary: held %d, SummaryDueAt %v, want both reset", held, notifications.throttle.SummaryDueAt)
+			}
+		})
+	}
+}
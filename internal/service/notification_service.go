@@ -6,20 +6,35 @@ import (
 	"github.com/google/uuid"
 	"bamboo-rescue/internal/domain/entity"
 	"bamboo-rescue/internal/domain/enum"
+	"bamboo-rescue/internal/handler/dto/request"
+	"bamboo-rescue/internal/middleware"
 	"bamboo-rescue/internal/repository"
 	"go.uber.org/zap"
 )
 
 // NotificationService defines the interface for notification operations
 type NotificationService interface {
-	GetByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Notification, int64, error)
+	// GetByUser returns a page of the user's notifications and the cursor of
+	// the next page, nil on the last one
+	GetByUser(ctx context.Context, userID uuid.UUID, req *request.GetNotificationsRequest) ([]entity.Notification, *entity.NotificationCursor, error)
 	GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, error)
 	MarkAsRead(ctx context.Context, userID uuid.UUID, notificationID uuid.UUID) error
 	MarkAllAsRead(ctx context.Context, userID uuid.UUID) error
+	DeleteOne(ctx context.Context, userID uuid.UUID, notificationID uuid.UUID) error
+
+	// Bulk changes skip IDs that are not the user's and return how many
+	// notifications changed
+	MarkManyAsRead(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error)
+	Archive(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error)
+	Delete(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error)
+
 	Create(ctx context.Context, notification *entity.Notification) error
 	CreateForCase(ctx context.Context, caseID uuid.UUID, userID uuid.UUID, notificationType enum.NotificationType, title string, body *string) error
 }
 
+// maxBulkNotifications bounds the IDs one bulk change may list
+const maxBulkNotifications = 100
+
 type notificationService struct {
 	notificationRepo repository.NotificationRepository
 	log              *zap.Logger
@@ -33,14 +48,47 @@ func NewNotificationService(notificationRepo repository.NotificationRepository,
 	}
 }
 
-func (s *notificationService) GetByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Notification, int64, error) {
-	notifications, total, err := s.notificationRepo.GetByUserID(ctx, userID, limit, offset)
+func (s *notificationService) GetByUser(ctx context.Context, userID uuid.UUID, req *request.GetNotificationsRequest) ([]entity.Notification, *entity.NotificationCursor, error) {
+	filter := repository.NotificationFilter{
+		IsRead:   req.Read,
+		Archived: req.Archived,
+	}
+	if req.Type != nil {
+		if !req.Type.IsValid() {
+			return nil, nil, middleware.NewAppError("VALIDATION_ERROR", "Invalid notification type", 400)
+		}
+		filter.Type = req.Type
+	}
+	if req.CaseID != "" {
+		caseID, err := uuid.Parse(req.CaseID)
+		if err != nil {
+			return nil, nil, middleware.NewAppError("VALIDATION_ERROR", "Invalid case ID", 400)
+		}
+		filter.CaseID = &caseID
+	}
+	if req.Cursor != "" {
+		cursor, err := entity.ParseNotificationCursor(req.Cursor)
+		if err != nil {
+			return nil, nil, middleware.NewAppError("VALIDATION_ERROR", "Invalid cursor", 400)
+		}
+		filter.After = cursor
+	}
+
+	// Fetch one extra row to tell whether another page follows
+	limit := req.GetDefaultLimit()
+	notifications, err := s.notificationRepo.GetByUserID(ctx, userID, filter, limit+1)
 	if err != nil {
 		s.log.Error("Failed to get notifications", zap.Error(err))
-		return nil, 0, err
+		return nil, nil, err
+	}
+
+	var next *entity.NotificationCursor
+	if len(notifications) > limit {
+		notifications = notifications[:limit]
+		next = entity.CursorAfter(&notifications[limit-1])
 	}
 
-	return notifications, total, nil
+	return notifications, next, nil
 }
 
 func (s *notificationService) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
@@ -48,13 +96,87 @@ func (s *notificationService) GetUnreadCount(ctx context.Context, userID uuid.UU
 }
 
 func (s *notificationService) MarkAsRead(ctx context.Context, userID uuid.UUID, notificationID uuid.UUID) error {
-	if err := s.notificationRepo.MarkAsRead(ctx, notificationID); err != nil {
+	if err := s.checkOwner(ctx, userID, notificationID); err != nil {
+		return err
+	}
+	if _, err := s.notificationRepo.MarkAsRead(ctx, userID, []uuid.UUID{notificationID}); err != nil {
 		s.log.Error("Failed to mark notification as read", zap.Error(err))
 		return err
 	}
 	return nil
 }
 
+func (s *notificationService) DeleteOne(ctx context.Context, userID uuid.UUID, notificationID uuid.UUID) error {
+	deleted, err := s.notificationRepo.Delete(ctx, userID, []uuid.UUID{notificationID})
+	if err != nil {
+		s.log.Error("Failed to delete notification", zap.Error(err))
+		return err
+	}
+	if deleted == 0 {
+		return middleware.ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (s *notificationService) MarkManyAsRead(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error) {
+	if err := validateNotificationIDs(ids); err != nil {
+		return 0, err
+	}
+	count, err := s.notificationRepo.MarkAsRead(ctx, userID, ids)
+	if err != nil {
+		s.log.Error("Failed to mark notifications as read", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+func (s *notificationService) Archive(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error) {
+	if err := validateNotificationIDs(ids); err != nil {
+		return 0, err
+	}
+	count, err := s.notificationRepo.Archive(ctx, userID, ids)
+	if err != nil {
+		s.log.Error("Failed to archive notifications", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+func (s *notificationService) Delete(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error) {
+	if err := validateNotificationIDs(ids); err != nil {
+		return 0, err
+	}
+	count, err := s.notificationRepo.Delete(ctx, userID, ids)
+	if err != nil {
+		s.log.Error("Failed to delete notifications", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// checkOwner fails unless the notification exists and belongs to userID
+func (s *notificationService) checkOwner(ctx context.Context, userID, notificationID uuid.UUID) error {
+	n, err := s.notificationRepo.GetByID(ctx, notificationID)
+	if err != nil {
+		return err
+	}
+	// Someone else's notification is reported as missing, not forbidden
+	if n == nil || n.UserID != userID {
+		return middleware.ErrNotificationNotFound
+	}
+	return nil
+}
+
+func validateNotificationIDs(ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return middleware.NewAppError("VALIDATION_ERROR", "At least one notification ID is required", 400)
+	}
+	if len(ids) > maxBulkNotifications {
+		return middleware.NewAppError("VALIDATION_ERROR", "Too many notification IDs", 400)
+	}
+	return nil
+}
+
 func (s *notificationService) MarkAllAsRead(ctx context.Context, userID uuid.UUID) error {
 	if err := s.notificationRepo.MarkAllAsRead(ctx, userID); err != nil {
 		s.log.Error("Failed to mark all notifications as read", zap.Error(err))
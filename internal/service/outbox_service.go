@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/config"
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/domain/enum"
+	"bamboo-rescue/internal/handler/dto/request"
+	"bamboo-rescue/internal/middleware"
+	"bamboo-rescue/internal/repository"
+	"go.uber.org/zap"
+)
+
+// OutboxHandler processes the payload of one outbox job. Returning an error
+// schedules a retry; wrap it with PermanentJobError to dead-letter at once.
+type OutboxHandler func(ctx context.Context, payload json.RawMessage) error
+
+// OutboxService defines the interface for durable background jobs
+type OutboxService interface {
+	// Enqueue records a job. Called with a context from
+	// Transactor.WithinTransaction, the job commits or rolls back with it.
+	Enqueue(ctx context.Context, kind string, payload interface{}) error
+	// EnqueueAt records a job that will not run before runAt
+	EnqueueAt(ctx context.Context, kind string, payload interface{}, runAt time.Time) error
+	Handle(kind string, handler OutboxHandler)
+	Start()
+	// Shutdown stops claiming jobs and waits for running ones to finish
+	Shutdown(ctx context.Context) error
+
+	// Admin
+	GetStuckJobs(ctx context.Context, req *request.GetOutboxJobsRequest) ([]entity.OutboxJob, int64, error)
+	GetStats(ctx context.Context) (*entity.OutboxStats, error)
+	RetryJob(ctx context.Context, id uuid.UUID) (*entity.OutboxJob, error)
+}
+
+// permanentJobError marks a job failure that retrying cannot fix
+type permanentJobError struct {
+	err error
+}
+
+func (e *permanentJobError) Error() string { return e.err.Error() }
+func (e *permanentJobError) Unwrap() error { return e.err }
+
+// PermanentJobError wraps err so the job is dead-lettered without retrying
+func PermanentJobError(err error) error {
+	return &permanentJobError{err: err}
+}
+
+type outboxService struct {
+	outboxRepo repository.OutboxRepository
+	cfg        config.OutboxConfig
+	log        *zap.Logger
+
+	mu       sync.RWMutex
+	handlers map[string]OutboxHandler
+
+	slots   chan struct{}
+	stop    chan struct{}
+	stopped chan struct{}
+	running sync.WaitGroup
+	started bool
+}
+
+// NewOutboxService creates a new OutboxService
+func NewOutboxService(cfg *config.Config, outboxRepo repository.OutboxRepository, log *zap.Logger) OutboxService {
+	outboxCfg := cfg.Outbox
+	if outboxCfg.Workers <= 0 {
+		outboxCfg.Workers = 4
+	}
+	if outboxCfg.PollInterval <= 0 {
+		outboxCfg.PollInterval = time.Second
+	}
+	if outboxCfg.MaxAttempts <= 0 {
+		outboxCfg.MaxAttempts = 8
+	}
+
+	return &outboxService{
+		outboxRepo: outboxRepo,
+		cfg:        outboxCfg,
+		log:        log,
+		handlers:   make(map[string]OutboxHandler),
+		slots:      make(chan struct{}, outboxCfg.Workers),
+		stop:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+}
+
+func (s *outboxService) Enqueue(ctx context.Context, kind string, payload interface{}) error {
+	return s.EnqueueAt(ctx, kind, payload, time.Time{})
+}
+
+func (s *outboxService) EnqueueAt(ctx context.Context, kind string, payload interface{}, runAt time.Time) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s job: %w", kind, err)
+	}
+
+	job := &entity.OutboxJob{
+		Kind:        kind,
+		Payload:     data,
+		MaxAttempts: s.cfg.MaxAttempts,
+		RunAt:       runAt,
+	}
+	if err := s.outboxRepo.Enqueue(ctx, job); err != nil {
+		s.log.Error("Failed to enqueue job", zap.String("kind", kind), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (s *outboxService) Handle(kind string, handler OutboxHandler) {
+	s.mu.Lock()
+	s.handlers[kind] = handler
+	s.mu.Unlock()
+}
+
+func (s *outboxService) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+
+	go s.poll()
+
+	s.log.Info("Outbox worker pool started",
+		zap.Int("workers", s.cfg.Workers),
+		zap.Duration("poll_interval", s.cfg.PollInterval),
+	)
+}
+
+func (s *outboxService) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	started := s.started
+	s.started = false
+	s.mu.Unlock()
+	if !started {
+		return nil
+	}
+
+	close(s.stop)
+	<-s.stopped
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.log.Info("Outbox worker pool drained")
+		return nil
+	case <-ctx.Done():
+		// Unfinished jobs stay locked and are reclaimed after the lock timeout
+		s.log.Warn("Outbox shutdown timed out with jobs still running")
+		return ctx.Err()
+	}
+}
+
+// poll claims due jobs whenever a worker slot is free
+func (s *outboxService) poll() {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	lastCleanup := time.Now()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+
+		// Keep claiming while there is work and free slots
+		for s.claimBatch() {
+		}
+
+		if s.cfg.Retention > 0 && time.Since(lastCleanup) > time.Hour {
+			lastCleanup = time.Now()
+			s.cleanup()
+		}
+	}
+}
+
+// claimBatch claims as many jobs as there are free slots and starts them. It
+// reports whether it filled every free slot, meaning more work may be waiting.
+func (s *outboxService) claimBatch() bool {
+	free := cap(s.slots) - len(s.slots)
+	if free == 0 {
+		return false
+	}
+
+	select {
+	case <-s.stop:
+		return false
+	default:
+	}
+
+	jobs, err := s.outboxRepo.Claim(context.Background(), free, s.cfg.LockTimeout)
+	if err != nil {
+		s.log.Error("Failed to claim outbox jobs", zap.Error(err))
+		return false
+	}
+
+	for _, job := range jobs {
+		s.slots <- struct{}{}
+		s.running.Add(1)
+		go func(job entity.OutboxJob) {
+			defer func() {
+				<-s.slots
+				s.running.Done()
+			}()
+			s.process(job)
+		}(job)
+	}
+
+	return len(jobs) == free
+}
+
+func (s *outboxService) process(job entity.OutboxJob) {
+	// Jobs finish on their own terms during shutdown rather than being cut off
+	ctx := context.Background()
+	log := s.log.With(
+		zap.String("job_id", job.ID.String()),
+		zap.String("kind", job.Kind),
+		zap.Int("attempt", job.Attempts),
+	)
+
+	s.mu.RLock()
+	handler, ok := s.handlers[job.Kind]
+	s.mu.RUnlock()
+
+	var err error
+	switch {
+	case !ok:
+		err = PermanentJobError(fmt.Errorf("no handler registered for job kind %q", job.Kind))
+	case job.Attempts > job.MaxAttempts:
+		// Reclaimed after its worker died on the final attempt
+		err = PermanentJobError(errors.New("attempts exhausted"))
+	default:
+		err = s.run(ctx, handler, job)
+	}
+
+	if err == nil {
+		if err := s.outboxRepo.MarkDone(ctx, job.ID); err != nil {
+			log.Error("Failed to mark job done", zap.Error(err))
+		}
+		return
+	}
+
+	var permanent *permanentJobError
+	if errors.As(err, &permanent) || job.Attempts >= job.MaxAttempts {
+		log.Error("Job dead-lettered", zap.Error(err))
+		if err := s.outboxRepo.MarkDead(ctx, job.ID, err.Error()); err != nil {
+			log.Error("Failed to dead-letter job", zap.Error(err))
+		}
+		return
+	}
+
+	runAt := time.Now().Add(s.backoff(job.Attempts))
+	log.Warn("Job failed, will retry", zap.Time("run_at", runAt), zap.Error(err))
+	if err := s.outboxRepo.MarkRetry(ctx, job.ID, err.Error(), runAt); err != nil {
+		log.Error("Failed to schedule job retry", zap.Error(err))
+	}
+}
+
+// run calls handler with a timeout, turning a panic into a job failure
+func (s *outboxService) run(ctx context.Context, handler OutboxHandler, job entity.OutboxJob) (err error) {
+	if s.cfg.JobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.JobTimeout)
+		defer cancel()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return handler(ctx, job.Payload)
+}
+
+// backoff returns the delay before retrying after attempt failures: the base
+// delay doubled per attempt, capped, with up to 20% jitter
+func (s *outboxService) backoff(attempt int) time.Duration {
+	delay := s.cfg.BaseBackoff
+	for i := 1; i < attempt && delay < s.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if s.cfg.MaxBackoff > 0 && delay > s.cfg.MaxBackoff {
+		delay = s.cfg.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+}
+
+func (s *outboxService) cleanup() {
+	deleted, err := s.outboxRepo.DeleteDoneBefore(context.Background(), time.Now().Add(-s.cfg.Retention))
+	if err != nil {
+		s.log.Warn("Failed to clean up finished jobs", zap.Error(err))
+		return
+	}
+	if deleted > 0 {
+		s.log.Info("Cleaned up finished jobs", zap.Int64("count", deleted))
+	}
+}
+
+func (s *outboxService) GetStuckJobs(ctx context.Context, req *request.GetOutboxJobsRequest) ([]entity.OutboxJob, int64, error) {
+	var status *enum.OutboxJobStatus
+	if req.Status != "" {
+		st := enum.OutboxJobStatus(req.Status)
+		if !st.IsValid() {
+			return nil, 0, middleware.NewAppError("VALIDATION_ERROR", "Invalid job status", 400)
+		}
+		status = &st
+	}
+
+	return s.outboxRepo.GetStuck(ctx, status, req.Kind, s.cfg.LockTimeout, req.GetDefaultLimit(), req.GetOffset())
+}
+
+func (s *outboxService) GetStats(ctx context.Context) (*entity.OutboxStats, error) {
+	return s.outboxRepo.GetStats(ctx, s.cfg.LockTimeout)
+}
+
+func (s *outboxService) RetryJob(ctx context.Context, id uuid.UUID) (*entity.OutboxJob, error) {
+	job, err := s.outboxRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, middleware.NewAppError("JOB_NOT_FOUND", "Job not found", 404)
+	}
+	if job.Status == enum.OutboxJobStatusProcessing {
+		return nil, middleware.NewAppError("JOB_RUNNING", "Job is being processed", 409)
+	}
+
+	if err := s.outboxRepo.Requeue(ctx, id); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Job requeued", zap.String("job_id", id.String()), zap.String("kind", job.Kind))
+	return s.outboxRepo.GetByID(ctx, id)
+}
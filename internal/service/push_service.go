@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/domain/enum"
+	"bamboo-rescue/internal/repository"
+	"go.uber.org/zap"
+)
+
+// PushService defines the interface for delivering push notifications to
+// every device a user has registered
+type PushService interface {
+	SendToUser(ctx context.Context, userID uuid.UUID, notification *entity.NotificationPayload) error
+	SendToUsers(ctx context.Context, userIDs []uuid.UUID, notification *entity.NotificationPayload) error
+}
+
+// pushService routes each token by platform: browsers get Web Push, mobile
+// devices get FCM
+type pushService struct {
+	userRepo   repository.UserRepository
+	fcmSvc     FCMService
+	webPushSvc WebPushService
+	log        *zap.Logger
+}
+
+// NewPushService creates a new PushService
+func NewPushService(userRepo repository.UserRepository, fcmSvc FCMService, webPushSvc WebPushService, log *zap.Logger) PushService {
+	return &pushService{
+		userRepo:   userRepo,
+		fcmSvc:     fcmSvc,
+		webPushSvc: webPushSvc,
+		log:        log,
+	}
+}
+
+func (s *pushService) SendToUser(ctx context.Context, userID uuid.UUID, notification *entity.NotificationPayload) error {
+	return s.SendToUsers(ctx, []uuid.UUID{userID}, notification)
+}
+
+func (s *pushService) SendToUsers(ctx context.Context, userIDs []uuid.UUID, notification *entity.NotificationPayload) error {
+	var mobile []string
+	var web []entity.PushToken
+
+	for _, userID := range userIDs {
+		tokens, err := s.userRepo.GetPushTokens(ctx, userID)
+		if err != nil {
+			if len(userIDs) == 1 {
+				s.log.Error("Failed to get push tokens", zap.Error(err))
+				return err
+			}
+			s.log.Warn("Failed to get push tokens for user", zap.String("user_id", userID.String()), zap.Error(err))
+			continue
+		}
+
+		for _, t := range tokens {
+			if t.Platform == enum.DevicePlatformWeb {
+				web = append(web, t)
+			} else {
+				mobile = append(mobile, t.Token)
+			}
+		}
+	}
+
+	var errs []error
+	if len(mobile) > 0 && s.fcmSvc != nil {
+		errs = append(errs, s.fcmSvc.SendToTokens(ctx, mobile, notification))
+	}
+	if len(web) > 0 && s.webPushSvc != nil {
+		errs = append(errs, s.webPushSvc.SendToTokens(ctx, web, notification))
+	}
+
+	return errors.Join(errs...)
+}
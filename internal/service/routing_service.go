@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"bamboo-rescue/internal/config"
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/domain/enum"
+	"bamboo-rescue/pkg/breaker"
+	"bamboo-rescue/pkg/clock"
+	"go.uber.org/zap"
+)
+
+// RoutingService estimates how long it takes to travel between points
+type RoutingService interface {
+	// Route returns the route from one point to another. When the routing
+	// engine is unavailable or finds no road, the route is estimated from
+	// the straight-line distance.
+	Route(ctx context.Context, from, to entity.GeoPoint, mode enum.TravelMode) Route
+	// RouteMany returns the route from each origin to one destination, in
+	// the order of origins, falling back to estimates like Route
+	RouteMany(ctx context.Context, origins []entity.GeoPoint, to entity.GeoPoint, mode enum.TravelMode) []Route
+}
+
+// Route is the travel distance and time between two points
+type Route struct {
+	DistanceKm float64
+	Duration   time.Duration
+	// Estimated is set when the route is a straight-line estimate rather
+	// than one from the routing engine
+	Estimated bool
+}
+
+// osrmTableSize is how many points a single table request may hold; OSRM
+// allows 100 by default
+const osrmTableSize = 100
+
+// osrmBackend is an OSRM server for one travel mode
+type osrmBackend struct {
+	baseURL string
+	profile string
+	breaker *breaker.Breaker
+}
+
+type routingService struct {
+	cfg        config.RoutingConfig
+	backends   map[enum.TravelMode]*osrmBackend
+	httpClient *http.Client
+	log        *zap.Logger
+}
+
+// NewRoutingService creates a new RoutingService
+func NewRoutingService(cfg *config.Config, log *zap.Logger) RoutingService {
+	s := &routingService{
+		cfg:      cfg.Routing,
+		backends: make(map[enum.TravelMode]*osrmBackend),
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		log: log,
+	}
+
+	urls := map[enum.TravelMode]string{
+		enum.TravelModeDriving: cfg.Routing.DrivingURL,
+		enum.TravelModeWalking: cfg.Routing.WalkingURL,
+	}
+	profiles := map[enum.TravelMode]string{
+		enum.TravelModeDriving: "driving",
+		enum.TravelModeWalking: "foot",
+	}
+	for mode, url := range urls {
+		if url == "" {
+			continue
+		}
+		s.backends[mode] = &osrmBackend{
+			baseURL: strings.TrimSuffix(url, "/"),
+			profile: profiles[mode],
+			breaker: breaker.New(cfg.Routing.BreakerThreshold, cfg.Routing.BreakerCooldown, clock.Real),
+		}
+	}
+
+	return s
+}
+
+func (s *routingService) Route(ctx context.Context, from, to entity.GeoPoint, mode enum.TravelMode) Route {
+	return s.RouteMany(ctx, []entity.GeoPoint{from}, to, mode)[0]
+}
+
+func (s *routingService) RouteMany(ctx context.Context, origins []entity.GeoPoint, to entity.GeoPoint, mode enum.TravelMode) []Route {
+	routes := make([]Route, len(origins))
+	for i := range origins {
+		routes[i] = s.estimate(origins[i], to, mode)
+	}
+
+	backend := s.backends[mode]
+	if backend == nil {
+		return routes
+	}
+
+	// The destination takes one place in each table
+	for start := 0; start < len(origins); start += osrmTableSize - 1 {
+		end := min(start+osrmTableSize-1, len(origins))
+		if !backend.breaker.Allow() {
+			break
+		}
+
+		err := s.table(ctx, backend, origins[start:end], to, routes[start:end])
+		if err == nil {
+			backend.breaker.Success()
+			continue
+		}
+		if backend.breaker.Failure() {
+			s.log.Warn("Routing server taken out of rotation",
+				zap.String("profile", backend.profile),
+				zap.Duration("cooldown", s.cfg.BreakerCooldown),
+				zap.Error(err),
+			)
+			break
+		}
+		s.log.Warn("Routing server failed, estimating routes", zap.String("profile", backend.profile), zap.Error(err))
+	}
+
+	return routes
+}
+
+// estimate guesses a route from the straight-line distance, lengthened by
+// the detour factor, at the mode's average speed
+func (s *routingService) estimate(from, to entity.GeoPoint, mode enum.TravelMode) Route {
+	detour := s.cfg.DetourFactor
+	if detour < 1 {
+		detour = 1
+	}
+	speed := s.cfg.DrivingSpeedKmh
+	if mode == enum.TravelModeWalking {
+		speed = s.cfg.WalkingSpeedKmh
+	}
+	if speed <= 0 {
+		speed = 1
+	}
+
+	km := from.DistanceKm(&to) * detour
+	return Route{
+		DistanceKm: km,
+		Duration:   time.Duration(km / speed * float64(time.Hour)).Round(time.Second),
+		Estimated:  true,
+	}
+}
+
+// osrmTableResponse is the answer to an OSRM table request. A null entry
+// means no road connects the two points.
+type osrmTableResponse struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Durations [][]*float64 `json:"durations"`
+	Distances [][]*float64 `json:"distances"`
+}
+
+// table asks the server for the routes from each origin to the
+// destination, overwriting the estimates in routes where it has one
+func (s *routingService) table(ctx context.Context, backend *osrmBackend, origins []entity.GeoPoint, to entity.GeoPoint, routes []Route) error {
+	// OSRM takes longitude first
+	coords := make([]string, 0, len(origins)+1)
+	sources := make([]string, len(origins))
+	for i, o := range origins {
+		coords = append(coords, formatOSRMCoord(o))
+		sources[i] = strconv.Itoa(i)
+	}
+	coords = append(coords, formatOSRMCoord(to))
+
+	reqURL := fmt.Sprintf("%s/table/v1/%s/%s?sources=%s&destinations=%d&annotations=duration,distance",
+		backend.baseURL, backend.profile, strings.Join(coords, ";"), strings.Join(sources, ";"), len(origins))
+
+	callCtx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(callCtx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// OSRM reports bad requests such as too many points with a 400 and a
+	// code in the body
+	var table osrmTableResponse
+	if err := json.NewDecoder(resp.Body).Decode(&table); err != nil {
+		return fmt.Errorf("osrm returned status %d: %w", resp.StatusCode, err)
+	}
+	if table.Code != "Ok" {
+		return fmt.Errorf("osrm returned %s: %s", table.Code, table.Message)
+	}
+	if len(table.Durations) != len(origins) || len(table.Distances) != len(origins) {
+		return fmt.Errorf("osrm returned %d rows for %d origins", len(table.Durations), len(origins))
+	}
+
+	for i := range origins {
+		if len(table.Durations[i]) == 0 || len(table.Distances[i]) == 0 {
+			continue
+		}
+		duration, distance := table.Durations[i][0], table.Distances[i][0]
+		if duration == nil || distance == nil {
+			continue
+		}
+		routes[i] = Route{
+			DistanceKm: *distance / 1000,
+			Duration:   time.Duration(math.Round(*duration)) * time.Second,
+		}
+	}
+	return nil
+}
+
+func formatOSRMCoord(p entity.GeoPoint) string {
+	return strconv.FormatFloat(p.Longitude, 'f', 6, 64) + "," + strconv.FormatFloat(p.Latitude, 'f', 6, 64)
+}
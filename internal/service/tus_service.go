@@ -0,0 +1,478 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"bamboo-rescue/internal/config"
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/handler/dto/request"
+	"bamboo-rescue/internal/middleware"
+	"bamboo-rescue/internal/repository"
+	"bamboo-rescue/pkg/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	// tusCleanupInterval is how often abandoned uploads are swept
+	tusCleanupInterval = 15 * time.Minute
+	tusCleanupBatch    = 100
+)
+
+// TusService defines the interface for resumable (tus) media uploads
+type TusService interface {
+	Create(ctx context.Context, req *request.CreateTusUploadRequest, userID uuid.UUID) (*entity.MediaUpload, error)
+	Get(ctx context.Context, id, userID uuid.UUID) (*entity.MediaUpload, error)
+	WriteChunk(ctx context.Context, id, userID uuid.UUID, offset int64, body io.Reader) (*entity.MediaUpload, error)
+	Terminate(ctx context.Context, id, userID uuid.UUID) error
+	CleanupExpired(ctx context.Context) (int, error)
+	MaxSize() int64
+}
+
+type tusService struct {
+	uploadRepo    repository.MediaUploadRepository
+	caseRepo      repository.CaseRepository
+	mediaSvc      MediaService
+	storageClient storage.Client
+	spoolDir      string
+	expiry        time.Duration
+	log           *zap.Logger
+
+	// active guards against concurrent PATCHes to the same upload
+	mu     sync.Mutex
+	active map[uuid.UUID]bool
+}
+
+// NewTusService creates a new TusService
+func NewTusService(
+	cfg *config.Config,
+	uploadRepo repository.MediaUploadRepository,
+	caseRepo repository.CaseRepository,
+	mediaSvc MediaService,
+	storageClient storage.Client,
+	log *zap.Logger,
+) TusService {
+	spoolDir := cfg.Tus.SpoolDir
+	if spoolDir == "" {
+		spoolDir = filepath.Join(os.TempDir(), "bamboo-rescue-tus")
+	}
+	if err := os.MkdirAll(spoolDir, 0o755); err != nil {
+		log.Error("Failed to create tus spool directory", zap.String("dir", spoolDir), zap.Error(err))
+	}
+
+	expiry := cfg.Tus.UploadExpiry
+	if expiry <= 0 {
+		expiry = 24 * time.Hour
+	}
+
+	s := &tusService{
+		uploadRepo:    uploadRepo,
+		caseRepo:      caseRepo,
+		mediaSvc:      mediaSvc,
+		storageClient: storageClient,
+		spoolDir:      spoolDir,
+		expiry:        expiry,
+		log:           log,
+		active:        make(map[uuid.UUID]bool),
+	}
+
+	// Start cleanup goroutine
+	go s.cleanup()
+
+	return s
+}
+
+// MaxSize returns the largest upload accepted
+func (s *tusService) MaxSize() int64 {
+	return entity.MaxVideoSize
+}
+
+func (s *tusService) Create(ctx context.Context, req *request.CreateTusUploadRequest, userID uuid.UUID) (*entity.MediaUpload, error) {
+	if req.CaseID == uuid.Nil {
+		return nil, middleware.NewAppError("VALIDATION_ERROR", "case_id metadata is required", 400)
+	}
+	if req.UploadLength <= 0 {
+		return nil, middleware.NewAppError("VALIDATION_ERROR", "Upload-Length is required", 400)
+	}
+
+	ext := strings.ToLower(filepath.Ext(req.FileName))
+	mediaType, ok := mediaTypeFromExt(ext)
+	if !ok || !entity.IsAllowedMediaType(req.ContentType) || entity.GetMediaTypeFromMIME(req.ContentType) != mediaType {
+		return nil, middleware.NewAppError("INVALID_FILE_TYPE", "File type not allowed", 400)
+	}
+
+	if req.UploadLength > entity.GetMaxSizeForType(mediaType) {
+		return nil, middleware.NewAppError("FILE_TOO_LARGE",
+			fmt.Sprintf("File size exceeds %dMB limit", entity.GetMaxSizeForType(mediaType)/(1024*1024)), 413)
+	}
+
+	c, err := s.caseRepo.GetByID(ctx, req.CaseID)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, middleware.ErrCaseNotFound
+	}
+
+	id := uuid.New()
+	key := mediaKey(req.CaseID, id, ext, req.IsSensitive || sensitiveByDefault(c))
+
+	storageUploadID, err := s.storageClient.CreateMultipartUpload(ctx, key, req.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	upload := &entity.MediaUpload{
+		ID:              id,
+		CaseID:          req.CaseID,
+		UploadedBy:      &userID,
+		FileName:        req.FileName,
+		ContentType:     req.ContentType,
+		MediaType:       mediaType,
+		StorageKey:      key,
+		StorageUploadID: storageUploadID,
+		UploadLength:    req.UploadLength,
+		ExpiresAt:       time.Now().Add(s.expiry),
+	}
+
+	if err := s.uploadRepo.Create(ctx, upload); err != nil {
+		_ = s.storageClient.AbortMultipartUpload(ctx, key, storageUploadID)
+		return nil, err
+	}
+
+	s.log.Info("Resumable upload created",
+		zap.String("upload_id", id.String()),
+		zap.String("case_id", req.CaseID.String()),
+		zap.Int64("length", req.UploadLength),
+	)
+
+	return upload, nil
+}
+
+func (s *tusService) Get(ctx context.Context, id, userID uuid.UUID) (*entity.MediaUpload, error) {
+	upload, err := s.getOwned(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !upload.IsComplete() {
+		if err := s.reconcile(ctx, upload); err != nil {
+			return nil, err
+		}
+	}
+
+	return upload, nil
+}
+
+// WriteChunk appends body at offset. Whatever arrives before the client goes
+// away is kept, so the next HEAD reports how far the upload actually got.
+func (s *tusService) WriteChunk(ctx context.Context, id, userID uuid.UUID, offset int64, body io.Reader) (*entity.MediaUpload, error) {
+	if !s.acquire(id) {
+		return nil, middleware.NewAppError("UPLOAD_LOCKED", "Upload is already being written", 423)
+	}
+	defer s.release(id)
+
+	upload, err := s.getOwned(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if upload.IsComplete() {
+		return nil, middleware.NewAppError("UPLOAD_COMPLETE", "Upload is already complete", 409)
+	}
+
+	if err := s.reconcile(ctx, upload); err != nil {
+		return nil, err
+	}
+	if offset != upload.UploadOffset {
+		return nil, middleware.NewAppError("OFFSET_MISMATCH",
+			fmt.Sprintf("Upload-Offset must be %d", upload.UploadOffset), 409)
+	}
+
+	spool, err := os.OpenFile(s.spoolPath(id), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spool file: %w", err)
+	}
+	defer spool.Close()
+
+	// Read one byte past the declared length so overruns are detected
+	remaining := upload.UploadLength - upload.UploadOffset
+	limited := io.LimitReader(body, remaining+1)
+
+	var writeErr error
+	for {
+		room := storage.MinPartSize - (upload.UploadOffset - upload.FlushedBytes)
+		n, err := io.CopyN(spool, limited, room)
+		upload.UploadOffset += n
+
+		// Bodies of unknown length can overrun; keep only the declared bytes
+		if upload.UploadOffset > upload.UploadLength {
+			s.log.Warn("Chunk overran Upload-Length, discarding excess", zap.String("upload_id", id.String()))
+			upload.UploadOffset = upload.UploadLength
+			if err := spool.Truncate(upload.UploadLength - upload.FlushedBytes); err != nil {
+				writeErr = fmt.Errorf("failed to truncate spool file: %w", err)
+			}
+			break
+		}
+
+		if upload.UploadOffset-upload.FlushedBytes >= storage.MinPartSize {
+			if err := s.flushPart(ctx, upload, spool); err != nil {
+				writeErr = err
+				break
+			}
+		}
+
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				writeErr = err
+			}
+			break
+		}
+	}
+
+	if writeErr == nil && upload.UploadOffset == upload.UploadLength {
+		writeErr = s.complete(ctx, upload, spool)
+	}
+
+	// Persist progress even if the client has already disconnected
+	upload.ExpiresAt = time.Now().Add(s.expiry)
+	if err := s.uploadRepo.Update(context.WithoutCancel(ctx), upload); err != nil {
+		s.log.Error("Failed to persist upload offset", zap.String("upload_id", id.String()), zap.Error(err))
+		if writeErr == nil {
+			writeErr = err
+		}
+	}
+
+	if writeErr != nil {
+		return upload, writeErr
+	}
+	return upload, nil
+}
+
+func (s *tusService) Terminate(ctx context.Context, id, userID uuid.UUID) error {
+	if !s.acquire(id) {
+		return middleware.NewAppError("UPLOAD_LOCKED", "Upload is already being written", 423)
+	}
+	defer s.release(id)
+
+	upload, err := s.getOwned(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if upload.IsComplete() {
+		return middleware.NewAppError("UPLOAD_COMPLETE", "Upload is already complete", 409)
+	}
+
+	return s.discard(ctx, upload)
+}
+
+// CleanupExpired aborts abandoned uploads and frees their parts and spool files
+func (s *tusService) CleanupExpired(ctx context.Context) (int, error) {
+	uploads, err := s.uploadRepo.GetExpired(ctx, time.Now(), tusCleanupBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for i := range uploads {
+		if !s.acquire(uploads[i].ID) {
+			continue
+		}
+		err := s.discard(ctx, &uploads[i])
+		s.release(uploads[i].ID)
+		if err != nil {
+			s.log.Warn("Failed to discard expired upload", zap.String("upload_id", uploads[i].ID.String()), zap.Error(err))
+			continue
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// cleanup removes expired uploads periodically
+func (s *tusService) cleanup() {
+	ticker := time.NewTicker(tusCleanupInterval)
+	for range ticker.C {
+		removed, err := s.CleanupExpired(context.Background())
+		if err != nil {
+			s.log.Error("Failed to clean up expired uploads", zap.Error(err))
+			continue
+		}
+		if removed > 0 {
+			s.log.Info("Expired uploads removed", zap.Int("count", removed))
+		}
+	}
+}
+
+func (s *tusService) getOwned(ctx context.Context, id, userID uuid.UUID) (*entity.MediaUpload, error) {
+	upload, err := s.uploadRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload == nil || upload.IsExpired() {
+		return nil, middleware.NewAppError("UPLOAD_NOT_FOUND", "Upload not found", 404)
+	}
+	if upload.UploadedBy == nil || *upload.UploadedBy != userID {
+		return nil, middleware.ErrForbidden
+	}
+	return upload, nil
+}
+
+// reconcile makes the recorded offset agree with the bytes actually held.
+// The spool file lives on local disk, so it can be lost on restart or be
+// ahead of the database if the last offset update failed.
+func (s *tusService) reconcile(ctx context.Context, upload *entity.MediaUpload) error {
+	var spooled int64
+	if info, err := os.Stat(s.spoolPath(upload.ID)); err == nil {
+		spooled = info.Size()
+	}
+
+	recorded := upload.UploadOffset - upload.FlushedBytes
+	switch {
+	case spooled > recorded:
+		if err := os.Truncate(s.spoolPath(upload.ID), recorded); err != nil {
+			return fmt.Errorf("failed to truncate spool file: %w", err)
+		}
+	case spooled < recorded:
+		s.log.Warn("Spooled upload data lost, rewinding offset",
+			zap.String("upload_id", upload.ID.String()),
+			zap.Int64("offset", upload.UploadOffset),
+			zap.Int64("rewound_to", upload.FlushedBytes+spooled),
+		)
+		upload.UploadOffset = upload.FlushedBytes + spooled
+		return s.uploadRepo.Update(ctx, upload)
+	}
+
+	return nil
+}
+
+// flushPart moves the spooled bytes into storage as the next multipart part
+func (s *tusService) flushPart(ctx context.Context, upload *entity.MediaUpload, spool *os.File) error {
+	size := upload.UploadOffset - upload.FlushedBytes
+	if size == 0 {
+		return nil
+	}
+
+	parts, err := s.uploadRepo.GetParts(ctx, upload.ID)
+	if err != nil {
+		return err
+	}
+	partNumber := int32(len(parts) + 1)
+
+	etag, err := s.storageClient.UploadPart(ctx, upload.StorageKey, upload.StorageUploadID, partNumber,
+		io.NewSectionReader(spool, 0, size), size)
+	if err != nil {
+		return err
+	}
+
+	if err := s.uploadRepo.AddPart(ctx, &entity.MediaUploadPart{
+		UploadID:   upload.ID,
+		PartNumber: partNumber,
+		ETag:       etag,
+		Size:       size,
+	}); err != nil {
+		return err
+	}
+
+	upload.FlushedBytes += size
+	return spool.Truncate(0)
+}
+
+// complete flushes the final part, assembles the object and attaches it to
+// the case. Assembly is recorded before attaching, so if attaching fails the
+// retry goes straight to it: storage has already used up the upload ID.
+func (s *tusService) complete(ctx context.Context, upload *entity.MediaUpload, spool *os.File) error {
+	if !upload.IsAssembled() {
+		if err := s.assemble(ctx, upload, spool); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.mediaSvc.AttachStoredObject(ctx, upload.CaseID, upload.ID, upload.StorageKey,
+		upload.FileName, upload.MediaType, upload.UploadLength, upload.UploadedBy); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	upload.CompletedAt = &now
+	_ = os.Remove(spool.Name())
+
+	s.log.Info("Resumable upload completed", zap.String("upload_id", upload.ID.String()))
+
+	return nil
+}
+
+// assemble flushes the final part and has storage put the parts together
+func (s *tusService) assemble(ctx context.Context, upload *entity.MediaUpload, spool *os.File) error {
+	if err := s.flushPart(ctx, upload, spool); err != nil {
+		return err
+	}
+
+	parts, err := s.uploadRepo.GetParts(ctx, upload.ID)
+	if err != nil {
+		return err
+	}
+
+	completed := make([]storage.CompletedPart, len(parts))
+	for i, p := range parts {
+		completed[i] = storage.CompletedPart{PartNumber: p.PartNumber, ETag: p.ETag}
+	}
+
+	if err := s.storageClient.CompleteMultipartUpload(ctx, upload.StorageKey, upload.StorageUploadID, completed); err != nil {
+		return err
+	}
+
+	upload.StorageUploadID = ""
+	if err := s.uploadRepo.Update(context.WithoutCancel(ctx), upload); err != nil {
+		return err
+	}
+
+	s.log.Info("Resumable upload assembled",
+		zap.String("upload_id", upload.ID.String()),
+		zap.Int("parts", len(parts)),
+	)
+	return nil
+}
+
+// discard aborts the storage upload, or deletes the object it was assembled
+// into, and forgets it
+func (s *tusService) discard(ctx context.Context, upload *entity.MediaUpload) error {
+	if upload.IsAssembled() {
+		if err := s.storageClient.Delete(ctx, upload.StorageKey); err != nil {
+			return err
+		}
+	} else if err := s.storageClient.AbortMultipartUpload(ctx, upload.StorageKey, upload.StorageUploadID); err != nil {
+		return err
+	}
+	_ = os.Remove(s.spoolPath(upload.ID))
+	return s.uploadRepo.Delete(ctx, upload.ID)
+}
+
+func (s *tusService) spoolPath(id uuid.UUID) string {
+	return filepath.Join(s.spoolDir, id.String())
+}
+
+func (s *tusService) acquire(id uuid.UUID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.active[id] {
+		return false
+	}
+	s.active[id] = true
+	return true
+}
+
+func (s *tusService) release(id uuid.UUID) {
+	s.mu.Lock()
+	delete(s.active, id)
+	s.mu.Unlock()
+}
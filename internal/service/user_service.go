@@ -2,12 +2,15 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/domain/enum"
 	"bamboo-rescue/internal/handler/dto/request"
 	"bamboo-rescue/internal/middleware"
 	"bamboo-rescue/internal/repository"
+	"bamboo-rescue/pkg/webpush"
 	"go.uber.org/zap"
 )
 
@@ -75,6 +78,9 @@ func (s *userService) Update(ctx context.Context, userID uuid.UUID, req *request
 	if req.AvatarURL != nil {
 		user.AvatarURL = req.AvatarURL
 	}
+	if req.Locale != nil {
+		user.Locale = *req.Locale
+	}
 
 	if err := s.userRepo.Update(ctx, user); err != nil {
 		s.log.Error("Failed to update user", zap.Error(err))
@@ -121,6 +127,8 @@ func (s *userService) GetPreferences(ctx context.Context, userID uuid.UUID) (*en
 			CaseTypes:            []string{"animal", "flood", "accident"},
 			NotificationRadiusKm: 10,
 			UseCurrentLocation:   true,
+			EmailEnabled:         true,
+			EmailDigestEnabled:   true,
 		}
 		if err := s.userRepo.CreatePreferences(ctx, prefs); err != nil {
 			s.log.Error("Failed to create default preferences", zap.Error(err))
@@ -164,6 +172,27 @@ func (s *userService) UpdatePreferences(ctx context.Context, userID uuid.UUID, r
 	if req.QuietHoursEnd != nil {
 		prefs.QuietHoursEnd = req.QuietHoursEnd
 	}
+	if req.EmailEnabled != nil {
+		prefs.EmailEnabled = *req.EmailEnabled
+	}
+	if req.EmailDigestEnabled != nil {
+		prefs.EmailDigestEnabled = *req.EmailDigestEnabled
+	}
+	if req.NotificationChannels != nil {
+		update := make(entity.ChannelMatrix, len(req.NotificationChannels))
+		for t, channels := range req.NotificationChannels {
+			if !t.IsValid() {
+				return nil, middleware.NewAppError("VALIDATION_ERROR", "Unknown notification type: "+string(t), 400)
+			}
+			for c := range channels {
+				if !c.IsValid() {
+					return nil, middleware.NewAppError("VALIDATION_ERROR", "Unknown notification channel: "+string(c), 400)
+				}
+			}
+			update[t] = channels
+		}
+		prefs.NotificationChannels = prefs.NotificationChannels.Merge(update)
+	}
 
 	if err := s.userRepo.UpdatePreferences(ctx, prefs); err != nil {
 		s.log.Error("Failed to update preferences", zap.Error(err))
@@ -191,6 +220,14 @@ func (s *userService) RegisterPushToken(ctx context.Context, userID uuid.UUID, r
 		IsActive: true,
 	}
 
+	if req.Platform == enum.DevicePlatformWeb {
+		if err := applyWebPushSubscription(token, req.Subscription); err != nil {
+			return err
+		}
+	} else if req.Token == "" {
+		return middleware.NewAppError("VALIDATION_ERROR", "Push token is required", 400)
+	}
+
 	if err := s.userRepo.CreatePushToken(ctx, token); err != nil {
 		s.log.Error("Failed to register push token", zap.Error(err))
 		return err
@@ -211,3 +248,29 @@ func (s *userService) DeletePushToken(ctx context.Context, token string) error {
 	}
 	return nil
 }
+
+// applyWebPushSubscription stores a browser subscription on token, keyed by
+// its endpoint
+func applyWebPushSubscription(token *entity.PushToken, sub *request.WebPushSubscriptionRequest) error {
+	if sub == nil {
+		return middleware.NewAppError("VALIDATION_ERROR", "Web push subscription is required", 400)
+	}
+
+	err := webpush.ValidateSubscription(&webpush.Subscription{
+		Endpoint: sub.Endpoint,
+		P256dh:   sub.Keys.P256dh,
+		Auth:     sub.Keys.Auth,
+	})
+	if err != nil {
+		return middleware.NewAppError("INVALID_SUBSCRIPTION", err.Error(), 400)
+	}
+
+	token.Token = sub.Endpoint
+	token.P256dh = &sub.Keys.P256dh
+	token.Auth = &sub.Keys.Auth
+	if sub.ExpirationTime != nil {
+		expiresAt := time.UnixMilli(*sub.ExpirationTime)
+		token.ExpiresAt = &expiresAt
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"bamboo-rescue/internal/config"
+	"bamboo-rescue/internal/domain/entity"
+	"bamboo-rescue/internal/domain/enum"
+	"bamboo-rescue/internal/repository"
+	"bamboo-rescue/pkg/webpush"
+	"go.uber.org/zap"
+)
+
+// WebPushService defines the interface for browser Web Push delivery
+type WebPushService interface {
+	// PublicKey returns the VAPID key browsers subscribe with, or "" when
+	// web push is disabled
+	PublicKey() string
+	SendToTokens(ctx context.Context, tokens []entity.PushToken, notification *entity.NotificationPayload) error
+}
+
+// webPushMessage is the JSON the service worker receives in its push event
+type webPushMessage struct {
+	Title string            `json:"title"`
+	Body  string            `json:"body"`
+	Tag   string            `json:"tag,omitempty"`
+	Data  map[string]string `json:"data"`
+}
+
+type webPushService struct {
+	client     *webpush.Client
+	publicKey  string
+	userRepo   repository.UserRepository
+	log        *zap.Logger
+	enabled    bool
+	ttl        time.Duration
+	linkBase   string
+	maxRetries int
+	retryDelay time.Duration
+}
+
+// NewWebPushService creates a new WebPushService. Web push is disabled when
+// no VAPID private key is configured.
+func NewWebPushService(cfg *config.Config, userRepo repository.UserRepository, log *zap.Logger) WebPushService {
+	// Transient failures follow the same retry policy as FCM
+	svc := &webPushService{
+		userRepo:   userRepo,
+		log:        log,
+		ttl:        cfg.WebPush.TTL,
+		linkBase:   cfg.WebPush.LinkBase,
+		maxRetries: cfg.FCM.MaxRetries,
+		retryDelay: cfg.FCM.RetryBaseDelay,
+	}
+
+	if cfg.WebPush.VAPIDPrivateKey == "" {
+		log.Warn("VAPID keys not configured, web push disabled")
+		return svc
+	}
+
+	keys, err := webpush.ParseVAPIDKeys(cfg.WebPush.VAPIDPrivateKey)
+	if err != nil {
+		log.Error("Failed to load VAPID keys, web push disabled", zap.Error(err))
+		return svc
+	}
+	if cfg.WebPush.VAPIDPublicKey != "" && cfg.WebPush.VAPIDPublicKey != keys.PublicKey {
+		log.Error("VAPID public key does not match the private key, web push disabled")
+		return svc
+	}
+
+	svc.client = webpush.NewClient(keys, cfg.WebPush.Subject, nil)
+	svc.publicKey = keys.PublicKey
+	svc.enabled = true
+	log.Info("Web push service initialized successfully")
+
+	return svc
+}
+
+func (s *webPushService) PublicKey() string {
+	return s.publicKey
+}
+
+func (s *webPushService) SendToTokens(ctx context.Context, tokens []entity.PushToken, notification *entity.NotificationPayload) error {
+	if !s.enabled || len(tokens) == 0 {
+		return nil
+	}
+
+	// Browsers replace a shown notification that has the same tag, and push
+	// services an undelivered message with the same topic
+	message := webPushMessage{
+		Title: notification.Title,
+		Body:  notification.Body,
+		Tag:   notification.CollapseKey(),
+		Data:  notification.ToData(s.linkBase),
+	}
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	opts := webpush.Options{
+		TTL:     s.ttl,
+		Urgency: webPushUrgency(notification),
+		Topic:   webpush.Topic(message.Tag),
+	}
+
+	dead := make(map[string][]string)
+	var failure int
+	now := time.Now()
+	for _, t := range tokens {
+		if t.Platform != enum.DevicePlatformWeb {
+			continue
+		}
+		if t.ExpiresAt != nil && t.ExpiresAt.Before(now) {
+			dead[tokenReasonExpired] = append(dead[tokenReasonExpired], t.Token)
+			continue
+		}
+
+		err := s.send(ctx, &t, payload, opts)
+		if err == nil {
+			continue
+		}
+
+		failure++
+		switch {
+		case webpush.IsGone(err):
+			dead[tokenReasonUnregistered] = append(dead[tokenReasonUnregistered], t.Token)
+		case errors.Is(err, webpush.ErrInvalidSubscription):
+			dead[tokenReasonInvalid] = append(dead[tokenReasonInvalid], t.Token)
+		default:
+			s.log.Warn("Failed to send web push", zap.String("user_id", t.UserID.String()), zap.Error(err))
+		}
+	}
+
+	for reason, endpoints := range dead {
+		s.deactivate(ctx, endpoints, reason)
+	}
+
+	if failure > 0 {
+		s.log.Warn("Some web push messages failed",
+			zap.Int("success", len(tokens)-failure),
+			zap.Int("failure", failure),
+		)
+	}
+
+	return nil
+}
+
+// send delivers one message, retrying transient failures with backoff and
+// honouring Retry-After when the push service sets it
+func (s *webPushService) send(ctx context.Context, t *entity.PushToken, payload []byte, opts webpush.Options) error {
+	sub := &webpush.Subscription{Endpoint: t.Token}
+	if t.P256dh != nil {
+		sub.P256dh = *t.P256dh
+	}
+	if t.Auth != nil {
+		sub.Auth = *t.Auth
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := s.client.Send(ctx, sub, payload, opts)
+		if err == nil || !webpush.IsTransient(err) || attempt >= s.maxRetries {
+			return err
+		}
+
+		delay := s.retryDelay << attempt
+		var se *webpush.StatusError
+		if errors.As(err, &se) && se.RetryAfter > delay {
+			delay = se.RetryAfter
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func (s *webPushService) deactivate(ctx context.Context, endpoints []string, reason string) {
+	if err := s.userRepo.DeactivatePushTokens(context.WithoutCancel(ctx), endpoints, reason); err != nil {
+		s.log.Warn("Failed to deactivate web push subscriptions", zap.Int("count", len(endpoints)), zap.Error(err))
+		return
+	}
+
+	s.log.Info("Deactivated dead web push subscriptions",
+		zap.Int("count", len(endpoints)),
+		zap.String("reason", reason),
+	)
+}
+
+// webPushUrgency lets push services wake devices for urgent cases only
+func webPushUrgency(n *entity.NotificationPayload) string {
+	if n.Urgency != nil && (*n.Urgency == enum.UrgencyCritical || *n.Urgency == enum.UrgencyHigh) {
+		return webpush.UrgencyHigh
+	}
+	return webpush.UrgencyNormal
+}
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS update_media_uploads_updated_at ON media_uploads;
+DROP TABLE IF EXISTS media_upload_parts;
+DROP TABLE IF EXISTS media_uploads;
+ALTER TABLE case_media DROP COLUMN IF EXISTS uploaded_by;
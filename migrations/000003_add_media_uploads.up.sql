@@ -0,0 +1,37 @@
+-- Track who uploaded each media file
+ALTER TABLE case_media ADD COLUMN IF NOT EXISTS uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+-- Resumable (tus) media uploads in progress
+CREATE TABLE IF NOT EXISTS media_uploads (
+    id UUID PRIMARY KEY,
+    case_id UUID NOT NULL REFERENCES cases(id) ON DELETE CASCADE,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    media_type VARCHAR(20) NOT NULL,
+    storage_key TEXT NOT NULL,
+    storage_upload_id TEXT NOT NULL,
+    upload_length BIGINT NOT NULL,
+    upload_offset BIGINT NOT NULL DEFAULT 0,
+    flushed_bytes BIGINT NOT NULL DEFAULT 0,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_media_uploads_expires ON media_uploads(expires_at) WHERE completed_at IS NULL;
+
+-- Parts already persisted to storage for an upload
+CREATE TABLE IF NOT EXISTS media_upload_parts (
+    upload_id UUID NOT NULL REFERENCES media_uploads(id) ON DELETE CASCADE,
+    part_number INTEGER NOT NULL,
+    etag TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (upload_id, part_number)
+);
+
+-- Trigger for updated_at
+CREATE TRIGGER update_media_uploads_updated_at BEFORE UPDATE ON media_uploads
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
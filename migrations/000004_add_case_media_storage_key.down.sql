@@ -0,0 +1 @@
+ALTER TABLE case_media DROP COLUMN IF EXISTS storage_key;
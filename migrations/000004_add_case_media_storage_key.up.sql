@@ -0,0 +1,7 @@
+-- Storage key of each media object, independent of the backend's public URL
+ALTER TABLE case_media ADD COLUMN IF NOT EXISTS storage_key TEXT;
+
+-- Backfill from URLs, which all end in the key
+UPDATE case_media
+SET storage_key = substring(url from '(cases/[^?#]+)$')
+WHERE storage_key IS NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE case_media DROP COLUMN IF EXISTS placeholder;
+ALTER TABLE case_media DROP COLUMN IF EXISTS is_sensitive;
@@ -0,0 +1,6 @@
+-- Sensitive media (e.g. accident-scene photos) is stored under private/ and
+-- only served through short-lived signed URLs
+ALTER TABLE case_media ADD COLUMN IF NOT EXISTS is_sensitive BOOLEAN NOT NULL DEFAULT false;
+
+-- Tiny blurred rendition shown to viewers who may not see the original
+ALTER TABLE case_media ADD COLUMN IF NOT EXISTS placeholder TEXT;
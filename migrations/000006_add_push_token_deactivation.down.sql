@@ -0,0 +1,3 @@
+ALTER TABLE push_tokens DROP COLUMN IF EXISTS last_used_at;
+ALTER TABLE push_tokens DROP COLUMN IF EXISTS deactivation_reason;
+ALTER TABLE push_tokens DROP COLUMN IF EXISTS deactivated_at;
@@ -0,0 +1,7 @@
+-- Tokens FCM reports as dead are deactivated rather than deleted, so a
+-- device that registers again simply reactivates its row
+ALTER TABLE push_tokens ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE push_tokens ADD COLUMN IF NOT EXISTS deactivation_reason VARCHAR(50);
+
+-- Written by token registration but missing from the initial schema
+ALTER TABLE push_tokens ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_outbox_jobs_updated_at ON outbox_jobs;
+DROP TABLE IF EXISTS outbox_jobs;
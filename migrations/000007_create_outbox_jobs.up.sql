@@ -0,0 +1,26 @@
+-- Background jobs written in the same transaction as the change that caused
+-- them, processed by the in-process worker pool
+CREATE TABLE IF NOT EXISTS outbox_jobs (
+    id UUID PRIMARY KEY,
+    kind VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Claiming due jobs
+CREATE INDEX IF NOT EXISTS idx_outbox_jobs_due ON outbox_jobs(run_at) WHERE status = 'pending';
+-- Reclaiming jobs from workers that died mid-job
+CREATE INDEX IF NOT EXISTS idx_outbox_jobs_locked ON outbox_jobs(locked_at) WHERE status = 'processing';
+-- Admin listing and retention
+CREATE INDEX IF NOT EXISTS idx_outbox_jobs_status ON outbox_jobs(status, created_at);
+
+CREATE TRIGGER update_outbox_jobs_updated_at BEFORE UPDATE ON outbox_jobs
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,2 @@
+-- Language used for push notification texts
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale VARCHAR(5) NOT NULL DEFAULT 'vi';
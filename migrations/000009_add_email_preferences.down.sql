@@ -0,0 +1,3 @@
+ALTER TABLE user_preferences DROP COLUMN IF EXISTS last_digest_at;
+ALTER TABLE user_preferences DROP COLUMN IF EXISTS email_digest_enabled;
+ALTER TABLE user_preferences DROP COLUMN IF EXISTS email_enabled;
@@ -0,0 +1,4 @@
+-- Email channel preferences and daily digest bookkeeping
+ALTER TABLE user_preferences ADD COLUMN IF NOT EXISTS email_enabled BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE user_preferences ADD COLUMN IF NOT EXISTS email_digest_enabled BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE user_preferences ADD COLUMN IF NOT EXISTS last_digest_at TIMESTAMP WITH TIME ZONE;
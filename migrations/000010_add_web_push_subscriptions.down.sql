@@ -0,0 +1,3 @@
+ALTER TABLE push_tokens DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE push_tokens DROP COLUMN IF EXISTS auth;
+ALTER TABLE push_tokens DROP COLUMN IF EXISTS p256dh;
@@ -0,0 +1,5 @@
+-- Web push subscriptions keep their endpoint in token plus the keys the
+-- payload is encrypted with
+ALTER TABLE push_tokens ADD COLUMN IF NOT EXISTS p256dh VARCHAR(100);
+ALTER TABLE push_tokens ADD COLUMN IF NOT EXISTS auth VARCHAR(50);
+ALTER TABLE push_tokens ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
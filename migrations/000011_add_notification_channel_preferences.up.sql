@@ -0,0 +1,3 @@
+-- Per notification type channel choices, e.g. {"case_update": {"push": false}}.
+-- Only overrides of the application defaults are stored.
+ALTER TABLE user_preferences ADD COLUMN IF NOT EXISTS notification_channels JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,18 @@
+-- Users following a case to be told about its updates, comments and status changes
+CREATE TABLE IF NOT EXISTS case_followers (
+    case_id UUID NOT NULL REFERENCES cases(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (case_id, user_id)
+);
+
+CREATE INDEX idx_case_followers_user ON case_followers(user_id, created_at DESC);
+
+-- Reporters and volunteers of existing cases follow them
+INSERT INTO case_followers (case_id, user_id, created_at)
+SELECT id, reporter_id, created_at FROM cases WHERE reporter_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO case_followers (case_id, user_id, created_at)
+SELECT case_id, volunteer_id, accepted_at FROM case_volunteers WHERE status <> 'withdrawn'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,9 @@
+-- Per-user push budget and the pushes held back for the next summary
+CREATE TABLE IF NOT EXISTS push_throttles (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    sent_count INTEGER NOT NULL DEFAULT 0,
+    held JSONB NOT NULL DEFAULT '{}',
+    summary_due_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,7 @@
+-- Volunteers who dismissed a case and must not be notified about it again
+CREATE TABLE IF NOT EXISTS case_dismissals (
+    case_id UUID NOT NULL REFERENCES cases(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (case_id, user_id)
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_notifications_user;
+CREATE INDEX idx_notifications_user ON notifications(user_id, created_at DESC);
+
+ALTER TABLE notifications DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,7 @@
+-- Archived notifications drop out of the inbox but can still be listed
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE;
+
+-- Inbox pages walk (created_at, id) so rows created in the same instant
+-- are neither skipped nor repeated
+DROP INDEX IF EXISTS idx_notifications_user;
+CREATE INDEX idx_notifications_user ON notifications(user_id, created_at DESC, id DESC);
@@ -0,0 +1,8 @@
+-- Geocoding results keyed by rounded coordinates or normalized query.
+-- Expired rows are kept to fall back on when the provider is down.
+CREATE TABLE IF NOT EXISTS geocode_cache (
+    key VARCHAR(128) PRIMARY KEY,
+    result JSONB NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
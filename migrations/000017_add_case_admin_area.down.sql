@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_cases_ward;
+DROP INDEX IF EXISTS idx_cases_province_district;
+ALTER TABLE cases DROP COLUMN IF EXISTS ward;
+ALTER TABLE cases DROP COLUMN IF EXISTS district;
+ALTER TABLE cases DROP COLUMN IF EXISTS province;
@@ -0,0 +1,9 @@
+-- Administrative units a case lies in, filled in by reverse geocoding so
+-- cases reported with only a GPS pin can still be filtered by area
+ALTER TABLE cases ADD COLUMN IF NOT EXISTS province VARCHAR(100);
+ALTER TABLE cases ADD COLUMN IF NOT EXISTS district VARCHAR(100);
+ALTER TABLE cases ADD COLUMN IF NOT EXISTS ward VARCHAR(100);
+
+-- Area filters match names case-insensitively
+CREATE INDEX IF NOT EXISTS idx_cases_province_district ON cases(LOWER(province), LOWER(district));
+CREATE INDEX IF NOT EXISTS idx_cases_ward ON cases(LOWER(ward)) WHERE ward IS NOT NULL;
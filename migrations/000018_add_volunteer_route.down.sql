@@ -0,0 +1,4 @@
+ALTER TABLE case_volunteers DROP COLUMN IF EXISTS eta_estimated;
+ALTER TABLE case_volunteers DROP COLUMN IF EXISTS eta_seconds;
+ALTER TABLE case_volunteers DROP COLUMN IF EXISTS route_distance_km;
+ALTER TABLE case_volunteers DROP COLUMN IF EXISTS travel_mode;
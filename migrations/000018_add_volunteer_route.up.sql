@@ -0,0 +1,6 @@
+-- Travel from a volunteer's accepted position to the case, by road when the
+-- routing engine answered and otherwise a straight-line estimate
+ALTER TABLE case_volunteers ADD COLUMN IF NOT EXISTS travel_mode VARCHAR(20);
+ALTER TABLE case_volunteers ADD COLUMN IF NOT EXISTS route_distance_km DECIMAL(10, 2);
+ALTER TABLE case_volunteers ADD COLUMN IF NOT EXISTS eta_seconds INTEGER;
+ALTER TABLE case_volunteers ADD COLUMN IF NOT EXISTS eta_estimated BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS case_alert_zones;
+DROP TABLE IF EXISTS alert_zones;
@@ -0,0 +1,31 @@
+-- Areas coordinators warn everyone inside of. The polygon is a JSON array
+-- of points; its bounding box is kept alongside to find candidates by index.
+CREATE TABLE IF NOT EXISTS alert_zones (
+    id UUID PRIMARY KEY,
+    title VARCHAR(200) NOT NULL,
+    message TEXT,
+    severity VARCHAR(20) NOT NULL,
+    polygon JSONB NOT NULL,
+    min_lat DECIMAL(10, 8) NOT NULL,
+    max_lat DECIMAL(10, 8) NOT NULL,
+    min_lng DECIMAL(11, 8) NOT NULL,
+    max_lng DECIMAL(11, 8) NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE,
+    last_broadcast_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_zones_active ON alert_zones(expires_at) WHERE ended_at IS NULL;
+
+-- Zones a case was reported inside, tagged when the case is created
+CREATE TABLE IF NOT EXISTS case_alert_zones (
+    case_id UUID NOT NULL REFERENCES cases(id) ON DELETE CASCADE,
+    alert_zone_id UUID NOT NULL REFERENCES alert_zones(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (case_id, alert_zone_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_case_alert_zones_zone ON case_alert_zones(alert_zone_id);
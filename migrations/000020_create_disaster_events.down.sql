@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_cases_event;
+ALTER TABLE cases DROP COLUMN IF EXISTS event_id;
+DROP TABLE IF EXISTS disaster_event_coordinators;
+DROP TABLE IF EXISTS disaster_events;
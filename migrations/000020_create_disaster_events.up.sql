@@ -0,0 +1,37 @@
+-- Large incidents grouping many cases. The area is a JSON array of points;
+-- its bounding box is kept alongside to find candidates by index.
+CREATE TABLE IF NOT EXISTS disaster_events (
+    id UUID PRIMARY KEY,
+    name VARCHAR(200) NOT NULL,
+    description TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    area JSONB NOT NULL,
+    min_lat DECIMAL(10, 8) NOT NULL,
+    max_lat DECIMAL(10, 8) NOT NULL,
+    min_lng DECIMAL(11, 8) NOT NULL,
+    max_lng DECIMAL(11, 8) NOT NULL,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE,
+    notify_radius_km INTEGER,
+    max_volunteers INTEGER,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_disaster_events_status ON disaster_events(status, starts_at DESC);
+
+-- Coordinators who own an event
+CREATE TABLE IF NOT EXISTS disaster_event_coordinators (
+    event_id UUID NOT NULL REFERENCES disaster_events(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (event_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_disaster_event_coordinators_user ON disaster_event_coordinators(user_id);
+
+-- The event a case is grouped under
+ALTER TABLE cases ADD COLUMN IF NOT EXISTS event_id UUID REFERENCES disaster_events(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_cases_event ON cases(event_id, created_at DESC) WHERE event_id IS NOT NULL;
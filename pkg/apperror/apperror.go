@@ -20,19 +20,27 @@ func (e *AppError) Error() string {
 
 // Common application errors
 var (
-	ErrNotFound           = &AppError{Code: "NOT_FOUND", Message: "Resource not found", Status: http.StatusNotFound, StatusCode: http.StatusNotFound}
-	ErrUnauthorized       = &AppError{Code: "UNAUTHORIZED", Message: "Unauthorized", Status: http.StatusUnauthorized, StatusCode: http.StatusUnauthorized}
-	ErrForbidden          = &AppError{Code: "FORBIDDEN", Message: "Forbidden", Status: http.StatusForbidden, StatusCode: http.StatusForbidden}
-	ErrBadRequest         = &AppError{Code: "BAD_REQUEST", Message: "Bad request", Status: http.StatusBadRequest, StatusCode: http.StatusBadRequest}
-	ErrConflict           = &AppError{Code: "CONFLICT", Message: "Resource already exists", Status: http.StatusConflict, StatusCode: http.StatusConflict}
-	ErrInternalServer     = &AppError{Code: "INTERNAL_SERVER_ERROR", Message: "Internal server error", Status: http.StatusInternalServerError, StatusCode: http.StatusInternalServerError}
-	ErrValidation         = &AppError{Code: "VALIDATION_ERROR", Message: "Validation failed", Status: http.StatusBadRequest, StatusCode: http.StatusBadRequest}
-	ErrInvalidToken       = &AppError{Code: "INVALID_TOKEN", Message: "Invalid or expired token", Status: http.StatusUnauthorized, StatusCode: http.StatusUnauthorized}
-	ErrUserNotFound       = &AppError{Code: "USER_NOT_FOUND", Message: "User not found", Status: http.StatusNotFound, StatusCode: http.StatusNotFound}
-	ErrCaseNotFound       = &AppError{Code: "CASE_NOT_FOUND", Message: "Case not found", Status: http.StatusNotFound, StatusCode: http.StatusNotFound}
-	ErrInvalidCredentials = &AppError{Code: "INVALID_CREDENTIALS", Message: "Invalid email or password", Status: http.StatusUnauthorized, StatusCode: http.StatusUnauthorized}
-	ErrEmailExists        = &AppError{Code: "EMAIL_EXISTS", Message: "Email already registered", Status: http.StatusConflict, StatusCode: http.StatusConflict}
-	ErrPhoneExists        = &AppError{Code: "PHONE_EXISTS", Message: "Phone number already registered", Status: http.StatusConflict, StatusCode: http.StatusConflict}
+	ErrNotFound             = &AppError{Code: "NOT_FOUND", Message: "Resource not found", Status: http.StatusNotFound, StatusCode: http.StatusNotFound}
+	ErrUnauthorized         = &AppError{Code: "UNAUTHORIZED", Message: "Unauthorized", Status: http.StatusUnauthorized, StatusCode: http.StatusUnauthorized}
+	ErrForbidden            = &AppError{Code: "FORBIDDEN", Message: "Forbidden", Status: http.StatusForbidden, StatusCode: http.StatusForbidden}
+	ErrBadRequest           = &AppError{Code: "BAD_REQUEST", Message: "Bad request", Status: http.StatusBadRequest, StatusCode: http.StatusBadRequest}
+	ErrConflict             = &AppError{Code: "CONFLICT", Message: "Resource already exists", Status: http.StatusConflict, StatusCode: http.StatusConflict}
+	ErrInternalServer       = &AppError{Code: "INTERNAL_SERVER_ERROR", Message: "Internal server error", Status: http.StatusInternalServerError, StatusCode: http.StatusInternalServerError}
+	ErrValidation           = &AppError{Code: "VALIDATION_ERROR", Message: "Validation failed", Status: http.StatusBadRequest, StatusCode: http.StatusBadRequest}
+	ErrInvalidToken         = &AppError{Code: "INVALID_TOKEN", Message: "Invalid or expired token", Status: http.StatusUnauthorized, StatusCode: http.StatusUnauthorized}
+	ErrUserNotFound         = &AppError{Code: "USER_NOT_FOUND", Message: "User not found", Status: http.StatusNotFound, StatusCode: http.StatusNotFound}
+	ErrCaseNotFound         = &AppError{Code: "CASE_NOT_FOUND", Message: "Case not found", Status: http.StatusNotFound, StatusCode: http.StatusNotFound}
+	ErrNotificationNotFound = &AppError{Code: "NOTIFICATION_NOT_FOUND", Message: "Notification not found", Status: http.StatusNotFound, StatusCode: http.StatusNotFound}
+	ErrInvalidCredentials   = &AppError{Code: "INVALID_CREDENTIALS", Message: "Invalid email or password", Status: http.StatusUnauthorized, StatusCode: http.StatusUnauthorized}
+	ErrEmailExists          = &AppError{Code: "EMAIL_EXISTS", Message: "Email already registered", Status: http.StatusConflict, StatusCode: http.StatusConflict}
+	ErrPhoneExists          = &AppError{Code: "PHONE_EXISTS", Message: "Phone number already registered", Status: http.StatusConflict, StatusCode: http.StatusConflict}
+	ErrGeocodeUnavailable   = &AppError{Code: "GEOCODE_UNAVAILABLE", Message: "Geocoding is temporarily unavailable", Status: http.StatusServiceUnavailable, StatusCode: http.StatusServiceUnavailable}
+	ErrLocationUnrecognized = &AppError{Code: "LOCATION_UNRECOGNIZED", Message: "Location is not in a recognized format", Status: http.StatusBadRequest, StatusCode: http.StatusBadRequest}
+	ErrLocationAmbiguous    = &AppError{Code: "LOCATION_AMBIGUOUS", Message: "Short plus code needs a nearby town or the reporter's position", Status: http.StatusBadRequest, StatusCode: http.StatusBadRequest}
+	ErrAlertZoneNotFound    = &AppError{Code: "ALERT_ZONE_NOT_FOUND", Message: "Alert zone not found", Status: http.StatusNotFound, StatusCode: http.StatusNotFound}
+	ErrAlertZoneInactive    = &AppError{Code: "ALERT_ZONE_INACTIVE", Message: "Alert zone has ended or expired", Status: http.StatusConflict, StatusCode: http.StatusConflict}
+	ErrEventNotFound        = &AppError{Code: "EVENT_NOT_FOUND", Message: "Disaster event not found", Status: http.StatusNotFound, StatusCode: http.StatusNotFound}
+	ErrEventNotCoordinator  = &AppError{Code: "EVENT_NOT_COORDINATOR", Message: "Only the event's coordinators can change it", Status: http.StatusForbidden, StatusCode: http.StatusForbidden}
 )
 
 // NewAppError creates a new AppError with a custom message
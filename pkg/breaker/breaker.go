@@ -0,0 +1,80 @@
+package breaker
+
+import (
+	"sync"
+	"time"
+
+	"bamboo-rescue/pkg/clock"
+)
+
+// Breaker stops calls to a failing dependency. After threshold failures in
+// a row it opens and refuses calls; once every cooldown it lets a single
+// trial call through, and the first success closes it again.
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	clock     clock.Clock
+	failures  int
+	openedAt  time.Time
+}
+
+// New creates a closed breaker. A threshold below one never opens.
+func New(threshold int, cooldown time.Duration, clk clock.Clock) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		clock:     clk,
+	}
+}
+
+// Allow reports whether a call may go ahead. While open it allows one
+// trial call per cooldown.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.isOpen() {
+		return true
+	}
+	now := b.clock.Now()
+	if now.Sub(b.openedAt) < b.cooldown {
+		return false
+	}
+	// Restart the cooldown so only this caller gets the trial
+	b.openedAt = now
+	return true
+}
+
+// Success records a call that worked, closing the breaker
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+}
+
+// Failure records a call that failed and reports whether it opened the
+// breaker
+func (b *Breaker) Failure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wasOpen := b.isOpen()
+	b.failures++
+	if !b.isOpen() {
+		return false
+	}
+	b.openedAt = b.clock.Now()
+	return !wasOpen
+}
+
+// Open reports whether calls are being refused
+func (b *Breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.isOpen()
+}
+
+func (b *Breaker) isOpen() bool {
+	return b.threshold > 0 && b.failures >= b.threshold
+}
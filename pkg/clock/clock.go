@@ -0,0 +1,19 @@
+package clock
+
+import "time"
+
+// Clock tells the current time. Time-dependent logic takes a Clock rather
+// than calling time.Now so it can be driven with a fixed or stepped time.
+type Clock interface {
+	Now() time.Time
+}
+
+// Func adapts a function to a Clock
+type Func func() time.Time
+
+func (f Func) Now() time.Time {
+	return f()
+}
+
+// Real is the system clock
+var Real Clock = Func(time.Now)
@@ -0,0 +1,412 @@
+// Package geoparse reads positions written the ways people share them:
+// decimal degrees, degrees-minutes-seconds, plus codes and map links
+package geoparse
+
+import (
+	"errors"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"bamboo-rescue/pkg/olc"
+)
+
+// Format names how a position was written
+type Format string
+
+const (
+	FormatDecimal       Format = "decimal"
+	FormatDMS           Format = "dms"
+	FormatPlusCode      Format = "plus_code"
+	FormatShortPlusCode Format = "short_plus_code"
+	FormatMapURL        Format = "map_url"
+)
+
+var (
+	// ErrUnrecognized is returned when input is in none of the known formats
+	ErrUnrecognized = errors.New("unrecognized location format")
+	// ErrOutOfRange is returned for coordinates that do not exist
+	ErrOutOfRange = errors.New("coordinates out of range")
+)
+
+// Result is a parsed position. Short plus codes and short links cannot be
+// placed on their own: Latitude and Longitude are then unset and ShortCode
+// or ShortLink says what is left to resolve.
+type Result struct {
+	Format    Format
+	Latitude  float64
+	Longitude float64
+	// PlusCode is the code as given, for plus code input
+	PlusCode string
+	// ShortCode needs a reference point near it to be recovered, such as
+	// the place named by Locality, e.g. "Hoàn Kiếm, Hà Nội"
+	ShortCode string
+	Locality  string
+	// ShortLink is a URL shortener link to follow to the actual map link
+	ShortLink string
+}
+
+// Resolved reports whether the result holds a position
+func (r *Result) Resolved() bool {
+	return r.ShortCode == "" && r.ShortLink == ""
+}
+
+// shortLinkHosts serve redirects to map links
+var shortLinkHosts = map[string]bool{
+	"goo.gl":          true,
+	"maps.app.goo.gl": true,
+}
+
+// IsShortLinkHost reports whether host is a map link shortener
+func IsShortLinkHost(host string) bool {
+	return shortLinkHosts[strings.ToLower(host)]
+}
+
+// Parse reads a position from input
+func Parse(input string) (*Result, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, ErrUnrecognized
+	}
+
+	if looksLikeURL(input) {
+		return parseURL(input)
+	}
+	if r, ok := parsePlusCode(input); ok {
+		return r, nil
+	}
+	return parseCoordinates(input)
+}
+
+func looksLikeURL(input string) bool {
+	lower := strings.ToLower(input)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "geo:") {
+		return true
+	}
+	// Links pasted without their scheme
+	for _, prefix := range []string{"www.google.", "google.", "maps.google.", "maps.app.goo.gl/", "goo.gl/", "www.openstreetmap.org/", "openstreetmap.org/", "maps.apple.com/"} {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// plusCodePattern finds a plus code within text, e.g. the "QPG4+4G" of
+// "QPG4+4G Hoàn Kiếm, Hà Nội"
+var plusCodePattern = regexp.MustCompile(`(?i)(?:^|[\s,])([23456789CFGHJMPQRVWX0]{2,8}\+[23456789CFGHJMPQRVWX]*)(?:$|[\s,])`)
+
+func parsePlusCode(input string) (*Result, bool) {
+	m := plusCodePattern.FindStringSubmatchIndex(input)
+	if m == nil {
+		return nil, false
+	}
+	code := strings.ToUpper(input[m[2]:m[3]])
+	if !olc.IsValid(code) {
+		return nil, false
+	}
+	locality := strings.Trim(input[:m[2]]+" "+input[m[3]:], " ,")
+
+	if olc.IsFull(code) {
+		area, err := olc.Decode(code)
+		if err != nil {
+			return nil, false
+		}
+		lat, lng := area.Center()
+		return &Result{Format: FormatPlusCode, Latitude: lat, Longitude: lng, PlusCode: code}, true
+	}
+	return &Result{Format: FormatShortPlusCode, PlusCode: code, ShortCode: code, Locality: locality}, true
+}
+
+// coordinate is one half of a position as written: up to three numbers
+// for degrees, minutes and seconds, and the hemisphere if given
+type coordinate struct {
+	values     []float64
+	negative   bool
+	hemisphere rune
+}
+
+func (c coordinate) degrees() (float64, bool) {
+	if len(c.values) == 0 || len(c.values) > 3 {
+		return 0, false
+	}
+	deg := c.values[0]
+	for i, v := range c.values[1:] {
+		// Minutes and seconds are below 60, and only the last part may
+		// have decimals
+		if v >= 60 || (i+2 < len(c.values) && v != math.Trunc(v)) {
+			return 0, false
+		}
+		deg += v / math.Pow(60, float64(i+1))
+	}
+	if len(c.values) > 1 && c.values[0] != math.Trunc(c.values[0]) {
+		return 0, false
+	}
+	if c.negative || c.hemisphere == 'S' || c.hemisphere == 'W' {
+		deg = -deg
+	}
+	return deg, true
+}
+
+// parseCoordinates reads a pair of coordinates in decimal degrees or
+// degrees, minutes and seconds, latitude first unless hemispheres say
+// otherwise, e.g. "21.0285, 105.8542" or 21°01'42.6"N 105°51'15.1"E
+func parseCoordinates(input string) (*Result, error) {
+	tokens, ok := tokenize(input)
+	if !ok || len(tokens) == 0 {
+		return nil, ErrUnrecognized
+	}
+
+	// Hemispheres are written either before or after their numbers, the
+	// same way for both halves
+	prefixed := isHemisphere(tokens[0])
+	var coords []coordinate
+	current := coordinate{}
+	flush := func() {
+		if len(current.values) > 0 || current.hemisphere != 0 {
+			coords = append(coords, current)
+		}
+		current = coordinate{}
+	}
+	for _, tok := range tokens {
+		switch {
+		case tok == ",":
+			flush()
+		case isHemisphere(tok):
+			if prefixed {
+				flush()
+				current.hemisphere = rune(tok[0])
+			} else {
+				current.hemisphere = rune(tok[0])
+				flush()
+			}
+		default:
+			v, err := strconv.ParseFloat(tok, 64)
+			if err != nil {
+				return nil, ErrUnrecognized
+			}
+			if v < 0 || tok[0] == '-' {
+				if len(current.values) > 0 {
+					// A sign starts the next coordinate, as in "21 -105"
+					flush()
+				}
+				current.negative = true
+				v = -v
+			}
+			current.values = append(current.values, v)
+		}
+	}
+	flush()
+
+	// Without separators, split the numbers evenly, e.g. "21 1 42 105 51 15"
+	if len(coords) == 1 && coords[0].hemisphere == 0 && len(coords[0].values)%2 == 0 {
+		values := coords[0].values
+		half := len(values) / 2
+		coords = []coordinate{{values: values[:half], negative: coords[0].negative}, {values: values[half:]}}
+	}
+	if len(coords) != 2 {
+		return nil, ErrUnrecognized
+	}
+
+	lat, ok := coords[0].degrees()
+	if !ok {
+		return nil, ErrUnrecognized
+	}
+	lng, ok := coords[1].degrees()
+	if !ok {
+		return nil, ErrUnrecognized
+	}
+	if isLongitudeHemisphere(coords[0].hemisphere) || isLatitudeHemisphere(coords[1].hemisphere) {
+		if isLatitudeHemisphere(coords[0].hemisphere) || isLongitudeHemisphere(coords[1].hemisphere) {
+			return nil, ErrUnrecognized
+		}
+		lat, lng = lng, lat
+	}
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return nil, ErrOutOfRange
+	}
+
+	format := FormatDecimal
+	if len(coords[0].values) > 1 || len(coords[1].values) > 1 {
+		format = FormatDMS
+	}
+	return &Result{Format: format, Latitude: lat, Longitude: lng}, nil
+}
+
+// tokenize splits input into numbers, hemisphere letters and commas,
+// dropping degree, minute and second marks. Anything else fails.
+func tokenize(input string) ([]string, bool) {
+	var tokens []string
+	runes := []rune(strings.ToUpper(input))
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r) || strings.ContainsRune("°º˚'′’‘\"″”“", r):
+			i++
+		case r == ',' || r == ';':
+			tokens = append(tokens, ",")
+			i++
+		case r == 'N' || r == 'S' || r == 'E' || r == 'W':
+			tokens = append(tokens, string(r))
+			i++
+		case r == '-' || r == '+' || r == '.' || unicode.IsDigit(r):
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			tok := string(runes[i:j])
+			tokens = append(tokens, strings.TrimPrefix(tok, "+"))
+			i = j
+		default:
+			return nil, false
+		}
+	}
+	return tokens, true
+}
+
+func isHemisphere(tok string) bool {
+	return tok == "N" || tok == "S" || tok == "E" || tok == "W"
+}
+
+func isLatitudeHemisphere(r rune) bool {
+	return r == 'N' || r == 'S'
+}
+
+func isLongitudeHemisphere(r rune) bool {
+	return r == 'E' || r == 'W'
+}
+
+// googlePinPattern matches the exact pin of a Google Maps place link,
+// "!3d<lat>!4d<lng>", which is more precise than the map center
+var googlePinPattern = regexp.MustCompile(`!3d(-?\d+(?:\.\d+)?)!4d(-?\d+(?:\.\d+)?)`)
+
+// googleCenterPattern matches the map center of a Google Maps link,
+// "@<lat>,<lng>,<zoom>z"
+var googleCenterPattern = regexp.MustCompile(`@(-?\d+(?:\.\d+)?),(-?\d+(?:\.\d+)?)`)
+
+// parseURL reads the position from a map link. Query parameters that
+// carry a position may themselves hold coordinates or a plus code.
+func parseURL(input string) (*Result, error) {
+	if !strings.Contains(input, "://") && !strings.HasPrefix(strings.ToLower(input), "geo:") {
+		input = "https://" + input
+	}
+	u, err := url.Parse(input)
+	if err != nil {
+		return nil, ErrUnrecognized
+	}
+
+	if strings.EqualFold(u.Scheme, "geo") {
+		return parseGeoURI(u)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if IsShortLinkHost(host) {
+		return &Result{Format: FormatMapURL, ShortLink: u.String()}, nil
+	}
+
+	query := u.Query()
+	path, _ := url.PathUnescape(u.EscapedPath())
+
+	switch {
+	case strings.HasPrefix(host, "consent.google."):
+		// Cookie consent pages wrap the link that was asked for
+		if next := query.Get("continue"); next != "" {
+			return parseURL(next)
+		}
+
+	case strings.Contains(host, "google."):
+		if m := googlePinPattern.FindStringSubmatch(path); m != nil {
+			return mapResult(m[1] + "," + m[2])
+		}
+		for _, key := range []string{"q", "query", "ll", "destination", "daddr", "center"} {
+			if r, err := mapResult(query.Get(key)); err == nil {
+				return r, nil
+			}
+		}
+		if m := googleCenterPattern.FindStringSubmatch(path); m != nil {
+			return mapResult(m[1] + "," + m[2])
+		}
+		// e.g. /maps/search/21.0285,+105.8542 or /maps/place/QPG4%2B4G+Hà+Nội.
+		// A + is a space here, so it is replaced before %2B is unescaped.
+		escaped := u.EscapedPath()
+		for _, marker := range []string{"/search/", "/place/", "/dir//"} {
+			if i := strings.Index(escaped, marker); i >= 0 {
+				segment := strings.SplitN(escaped[i+len(marker):], "/", 2)[0]
+				segment, err := url.PathUnescape(strings.ReplaceAll(segment, "+", " "))
+				if err != nil {
+					continue
+				}
+				if r, err := mapResult(segment); err == nil {
+					return r, nil
+				}
+			}
+		}
+
+	case strings.Contains(host, "openstreetmap.org"):
+		if r, err := mapResult(query.Get("mlat") + "," + query.Get("mlon")); err == nil {
+			return r, nil
+		}
+		// #map=<zoom>/<lat>/<lng>
+		fragment, _ := url.ParseQuery(u.Fragment)
+		if parts := strings.Split(fragment.Get("map"), "/"); len(parts) == 3 {
+			return mapResult(parts[1] + "," + parts[2])
+		}
+
+	default:
+		// Apple Maps, Waze and most others use ll or q
+		for _, key := range []string{"ll", "q", "coordinate", "sll"} {
+			if r, err := mapResult(query.Get(key)); err == nil {
+				return r, nil
+			}
+		}
+		// Bing uses cp=<lat>~<lng>
+		if cp := query.Get("cp"); cp != "" {
+			return mapResult(strings.Replace(cp, "~", ",", 1))
+		}
+	}
+
+	return nil, ErrUnrecognized
+}
+
+// parseGeoURI reads a geo: URI, "geo:<lat>,<lng>[,<alt>][;params]", whose
+// coordinates may be 0,0 when a query names the place instead
+func parseGeoURI(u *url.URL) (*Result, error) {
+	opaque := u.Opaque
+	if i := strings.IndexAny(opaque, ";?"); i >= 0 {
+		opaque = opaque[:i]
+	}
+	parts := strings.Split(opaque, ",")
+	if len(parts) >= 2 && !(parts[0] == "0" && parts[1] == "0") {
+		return mapResult(parts[0] + "," + parts[1])
+	}
+
+	// geo:0,0?q=<lat>,<lng>(<label>)
+	q := u.Query().Get("q")
+	if i := strings.IndexByte(q, '('); i >= 0 {
+		q = q[:i]
+	}
+	return mapResult(q)
+}
+
+// mapResult parses a position found inside a map link
+func mapResult(value string) (*Result, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "," {
+		return nil, ErrUnrecognized
+	}
+
+	var r *Result
+	if pc, ok := parsePlusCode(value); ok {
+		r = pc
+	} else {
+		var err error
+		if r, err = parseCoordinates(value); err != nil {
+			return nil, err
+		}
+	}
+	r.Format = FormatMapURL
+	return r, nil
+}
@@ -0,0 +1,153 @@
+package geoparse
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		format Format
+		lat    float64
+		lng    float64
+	}{
+		{name: "decimal with comma", input: "21.0285, 105.8542", format: FormatDecimal, lat: 21.0285, lng: 105.8542},
+		{name: "decimal with space", input: "  21.0285 105.8542 ", format: FormatDecimal, lat: 21.0285, lng: 105.8542},
+		{name: "decimal with semicolon", input: "-33.8688;151.2093", format: FormatDecimal, lat: -33.8688, lng: 151.2093},
+		{name: "sign starts the second coordinate", input: "21 -105", format: FormatDecimal, lat: 21, lng: -105},
+		{name: "explicit plus sign", input: "+21.5, +105.5", format: FormatDecimal, lat: 21.5, lng: 105.5},
+		{name: "decimal with hemispheres", input: "21.0285 N, 105.8542 E", format: FormatDecimal, lat: 21.0285, lng: 105.8542},
+
+		{name: "dms", input: `21°01'42.6"N 105°51'15.1"E`, format: FormatDMS, lat: 21.0285, lng: 105.8541944},
+		{name: "dms with typographic marks", input: `21°01′42.6″N, 105°51′15.1″E`, format: FormatDMS, lat: 21.0285, lng: 105.8541944},
+		{name: "dms southern and western", input: `33°52'7.7"S 70°40'30"W`, format: FormatDMS, lat: -33.8688056, lng: -70.675},
+		{name: "dms lowercase hemispheres", input: `21°01'42.6"n 105°51'15.1"e`, format: FormatDMS, lat: 21.0285, lng: 105.8541944},
+		{name: "hemispheres first", input: "N 21 1 42.6 E 105 51 15.1", format: FormatDMS, lat: 21.0285, lng: 105.8541944},
+		{name: "longitude first by hemisphere", input: `105°51'15.1"E 21°01'42.6"N`, format: FormatDMS, lat: 21.0285, lng: 105.8541944},
+		{name: "degrees and decimal minutes", input: "21 1.71 N 105 51.2517 E", format: FormatDMS, lat: 21.0285, lng: 105.854195},
+		{name: "numbers split evenly", input: "21 1 42.6 105 51 15.1", format: FormatDMS, lat: 21.0285, lng: 105.8541944},
+		{name: "negative dms", input: "-33 52 7.7, 151 12 33.5", format: FormatDMS, lat: -33.8688056, lng: 151.2093056},
+
+		{name: "full plus code", input: "7PH7QPG4+4G", format: FormatPlusCode, lat: 21.7753125, lng: 105.7063125},
+
+		{name: "google pin beats map center", input: "https://www.google.com/maps/place/Ho+Guom/@21.02,105.85,17z/data=!3m1!4b1!4m6!3m5!1s0x0:0x0!8m2!3d21.0285!4d105.8542", format: FormatMapURL, lat: 21.0285, lng: 105.8542},
+		{name: "google query", input: "https://maps.google.com/?q=21.0285,105.8542", format: FormatMapURL, lat: 21.0285, lng: 105.8542},
+		{name: "google map center", input: "https://www.google.com/maps/@21.0285,105.8542,15z", format: FormatMapURL, lat: 21.0285, lng: 105.8542},
+		{name: "google search path", input: "https://www.google.com/maps/search/21.0285,+105.8542", format: FormatMapURL, lat: 21.0285, lng: 105.8542},
+		{name: "google link without scheme", input: "www.google.com/maps?q=21.0285,105.8542", format: FormatMapURL, lat: 21.0285, lng: 105.8542},
+		{name: "google consent wrapper", input: "https://consent.google.com/ml?continue=https://www.google.com/maps?q%3D21.0285,105.8542", format: FormatMapURL, lat: 21.0285, lng: 105.8542},
+		{name: "openstreetmap marker", input: "https://www.openstreetmap.org/?mlat=21.0285&mlon=105.8542#map=17/21.03/105.85", format: FormatMapURL, lat: 21.0285, lng: 105.8542},
+		{name: "openstreetmap view", input: "https://www.openstreetmap.org/#map=17/21.0285/105.8542", format: FormatMapURL, lat: 21.0285, lng: 105.8542},
+		{name: "apple maps", input: "https://maps.apple.com/?ll=21.0285,105.8542", format: FormatMapURL, lat: 21.0285, lng: 105.8542},
+		{name: "bing", input: "https://www.bing.com/maps?cp=21.0285~105.8542&lvl=16", format: FormatMapURL, lat: 21.0285, lng: 105.8542},
+		{name: "geo uri", input: "geo:21.0285,105.8542;u=35", format: FormatMapURL, lat: 21.0285, lng: 105.8542},
+		{name: "geo uri with query", input: "geo:0,0?q=21.0285,105.8542(Hồ Gươm)", format: FormatMapURL, lat: 21.0285, lng: 105.8542},
+	}
+
+	const eps = 1e-6
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.input, err)
+			}
+			if !got.Resolved() {
+				t.Fatalf("Parse(%q) = %+v, want a resolved position", tt.input, got)
+			}
+			if got.Format != tt.format || math.Abs(got.Latitude-tt.lat) > eps || math.Abs(got.Longitude-tt.lng) > eps {
+				t.Errorf("Parse(%q) = %s %v, %v, want %s %v, %v",
+					tt.input, got.Format, got.Latitude, got.Longitude, tt.format, tt.lat, tt.lng)
+			}
+		})
+	}
+}
+
+func TestParseUnresolved(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Result
+	}{
+		{
+			name:  "short plus code with locality",
+			input: "QPG4+4G Hoàn Kiếm, Hà Nội",
+			want:  Result{Format: FormatShortPlusCode, PlusCode: "QPG4+4G", ShortCode: "QPG4+4G", Locality: "Hoàn Kiếm, Hà Nội"},
+		},
+		{
+			name:  "locality before the code",
+			input: "Hà Nội, qpg4+4g",
+			want:  Result{Format: FormatShortPlusCode, PlusCode: "QPG4+4G", ShortCode: "QPG4+4G", Locality: "Hà Nội"},
+		},
+		{
+			name:  "bare short plus code",
+			input: "QPG4+4G",
+			want:  Result{Format: FormatShortPlusCode, PlusCode: "QPG4+4G", ShortCode: "QPG4+4G"},
+		},
+		{
+			name:  "short plus code in a google link",
+			input: "https://www.google.com/maps/place/QPG4%2B4G+H%C3%A0+N%E1%BB%99i",
+			want:  Result{Format: FormatMapURL, PlusCode: "QPG4+4G", ShortCode: "QPG4+4G", Locality: "Hà Nội"},
+		},
+		{
+			name:  "short link",
+			input: "https://maps.app.goo.gl/AbCdEf123",
+			want:  Result{Format: FormatMapURL, ShortLink: "https://maps.app.goo.gl/AbCdEf123"},
+		},
+		{
+			name:  "short link without scheme",
+			input: "goo.gl/maps/AbCdEf123",
+			want:  Result{Format: FormatMapURL, ShortLink: "https://goo.gl/maps/AbCdEf123"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.input, err)
+			}
+			if got.Resolved() {
+				t.Fatalf("Parse(%q) resolved to %v, %v, want it left to resolve", tt.input, got.Latitude, got.Longitude)
+			}
+			if *got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.input, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  error
+	}{
+		{name: "empty", input: "   ", want: ErrUnrecognized},
+		{name: "words", input: "near the old bridge", want: ErrUnrecognized},
+		{name: "one number", input: "21.0285", want: ErrUnrecognized},
+		{name: "three coordinates", input: "21, 105, 3", want: ErrUnrecognized},
+		{name: "minutes of 60", input: `21°60'0"N 105°51'15"E`, want: ErrUnrecognized},
+		{name: "decimal degrees with minutes", input: "21.5 30 N 105 30 E", want: ErrUnrecognized},
+		{name: "decimal minutes with seconds", input: "21 1.5 30 N 105 51 15 E", want: ErrUnrecognized},
+		{name: "both latitudes", input: "21 N, 105 S", want: ErrUnrecognized},
+		{name: "both longitudes", input: "21 E, 105 W", want: ErrUnrecognized},
+		{name: "latitude out of range", input: "91, 105", want: ErrOutOfRange},
+		{name: "longitude out of range", input: "21, 181", want: ErrOutOfRange},
+		{name: "swapped into range still checked", input: "200 E, 21 N", want: ErrOutOfRange},
+		{name: "invalid plus code", input: "7PH7QPG4+4", want: ErrUnrecognized},
+		{name: "map link without a position", input: "https://www.google.com/maps", want: ErrUnrecognized},
+		{name: "openstreetmap without a position", input: "https://www.openstreetmap.org/", want: ErrUnrecognized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Parse(%q) = %+v, %v, want error %v", tt.input, got, err, tt.want)
+			}
+		})
+	}
+}
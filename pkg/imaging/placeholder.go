@@ -0,0 +1,93 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+)
+
+// PlaceholderWidth is the width in pixels of generated placeholders. At this
+// size nothing in the scene is recognisable, even when scaled up.
+const PlaceholderWidth = 16
+
+// MaxPlaceholderPixels bounds the source images decoded for a placeholder
+const MaxPlaceholderPixels = 50_000_000
+
+// ErrImageTooLarge is returned when a source image has too many pixels to decode safely
+var ErrImageTooLarge = errors.New("image too large")
+
+// Placeholder decodes a JPEG, PNG or GIF image and returns a heavily
+// downscaled copy as a JPEG data URI, for display behind a CSS blur
+func Placeholder(r io.Reader) (string, error) {
+	var buf bytes.Buffer
+	cfg, _, err := image.DecodeConfig(io.TeeReader(r, &buf))
+	if err != nil {
+		return "", err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return "", errors.New("empty image")
+	}
+	if cfg.Width*cfg.Height > MaxPlaceholderPixels {
+		return "", ErrImageTooLarge
+	}
+
+	src, _, err := image.Decode(io.MultiReader(&buf, r))
+	if err != nil {
+		return "", err
+	}
+
+	width := min(PlaceholderWidth, cfg.Width)
+	height := max(1, cfg.Height*width/cfg.Width)
+	dst := downscale(src, width, height)
+
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, dst, &jpeg.Options{Quality: 50}); err != nil {
+		return "", err
+	}
+
+	return "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(out.Bytes()), nil
+}
+
+// downscale shrinks src to width x height by averaging every source pixel
+// that falls in each destination pixel
+func downscale(src image.Image, width, height int) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for dy := 0; dy < height; dy++ {
+		y0 := b.Min.Y + dy*b.Dy()/height
+		y1 := max(y0+1, b.Min.Y+(dy+1)*b.Dy()/height)
+
+		for dx := 0; dx < width; dx++ {
+			x0 := b.Min.X + dx*b.Dx()/width
+			x1 := max(x0+1, b.Min.X+(dx+1)*b.Dx()/width)
+
+			var r, g, bl, a, n uint64
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					pr, pg, pb, pa := src.At(x, y).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					bl += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+
+			dst.Set(dx, dy, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(bl / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return dst
+}
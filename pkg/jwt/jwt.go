@@ -1,6 +1,8 @@
 package jwt
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"errors"
 	"time"
 
@@ -27,6 +29,23 @@ type RefreshClaims struct {
 	jwt.RegisteredClaims
 }
 
+// ActionClaims represents the claims of a case action token. An action token
+// lets its holder take one action on one case as the user it was issued to,
+// without a session.
+type ActionClaims struct {
+	CaseID uuid.UUID `json:"case_id"`
+	Action string    `json:"action"`
+	jwt.RegisteredClaims
+}
+
+// UserID returns the user the action token was issued to
+func (c *ActionClaims) UserID() (uuid.UUID, error) {
+	return uuid.Parse(c.Subject)
+}
+
+// actionAudience marks action tokens so they are never mistaken for others
+const actionAudience = "case-action"
+
 // TokenPair represents access and refresh tokens
 type TokenPair struct {
 	AccessToken  string    `json:"access_token"`
@@ -37,19 +56,31 @@ type TokenPair struct {
 // Service handles JWT operations
 type Service struct {
 	secret        []byte
+	actionSecret  []byte
 	accessExpiry  time.Duration
 	refreshExpiry time.Duration
+	actionExpiry  time.Duration
 }
 
 // NewService creates a new JWT service
 func NewService(cfg *config.JWTConfig) *Service {
 	return &Service{
 		secret:        []byte(cfg.Secret),
+		actionSecret:  deriveKey(cfg.Secret, actionAudience),
 		accessExpiry:  cfg.AccessExpiry,
 		refreshExpiry: cfg.RefreshExpiry,
+		actionExpiry:  cfg.ActionExpiry,
 	}
 }
 
+// deriveKey derives a signing key for one kind of token from the secret, so
+// a token of that kind can never pass as an access or refresh token
+func deriveKey(secret, purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
 // GenerateTokenPair generates both access and refresh tokens
 func (s *Service) GenerateTokenPair(userID uuid.UUID, email string) (*TokenPair, error) {
 	accessToken, expiresAt, err := s.generateAccessToken(userID, email)
@@ -159,6 +190,61 @@ func (s *Service) ValidateRefreshToken(tokenString string) (*RefreshClaims, erro
 	return claims, nil
 }
 
+// GenerateActionToken generates a short-lived token allowing userID to take
+// action on caseID
+func (s *Service) GenerateActionToken(userID, caseID uuid.UUID, action string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.actionExpiry)
+
+	claims := &ActionClaims{
+		CaseID: caseID,
+		Action: action,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "rescue-app",
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{actionAudience},
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString(s.actionSecret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenString, expiresAt, nil
+}
+
+// ValidateActionToken validates a case action token and returns claims
+func (s *Service) ValidateActionToken(tokenString string) (*ActionClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &ActionClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+		return s.actionSecret, nil
+	}, jwt.WithAudience(actionAudience))
+
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(*ActionClaims)
+	if !ok || !token.Valid || claims.CaseID == uuid.Nil {
+		return nil, ErrInvalidToken
+	}
+	if _, err := claims.UserID(); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
 // RefreshTokens generates new token pair using a valid refresh token
 func (s *Service) RefreshTokens(refreshTokenString string, email string) (*TokenPair, error) {
 	claims, err := s.ValidateRefreshToken(refreshTokenString)
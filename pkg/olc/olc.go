@@ -0,0 +1,272 @@
+// Package olc encodes and decodes Open Location Codes, also known as plus
+// codes, following the reference implementation at
+// https://github.com/google/open-location-code
+package olc
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+const (
+	// Separator sits after the eighth digit of every code
+	Separator = '+'
+	// Padding fills out codes shorter than eight digits
+	Padding = '0'
+
+	alphabet          = "23456789CFGHJMPQRVWX"
+	separatorPosition = 8
+	encodingBase      = 20
+	pairCodeLength    = 10
+	maxCodeLength     = 15
+	gridColumns       = 4
+	gridRows          = 5
+	latMax            = 90
+	lngMax            = 180
+
+	// pairPrecision is the number of steps per degree at the end of the
+	// pair section; finer precisions add the grid section
+	pairPrecision     = 8000
+	pairFirstPlace    = 160000               // encodingBase^4
+	finalLatPrecision = pairPrecision * 3125 // gridRows^5
+	finalLngPrecision = pairPrecision * 1024 // gridColumns^5
+	gridLatFirstPlace = 625                  // gridRows^4
+	gridLngFirstPlace = 256                  // gridColumns^4
+	defaultCodeLength = pairCodeLength
+)
+
+var (
+	// ErrInvalidCode is returned for strings that are not plus codes
+	ErrInvalidCode = errors.New("invalid plus code")
+	// ErrNotShort is returned when recovering a code that is not short
+	ErrNotShort = errors.New("plus code is not short")
+)
+
+// CodeArea is the rectangle a code stands for
+type CodeArea struct {
+	LatLo, LngLo float64
+	LatHi, LngHi float64
+	CodeLength   int
+}
+
+// Center returns the middle of the area
+func (a CodeArea) Center() (lat, lng float64) {
+	lat = math.Min((a.LatLo+a.LatHi)/2, latMax)
+	lng = math.Min((a.LngLo+a.LngHi)/2, lngMax)
+	return lat, lng
+}
+
+// Encode returns the code of the given length for a point. Lengths below
+// ten must be even; 10 is about 14 m across and each digit past it about
+// five times finer.
+func Encode(lat, lng float64, codeLength int) string {
+	if codeLength < 2 || (codeLength < pairCodeLength && codeLength%2 == 1) {
+		codeLength = defaultCodeLength
+	}
+	if codeLength > maxCodeLength {
+		codeLength = maxCodeLength
+	}
+
+	latVal := int64(math.Floor(math.Round((clipLatitude(lat)+latMax)*finalLatPrecision*1e6) / 1e6))
+	lngVal := int64(math.Floor(math.Round((lng+lngMax)*finalLngPrecision*1e6) / 1e6))
+	// The north pole belongs to the row below it
+	if latVal >= 2*latMax*finalLatPrecision {
+		latVal = 2*latMax*finalLatPrecision - 1
+	}
+	lngVal %= 2 * lngMax * finalLngPrecision
+	if lngVal < 0 {
+		lngVal += 2 * lngMax * finalLngPrecision
+	}
+
+	code := make([]byte, maxCodeLength)
+	if codeLength > pairCodeLength {
+		for i := maxCodeLength - 1; i >= pairCodeLength; i-- {
+			code[i] = alphabet[(latVal%gridRows)*gridColumns+lngVal%gridColumns]
+			latVal /= gridRows
+			lngVal /= gridColumns
+		}
+	} else {
+		latVal /= 3125
+		lngVal /= 1024
+	}
+	for i := pairCodeLength/2 - 1; i >= 0; i-- {
+		code[i*2] = alphabet[latVal%encodingBase]
+		code[i*2+1] = alphabet[lngVal%encodingBase]
+		latVal /= encodingBase
+		lngVal /= encodingBase
+	}
+
+	if codeLength < separatorPosition {
+		return string(code[:codeLength]) + strings.Repeat(string(Padding), separatorPosition-codeLength) + string(Separator)
+	}
+	return string(code[:separatorPosition]) + string(Separator) + string(code[separatorPosition:codeLength])
+}
+
+// Decode returns the area a full code stands for
+func Decode(code string) (CodeArea, error) {
+	if !IsFull(code) {
+		return CodeArea{}, ErrInvalidCode
+	}
+
+	digits := strings.ToUpper(strings.NewReplacer(string(Separator), "", string(Padding), "").Replace(code))
+	if len(digits) > maxCodeLength {
+		digits = digits[:maxCodeLength]
+	}
+
+	normalLat := int64(-latMax * pairPrecision)
+	normalLng := int64(-lngMax * pairPrecision)
+	pairDigits := min(len(digits), pairCodeLength)
+	placeValue := int64(pairFirstPlace)
+	for i := 0; i < pairDigits; i += 2 {
+		normalLat += int64(strings.IndexByte(alphabet, digits[i])) * placeValue
+		normalLng += int64(strings.IndexByte(alphabet, digits[i+1])) * placeValue
+		if i < pairDigits-2 {
+			placeValue /= encodingBase
+		}
+	}
+	latPrecision := float64(placeValue) / pairPrecision
+	lngPrecision := float64(placeValue) / pairPrecision
+
+	var gridLat, gridLng int64
+	if len(digits) > pairCodeLength {
+		rowPlace, colPlace := int64(gridLatFirstPlace), int64(gridLngFirstPlace)
+		for i := pairCodeLength; i < len(digits); i++ {
+			value := int64(strings.IndexByte(alphabet, digits[i]))
+			gridLat += value / gridColumns * rowPlace
+			gridLng += value % gridColumns * colPlace
+			if i < len(digits)-1 {
+				rowPlace /= gridRows
+				colPlace /= gridColumns
+			}
+		}
+		latPrecision = float64(rowPlace) / finalLatPrecision
+		lngPrecision = float64(colPlace) / finalLngPrecision
+	}
+
+	lat := float64(normalLat)/pairPrecision + float64(gridLat)/finalLatPrecision
+	lng := float64(normalLng)/pairPrecision + float64(gridLng)/finalLngPrecision
+	return CodeArea{
+		LatLo:      lat,
+		LngLo:      lng,
+		LatHi:      lat + latPrecision,
+		LngHi:      lng + lngPrecision,
+		CodeLength: len(digits),
+	}, nil
+}
+
+// IsValid reports whether code is a full or short plus code
+func IsValid(code string) bool {
+	if code == "" {
+		return false
+	}
+	code = strings.ToUpper(code)
+
+	sep := strings.IndexByte(code, Separator)
+	if sep < 0 || sep != strings.LastIndexByte(code, Separator) || sep > separatorPosition || sep%2 == 1 {
+		return false
+	}
+
+	if pad := strings.IndexByte(code, Padding); pad >= 0 {
+		// Padding only fits codes that stop before the separator, in whole
+		// pairs, and nothing may follow it
+		if sep < separatorPosition || pad == 0 {
+			return false
+		}
+		end := pad
+		for end < len(code) && code[end] == Padding {
+			end++
+		}
+		if end != sep || (end-pad)%2 == 1 || len(code) > sep+1 {
+			return false
+		}
+	}
+
+	// A single digit after the separator is not a valid refinement
+	if len(code)-sep-1 == 1 {
+		return false
+	}
+
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		if c != Separator && c != Padding && strings.IndexByte(alphabet, c) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsShort reports whether code is a valid short code, one with leading
+// digits removed that only makes sense near a reference point
+func IsShort(code string) bool {
+	return IsValid(code) && strings.IndexByte(code, Separator) < separatorPosition
+}
+
+// IsFull reports whether code is a valid code for a single place on Earth
+func IsFull(code string) bool {
+	if !IsValid(code) || IsShort(code) {
+		return false
+	}
+	code = strings.ToUpper(code)
+	if strings.IndexByte(alphabet, code[0])*encodingBase >= latMax*2 {
+		return false
+	}
+	if len(code) > 1 && strings.IndexByte(alphabet, code[1])*encodingBase >= lngMax*2 {
+		return false
+	}
+	return true
+}
+
+// RecoverNearest completes a short code to the full code of the matching
+// area nearest the reference point. Full codes are returned as they are.
+func RecoverNearest(code string, refLat, refLng float64) (string, error) {
+	if IsFull(code) {
+		return strings.ToUpper(code), nil
+	}
+	if !IsShort(code) {
+		return "", ErrNotShort
+	}
+	code = strings.ToUpper(code)
+
+	refLat = clipLatitude(refLat)
+	refLng = normalizeLongitude(refLng)
+
+	paddingLength := separatorPosition - strings.IndexByte(code, Separator)
+	resolution := math.Pow(encodingBase, float64(2-paddingLength/2))
+	halfResolution := resolution / 2
+
+	area, err := Decode(Encode(refLat, refLng, defaultCodeLength)[:paddingLength] + code)
+	if err != nil {
+		return "", err
+	}
+	lat, lng := area.Center()
+
+	// The prefix taken from the reference may put the area on the wrong
+	// side of a cell boundary, so move it by a cell if that is nearer
+	if refLat+halfResolution < lat && lat-resolution >= -latMax {
+		lat -= resolution
+	} else if refLat-halfResolution > lat && lat+resolution <= latMax {
+		lat += resolution
+	}
+	if refLng+halfResolution < lng {
+		lng -= resolution
+	} else if refLng-halfResolution > lng {
+		lng += resolution
+	}
+
+	return Encode(lat, lng, area.CodeLength), nil
+}
+
+func clipLatitude(lat float64) float64 {
+	return math.Min(latMax, math.Max(-latMax, lat))
+}
+
+func normalizeLongitude(lng float64) float64 {
+	for lng < -lngMax {
+		lng += 2 * lngMax
+	}
+	for lng >= lngMax {
+		lng -= 2 * lngMax
+	}
+	return lng
+}
@@ -0,0 +1,176 @@
+package olc
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name       string
+		lat, lng   float64
+		codeLength int
+		want       string
+	}{
+		{name: "padded", lat: 20.375, lng: 2.775, codeLength: 6, want: "7FG49Q00+"},
+		{name: "standard length", lat: 20.3700625, lng: 2.7821875, codeLength: 10, want: "7FG49QCJ+2V"},
+		{name: "grid refinement", lat: 20.3701125, lng: 2.782234375, codeLength: 11, want: "7FG49QCJ+2VX"},
+		{name: "southern and eastern", lat: -41.2730625, lng: 174.7859375, codeLength: 10, want: "4VCPPQGP+Q9"},
+		{name: "origin corner", lat: -89.9999375, lng: -179.9999375, codeLength: 10, want: "22222222+22"},
+		{name: "north pole belongs to the row below", lat: 90, lng: 1, codeLength: 4, want: "CFX30000+"},
+		{name: "latitude clipped", lat: 92, lng: 1, codeLength: 4, want: "CFX30000+"},
+		{name: "longitude 180 wraps", lat: 1, lng: 180, codeLength: 4, want: "62H20000+"},
+		{name: "longitude past 180 wraps", lat: 1, lng: 181, codeLength: 4, want: "62H30000+"},
+		{name: "odd short length falls back to default", lat: 20.3700625, lng: 2.7821875, codeLength: 7, want: "7FG49QCJ+2V"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Encode(tt.lat, tt.lng, tt.codeLength); got != tt.want {
+				t.Errorf("Encode(%v, %v, %d) = %q, want %q", tt.lat, tt.lng, tt.codeLength, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		code string
+		want CodeArea
+	}{
+		{code: "7FG49QCJ+2V", want: CodeArea{LatLo: 20.37, LngLo: 2.782125, LatHi: 20.370125, LngHi: 2.78225, CodeLength: 10}},
+		{code: "7fg49qcj+2v", want: CodeArea{LatLo: 20.37, LngLo: 2.782125, LatHi: 20.370125, LngHi: 2.78225, CodeLength: 10}},
+		{code: "7FG49Q00+", want: CodeArea{LatLo: 20.35, LngLo: 2.75, LatHi: 20.4, LngHi: 2.8, CodeLength: 6}},
+		{code: "CFX30000+", want: CodeArea{LatLo: 89, LngLo: 1, LatHi: 90, LngHi: 2, CodeLength: 4}},
+	}
+
+	const eps = 1e-9
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			got, err := Decode(tt.code)
+			if err != nil {
+				t.Fatalf("Decode(%q) error = %v", tt.code, err)
+			}
+			if got.CodeLength != tt.want.CodeLength ||
+				math.Abs(got.LatLo-tt.want.LatLo) > eps || math.Abs(got.LngLo-tt.want.LngLo) > eps ||
+				math.Abs(got.LatHi-tt.want.LatHi) > eps || math.Abs(got.LngHi-tt.want.LngHi) > eps {
+				t.Errorf("Decode(%q) = %+v, want %+v", tt.code, got, tt.want)
+			}
+		})
+	}
+
+	for _, code := range []string{"9G8F+6X", "7FG49QCJ", "WFG49QCJ+2V", "7FG49QCJ+2V+"} {
+		if _, err := Decode(code); !errors.Is(err, ErrInvalidCode) {
+			t.Errorf("Decode(%q) error = %v, want ErrInvalidCode", code, err)
+		}
+	}
+}
+
+func TestCodeKinds(t *testing.T) {
+	tests := []struct {
+		code             string
+		valid, short, ok bool
+	}{
+		{code: "8FVC9G8F+6X", valid: true, ok: true},
+		{code: "8fvc9g8f+6x", valid: true, ok: true},
+		{code: "8FVC9G8F+6XR", valid: true, ok: true},
+		{code: "8FVC0000+", valid: true, ok: true},
+		{code: "9G8F+6X", valid: true, short: true},
+		{code: "+6X", valid: true, short: true},
+		{code: "8FVC9G8F", valid: false},
+		{code: "8FVC9G8F+6", valid: false},
+		{code: "8FVC9G8+6X", valid: false},
+		{code: "8FV00000+", valid: false},
+		{code: "8FVC0000+6X", valid: false},
+		{code: "0FVC0000+", valid: false},
+		{code: "8FVC9G8F+6A", valid: false},
+		{code: "8F+VC+9G", valid: false},
+		{code: "", valid: false},
+		// Valid digits, but past the north pole or the antimeridian
+		{code: "F2222222+22", valid: true},
+		{code: "2W222222+22", valid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := IsValid(tt.code); got != tt.valid {
+				t.Errorf("IsValid() = %v, want %v", got, tt.valid)
+			}
+			if got := IsShort(tt.code); got != tt.short {
+				t.Errorf("IsShort() = %v, want %v", got, tt.short)
+			}
+			if got := IsFull(tt.code); got != tt.ok {
+				t.Errorf("IsFull() = %v, want %v", got, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRecoverNearest(t *testing.T) {
+	tests := []struct {
+		name           string
+		code           string
+		refLat, refLng float64
+		want           string
+	}{
+		{name: "nearby reference", code: "9G8F+6X", refLat: 47.4, refLng: 8.6, want: "8FVC9G8F+6X"},
+		{name: "lowercase", code: "9g8f+6x", refLat: 47.4, refLng: 8.6, want: "8FVC9G8F+6X"},
+		{name: "full code returned as is", code: "8fvc9g8f+6x", refLat: 0, refLng: 0, want: "8FVC9G8F+6X"},
+		// QPG4+4G lies at about 20.75 or 21.75 near here; 20.75 is nearer
+		{name: "nearer of two candidate areas", code: "QPG4+4G", refLat: 21.03, refLng: 105.85, want: "7PG7QPG4+4G"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RecoverNearest(tt.code, tt.refLat, tt.refLng)
+			if err != nil {
+				t.Fatalf("RecoverNearest() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RecoverNearest(%q, %v, %v) = %q, want %q", tt.code, tt.refLat, tt.refLng, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := RecoverNearest("not a code", 0, 0); !errors.Is(err, ErrNotShort) {
+		t.Errorf("RecoverNearest(invalid) error = %v, want ErrNotShort", err)
+	}
+}
+
+// TestRecoverNearestAcrossBoundaries shortens the code of a point and
+// recovers it from a reference on the other side of a cell edge, where the
+// reference's own leading digits are wrong
+func TestRecoverNearestAcrossBoundaries(t *testing.T) {
+	tests := []struct {
+		name           string
+		lat, lng       float64
+		refLat, refLng float64
+		removedDigits  int
+	}{
+		{name: "same cell", lat: 21.0285, lng: 105.8542, refLat: 21.1, refLng: 105.9, removedDigits: 4},
+		{name: "cell to the north", lat: 21.0005, lng: 105.8542, refLat: 20.9995, refLng: 105.8542, removedDigits: 4},
+		{name: "cell to the south", lat: 20.9995, lng: 105.8542, refLat: 21.0005, refLng: 105.8542, removedDigits: 4},
+		{name: "cell to the east", lat: 21.0285, lng: 106.0005, refLat: 21.0285, refLng: 105.9995, removedDigits: 4},
+		{name: "cell to the west", lat: 21.0285, lng: 105.9995, refLat: 21.0285, refLng: 106.0005, removedDigits: 4},
+		{name: "across the antimeridian going east", lat: -17.5, lng: -179.99, refLat: -17.5, refLng: 179.99, removedDigits: 4},
+		{name: "across the antimeridian going west", lat: -17.5, lng: 179.99, refLat: -17.5, refLng: -179.99, removedDigits: 4},
+		{name: "two digits removed", lat: 10.7769, lng: 106.7009, refLat: 12, refLng: 104, removedDigits: 2},
+		{name: "six digits removed", lat: 10.7769, lng: 106.7009, refLat: 10.78, refLng: 106.70, removedDigits: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			full := Encode(tt.lat, tt.lng, 10)
+			short := full[tt.removedDigits:]
+
+			got, err := RecoverNearest(short, tt.refLat, tt.refLng)
+			if err != nil {
+				t.Fatalf("RecoverNearest(%q) error = %v", short, err)
+			}
+			if got != full {
+				t.Errorf("RecoverNearest(%q, %v, %v) = %q, want %q", short, tt.refLat, tt.refLng, got, full)
+			}
+		})
+	}
+}
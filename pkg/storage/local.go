@@ -0,0 +1,445 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// localMetaDir holds per-object metadata alongside the data files
+	localMetaDir = ".meta"
+	// localUploadsDir holds multipart upload parts until completion
+	localUploadsDir = ".uploads"
+)
+
+// ErrInvalidSignature is returned when a signed local upload URL does not verify
+var ErrInvalidSignature = errors.New("invalid or expired signature")
+
+// FileServer is implemented by backends whose objects are served by this API
+// rather than by an external object store
+type FileServer interface {
+	Open(key string) (io.ReadSeekCloser, *ObjectInfo, error)
+	VerifyUpload(key string, query url.Values) (*ObjectInfo, error)
+	// VerifyDownload checks the signature on a read of a non-public object;
+	// it returns the signed expiry, or the zero time for public objects
+	VerifyDownload(key string, query url.Values) (time.Time, error)
+}
+
+type localClient struct {
+	root    string
+	baseURL string
+	secret  []byte
+	private bool
+	log     *zap.Logger
+}
+
+type localMeta struct {
+	ContentType string            `json:"content_type"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// NewLocalClient creates a storage client backed by a directory on disk.
+// Objects are served under baseURL by the API's file route; when private is
+// set, every read needs a signed URL.
+func NewLocalClient(root, baseURL string, secret []byte, private bool, log *zap.Logger) (Client, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("local storage needs a signing secret (STORAGE_SIGNING_SECRET)")
+	}
+	for _, dir := range []string{root, filepath.Join(root, localMetaDir), filepath.Join(root, localUploadsDir)} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create storage directory: %w", err)
+		}
+	}
+
+	return &localClient{
+		root:    root,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		secret:  secret,
+		private: private,
+		log:     log,
+	}, nil
+}
+
+// VerifyDownload checks a URL produced by PresignGet for non-public objects
+func (c *localClient) VerifyDownload(key string, query url.Values) (time.Time, error) {
+	if !c.private && !strings.HasPrefix(key, PrivatePrefix) {
+		return time.Time{}, nil
+	}
+
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return time.Time{}, ErrInvalidSignature
+	}
+
+	expected := c.signGet(key, expires)
+	if !hmac.Equal([]byte(expected), []byte(query.Get("signature"))) {
+		return time.Time{}, ErrInvalidSignature
+	}
+
+	return time.Unix(expires, 0), nil
+}
+
+// Put streams exactly size bytes from body into the object, replacing it atomically
+func (c *localClient) Put(ctx context.Context, key string, body io.Reader, size int64, opts PutOptions) (*ObjectInfo, error) {
+	path, err := c.objectPath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.writeFile(path, body, size); err != nil {
+		c.log.Error("Failed to write local file", zap.String("key", key), zap.Error(err))
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	if err := c.writeMeta(key, localMeta{ContentType: opts.ContentType, Metadata: opts.Metadata}); err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	return c.Stat(ctx, key)
+}
+
+func (c *localClient) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	return c.Open(key)
+}
+
+func (c *localClient) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	return filepath.WalkDir(c.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name := d.Name()
+		if path != c.root && strings.HasPrefix(name, ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(c.root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := c.Stat(ctx, key)
+		if err != nil {
+			return err
+		}
+		return fn(*info)
+	})
+}
+
+func (c *localClient) Copy(ctx context.Context, srcKey, dstKey string) error {
+	src, info, err := c.Open(srcKey)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	_, err = c.Put(ctx, dstKey, src, info.Size, PutOptions{
+		ContentType: info.ContentType,
+		Metadata:    info.Metadata,
+	})
+	return err
+}
+
+func (c *localClient) Delete(ctx context.Context, key string) error {
+	path, err := c.objectPath(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		c.log.Error("Failed to delete local file", zap.String("key", key), zap.Error(err))
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	_ = os.Remove(filepath.Join(c.root, localMetaDir, filepath.FromSlash(key)))
+
+	return nil
+}
+
+func (c *localClient) GetURL(key string) string {
+	return fmt.Sprintf("%s/%s", c.baseURL, key)
+}
+
+// PresignGet returns a GET URL to the API's file route, signed with an HMAC
+// over the key and expiry
+func (c *localClient) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if _, err := c.objectPath(key); err != nil {
+		return "", err
+	}
+
+	expires := time.Now().Add(expiry).Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", c.signGet(key, expires))
+
+	return c.GetURL(key) + "?" + query.Encode(), nil
+}
+
+// PresignUpload returns a PUT URL to the API's file route, signed with an
+// HMAC over the key, content type, size and expiry
+func (c *localClient) PresignUpload(ctx context.Context, key, contentType string, size int64, expiry time.Duration) (*PresignedUpload, error) {
+	if _, err := c.objectPath(key); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(expiry)
+	query := url.Values{}
+	query.Set("content_type", contentType)
+	query.Set("size", strconv.FormatInt(size, 10))
+	query.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	query.Set("signature", c.sign(key, contentType, size, expiresAt.Unix()))
+
+	return &PresignedUpload{
+		URL:       c.GetURL(key) + "?" + query.Encode(),
+		Method:    http.MethodPut,
+		Headers:   map[string]string{"Content-Type": contentType},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (c *localClient) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	path, err := c.objectPath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	meta := c.readMeta(key)
+	return &ObjectInfo{
+		Key:          key,
+		Size:         stat.Size(),
+		ContentType:  meta.ContentType,
+		Metadata:     meta.Metadata,
+		LastModified: stat.ModTime(),
+	}, nil
+}
+
+func (c *localClient) CreateMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
+	if _, err := c.objectPath(key); err != nil {
+		return "", err
+	}
+
+	uploadID := uuid.New().String()
+	dir := filepath.Join(c.root, localUploadsDir, uploadID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create multipart upload: %w", err)
+	}
+
+	meta, _ := json.Marshal(localMeta{ContentType: contentType})
+	if err := os.WriteFile(filepath.Join(dir, "meta.json"), meta, 0o644); err != nil {
+		return "", fmt.Errorf("failed to create multipart upload: %w", err)
+	}
+
+	return uploadID, nil
+}
+
+func (c *localClient) UploadPart(ctx context.Context, key, uploadID string, partNumber int32, body io.Reader, size int64) (string, error) {
+	dir, err := c.uploadDir(uploadID)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	if err := c.writeFile(filepath.Join(dir, partFileName(partNumber)), io.TeeReader(body, hash), size); err != nil {
+		return "", fmt.Errorf("failed to upload part: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)[:16]), nil
+}
+
+func (c *localClient) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []CompletedPart) error {
+	dir, err := c.uploadDir(uploadID)
+	if err != nil {
+		return err
+	}
+
+	sorted := make([]CompletedPart, len(parts))
+	copy(sorted, parts)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].PartNumber < sorted[j].PartNumber })
+
+	readers := make([]io.Reader, 0, len(sorted))
+	var size int64
+	for _, p := range sorted {
+		f, err := os.Open(filepath.Join(dir, partFileName(p.PartNumber)))
+		if err != nil {
+			return fmt.Errorf("failed to complete multipart upload: %w", err)
+		}
+		defer f.Close()
+
+		stat, err := f.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to complete multipart upload: %w", err)
+		}
+		size += stat.Size()
+		readers = append(readers, f)
+	}
+
+	var meta localMeta
+	if raw, err := os.ReadFile(filepath.Join(dir, "meta.json")); err == nil {
+		_ = json.Unmarshal(raw, &meta)
+	}
+
+	if _, err := c.Put(ctx, key, io.MultiReader(readers...), size, PutOptions{ContentType: meta.ContentType}); err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+
+	return os.RemoveAll(dir)
+}
+
+func (c *localClient) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	dir, err := c.uploadDir(uploadID)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// Open returns the object for serving; the caller closes it
+func (c *localClient) Open(key string) (io.ReadSeekCloser, *ObjectInfo, error) {
+	info, err := c.Stat(context.Background(), key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	path, _ := c.objectPath(key)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	return f, info, nil
+}
+
+// VerifyUpload checks a URL produced by PresignUpload and returns what it allows
+func (c *localClient) VerifyUpload(key string, query url.Values) (*ObjectInfo, error) {
+	contentType := query.Get("content_type")
+	size, err := strconv.ParseInt(query.Get("size"), 10, 64)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return nil, ErrInvalidSignature
+	}
+
+	expected := c.sign(key, contentType, size, expires)
+	if !hmac.Equal([]byte(expected), []byte(query.Get("signature"))) {
+		return nil, ErrInvalidSignature
+	}
+
+	return &ObjectInfo{Key: key, Size: size, ContentType: contentType}, nil
+}
+
+// writeFile streams exactly size bytes into path via a temp file and rename
+func (c *localClient) writeFile(path string, r io.Reader, size int64) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, io.LimitReader(r, size))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if written != size {
+		return fmt.Errorf("short write: got %d of %d bytes", written, size)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// objectPath maps a key to a path inside root, rejecting traversal and
+// the reserved metadata directories
+func (c *localClient) objectPath(key string) (string, error) {
+	clean := filepath.ToSlash(filepath.Clean("/" + key))[1:]
+	if clean == "" || clean != key || strings.HasPrefix(clean, ".") || strings.Contains(clean, "/.") {
+		return "", ErrObjectNotFound
+	}
+	return filepath.Join(c.root, filepath.FromSlash(clean)), nil
+}
+
+func (c *localClient) uploadDir(uploadID string) (string, error) {
+	if _, err := uuid.Parse(uploadID); err != nil {
+		return "", fmt.Errorf("invalid upload id: %s", uploadID)
+	}
+	return filepath.Join(c.root, localUploadsDir, uploadID), nil
+}
+
+func (c *localClient) readMeta(key string) localMeta {
+	var meta localMeta
+	if raw, err := os.ReadFile(filepath.Join(c.root, localMetaDir, filepath.FromSlash(key))); err == nil {
+		_ = json.Unmarshal(raw, &meta)
+	}
+	return meta
+}
+
+func (c *localClient) writeMeta(key string, meta localMeta) error {
+	path := filepath.Join(c.root, localMetaDir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
+
+func (c *localClient) sign(key, contentType string, size, expires int64) string {
+	mac := hmac.New(sha256.New, c.secret)
+	fmt.Fprintf(mac, "PUT\n%s\n%s\n%d\n%d", key, contentType, size, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (c *localClient) signGet(key string, expires int64) string {
+	mac := hmac.New(sha256.New, c.secret)
+	fmt.Fprintf(mac, "GET\n%s\n%d", key, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func partFileName(partNumber int32) string {
+	return fmt.Sprintf("%05d", partNumber)
+}
@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memoryObject struct {
+	data []byte
+	info ObjectInfo
+}
+
+type memoryUpload struct {
+	contentType string
+	parts       map[int32][]byte
+}
+
+// memoryClient keeps objects in process memory, for tests. Nothing survives
+// a restart and nothing serves its URLs, so it is not a selectable backend.
+type memoryClient struct {
+	baseURL string
+
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+	uploads map[string]*memoryUpload
+}
+
+// NewMemoryClient creates an in-memory storage client
+func NewMemoryClient(baseURL string) Client {
+	if baseURL == "" {
+		baseURL = "memory://"
+	}
+
+	return &memoryClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		objects: make(map[string]memoryObject),
+		uploads: make(map[string]*memoryUpload),
+	}
+}
+
+func (c *memoryClient) Put(ctx context.Context, key string, body io.Reader, size int64, opts PutOptions) (*ObjectInfo, error) {
+	data, err := io.ReadAll(io.LimitReader(body, size))
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+	if int64(len(data)) != size {
+		return nil, fmt.Errorf("failed to upload file: short write: got %d of %d bytes", len(data), size)
+	}
+
+	info := ObjectInfo{
+		Key:          key,
+		Size:         size,
+		ContentType:  opts.ContentType,
+		Metadata:     copyMetadata(opts.Metadata),
+		LastModified: time.Now(),
+	}
+
+	c.mu.Lock()
+	c.objects[key] = memoryObject{data: data, info: info}
+	c.mu.Unlock()
+
+	return &info, nil
+}
+
+func (c *memoryClient) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	c.mu.RLock()
+	obj, ok := c.objects[key]
+	c.mu.RUnlock()
+
+	if !ok {
+		return nil, nil, ErrObjectNotFound
+	}
+
+	info := obj.info
+	return io.NopCloser(bytes.NewReader(obj.data)), &info, nil
+}
+
+func (c *memoryClient) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	c.mu.RLock()
+	obj, ok := c.objects[key]
+	c.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+
+	info := obj.info
+	return &info, nil
+}
+
+func (c *memoryClient) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	c.mu.RLock()
+	infos := make([]ObjectInfo, 0, len(c.objects))
+	for key, obj := range c.objects {
+		if strings.HasPrefix(key, prefix) {
+			infos = append(infos, obj.info)
+		}
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+	for _, info := range infos {
+		if err := fn(info); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *memoryClient) Copy(ctx context.Context, srcKey, dstKey string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	obj, ok := c.objects[srcKey]
+	if !ok {
+		return ErrObjectNotFound
+	}
+
+	obj.info.Key = dstKey
+	obj.info.LastModified = time.Now()
+	obj.info.Metadata = copyMetadata(obj.info.Metadata)
+	c.objects[dstKey] = obj
+
+	return nil
+}
+
+func (c *memoryClient) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	delete(c.objects, key)
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (c *memoryClient) GetURL(key string) string {
+	return fmt.Sprintf("%s/%s", c.baseURL, key)
+}
+
+func (c *memoryClient) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return fmt.Sprintf("%s?expires=%d", c.GetURL(key), time.Now().Add(expiry).Unix()), nil
+}
+
+func (c *memoryClient) PresignUpload(ctx context.Context, key, contentType string, size int64, expiry time.Duration) (*PresignedUpload, error) {
+	return nil, ErrNotSupported
+}
+
+func (c *memoryClient) CreateMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
+	uploadID := uuid.New().String()
+
+	c.mu.Lock()
+	c.uploads[uploadID] = &memoryUpload{contentType: contentType, parts: make(map[int32][]byte)}
+	c.mu.Unlock()
+
+	return uploadID, nil
+}
+
+func (c *memoryClient) UploadPart(ctx context.Context, key, uploadID string, partNumber int32, body io.Reader, size int64) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(body, size))
+	if err != nil {
+		return "", fmt.Errorf("failed to upload part: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	upload, ok := c.uploads[uploadID]
+	if !ok {
+		return "", fmt.Errorf("unknown upload id: %s", uploadID)
+	}
+	upload.parts[partNumber] = data
+
+	return fmt.Sprintf("%s-%d", uploadID, partNumber), nil
+}
+
+func (c *memoryClient) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []CompletedPart) error {
+	c.mu.Lock()
+	upload, ok := c.uploads[uploadID]
+	delete(c.uploads, uploadID)
+	c.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("unknown upload id: %s", uploadID)
+	}
+
+	sorted := make([]CompletedPart, len(parts))
+	copy(sorted, parts)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].PartNumber < sorted[j].PartNumber })
+
+	var buf bytes.Buffer
+	for _, p := range sorted {
+		data, ok := upload.parts[p.PartNumber]
+		if !ok {
+			return fmt.Errorf("missing part %d", p.PartNumber)
+		}
+		buf.Write(data)
+	}
+
+	_, err := c.Put(ctx, key, &buf, int64(buf.Len()), PutOptions{ContentType: upload.contentType})
+	return err
+}
+
+func (c *memoryClient) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	c.mu.Lock()
+	delete(c.uploads, uploadID)
+	c.mu.Unlock()
+
+	return nil
+}
+
+func copyMetadata(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
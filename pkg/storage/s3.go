@@ -1,27 +1,28 @@
 package storage
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	appconfig "bamboo-rescue/internal/config"
 	"go.uber.org/zap"
 )
 
-// Client defines the interface for storage operations
-type Client interface {
-	Upload(ctx context.Context, key string, data []byte, contentType string) (string, error)
-	Delete(ctx context.Context, key string) error
-	GetURL(key string) string
-}
-
 type s3Client struct {
 	client    *s3.Client
+	presign   *s3.PresignClient
 	bucket    string
 	publicURL string
 	log       *zap.Logger
@@ -30,8 +31,7 @@ type s3Client struct {
 // NewS3Client creates a new S3-compatible storage client
 func NewS3Client(cfg *appconfig.Config, log *zap.Logger) (Client, error) {
 	if cfg.S3.Endpoint == "" {
-		log.Warn("S3 endpoint not configured, using mock storage")
-		return &mockClient{log: log}, nil
+		return nil, errors.New("S3 endpoint not configured")
 	}
 
 	customResolver := aws.EndpointResolverWithOptionsFunc(func(service, region string, options ...interface{}) (aws.Endpoint, error) {
@@ -64,27 +64,105 @@ func NewS3Client(cfg *appconfig.Config, log *zap.Logger) (Client, error) {
 
 	return &s3Client{
 		client:    client,
+		presign:   s3.NewPresignClient(client),
 		bucket:    cfg.S3.Bucket,
 		publicURL: publicURL,
 		log:       log,
 	}, nil
 }
 
-func (c *s3Client) Upload(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+func (c *s3Client) Put(ctx context.Context, key string, body io.Reader, size int64, opts PutOptions) (*ObjectInfo, error) {
 	input := &s3.PutObjectInput{
-		Bucket:      aws.String(c.bucket),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader(data),
-		ContentType: aws.String(contentType),
+		Bucket:        aws.String(c.bucket),
+		Key:           aws.String(key),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+		ContentType:   aws.String(opts.ContentType),
+		Metadata:      opts.Metadata,
 	}
 
-	_, err := c.client.PutObject(ctx, input)
+	_, err := c.client.PutObject(ctx, input, unsignedIfUnseekable(body))
 	if err != nil {
 		c.log.Error("Failed to upload to S3", zap.String("key", key), zap.Error(err))
-		return "", fmt.Errorf("failed to upload file: %w", err)
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	return &ObjectInfo{
+		Key:          key,
+		Size:         size,
+		ContentType:  opts.ContentType,
+		Metadata:     opts.Metadata,
+		LastModified: time.Now(),
+	}, nil
+}
+
+func (c *s3Client) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	}
+
+	output, err := c.client.GetObject(ctx, input)
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, nil, ErrObjectNotFound
+		}
+		c.log.Error("Failed to get S3 object", zap.String("key", key), zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to get file: %w", err)
+	}
+
+	return output.Body, &ObjectInfo{
+		Key:          key,
+		Size:         aws.ToInt64(output.ContentLength),
+		ContentType:  aws.ToString(output.ContentType),
+		Metadata:     output.Metadata,
+		LastModified: aws.ToTime(output.LastModified),
+	}, nil
+}
+
+func (c *s3Client) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	paginator := s3.NewListObjectsV2Paginator(c.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(c.bucket),
+		Prefix: aws.String(prefix),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			c.log.Error("Failed to list S3 objects", zap.String("prefix", prefix), zap.Error(err))
+			return fmt.Errorf("failed to list files: %w", err)
+		}
+
+		for _, obj := range page.Contents {
+			if err := fn(ObjectInfo{
+				Key:          aws.ToString(obj.Key),
+				Size:         aws.ToInt64(obj.Size),
+				LastModified: aws.ToTime(obj.LastModified),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *s3Client) Copy(ctx context.Context, srcKey, dstKey string) error {
+	input := &s3.CopyObjectInput{
+		Bucket:     aws.String(c.bucket),
+		Key:        aws.String(dstKey),
+		CopySource: aws.String(url.PathEscape(c.bucket + "/" + srcKey)),
 	}
 
-	return c.GetURL(key), nil
+	if _, err := c.client.CopyObject(ctx, input); err != nil {
+		if isS3NotFound(err) {
+			return ErrObjectNotFound
+		}
+		c.log.Error("Failed to copy S3 object", zap.String("src", srcKey), zap.String("dst", dstKey), zap.Error(err))
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+
+	return nil
 }
 
 func (c *s3Client) Delete(ctx context.Context, key string) error {
@@ -106,21 +184,167 @@ func (c *s3Client) GetURL(key string) string {
 	return fmt.Sprintf("%s/%s/%s", c.publicURL, c.bucket, key)
 }
 
-// Mock client for development
-type mockClient struct {
-	log *zap.Logger
+func (c *s3Client) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	}
+
+	req, err := c.presign.PresignGetObject(ctx, input, s3.WithPresignExpires(expiry))
+	if err != nil {
+		c.log.Error("Failed to presign S3 download", zap.String("key", key), zap.Error(err))
+		return "", fmt.Errorf("failed to presign download: %w", err)
+	}
+
+	return req.URL, nil
 }
 
-func (c *mockClient) Upload(ctx context.Context, key string, data []byte, contentType string) (string, error) {
-	c.log.Debug("Mock upload", zap.String("key", key), zap.Int("size", len(data)))
-	return fmt.Sprintf("http://localhost:8080/mock-storage/%s", key), nil
+// PresignUpload returns a presigned PUT URL. Content-Length is part of the
+// signature, so storage rejects any body that differs from the declared size.
+func (c *s3Client) PresignUpload(ctx context.Context, key, contentType string, size int64, expiry time.Duration) (*PresignedUpload, error) {
+	input := &s3.PutObjectInput{
+		Bucket:        aws.String(c.bucket),
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	}
+
+	req, err := c.presign.PresignPutObject(ctx, input, s3.WithPresignExpires(expiry))
+	if err != nil {
+		c.log.Error("Failed to presign S3 upload", zap.String("key", key), zap.Error(err))
+		return nil, fmt.Errorf("failed to presign upload: %w", err)
+	}
+
+	headers := make(map[string]string, len(req.SignedHeader))
+	for name, values := range req.SignedHeader {
+		if name == "Host" || len(values) == 0 {
+			continue
+		}
+		headers[name] = values[0]
+	}
+
+	return &PresignedUpload{
+		URL:       req.URL,
+		Method:    req.Method,
+		Headers:   headers,
+		ExpiresAt: time.Now().Add(expiry),
+	}, nil
+}
+
+func (c *s3Client) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	}
+
+	output, err := c.client.HeadObject(ctx, input)
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, ErrObjectNotFound
+		}
+		c.log.Error("Failed to head S3 object", zap.String("key", key), zap.Error(err))
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	return &ObjectInfo{
+		Key:          key,
+		Size:         aws.ToInt64(output.ContentLength),
+		ContentType:  aws.ToString(output.ContentType),
+		Metadata:     output.Metadata,
+		LastModified: aws.ToTime(output.LastModified),
+	}, nil
 }
 
-func (c *mockClient) Delete(ctx context.Context, key string) error {
-	c.log.Debug("Mock delete", zap.String("key", key))
+func (c *s3Client) CreateMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
+	input := &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(c.bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	}
+
+	output, err := c.client.CreateMultipartUpload(ctx, input)
+	if err != nil {
+		c.log.Error("Failed to create S3 multipart upload", zap.String("key", key), zap.Error(err))
+		return "", fmt.Errorf("failed to create multipart upload: %w", err)
+	}
+
+	return aws.ToString(output.UploadId), nil
+}
+
+func (c *s3Client) UploadPart(ctx context.Context, key, uploadID string, partNumber int32, body io.Reader, size int64) (string, error) {
+	input := &s3.UploadPartInput{
+		Bucket:        aws.String(c.bucket),
+		Key:           aws.String(key),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(partNumber),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+	}
+
+	output, err := c.client.UploadPart(ctx, input, unsignedIfUnseekable(body))
+	if err != nil {
+		c.log.Error("Failed to upload S3 part",
+			zap.String("key", key),
+			zap.Int32("part", partNumber),
+			zap.Error(err),
+		)
+		return "", fmt.Errorf("failed to upload part: %w", err)
+	}
+
+	return aws.ToString(output.ETag), nil
+}
+
+func (c *s3Client) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []CompletedPart) error {
+	completed := make([]types.CompletedPart, len(parts))
+	for i, p := range parts {
+		completed[i] = types.CompletedPart{
+			PartNumber: aws.Int32(p.PartNumber),
+			ETag:       aws.String(p.ETag),
+		}
+	}
+
+	input := &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(c.bucket),
+		Key:             aws.String(key),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	}
+
+	if _, err := c.client.CompleteMultipartUpload(ctx, input); err != nil {
+		c.log.Error("Failed to complete S3 multipart upload", zap.String("key", key), zap.Error(err))
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+
 	return nil
 }
 
-func (c *mockClient) GetURL(key string) string {
-	return fmt.Sprintf("http://localhost:8080/mock-storage/%s", key)
+func (c *s3Client) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	input := &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(c.bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	}
+
+	if _, err := c.client.AbortMultipartUpload(ctx, input); err != nil {
+		c.log.Error("Failed to abort S3 multipart upload", zap.String("key", key), zap.Error(err))
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
+	}
+
+	return nil
+}
+
+func isS3NotFound(err error) bool {
+	var respErr *awshttp.ResponseError
+	return errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusNotFound
+}
+
+// unsignedIfUnseekable skips payload hashing for bodies that cannot be
+// rewound, so they stream straight through instead of being buffered
+func unsignedIfUnseekable(body io.Reader) func(*s3.Options) {
+	return func(o *s3.Options) {
+		if _, ok := body.(io.Seeker); ok {
+			return
+		}
+		o.APIOptions = append(o.APIOptions, v4.SwapComputePayloadSHA256ForUnsignedPayloadMiddleware)
+	}
 }
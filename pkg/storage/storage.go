@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	appconfig "bamboo-rescue/internal/config"
+	"go.uber.org/zap"
+)
+
+// Backend names selectable through configuration
+const (
+	BackendS3    = "s3"
+	BackendLocal = "local"
+)
+
+// Client defines the interface for storage operations
+type Client interface {
+	// Put streams exactly size bytes from body into key
+	Put(ctx context.Context, key string, body io.Reader, size int64, opts PutOptions) (*ObjectInfo, error)
+	// Get opens key for reading; the caller closes the returned reader
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// List calls fn for every object under prefix, stopping at the first error
+	List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error
+	Copy(ctx context.Context, srcKey, dstKey string) error
+	Delete(ctx context.Context, key string) error
+	GetURL(key string) string
+	// PresignGet returns a URL that grants read access to key until expiry
+	PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error)
+	PresignUpload(ctx context.Context, key, contentType string, size int64, expiry time.Duration) (*PresignedUpload, error)
+
+	// Multipart uploads for large files assembled from chunks
+	CreateMultipartUpload(ctx context.Context, key, contentType string) (string, error)
+	UploadPart(ctx context.Context, key, uploadID string, partNumber int32, body io.Reader, size int64) (string, error)
+	CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []CompletedPart) error
+	AbortMultipartUpload(ctx context.Context, key, uploadID string) error
+}
+
+const (
+	// MinPartSize is the smallest part S3 accepts for every part but the last
+	MinPartSize = 5 * 1024 * 1024
+
+	// PrivatePrefix marks keys that are never publicly readable and are only
+	// reachable through signed URLs
+	PrivatePrefix = "private/"
+)
+
+var (
+	// ErrObjectNotFound is returned when an object does not exist in storage
+	ErrObjectNotFound = errors.New("object not found")
+	// ErrNotSupported is returned when a backend cannot perform an operation
+	ErrNotSupported = errors.New("operation not supported by storage backend")
+)
+
+// PutOptions describes an object being written
+type PutOptions struct {
+	ContentType string
+	Metadata    map[string]string
+}
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	Metadata     map[string]string
+	LastModified time.Time
+}
+
+// PresignedUpload describes a direct-to-storage upload the client performs itself
+type PresignedUpload struct {
+	URL       string
+	Method    string
+	Headers   map[string]string
+	ExpiresAt time.Time
+}
+
+// CompletedPart identifies an uploaded part of a multipart upload
+type CompletedPart struct {
+	PartNumber int32
+	ETag       string
+}
+
+// NewClient creates the storage client selected by configuration
+func NewClient(cfg *appconfig.Config, log *zap.Logger) (Client, error) {
+	return NewBackend(cfg, cfg.Storage.Backend, log)
+}
+
+// NewBackend creates a storage client for the named backend
+func NewBackend(cfg *appconfig.Config, backend string, log *zap.Logger) (Client, error) {
+	switch backend {
+	case BackendS3:
+		return NewS3Client(cfg, log)
+	case BackendLocal:
+		return NewLocalClient(cfg.Storage.LocalDir, cfg.Storage.LocalURL, []byte(cfg.Storage.SigningSecret), cfg.Storage.Private, log)
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %q", backend)
+	}
+}
+
+// Transfer copies a single object from src to dst, preserving its metadata
+func Transfer(ctx context.Context, src, dst Client, key string) (*ObjectInfo, error) {
+	body, info, err := src.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return dst.Put(ctx, key, body, info.Size, PutOptions{
+		ContentType: info.ContentType,
+		Metadata:    info.Metadata,
+	})
+}
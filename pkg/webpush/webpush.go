@@ -0,0 +1,357 @@
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// recordSize is the aes128gcm record size; a push message is one record
+	recordSize = 4096
+	// MaxPayloadSize is the largest plaintext push services accept: 4096
+	// bytes less the 86-byte header, the GCM tag and the record delimiter
+	MaxPayloadSize = 4096 - 86 - 16 - 1
+
+	// vapidTokenTTL is how long a VAPID JWT is valid; push services reject
+	// tokens valid for more than 24 hours
+	vapidTokenTTL = 12 * time.Hour
+
+	// maxTopicLength is the longest Topic header RFC 8030 allows
+	maxTopicLength = 32
+)
+
+// Urgency values defined by RFC 8030
+const (
+	UrgencyVeryLow = "very-low"
+	UrgencyLow     = "low"
+	UrgencyNormal  = "normal"
+	UrgencyHigh    = "high"
+)
+
+var (
+	// ErrPayloadTooLarge is returned when a payload does not fit in one push message
+	ErrPayloadTooLarge = errors.New("web push payload too large")
+	// ErrInvalidSubscription is returned for subscriptions with malformed keys
+	ErrInvalidSubscription = errors.New("invalid web push subscription")
+)
+
+// Subscription is a browser push subscription as returned by
+// PushSubscription.toJSON(), with keys in unpadded base64url
+type Subscription struct {
+	Endpoint string
+	P256dh   string
+	Auth     string
+}
+
+// Options controls how the push service handles a message
+type Options struct {
+	// TTL is how long the push service keeps the message for an offline browser
+	TTL     time.Duration
+	Urgency string
+	// Topic lets a newer message replace an undelivered one with the same topic
+	Topic string
+}
+
+// StatusError is returned when the push service rejects a message
+type StatusError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("push service returned %d: %s", e.StatusCode, e.Body)
+}
+
+// IsGone reports whether err means the subscription no longer exists and
+// should be deleted
+func IsGone(err error) bool {
+	var se *StatusError
+	return errors.As(err, &se) && (se.StatusCode == http.StatusNotFound || se.StatusCode == http.StatusGone)
+}
+
+// IsTransient reports whether a send may succeed if retried
+func IsTransient(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.StatusCode == http.StatusTooManyRequests || se.StatusCode >= 500
+	}
+	// Network failures reaching the push service
+	return err != nil && !errors.Is(err, ErrPayloadTooLarge) && !errors.Is(err, ErrInvalidSubscription)
+}
+
+// Topic derives a valid Topic header from an arbitrary key. Topics are at
+// most 32 characters of the base64url alphabet, so longer or unsafe keys are
+// hashed.
+func Topic(key string) string {
+	if key == "" {
+		return ""
+	}
+	if len(key) <= maxTopicLength && isBase64URL(key) {
+		return key
+	}
+	sum := sha256.Sum256([]byte(key))
+	return encode(sum[:])[:maxTopicLength]
+}
+
+func isBase64URL(s string) bool {
+	for _, r := range s {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// VAPIDKeys is the application server key pair that identifies us to push services
+type VAPIDKeys struct {
+	private *ecdsa.PrivateKey
+	// PublicKey is the uncompressed public key in unpadded base64url, the
+	// applicationServerKey browsers subscribe with
+	PublicKey string
+}
+
+// GenerateVAPIDKeys creates a new key pair, returning both keys in unpadded base64url
+func GenerateVAPIDKeys() (privateKey, publicKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return encode(key.Bytes()), encode(key.PublicKey().Bytes()), nil
+}
+
+// ParseVAPIDKeys loads a key pair from a base64url private key
+func ParseVAPIDKeys(privateKey string) (*VAPIDKeys, error) {
+	raw, err := decode(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	pub := key.PublicKey().Bytes()
+	signer := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(pub[1:33]),
+			Y:     new(big.Int).SetBytes(pub[33:65]),
+		},
+		D: new(big.Int).SetBytes(raw),
+	}
+
+	return &VAPIDKeys{private: signer, PublicKey: encode(pub)}, nil
+}
+
+// Client sends encrypted messages to browser push services
+type Client struct {
+	keys       *VAPIDKeys
+	subject    string
+	httpClient *http.Client
+}
+
+// NewClient creates a new Client. subject is a mailto: or https: contact
+// push services can use to reach the sender.
+func NewClient(keys *VAPIDKeys, subject string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &Client{keys: keys, subject: subject, httpClient: httpClient}
+}
+
+// ValidateSubscription checks that sub has an https endpoint and well-formed keys
+func ValidateSubscription(sub *Subscription) error {
+	u, err := url.Parse(sub.Endpoint)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%w: endpoint must be an https URL", ErrInvalidSubscription)
+	}
+	if _, err := parseUAKeys(sub); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Send encrypts payload for sub and delivers it to the subscription's push service
+func (c *Client) Send(ctx context.Context, sub *Subscription, payload []byte, opts Options) error {
+	body, err := Encrypt(sub, payload)
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSubscription, err)
+	}
+	token, err := c.vapidToken(endpoint.Scheme + "://" + endpoint.Host)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(opts.TTL.Seconds())))
+	req.Header.Set("Authorization", "vapid t="+token+", k="+c.keys.PublicKey)
+	if opts.Urgency != "" {
+		req.Header.Set("Urgency", opts.Urgency)
+	}
+	if opts.Topic != "" {
+		req.Header.Set("Topic", opts.Topic)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	se := &StatusError{StatusCode: resp.StatusCode, Body: string(msg)}
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		se.RetryAfter = time.Duration(secs) * time.Second
+	}
+	return se
+}
+
+// vapidToken signs the JWT that authorizes us to the push service at audience
+func (c *Client) vapidToken(audience string) (string, error) {
+	claims := jwt.MapClaims{
+		"aud": audience,
+		"exp": time.Now().Add(vapidTokenTTL).Unix(),
+		"sub": c.subject,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodES256, claims).SignedString(c.keys.private)
+}
+
+type uaKeys struct {
+	public *ecdh.PublicKey
+	auth   []byte
+}
+
+func parseUAKeys(sub *Subscription) (*uaKeys, error) {
+	p256dh, err := decode(sub.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("%w: p256dh: %v", ErrInvalidSubscription, err)
+	}
+	pub, err := ecdh.P256().NewPublicKey(p256dh)
+	if err != nil {
+		return nil, fmt.Errorf("%w: p256dh: %v", ErrInvalidSubscription, err)
+	}
+	auth, err := decode(sub.Auth)
+	if err != nil || len(auth) != 16 {
+		return nil, fmt.Errorf("%w: auth secret must be 16 bytes", ErrInvalidSubscription)
+	}
+	return &uaKeys{public: pub, auth: auth}, nil
+}
+
+// Encrypt encrypts payload for sub with the aes128gcm content encoding of
+// RFC 8291, as a single record
+func Encrypt(sub *Subscription, payload []byte) ([]byte, error) {
+	if len(payload) > MaxPayloadSize {
+		return nil, ErrPayloadTooLarge
+	}
+
+	ua, err := parseUAKeys(sub)
+	if err != nil {
+		return nil, err
+	}
+
+	// A fresh key pair per message, as the spec requires
+	asKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := asKey.ECDH(ua.public)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	uaPublic := ua.public.Bytes()
+	asPublic := asKey.PublicKey().Bytes()
+
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublic...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm, err := hkdf.Key(sha256.New, secret, ua.auth, string(keyInfo), 32)
+	if err != nil {
+		return nil, err
+	}
+
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// 0x02 marks the last (and only) record
+	plaintext := append(append([]byte{}, payload...), 0x02)
+
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decode accepts base64url with or without padding, as browsers differ
+func decode(s string) ([]byte, error) {
+	if b, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.URLEncoding.DecodeString(s)
+}